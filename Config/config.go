@@ -1,46 +1,804 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/mail"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"POJECT_UAS/model"
+
+	"gopkg.in/yaml.v3"
 )
 
-// GetJWTSecret mengambil JWT secret dari environment variable
-func GetJWTSecret() string {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "default-secret-key-change-in-production"
+// DefaultJWTSecret secret bawaan untuk development, ditolak saat APP_ENV=production
+const DefaultJWTSecret = "default-secret-key-change-in-production"
+
+const redacted = "******"
+
+// Environment yang dikenali aplikasi
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvProduction  = "production"
+)
+
+// Config konfigurasi lengkap aplikasi
+type Config struct {
+	App        AppConfig        `yaml:"app" json:"app"`
+	Postgres   PostgresConfig   `yaml:"postgres" json:"postgres"`
+	Mongo      MongoConfig      `yaml:"mongo" json:"mongo"`
+	JWT        JWTConfig        `yaml:"jwt" json:"jwt"`
+	Storage    StorageConfig    `yaml:"storage" json:"storage"`
+	Outbox     OutboxConfig     `yaml:"outbox" json:"outbox"`
+	Realtime   RealtimeConfig   `yaml:"realtime" json:"realtime"`
+	Email      EmailConfig      `yaml:"email" json:"email"`
+	Webhook    WebhookConfig    `yaml:"webhook" json:"webhook"`
+	Statistics StatisticsConfig `yaml:"statistics" json:"statistics"`
+	Export     ExportConfig     `yaml:"export" json:"export"`
+	Report     ReportConfig     `yaml:"report" json:"report"`
+	Import     ImportConfig     `yaml:"import" json:"import"`
+}
+
+type AppConfig struct {
+	Env  string `yaml:"env" json:"env"`
+	Port string `yaml:"port" json:"port"`
+	Demo bool   `yaml:"demo" json:"demo"`
+}
+
+type PostgresConfig struct {
+	URL             string        `yaml:"url" json:"url,omitempty"` // jika diisi, field koneksi lain diabaikan
+	Host            string        `yaml:"host" json:"host"`
+	Port            int           `yaml:"port" json:"port"`
+	User            string        `yaml:"user" json:"user"`
+	Password        string        `yaml:"password" json:"password"`
+	Name            string        `yaml:"name" json:"name"`
+	SSLMode         string        `yaml:"sslmode" json:"sslmode"`
+	MaxOpenConns    int           `yaml:"max_open_conns" json:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" json:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" json:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" json:"conn_max_idle_time"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout" json:"connect_timeout"`
+}
+
+type MongoConfig struct {
+	URI                    string        `yaml:"uri" json:"uri"`
+	Database               string        `yaml:"database" json:"database"`
+	MaxPoolSize            uint64        `yaml:"max_pool_size" json:"max_pool_size"`
+	MinPoolSize            uint64        `yaml:"min_pool_size" json:"min_pool_size"`
+	ConnectTimeout         time.Duration `yaml:"connect_timeout" json:"connect_timeout"`
+	ServerSelectionTimeout time.Duration `yaml:"server_selection_timeout" json:"server_selection_timeout"`
+}
+
+type JWTConfig struct {
+	Secret                    string        `yaml:"secret" json:"secret"`
+	AccessTokenTTL            time.Duration `yaml:"access_token_ttl" json:"access_token_ttl"`
+	RefreshTokenTTL           time.Duration `yaml:"refresh_token_ttl" json:"refresh_token_ttl"`
+	RevocationCacheTTL        time.Duration `yaml:"revocation_cache_ttl" json:"revocation_cache_ttl"`
+	RevocationCleanupInterval time.Duration `yaml:"revocation_cleanup_interval" json:"revocation_cleanup_interval"` // jeda pembersihan token dicabut yang kedaluwarsa
+}
+
+// Driver penyimpanan lampiran
+const (
+	StorageLocal = "local"
+	StorageS3    = "s3"
+)
+
+type StorageConfig struct {
+	Driver      string   `yaml:"driver" json:"driver"`
+	LocalDir    string   `yaml:"local_dir" json:"local_dir"`
+	MaxFileSize int64    `yaml:"max_file_size" json:"max_file_size"` // byte per file
+	MaxFiles    int      `yaml:"max_files" json:"max_files"`
+	S3          S3Config `yaml:"s3" json:"s3"`
+}
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" json:"endpoint"`
+	AccessKey string `yaml:"access_key" json:"access_key"`
+	SecretKey string `yaml:"secret_key" json:"secret_key"`
+	Bucket    string `yaml:"bucket" json:"bucket"`
+	Region    string `yaml:"region" json:"region"`
+	UseSSL    bool   `yaml:"use_ssl" json:"use_ssl"`
+}
+
+// OutboxConfig worker yang menjalankan operasi MongoDB dari tabel achievement_outbox
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" json:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size" json:"batch_size"`
+	MaxAttempts  int           `yaml:"max_attempts" json:"max_attempts"` // setelah ini perubahan PostgreSQL dikompensasi
+}
+
+// Broker event realtime (SSE/WebSocket)
+const (
+	RealtimeMemory   = "memory"   // satu replika
+	RealtimePostgres = "postgres" // beberapa replika lewat LISTEN/NOTIFY
+)
+
+type RealtimeConfig struct {
+	Broker string `yaml:"broker" json:"broker"`
+}
+
+// EmailConfig channel email notifikasi. Jika Enabled false, email tidak dimasukkan ke antrean.
+type EmailConfig struct {
+	Enabled      bool          `yaml:"enabled" json:"enabled"`
+	Host         string        `yaml:"host" json:"host"`
+	Port         int           `yaml:"port" json:"port"`
+	Username     string        `yaml:"username" json:"username"`
+	Password     string        `yaml:"password" json:"password"`
+	From         string        `yaml:"from" json:"from"`
+	LinkBaseURL  string        `yaml:"link_base_url" json:"link_base_url"` // URL frontend untuk tautan di email
+	Timeout      time.Duration `yaml:"timeout" json:"timeout"`
+	PollInterval time.Duration `yaml:"poll_interval" json:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size" json:"batch_size"`
+	MaxAttempts  int           `yaml:"max_attempts" json:"max_attempts"`
+}
+
+// WebhookConfig worker pengiriman webhook
+type WebhookConfig struct {
+	Timeout      time.Duration `yaml:"timeout" json:"timeout"` // batas waktu satu request ke penerima
+	PollInterval time.Duration `yaml:"poll_interval" json:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size" json:"batch_size"`
+	MaxAttempts  int           `yaml:"max_attempts" json:"max_attempts"`
+}
+
+// Backend cache statistik
+const (
+	StatsCacheNone     = "none"     // tanpa cache
+	StatsCacheMemory   = "memory"   // LRU di memori tiap replika
+	StatsCachePostgres = "postgres" // tabel statistics_cache, dipakai bersama semua replika
+)
+
+// StatisticsConfig pengaturan statistik prestasi
+type StatisticsConfig struct {
+	// Tanggal mulai semester (MM-DD) untuk period_type=semester
+	SemesterGanjilStart string `yaml:"semester_ganjil_start" json:"semester_ganjil_start"`
+	SemesterGenapStart  string `yaml:"semester_genap_start" json:"semester_genap_start"`
+
+	CacheBackend string        `yaml:"cache_backend" json:"cache_backend"`
+	CacheTTL     time.Duration `yaml:"cache_ttl" json:"cache_ttl"`   // batas umur hasil, juga untuk perubahan selain status
+	CacheSize    int           `yaml:"cache_size" json:"cache_size"` // jumlah entry maksimum untuk backend memory
+}
+
+// AcademicCalendar kalender semester dari SemesterGanjilStart dan SemesterGenapStart
+func (s StatisticsConfig) AcademicCalendar() (model.AcademicCalendar, error) {
+	return model.ParseAcademicCalendar(s.SemesterGanjilStart, s.SemesterGenapStart)
+}
+
+// ExportConfig ekspor daftar prestasi ke CSV/XLSX. Ekspor yang lebih besar dari SyncLimit
+// dijalankan sebagai job dan hasilnya disimpan di storage lampiran selama Retention.
+type ExportConfig struct {
+	SyncLimit    int           `yaml:"sync_limit" json:"sync_limit"` // jumlah baris maksimum yang diunduh langsung
+	BatchSize    int           `yaml:"batch_size" json:"batch_size"` // baris per pengambilan dokumen MongoDB
+	PollInterval time.Duration `yaml:"poll_interval" json:"poll_interval"`
+	Retention    time.Duration `yaml:"retention" json:"retention"`
+	MaxAttempts  int           `yaml:"max_attempts" json:"max_attempts"`
+}
+
+// ReportConfig laporan PDF prestasi mahasiswa yang ditandatangani server
+type ReportConfig struct {
+	// Seed Ed25519 32 byte dalam base64. Jika kosong, kunci diturunkan dari jwt.secret
+	// (hanya untuk development); wajib diisi di production.
+	SigningKey    string `yaml:"signing_key" json:"signing_key"`
+	PublicBaseURL string `yaml:"public_base_url" json:"public_base_url"` // URL API yang bisa diakses publik, untuk QR code verifikasi
+}
+
+// SigningSeed seed kunci tanda tangan laporan
+func (r ReportConfig) SigningSeed(jwtSecret string) ([]byte, error) {
+	if r.SigningKey == "" {
+		seed := sha256.Sum256([]byte("report-signing-key:" + jwtSecret))
+		return seed[:], nil
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(r.SigningKey)
+	if err != nil || len(seed) != 32 {
+		return nil, errors.New("report.signing_key harus seed Ed25519 32 byte dalam base64")
+	}
+	return seed, nil
+}
+
+// ImportConfig import user, mahasiswa dan dosen dari file CSV/XLSX
+type ImportConfig struct {
+	// Program studi yang diterima untuk mahasiswa (tidak membedakan huruf besar/kecil).
+	// Kosong berarti semua program studi diterima.
+	ProgramStudies []string `yaml:"program_studies" json:"program_studies"`
+	MaxRows        int      `yaml:"max_rows" json:"max_rows"` // jumlah baris data maksimum per file
+}
+
+// current konfigurasi aktif, diganti oleh Load
+var current = Default()
+
+// Default konfigurasi bawaan untuk development lokal
+func Default() *Config {
+	return &Config{
+		App: AppConfig{
+			Env:  EnvDevelopment,
+			Port: "8080",
+		},
+		Postgres: PostgresConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "postgres",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectTimeout:  10 * time.Second,
+		},
+		Mongo: MongoConfig{
+			URI:                    "mongodb://localhost:27017",
+			Database:               "uas_prestasi",
+			MaxPoolSize:            100,
+			ConnectTimeout:         10 * time.Second,
+			ServerSelectionTimeout: 10 * time.Second,
+		},
+		JWT: JWTConfig{
+			Secret:                    DefaultJWTSecret,
+			AccessTokenTTL:            15 * time.Minute,
+			RefreshTokenTTL:           30 * 24 * time.Hour,
+			RevocationCacheTTL:        30 * time.Second,
+			RevocationCleanupInterval: time.Hour,
+		},
+		Storage: StorageConfig{
+			Driver:      StorageLocal,
+			LocalDir:    "uploads",
+			MaxFileSize: 5 << 20,
+			MaxFiles:    5,
+			S3: S3Config{
+				Bucket: "prestasi-attachments",
+				UseSSL: true,
+			},
+		},
+		Outbox: OutboxConfig{
+			PollInterval: 5 * time.Second,
+			BatchSize:    50,
+			MaxAttempts:  10,
+		},
+		Realtime: RealtimeConfig{
+			Broker: RealtimeMemory,
+		},
+		Email: EmailConfig{
+			Host:         "localhost",
+			Port:         1025,
+			From:         "Sistem Prestasi <no-reply@localhost>",
+			LinkBaseURL:  "http://localhost:3000",
+			Timeout:      10 * time.Second,
+			PollInterval: 10 * time.Second,
+			BatchSize:    20,
+			MaxAttempts:  8,
+		},
+		Webhook: WebhookConfig{
+			Timeout:      10 * time.Second,
+			PollInterval: 5 * time.Second,
+			BatchSize:    20,
+			MaxAttempts:  10,
+		},
+		Statistics: StatisticsConfig{
+			SemesterGanjilStart: "08-01",
+			SemesterGenapStart:  "02-01",
+			CacheBackend:        StatsCacheMemory,
+			CacheTTL:            5 * time.Minute,
+			CacheSize:           1000,
+		},
+		Export: ExportConfig{
+			SyncLimit:    5000,
+			BatchSize:    500,
+			PollInterval: 5 * time.Second,
+			Retention:    24 * time.Hour,
+			MaxAttempts:  3,
+		},
+		Report: ReportConfig{
+			PublicBaseURL: "http://localhost:8080",
+		},
+		Import: ImportConfig{
+			MaxRows: 2000,
+		},
+	}
+}
+
+// Load membaca konfigurasi dengan prioritas: default < file YAML < environment variable < flag.
+// File YAML dipilih lewat flag --config atau env CONFIG_FILE. Argumen sisa (subcommand) dikembalikan.
+func Load(args []string) (*Config, []string, error) {
+	LoadEnv()
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path file konfigurasi YAML")
+	env := fs.String("env", "", "environment aplikasi (development, test, production)")
+	port := fs.String("port", "", "port HTTP server")
+	demo := fs.Bool("demo", false, "jalankan server demo dengan user in-memory (tanpa PostgreSQL/MongoDB)")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	cfg := Default()
+
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, nil, err
+	}
+
+	// Flag hanya menimpa jika benar-benar diberikan
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "env":
+			cfg.App.Env = *env
+		case "port":
+			cfg.App.Port = *port
+		case "demo":
+			cfg.App.Demo = *demo
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	current = cfg
+	return cfg, fs.Args(), nil
+}
+
+// Current mengembalikan konfigurasi aktif
+func Current() *Config {
+	return current
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("gagal membaca file konfigurasi %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("gagal membaca file konfigurasi %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	var errs []error
+
+	setString(&c.App.Env, "APP_ENV")
+	setString(&c.App.Port, "APP_PORT")
+	errs = append(errs, setBool(&c.App.Demo, "APP_DEMO"))
+
+	setString(&c.Postgres.URL, "DB_DSN")
+	setString(&c.Postgres.URL, "DATABASE_URL")
+	setString(&c.Postgres.Host, "DB_HOST")
+	errs = append(errs, setInt(&c.Postgres.Port, "DB_PORT"))
+	setString(&c.Postgres.User, "DB_USER")
+	setString(&c.Postgres.Password, "DB_PASSWORD")
+	setString(&c.Postgres.Name, "DB_NAME")
+	setString(&c.Postgres.SSLMode, "DB_SSLMODE")
+	errs = append(errs,
+		setInt(&c.Postgres.MaxOpenConns, "DB_MAX_OPEN_CONNS"),
+		setInt(&c.Postgres.MaxIdleConns, "DB_MAX_IDLE_CONNS"),
+		setDuration(&c.Postgres.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME"),
+		setDuration(&c.Postgres.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME"),
+		setDuration(&c.Postgres.ConnectTimeout, "DB_CONNECT_TIMEOUT"),
+	)
+
+	setString(&c.Mongo.URI, "MONGO_URI")
+	setString(&c.Mongo.Database, "MONGO_DATABASE")
+	errs = append(errs,
+		setUint(&c.Mongo.MaxPoolSize, "MONGO_MAX_POOL_SIZE"),
+		setUint(&c.Mongo.MinPoolSize, "MONGO_MIN_POOL_SIZE"),
+		setDuration(&c.Mongo.ConnectTimeout, "MONGO_CONNECT_TIMEOUT"),
+		setDuration(&c.Mongo.ServerSelectionTimeout, "MONGO_SERVER_SELECTION_TIMEOUT"),
+	)
+
+	setString(&c.JWT.Secret, "JWT_SECRET")
+	errs = append(errs,
+		setDuration(&c.JWT.AccessTokenTTL, "JWT_ACCESS_TOKEN_TTL"),
+		setDuration(&c.JWT.RefreshTokenTTL, "JWT_REFRESH_TOKEN_TTL"),
+		setDuration(&c.JWT.RevocationCacheTTL, "JWT_REVOCATION_CACHE_TTL"),
+		setDuration(&c.JWT.RevocationCleanupInterval, "JWT_REVOCATION_CLEANUP_INTERVAL"),
+	)
+
+	setString(&c.Storage.Driver, "STORAGE_DRIVER")
+	setString(&c.Storage.LocalDir, "STORAGE_LOCAL_DIR")
+	errs = append(errs,
+		setInt64(&c.Storage.MaxFileSize, "STORAGE_MAX_FILE_SIZE"),
+		setInt(&c.Storage.MaxFiles, "STORAGE_MAX_FILES"),
+	)
+	setString(&c.Storage.S3.Endpoint, "S3_ENDPOINT")
+	setString(&c.Storage.S3.AccessKey, "S3_ACCESS_KEY")
+	setString(&c.Storage.S3.SecretKey, "S3_SECRET_KEY")
+	setString(&c.Storage.S3.Bucket, "S3_BUCKET")
+	setString(&c.Storage.S3.Region, "S3_REGION")
+	errs = append(errs, setBool(&c.Storage.S3.UseSSL, "S3_USE_SSL"))
+
+	errs = append(errs,
+		setDuration(&c.Outbox.PollInterval, "OUTBOX_POLL_INTERVAL"),
+		setInt(&c.Outbox.BatchSize, "OUTBOX_BATCH_SIZE"),
+		setInt(&c.Outbox.MaxAttempts, "OUTBOX_MAX_ATTEMPTS"),
+	)
+
+	setString(&c.Realtime.Broker, "REALTIME_BROKER")
+
+	errs = append(errs, setBool(&c.Email.Enabled, "EMAIL_ENABLED"))
+	setString(&c.Email.Host, "SMTP_HOST")
+	errs = append(errs, setInt(&c.Email.Port, "SMTP_PORT"))
+	setString(&c.Email.Username, "SMTP_USERNAME")
+	setString(&c.Email.Password, "SMTP_PASSWORD")
+	setString(&c.Email.From, "EMAIL_FROM")
+	setString(&c.Email.LinkBaseURL, "EMAIL_LINK_BASE_URL")
+	errs = append(errs,
+		setDuration(&c.Email.Timeout, "SMTP_TIMEOUT"),
+		setDuration(&c.Email.PollInterval, "EMAIL_POLL_INTERVAL"),
+		setInt(&c.Email.BatchSize, "EMAIL_BATCH_SIZE"),
+		setInt(&c.Email.MaxAttempts, "EMAIL_MAX_ATTEMPTS"),
+	)
+
+	errs = append(errs,
+		setDuration(&c.Webhook.Timeout, "WEBHOOK_TIMEOUT"),
+		setDuration(&c.Webhook.PollInterval, "WEBHOOK_POLL_INTERVAL"),
+		setInt(&c.Webhook.BatchSize, "WEBHOOK_BATCH_SIZE"),
+		setInt(&c.Webhook.MaxAttempts, "WEBHOOK_MAX_ATTEMPTS"),
+	)
+
+	setString(&c.Statistics.SemesterGanjilStart, "STATS_SEMESTER_GANJIL_START")
+	setString(&c.Statistics.SemesterGenapStart, "STATS_SEMESTER_GENAP_START")
+	setString(&c.Statistics.CacheBackend, "STATS_CACHE_BACKEND")
+	errs = append(errs,
+		setDuration(&c.Statistics.CacheTTL, "STATS_CACHE_TTL"),
+		setInt(&c.Statistics.CacheSize, "STATS_CACHE_SIZE"),
+	)
+
+	errs = append(errs,
+		setInt(&c.Export.SyncLimit, "EXPORT_SYNC_LIMIT"),
+		setInt(&c.Export.BatchSize, "EXPORT_BATCH_SIZE"),
+		setDuration(&c.Export.PollInterval, "EXPORT_POLL_INTERVAL"),
+		setDuration(&c.Export.Retention, "EXPORT_RETENTION"),
+		setInt(&c.Export.MaxAttempts, "EXPORT_MAX_ATTEMPTS"),
+	)
+
+	setString(&c.Report.SigningKey, "REPORT_SIGNING_KEY")
+	setString(&c.Report.PublicBaseURL, "REPORT_PUBLIC_BASE_URL")
+
+	setStrings(&c.Import.ProgramStudies, "IMPORT_PROGRAM_STUDIES")
+	errs = append(errs, setInt(&c.Import.MaxRows, "IMPORT_MAX_ROWS"))
+
+	return errors.Join(errs...)
+}
+
+// Validate memeriksa konfigurasi sebelum aplikasi dijalankan
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.App.Env {
+	case EnvDevelopment, EnvTest, EnvProduction:
+	default:
+		errs = append(errs, fmt.Errorf("app.env tidak valid: %q", c.App.Env))
+	}
+
+	if port, err := strconv.Atoi(c.App.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("app.port tidak valid: %q", c.App.Port))
 	}
-	return secret
+
+	if c.JWT.Secret == "" {
+		errs = append(errs, errors.New("jwt.secret harus diisi"))
+	}
+	if c.App.Env == EnvProduction && (c.JWT.Secret == DefaultJWTSecret || len(c.JWT.Secret) < 32) {
+		errs = append(errs, errors.New("jwt.secret bawaan atau kurang dari 32 karakter tidak boleh dipakai di production"))
+	}
+	if c.JWT.AccessTokenTTL <= 0 || c.JWT.RefreshTokenTTL <= 0 {
+		errs = append(errs, errors.New("jwt.access_token_ttl dan jwt.refresh_token_ttl harus lebih dari 0"))
+	}
+	if c.JWT.AccessTokenTTL >= c.JWT.RefreshTokenTTL {
+		errs = append(errs, errors.New("jwt.access_token_ttl harus lebih pendek dari jwt.refresh_token_ttl"))
+	}
+	if c.JWT.RevocationCleanupInterval <= 0 {
+		errs = append(errs, errors.New("jwt.revocation_cleanup_interval harus lebih dari 0"))
+	}
+
+	if c.Postgres.URL == "" && c.Postgres.Host == "" {
+		errs = append(errs, errors.New("postgres.url atau postgres.host harus diisi"))
+	}
+	if c.Postgres.MaxOpenConns < 0 || c.Postgres.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("ukuran pool postgres tidak boleh negatif"))
+	}
+	if c.Postgres.MaxOpenConns > 0 && c.Postgres.MaxIdleConns > c.Postgres.MaxOpenConns {
+		errs = append(errs, errors.New("postgres.max_idle_conns tidak boleh melebihi postgres.max_open_conns"))
+	}
+	if c.Postgres.ConnectTimeout <= 0 {
+		errs = append(errs, errors.New("postgres.connect_timeout harus lebih dari 0"))
+	}
+
+	if c.Mongo.URI == "" || c.Mongo.Database == "" {
+		errs = append(errs, errors.New("mongo.uri dan mongo.database harus diisi"))
+	}
+	if c.Mongo.MaxPoolSize > 0 && c.Mongo.MinPoolSize > c.Mongo.MaxPoolSize {
+		errs = append(errs, errors.New("mongo.min_pool_size tidak boleh melebihi mongo.max_pool_size"))
+	}
+	if c.Mongo.ConnectTimeout <= 0 || c.Mongo.ServerSelectionTimeout <= 0 {
+		errs = append(errs, errors.New("timeout mongo harus lebih dari 0"))
+	}
+
+	switch c.Storage.Driver {
+	case StorageLocal:
+		if c.Storage.LocalDir == "" {
+			errs = append(errs, errors.New("storage.local_dir harus diisi untuk driver local"))
+		}
+	case StorageS3:
+		if c.Storage.S3.Endpoint == "" || c.Storage.S3.Bucket == "" {
+			errs = append(errs, errors.New("storage.s3.endpoint dan storage.s3.bucket harus diisi untuk driver s3"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("storage.driver tidak valid: %q", c.Storage.Driver))
+	}
+	if c.Storage.MaxFileSize <= 0 || c.Storage.MaxFiles <= 0 {
+		errs = append(errs, errors.New("storage.max_file_size dan storage.max_files harus lebih dari 0"))
+	}
+
+	if c.Outbox.PollInterval <= 0 || c.Outbox.BatchSize <= 0 || c.Outbox.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("outbox.poll_interval, outbox.batch_size dan outbox.max_attempts harus lebih dari 0"))
+	}
+
+	switch c.Realtime.Broker {
+	case RealtimeMemory, RealtimePostgres:
+	default:
+		errs = append(errs, fmt.Errorf("realtime.broker tidak valid: %q", c.Realtime.Broker))
+	}
+
+	if c.Email.Enabled {
+		if c.Email.Host == "" || c.Email.Port < 1 || c.Email.Port > 65535 {
+			errs = append(errs, errors.New("email.host dan email.port harus valid jika email.enabled"))
+		}
+		if _, err := mail.ParseAddress(c.Email.From); err != nil {
+			errs = append(errs, fmt.Errorf("email.from tidak valid: %q", c.Email.From))
+		}
+		if c.Email.Timeout <= 0 || c.Email.PollInterval <= 0 || c.Email.BatchSize <= 0 || c.Email.MaxAttempts <= 0 {
+			errs = append(errs, errors.New("email.timeout, email.poll_interval, email.batch_size dan email.max_attempts harus lebih dari 0"))
+		}
+	}
+
+	if c.Webhook.Timeout <= 0 || c.Webhook.PollInterval <= 0 || c.Webhook.BatchSize <= 0 || c.Webhook.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("webhook.timeout, webhook.poll_interval, webhook.batch_size dan webhook.max_attempts harus lebih dari 0"))
+	}
+
+	if _, err := c.Statistics.AcademicCalendar(); err != nil {
+		errs = append(errs, fmt.Errorf("statistics: %w", err))
+	}
+	switch c.Statistics.CacheBackend {
+	case StatsCacheNone:
+	case StatsCacheMemory, StatsCachePostgres:
+		if c.Statistics.CacheTTL <= 0 || c.Statistics.CacheSize <= 0 {
+			errs = append(errs, errors.New("statistics.cache_ttl dan statistics.cache_size harus lebih dari 0"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("statistics.cache_backend tidak valid: %q", c.Statistics.CacheBackend))
+	}
+
+	if c.Export.SyncLimit < 0 {
+		errs = append(errs, errors.New("export.sync_limit tidak boleh negatif"))
+	}
+	if c.Export.BatchSize <= 0 || c.Export.PollInterval <= 0 || c.Export.Retention <= 0 || c.Export.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("export.batch_size, export.poll_interval, export.retention dan export.max_attempts harus lebih dari 0"))
+	}
+
+	if _, err := c.Report.SigningSeed(c.JWT.Secret); err != nil {
+		errs = append(errs, err)
+	}
+	if c.App.Env == EnvProduction && c.Report.SigningKey == "" {
+		errs = append(errs, errors.New("report.signing_key wajib diisi di production"))
+	}
+	if u, err := url.Parse(c.Report.PublicBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("report.public_base_url tidak valid: %q", c.Report.PublicBaseURL))
+	}
+
+	if c.Import.MaxRows <= 0 {
+		errs = append(errs, errors.New("import.max_rows harus lebih dari 0"))
+	}
+	for _, programStudy := range c.Import.ProgramStudies {
+		if strings.TrimSpace(programStudy) == "" {
+			errs = append(errs, errors.New("import.program_studies tidak boleh berisi nama kosong"))
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsProduction true jika aplikasi berjalan di production
+func (c *Config) IsProduction() bool {
+	return c.App.Env == EnvProduction
 }
 
-// GetDatabaseURL mengambil database URL dari environment variable
-func GetDatabaseURL() string {
-	return os.Getenv("DATABASE_URL")
+// UsesDefaultJWTSecret true jika secret JWT masih bawaan (hanya boleh di development/test)
+func (c *Config) UsesDefaultJWTSecret() bool {
+	return c.JWT.Secret == DefaultJWTSecret
 }
 
-// GetAppPort mengambil port aplikasi dari environment variable
+// Redacted salinan konfigurasi dengan semua secret disamarkan, aman untuk di-log
+func (c *Config) Redacted() Config {
+	out := *c
+	if out.Postgres.Password != "" {
+		out.Postgres.Password = redacted
+	}
+	out.Postgres.URL = redactURL(out.Postgres.URL)
+	out.Mongo.URI = redactURL(out.Mongo.URI)
+	if out.JWT.Secret != "" {
+		out.JWT.Secret = redacted
+	}
+	if out.Storage.S3.SecretKey != "" {
+		out.Storage.S3.SecretKey = redacted
+	}
+	if out.Email.Password != "" {
+		out.Email.Password = redacted
+	}
+	if out.Report.SigningKey != "" {
+		out.Report.SigningKey = redacted
+	}
+	return out
+}
+
+// String representasi JSON tanpa secret
+func (c *Config) String() string {
+	data, err := json.Marshal(c.Redacted())
+	if err != nil {
+		return "<invalid config>"
+	}
+	return string(data)
+}
+
+// DSN connection string PostgreSQL
+func (p PostgresConfig) DSN() string {
+	if p.URL != "" {
+		return p.URL
+	}
+
+	parts := []string{
+		"host=" + dsnValue(p.Host),
+		"port=" + strconv.Itoa(p.Port),
+		"user=" + dsnValue(p.User),
+		"dbname=" + dsnValue(p.Name),
+		"sslmode=" + dsnValue(p.SSLMode),
+	}
+	if p.Password != "" {
+		parts = append(parts, "password="+dsnValue(p.Password))
+	}
+	if p.ConnectTimeout > 0 {
+		parts = append(parts, "connect_timeout="+strconv.Itoa(int(p.ConnectTimeout.Seconds())))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// dsnValue memberi kutip pada nilai DSN key=value sesuai aturan libpq: nilai kosong atau yang
+// berisi spasi, kutip atau backslash dikutip tunggal dengan ' dan \ di-escape dengan backslash
+func dsnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r\f\v'\\") {
+		return value
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + replacer.Replace(value) + "'"
+}
+
+// redactURL menyamarkan password di dalam URL koneksi
+func redactURL(raw string) string {
+	if raw == "" {
+		return raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+
+	return u.String()
+}
+
+// GetJWTSecret mengambil JWT secret dari konfigurasi aktif
+func GetJWTSecret() string {
+	return current.JWT.Secret
+}
+
+// GetAppPort mengambil port aplikasi dari konfigurasi aktif
 func GetAppPort() string {
-	port := os.Getenv("APP_PORT")
-	if port == "" {
-		port = "8080"
+	return current.App.Port
+}
+
+func setString(target *string, key string) {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		*target = value
+	}
+}
+
+// setStrings daftar dipisah koma, misalnya "Teknik Informatika,Sistem Informasi"
+func setStrings(target *[]string, key string) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return
+	}
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
 	}
-	return port
+	*target = values
 }
 
-// GetMongoURI mengambil MongoDB URI dari environment variable
-func GetMongoURI() string {
-	uri := os.Getenv("MONGO_URI")
-	if uri == "" {
-		uri = "mongodb://localhost:27017"
+func setBool(target *bool, key string) error {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%s tidak valid: %w", key, err)
 	}
-	return uri
+	*target = parsed
+	return nil
 }
 
-// GetMongoDatabase mengambil MongoDB database name dari environment variable
-func GetMongoDatabase() string {
-	dbName := os.Getenv("MONGO_DATABASE")
-	if dbName == "" {
-		dbName = "uas_prestasi"
+func setInt(target *int, key string) error {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return nil
 	}
-	return dbName
-}
\ No newline at end of file
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s tidak valid: %w", key, err)
+	}
+	*target = parsed
+	return nil
+}
+
+func setInt64(target *int64, key string) error {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s tidak valid: %w", key, err)
+	}
+	*target = parsed
+	return nil
+}
+
+func setUint(target *uint64, key string) error {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s tidak valid: %w", key, err)
+	}
+	*target = parsed
+	return nil
+}
+
+func setDuration(target *time.Duration, key string) error {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s tidak valid: %w", key, err)
+	}
+	*target = parsed
+	return nil
+}
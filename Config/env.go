@@ -1,14 +1,18 @@
 package config
 
 import (
+	"errors"
+	"io/fs"
 	"log"
 
 	"github.com/joho/godotenv"
 )
 
+// LoadEnv memuat file .env jika ada. File .env opsional; environment variable
+// yang sudah di-set tidak ditimpa.
 func LoadEnv() {
 	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Println("Gagal membaca file .env:", err)
 	}
-}
\ No newline at end of file
+}
@@ -3,18 +3,24 @@ package config
 import (
 	"context"
 	"log"
-	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func InitMongoDB() *mongo.Database {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func InitMongoDB(cfg MongoConfig) *mongo.Database {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
 	defer cancel()
 
+	clientOptions := options.Client().
+		ApplyURI(cfg.URI).
+		SetMaxPoolSize(cfg.MaxPoolSize).
+		SetMinPoolSize(cfg.MinPoolSize).
+		SetConnectTimeout(cfg.ConnectTimeout).
+		SetServerSelectionTimeout(cfg.ServerSelectionTimeout)
+
 	// Connect to MongoDB
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(GetMongoURI()))
+	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
 		log.Fatal("Gagal koneksi ke MongoDB:", err)
 	}
@@ -28,5 +34,5 @@ func InitMongoDB() *mongo.Database {
 	log.Println("MongoDB connected successfully")
 
 	// Return database
-	return client.Database(GetMongoDatabase())
+	return client.Database(cfg.Database)
 }
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	_ "github.com/lib/pq"
+)
+
+// InitDB membuka koneksi PostgreSQL dengan pengaturan pool dari konfigurasi
+func InitDB(cfg PostgresConfig) *sql.DB {
+	db, err := sql.Open("postgres", cfg.DSN())
+	if err != nil {
+		log.Fatal("Gagal koneksi ke database:", err)
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	defer cancel()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		log.Fatal("Gagal ping database:", err)
+	}
+
+	return db
+}
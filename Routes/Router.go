@@ -15,8 +15,14 @@ func SetupRoutes(
 	lecturerService *service.LecturerService,
 	adminService *service.AdminService,
 	statisticsService *service.StatisticsService,
+	outboxService *service.OutboxService,
+	notificationService *service.NotificationService,
+	webhookService *service.WebhookService,
+	exportService *service.ExportService,
+	roleService *service.RoleService,
 	permMiddleware *middleware.PermissionMiddleware,
 	roleMiddleware *middleware.RoleMiddleware,
+	revocations *middleware.RevocationStore,
 ) {
 	// Health check
 	app.Get("/ping", func(c *fiber.Ctx) error {
@@ -30,23 +36,34 @@ func SetupRoutes(
 	auth := v1.Group("/auth")
 	auth.Post("/login", authService.Login)
 	auth.Post("/refresh", authService.RefreshToken)
-	auth.Post("/logout", authService.Logout)
 
 	// Protected routes - require authentication
-	authProtected := v1.Group("/auth", middleware.JWTAuth())
+	authProtected := v1.Group("/auth", middleware.JWTAuth(revocations))
+	authProtected.Post("/logout", authService.Logout)
 	authProtected.Get("/profile", authService.GetProfile)
 
+	// Notification stream (SSE/WebSocket). Didaftarkan sebelum group api karena token
+	// boleh dikirim lewat query access_token.
+	streamAuth := middleware.JWTAuthStream(revocations)
+	v1.Get("/notifications/stream", streamAuth, notificationService.StreamNotifications)
+	v1.Get("/notifications/ws", streamAuth, notificationService.UpgradeWebSocket, notificationService.NotificationsWebSocket())
+
+	// Verifikasi laporan PDF dari QR code, terbuka tanpa login
+	v1.Get("/public/reports/:id", statisticsService.VerifyStudentReport)
+
 	// Protected API routes
-	api := v1.Group("", middleware.JWTAuth())
+	api := v1.Group("", middleware.JWTAuth(revocations))
 
 	// 5.2 Users (Admin only)
 	users := api.Group("/users", roleMiddleware.RequireRole("admin", "super_admin"))
 	users.Get("/", adminService.GetAllUsers)
 	users.Get("/:id", adminService.GetUserByID)
 	users.Post("/", adminService.CreateUser)
+	users.Post("/import", adminService.ImportUsers)
 	users.Put("/:id", adminService.UpdateUser)
 	users.Delete("/:id", adminService.DeleteUser)
 	users.Put("/:id/role", adminService.UpdateUserRole)
+	users.Post("/:id/revoke-sessions", adminService.RevokeUserSessions)
 
 	// 5.4 Achievements routes
 	achievements := api.Group("/achievements")
@@ -101,6 +118,11 @@ func SetupRoutes(
 		achievementService.UploadAttachments,
 	)
 
+	// Download attachment
+	achievements.Get("/:id/attachments/:attachmentId",
+		achievementService.DownloadAttachment,
+	)
+
 	// 5.5 Students & Lecturers
 	students := api.Group("/students")
 	students.Get("/", adminService.GetAllStudents)
@@ -115,14 +137,63 @@ func SetupRoutes(
 	lecturers.Get("/", adminService.GetAllLecturers)
 	lecturers.Get("/:id/advisees", lecturerService.GetAdvisees)
 
+	// Notifications (milik user yang login)
+	notifications := api.Group("/notifications")
+	notifications.Get("/", notificationService.GetNotifications)
+	notifications.Get("/unread-count", notificationService.GetUnreadCount)
+	notifications.Get("/preferences", notificationService.GetPreferences)
+	notifications.Put("/preferences", notificationService.UpdatePreferences)
+	notifications.Post("/read-all", notificationService.MarkAllAsRead)
+	notifications.Post("/:id/read", notificationService.MarkAsRead)
+	notifications.Delete("/:id", notificationService.DeleteNotification)
+
 	// 5.8 Reports & Analytics
 	reports := api.Group("/reports")
 	reports.Get("/statistics", statisticsService.GetAllStatistics)
+	reports.Get("/statistics/me",
+		roleMiddleware.RequireRole("student"),
+		statisticsService.GetMyStatistics,
+	)
+	reports.Get("/statistics/advisees",
+		roleMiddleware.RequireRole("lecturer", "dosen"),
+		statisticsService.GetAdviseeStatistics,
+	)
 	reports.Get("/student/:id", statisticsService.GetStudentReport)
+	reports.Get("/achievements/export", exportService.ExportAchievements)
+	reports.Get("/exports/:id", exportService.GetExportJob)
+	reports.Get("/exports/:id/download", exportService.DownloadExport)
 
 	// Admin routes (legacy support)
 	admin := api.Group("/admin", roleMiddleware.RequireRole("admin", "super_admin"))
 	admin.Post("/students/profile", adminService.CreateStudentProfile)
 	admin.Post("/lecturers/profile", adminService.CreateLecturerProfile)
-	admin.Get("/roles", adminService.GetAllRoles)
+	admin.Get("/roles", roleService.GetRoles)
+	admin.Get("/outbox", outboxService.GetOutbox)
+	admin.Post("/outbox/:id/retry", outboxService.RetryOutbox)
+
+	// Webhook
+	admin.Get("/webhooks/deliveries/:deliveryId", webhookService.GetWebhookDelivery)
+	admin.Post("/webhooks/deliveries/:deliveryId/redeliver", webhookService.RedeliverWebhook)
+	admin.Get("/webhooks", webhookService.GetWebhooks)
+	admin.Post("/webhooks", webhookService.CreateWebhook)
+	admin.Get("/webhooks/:id", webhookService.GetWebhook)
+	admin.Put("/webhooks/:id", webhookService.UpdateWebhook)
+	admin.Delete("/webhooks/:id", webhookService.DeleteWebhook)
+	admin.Get("/webhooks/:id/deliveries", webhookService.GetWebhookDeliveries)
+
+	// Role & permission (RBAC): admin boleh melihat, perubahan hanya oleh super_admin
+	superAdmin := roleMiddleware.RequireRole("super_admin")
+	admin.Post("/roles", superAdmin, roleService.CreateRole)
+	admin.Get("/roles/:id", roleService.GetRole)
+	admin.Put("/roles/:id", superAdmin, roleService.UpdateRole)
+	admin.Delete("/roles/:id", superAdmin, roleService.DeleteRole)
+	admin.Put("/roles/:id/permissions", superAdmin, roleService.SetRolePermissions)
+	admin.Post("/roles/:id/permissions/:permissionId", superAdmin, roleService.AddRolePermission)
+	admin.Delete("/roles/:id/permissions/:permissionId", superAdmin, roleService.RemoveRolePermission)
+	admin.Get("/permissions", roleService.GetPermissions)
+	admin.Get("/permissions/available", roleService.GetAvailablePermissions)
+	admin.Get("/permissions/matrix", roleService.GetPermissionMatrix)
+	admin.Post("/permissions", superAdmin, roleService.CreatePermission)
+	admin.Put("/permissions/:id", superAdmin, roleService.UpdatePermission)
+	admin.Delete("/permissions/:id", superAdmin, roleService.DeletePermission)
 }
\ No newline at end of file
@@ -0,0 +1,197 @@
+// Package cache menyimpan hasil perhitungan yang mahal (statistik prestasi) dengan
+// invalidasi per scope.
+//
+// Backend disimpan di balik interface Store: LRUStore di memori untuk satu replika,
+// PostgresStore untuk beberapa replika, atau implementasi lain (misalnya Redis).
+// Setiap entry bergantung pada satu atau lebih scope. Invalidate mengganti generation
+// scope tersebut sehingga semua key lama tidak lagi terbaca dan hilang sendiri lewat
+// LRU atau TTL, tanpa perlu mencari key satu per satu.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store backend cache. ttl 0 berarti tidak kedaluwarsa.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Clear menghapus semua entry, dipakai jika event invalidasi mungkin terlewat
+	Clear(ctx context.Context) error
+}
+
+// Meta keterangan kesegaran hasil yang dikembalikan ke client
+type Meta struct {
+	Cached      bool       `json:"cached"`
+	GeneratedAt time.Time  `json:"generated_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // nil jika tanpa TTL
+	AgeSeconds  int        `json:"age_seconds"`
+}
+
+// Cache entry JSON di atas Store dengan TTL dan invalidasi per scope
+type Cache struct {
+	Store  Store
+	TTL    time.Duration
+	Prefix string
+
+	now func() time.Time
+}
+
+func New(store Store, ttl time.Duration, prefix string) *Cache {
+	return &Cache{Store: store, TTL: ttl, Prefix: prefix, now: time.Now}
+}
+
+type entry struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	Value       json.RawMessage `json:"value"`
+}
+
+// Fetch membaca hasil untuk key yang bergantung pada scopes. Jika tidak ada, sudah
+// kedaluwarsa atau salah satu scope sudah diinvalidasi, compute dipanggil dan hasilnya
+// disimpan. Key dihitung sebelum compute sehingga invalidasi yang terjadi selama compute
+// tidak tertimpa hasil lama. Kegagalan Store hanya di-log, hasil tetap dihitung.
+// Cache nil berarti tanpa cache.
+func Fetch[T any](ctx context.Context, c *Cache, key string, scopes []string, compute func() (T, error)) (T, Meta, error) {
+	if c == nil {
+		value, err := compute()
+		return value, Meta{GeneratedAt: time.Now()}, err
+	}
+
+	fullKey, err := c.key(ctx, key, scopes)
+	if err != nil {
+		log.Printf("cache: gagal membaca generation %s: %v", key, err)
+	} else {
+		var cached T
+		if meta, ok := c.get(ctx, fullKey, &cached); ok {
+			return cached, meta, nil
+		}
+	}
+
+	value, err := compute()
+	if err != nil {
+		return value, Meta{}, err
+	}
+
+	meta, data, err := c.encode(value)
+	if err == nil && fullKey != "" {
+		err = c.Store.Set(ctx, fullKey, data, c.TTL)
+	}
+	if err != nil {
+		log.Printf("cache: gagal menyimpan %s: %v", key, err)
+	}
+	return value, meta, nil
+}
+
+func (c *Cache) get(ctx context.Context, fullKey string, dst interface{}) (Meta, bool) {
+	raw, ok, err := c.Store.Get(ctx, fullKey)
+	if err != nil {
+		log.Printf("cache: gagal membaca %s: %v", fullKey, err)
+		return Meta{}, false
+	}
+	if !ok {
+		return Meta{}, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return Meta{}, false
+	}
+	now := c.now()
+	if !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt) {
+		return Meta{}, false
+	}
+	if err := json.Unmarshal(e.Value, dst); err != nil {
+		return Meta{}, false
+	}
+
+	meta := Meta{
+		Cached:      true,
+		GeneratedAt: e.GeneratedAt,
+		AgeSeconds:  int(now.Sub(e.GeneratedAt).Seconds()),
+	}
+	if !e.ExpiresAt.IsZero() {
+		meta.ExpiresAt = &e.ExpiresAt
+	}
+	return meta, true
+}
+
+// encode membungkus value dengan waktu dibuat dan kedaluwarsa
+func (c *Cache) encode(value interface{}) (Meta, []byte, error) {
+	now := c.now()
+	meta := Meta{GeneratedAt: now}
+	var expiresAt time.Time
+	if c.TTL > 0 {
+		expiresAt = now.Add(c.TTL)
+		meta.ExpiresAt = &expiresAt
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return meta, nil, err
+	}
+	data, err := json.Marshal(entry{GeneratedAt: now, ExpiresAt: expiresAt, Value: raw})
+	return meta, data, err
+}
+
+// Invalidate membuat semua entry yang bergantung pada scopes tidak terbaca lagi
+func (c *Cache) Invalidate(ctx context.Context, scopes ...string) error {
+	for _, scope := range scopes {
+		if _, err := c.newGeneration(ctx, scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clear menghapus semua entry
+func (c *Cache) Clear(ctx context.Context) error {
+	return c.Store.Clear(ctx)
+}
+
+// key menggabungkan key dengan generation terbaru setiap scope
+func (c *Cache) key(ctx context.Context, key string, scopes []string) (string, error) {
+	var b strings.Builder
+	b.WriteString(c.Prefix)
+	b.WriteString(key)
+	for _, scope := range scopes {
+		generation, err := c.generation(ctx, scope)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString("|")
+		b.WriteString(generation)
+	}
+	return b.String(), nil
+}
+
+// generation generation scope saat ini. Jika belum ada (atau sudah dibuang LRU), dibuat
+// yang baru agar entry lama dengan generation sebelumnya tidak terbaca lagi.
+func (c *Cache) generation(ctx context.Context, scope string) (string, error) {
+	raw, ok, err := c.Store.Get(ctx, c.generationKey(scope))
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return string(raw), nil
+	}
+	return c.newGeneration(ctx, scope)
+}
+
+func (c *Cache) newGeneration(ctx context.Context, scope string) (string, error) {
+	generation := uuid.NewString()
+	if err := c.Store.Set(ctx, c.generationKey(scope), []byte(generation), 0); err != nil {
+		return "", err
+	}
+	return generation, nil
+}
+
+func (c *Cache) generationKey(scope string) string {
+	return c.Prefix + "gen:" + scope
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type result struct {
+	Total int `json:"total"`
+}
+
+func TestLRUStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	store := NewLRUStore(2)
+
+	store.Set(ctx, "a", []byte("1"), 0)
+	store.Set(ctx, "b", []byte("2"), 0)
+	store.Get(ctx, "a") // a lebih baru dipakai daripada b
+	store.Set(ctx, "c", []byte("3"), 0)
+
+	// Assert
+	_, ok, _ := store.Get(ctx, "b")
+	assert.False(t, ok)
+	value, ok, _ := store.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestLRUStore_Expires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewLRUStore(10)
+	store.now = func() time.Time { return now }
+
+	store.Set(ctx, "a", []byte("1"), time.Minute)
+	now = now.Add(time.Minute)
+
+	// Assert
+	_, ok, _ := store.Get(ctx, "a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestFetch_CachesUntilScopeInvalidated(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewLRUStore(100), time.Minute, "test:")
+	calls := 0
+	compute := func() (result, error) {
+		calls++
+		return result{Total: calls}, nil
+	}
+
+	// Execute
+	first, meta, err := Fetch(ctx, c, "student:1", []string{"student:1"}, compute)
+	assert.NoError(t, err)
+	assert.False(t, meta.Cached)
+
+	second, meta, err := Fetch(ctx, c, "student:1", []string{"student:1"}, compute)
+	assert.NoError(t, err)
+	assert.True(t, meta.Cached)
+	assert.Equal(t, first, second)
+	assert.NotNil(t, meta.ExpiresAt)
+
+	// Scope lain tidak memengaruhi entry ini
+	assert.NoError(t, c.Invalidate(ctx, "student:2"))
+	_, meta, _ = Fetch(ctx, c, "student:1", []string{"student:1"}, compute)
+	assert.True(t, meta.Cached)
+
+	assert.NoError(t, c.Invalidate(ctx, "student:1"))
+	third, meta, err := Fetch(ctx, c, "student:1", []string{"student:1"}, compute)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, meta.Cached)
+	assert.Equal(t, 2, third.Total)
+	assert.Equal(t, 2, calls)
+}
+
+func TestFetch_InvalidationDuringComputeNotOverwritten(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewLRUStore(100), time.Minute, "")
+	calls := 0
+
+	// Status berubah saat hasil sedang dihitung: hasil lama tidak boleh dipakai lagi
+	_, _, err := Fetch(ctx, c, "all", []string{"all"}, func() (result, error) {
+		calls++
+		c.Invalidate(ctx, "all")
+		return result{Total: 1}, nil
+	})
+	assert.NoError(t, err)
+
+	_, meta, _ := Fetch(ctx, c, "all", []string{"all"}, func() (result, error) {
+		calls++
+		return result{Total: 2}, nil
+	})
+
+	// Assert
+	assert.False(t, meta.Cached)
+	assert.Equal(t, 2, calls)
+}
+
+func TestFetch_AgeAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(NewLRUStore(100), time.Minute, "")
+	c.now = func() time.Time { return now }
+	compute := func() (result, error) { return result{Total: 1}, nil }
+
+	Fetch(ctx, c, "all", nil, compute)
+	now = now.Add(20 * time.Second)
+	_, meta, _ := Fetch(ctx, c, "all", nil, compute)
+	assert.True(t, meta.Cached)
+	assert.Equal(t, 20, meta.AgeSeconds)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC), *meta.ExpiresAt)
+
+	now = now.Add(40 * time.Second)
+	_, meta, _ = Fetch(ctx, c, "all", nil, compute)
+	assert.False(t, meta.Cached)
+}
+
+func TestFetch_NilCacheAndComputeError(t *testing.T) {
+	ctx := context.Background()
+
+	value, meta, err := Fetch(ctx, nil, "all", nil, func() (result, error) { return result{Total: 3}, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 3, value.Total)
+	assert.False(t, meta.Cached)
+
+	c := New(NewLRUStore(100), time.Minute, "")
+	_, _, err = Fetch(ctx, c, "all", nil, func() (result, error) { return result{}, errors.New("db down") })
+	assert.EqualError(t, err, "db down")
+	_, meta, _ = Fetch(ctx, c, "all", nil, func() (result, error) { return result{}, nil })
+	assert.False(t, meta.Cached) // error tidak disimpan
+}
+
+func TestFetch_StoreErrorFallsBackToCompute(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	c := New(NewPostgresStore(db), time.Minute, "")
+	mock.ExpectQuery(`SELECT value FROM statistics_cache`).WillReturnError(errors.New("connection refused"))
+
+	// Execute
+	value, meta, err := Fetch(context.Background(), c, "all", []string{"all"}, func() (result, error) {
+		return result{Total: 5}, nil
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 5, value.Total)
+	assert.False(t, meta.Cached)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStore_GetSet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresStore(db)
+	store.lastCleanup = time.Now()
+
+	mock.ExpectExec(`INSERT INTO statistics_cache \(key, value, expires_at\)`).
+		WithArgs("k", []byte("v"), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT value FROM statistics_cache\s+WHERE key = \$1 AND \(expires_at IS NULL OR expires_at > NOW\(\)\)`).
+		WithArgs("k").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow([]byte("v")))
+	mock.ExpectQuery(`SELECT value FROM statistics_cache`).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+	// Execute
+	assert.NoError(t, store.Set(context.Background(), "k", []byte("v"), time.Minute))
+	value, ok, err := store.Get(context.Background(), "k")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("v"), value)
+	_, ok, err = store.Get(context.Background(), "missing")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
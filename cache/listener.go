@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// StatusChangedChannel channel NOTIFY dari trigger achievement_references, payload berisi
+// student_id yang status prestasinya berubah
+const StatusChangedChannel = "achievement_status_changed"
+
+// Listen memanggil onEvent untuk setiap NOTIFY di channel sampai ctx dibatalkan.
+// onReconnect dipanggil setelah koneksi tersambung ulang, karena event selama koneksi
+// putus hilang dan cache sebaiknya dikosongkan.
+func Listen(ctx context.Context, dsn, channel string, onEvent func(payload string), onReconnect func()) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("cache: koneksi LISTEN: %v", err)
+		}
+	})
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return err
+	}
+	defer listener.Close()
+
+	// Ping berkala agar koneksi yang putus terdeteksi dan disambung ulang
+	ticker := time.NewTicker(90 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			go listener.Ping()
+		case n := <-listener.Notify:
+			// nil dikirim setelah reconnect
+			if n == nil {
+				onReconnect()
+				continue
+			}
+			onEvent(n.Extra)
+		}
+	}
+}
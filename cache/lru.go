@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRUStore Store di memori dengan jumlah entry maksimum. Entry yang paling lama tidak
+// dipakai dibuang lebih dulu.
+type LRUStore struct {
+	Capacity int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	now   func() time.Time
+}
+
+type lruItem struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func NewLRUStore(capacity int) *LRUStore {
+	return &LRUStore{
+		Capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func (s *LRUStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	item := el.Value.(*lruItem)
+	if !item.expiresAt.IsZero() && !s.now().Before(item.expiresAt) {
+		s.remove(el)
+		return nil, false, nil
+	}
+
+	s.ll.MoveToFront(el)
+	return item.value, true, nil
+}
+
+func (s *LRUStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = s.now().Add(ttl)
+	}
+
+	if el, ok := s.items[key]; ok {
+		item := el.Value.(*lruItem)
+		item.value, item.expiresAt = value, expiresAt
+		s.ll.MoveToFront(el)
+		return nil
+	}
+
+	s.items[key] = s.ll.PushFront(&lruItem{key: key, value: value, expiresAt: expiresAt})
+	for s.Capacity > 0 && s.ll.Len() > s.Capacity {
+		s.remove(s.ll.Back())
+	}
+	return nil
+}
+
+func (s *LRUStore) Clear(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ll.Init()
+	s.items = make(map[string]*list.Element)
+	return nil
+}
+
+// Len jumlah entry, termasuk yang sudah kedaluwarsa tapi belum dibuang
+func (s *LRUStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+func (s *LRUStore) remove(el *list.Element) {
+	s.ll.Remove(el)
+	delete(s.items, el.Value.(*lruItem).key)
+}
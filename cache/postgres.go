@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+// cleanupInterval jeda minimum antar penghapusan entry kedaluwarsa di PostgresStore
+const cleanupInterval = time.Minute
+
+// PostgresStore Store bersama untuk beberapa replika di tabel UNLOGGED statistics_cache
+type PostgresStore struct {
+	DB *sql.DB
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{DB: db}
+}
+
+func (s *PostgresStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var value []byte
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT value FROM statistics_cache
+		WHERE key = $1 AND (expires_at IS NULL OR expires_at > NOW())
+	`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *PostgresStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := time.Now().Add(ttl)
+		expiresAt = &t
+	}
+
+	_, err := s.DB.ExecContext(ctx, `
+		INSERT INTO statistics_cache (key, value, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at
+	`, key, value, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	s.cleanup(ctx)
+	return nil
+}
+
+func (s *PostgresStore) Clear(ctx context.Context) error {
+	_, err := s.DB.ExecContext(ctx, `DELETE FROM statistics_cache`)
+	return err
+}
+
+// cleanup menghapus entry kedaluwarsa paling sering sekali per cleanupInterval. Generation
+// scope tidak punya TTL dan tetap disimpan.
+func (s *PostgresStore) cleanup(ctx context.Context) {
+	s.mu.Lock()
+	due := time.Since(s.lastCleanup) >= cleanupInterval
+	if due {
+		s.lastCleanup = time.Now()
+	}
+	s.mu.Unlock()
+
+	if due {
+		s.DB.ExecContext(ctx, `DELETE FROM statistics_cache WHERE expires_at <= NOW()`)
+	}
+}
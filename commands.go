@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	config "POJECT_UAS/Config"
+	"POJECT_UAS/consistency"
+	"POJECT_UAS/migration"
+	"POJECT_UAS/seed"
+	"POJECT_UAS/userimport"
+)
+
+const commandUsage = `Usage:
+  app [flags]                    menjalankan HTTP server
+  app [flags] migrate up         menerapkan semua migrasi yang belum diterapkan
+  app [flags] migrate down [N]   membatalkan N migrasi terakhir (default 1)
+  app [flags] migrate status     menampilkan status migrasi
+  app [flags] seed [--demo-data] mengisi role dan permission bawaan (opsional: data demo)
+  app [flags] consistency check [--fix]
+                                 membandingkan PostgreSQL dengan MongoDB, laporan JSON ke stdout
+                                 (--fix: jalankan perbaikan yang aman)
+  app [flags] import [--dry-run] <file>
+                                 import user, mahasiswa dan dosen dari CSV/XLSX, laporan JSON ke stdout
+                                 (--dry-run: hanya validasi)`
+
+// runCommand menjalankan subcommand CLI (argumen setelah flag)
+func runCommand(cfg *config.Config, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(cfg, args[1:])
+	case "seed":
+		return runSeed(cfg, args[1:])
+	case "consistency":
+		return runConsistency(cfg, args[1:])
+	case "import":
+		return runImport(cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], commandUsage)
+	}
+}
+
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate action\n\n%s", commandUsage)
+	}
+
+	action := args[0]
+	steps := 1
+	switch action {
+	case "up", "status":
+		if len(args) > 1 {
+			return fmt.Errorf("migrate %s takes no arguments", action)
+		}
+	case "down":
+		if len(args) > 2 {
+			return fmt.Errorf("migrate down takes at most one argument")
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+	default:
+		return fmt.Errorf("unknown migrate action %q\n\n%s", action, commandUsage)
+	}
+
+	db := config.InitDB(cfg.Postgres)
+	defer db.Close()
+
+	migrator, err := migration.NewMigrator(db, nil)
+	if err != nil {
+		return err
+	}
+	// MongoDB hanya dibutuhkan jika ada migrasi Mongo yang akan dijalankan
+	if action != "status" {
+		migrator.MongoDB = config.InitMongoDB(cfg.Mongo)
+		defer migrator.MongoDB.Client().Disconnect(context.Background())
+	}
+
+	ctx := context.Background()
+
+	switch action {
+	case "up":
+		done, err := migrator.Up(ctx)
+		printMigrations("applied", done)
+		return err
+	case "down":
+		done, err := migrator.Down(ctx, steps)
+		printMigrations("reverted", done)
+		return err
+	default:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printMigrationStatus(statuses)
+		return nil
+	}
+}
+
+func runSeed(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	demoData := fs.Bool("demo-data", false, "ikut membuat user, dosen, mahasiswa dan prestasi demo")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("seed takes no arguments\n\n%s", commandUsage)
+	}
+
+	db := config.InitDB(cfg.Postgres)
+	defer db.Close()
+
+	seeder := seed.NewSeeder(db, nil)
+	// MongoDB hanya dibutuhkan untuk contoh prestasi
+	if *demoData {
+		seeder.MongoDB = config.InitMongoDB(cfg.Mongo)
+		defer seeder.MongoDB.Client().Disconnect(context.Background())
+	}
+
+	report, err := seeder.Run(context.Background(), seed.Options{Demo: *demoData})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("seeded: %d roles, %d permissions, %d role permissions, %d users, %d lecturers, %d students, %d achievements\n",
+		report.Roles, report.Permissions, report.RolePermissions,
+		report.Users, report.Lecturers, report.Students, report.Achievements)
+	if *demoData {
+		fmt.Printf("demo users memakai password %q\n", seed.DemoPassword)
+	}
+
+	return nil
+}
+
+func runConsistency(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		return fmt.Errorf("unknown consistency action\n\n%s", commandUsage)
+	}
+
+	fs := flag.NewFlagSet("consistency check", flag.ContinueOnError)
+	fix := fs.Bool("fix", false, "jalankan perbaikan yang aman")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("consistency check takes no arguments\n\n%s", commandUsage)
+	}
+
+	db := config.InitDB(cfg.Postgres)
+	defer db.Close()
+
+	mongoDB := config.InitMongoDB(cfg.Mongo)
+	defer mongoDB.Client().Disconnect(context.Background())
+
+	report, err := consistency.NewChecker(db, mongoDB).Run(context.Background(), consistency.Options{Fix: *fix})
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+
+	// Exit code bukan 0 jika masih ada yang tidak sinkron, berguna untuk cron/CI
+	if n := report.Unresolved(); n > 0 {
+		return fmt.Errorf("%d inconsistencies unresolved", n)
+	}
+
+	return nil
+}
+
+func runImport(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "hanya validasi, tidak ada yang disimpan")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("import takes exactly one file\n\n%s", commandUsage)
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	rows, err := userimport.ReadFile(data)
+	if err != nil {
+		return err
+	}
+
+	db := config.InitDB(cfg.Postgres)
+	defer db.Close()
+
+	report, err := newUserImporter(cfg.Import, db).Run(context.Background(), rows, userimport.Options{DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+
+	if report.ErrorRows > 0 {
+		return fmt.Errorf("%d of %d rows invalid, nothing was imported", report.ErrorRows, report.TotalRows)
+	}
+
+	return nil
+}
+
+func printMigrations(verb string, migrations []migration.Migration) {
+	if len(migrations) == 0 {
+		fmt.Println("no migrations " + verb)
+		return
+	}
+	for _, m := range migrations {
+		fmt.Printf("%s %04d_%s (%s)\n", verb, m.Version, m.Name, m.Kind())
+	}
+}
+
+func printMigrationStatus(statuses []migration.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tKIND\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		if s.Missing {
+			appliedAt += " (missing in binary)"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, s.Kind, appliedAt)
+	}
+	w.Flush()
+}
@@ -0,0 +1,395 @@
+package consistency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Jenis ketidaksesuaian antara achievement_references dan koleksi achievements
+const (
+	IssueMissingDocument = "missing_document" // reference tanpa dokumen MongoDB
+	IssueOrphanDocument  = "orphan_document"  // dokumen MongoDB tanpa reference
+	IssueDeletedMismatch = "deleted_mismatch" // isDeleted tidak sesuai status = 'deleted'
+	IssueStudentMismatch = "student_mismatch" // studentId berbeda dengan student_id reference
+)
+
+// Perbaikan yang dijalankan dengan Options.Fix. PostgreSQL dianggap sumber kebenaran
+// untuk status dan pemilik prestasi; data tidak pernah dihapus permanen.
+const (
+	RepairMarkReferenceDeleted = "mark_reference_deleted" // reference draft tanpa dokumen ditandai deleted
+	RepairSoftDeleteDocument   = "soft_delete_document"
+	RepairRestoreDocument      = "restore_document"
+	RepairSetDocumentStudent   = "set_document_student"
+)
+
+// recentWindow data yang lebih baru dari ini tidak diperiksa karena mungkin masih disinkronkan
+const recentWindow = time.Minute
+
+var errChangedSinceCheck = errors.New("data berubah sejak diperiksa, jalankan ulang pemeriksaan")
+
+// Options pilihan pemeriksaan
+type Options struct {
+	Fix bool // jalankan perbaikan yang aman
+}
+
+// Issue satu ketidaksesuaian. Repair kosong berarti harus ditangani manual.
+type Issue struct {
+	Kind               string     `json:"kind"`
+	ReferenceID        *uuid.UUID `json:"reference_id,omitempty"`
+	MongoAchievementID string     `json:"mongo_achievement_id"`
+	Detail             string     `json:"detail"`
+	Repair             string     `json:"repair,omitempty"`
+	Fixed              bool       `json:"fixed"`
+	Error              string     `json:"error,omitempty"`
+
+	studentID uuid.UUID // student_id reference untuk RepairSetDocumentStudent
+}
+
+// Report hasil pemeriksaan dalam bentuk JSON
+type Report struct {
+	CheckedAt    time.Time      `json:"checked_at"`
+	Fix          bool           `json:"fix"`
+	References   int            `json:"references"`
+	Documents    int            `json:"documents"`
+	InFlight     int            `json:"in_flight"`     // reference dengan operasi outbox yang belum selesai, dilewati
+	FailedOutbox int            `json:"failed_outbox"` // operasi outbox yang perlu ditangani admin
+	Summary      map[string]int `json:"summary"`
+	Issues       []Issue        `json:"issues"`
+}
+
+// Unresolved jumlah issue yang belum diperbaiki
+func (r *Report) Unresolved() int {
+	n := 0
+	for _, issue := range r.Issues {
+		if !issue.Fixed {
+			n++
+		}
+	}
+	return n
+}
+
+// Checker membandingkan achievement_references (PostgreSQL) dengan koleksi achievements (MongoDB)
+type Checker struct {
+	DB      *sql.DB
+	MongoDB *mongo.Database
+}
+
+func NewChecker(db *sql.DB, mongoDB *mongo.Database) *Checker {
+	return &Checker{DB: db, MongoDB: mongoDB}
+}
+
+// reference baris achievement_references yang diperiksa
+type reference struct {
+	ID        uuid.UUID
+	StudentID uuid.UUID
+	MongoID   string
+	Status    string
+	InFlight  bool
+	Recent    bool
+}
+
+// document field dokumen achievements yang diperiksa
+type document struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	StudentID uuid.UUID          `bson:"studentId"`
+	IsDeleted bool               `bson:"isDeleted"`
+}
+
+// Run memeriksa semua prestasi dan, jika opts.Fix, memperbaiki yang bisa diperbaiki dengan aman
+func (c *Checker) Run(ctx context.Context, opts Options) (*Report, error) {
+	now := time.Now()
+	cutoff := now.Add(-recentWindow)
+
+	// Dokumen dibaca lebih dulu: lewat outbox, reference selalu di-commit sebelum dokumennya
+	// dibuat, sehingga dokumen yang terbaca sudah punya reference saat reference dibaca
+	docs, err := c.loadDocuments(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := c.loadReferences(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		CheckedAt:  now,
+		Fix:        opts.Fix,
+		References: len(refs),
+		Documents:  len(docs),
+		Summary:    map[string]int{},
+	}
+
+	err = c.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM achievement_outbox WHERE status = 'failed'`).Scan(&report.FailedOutbox)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Issues, report.InFlight = compare(refs, docs)
+	for i := range report.Issues {
+		issue := &report.Issues[i]
+		report.Summary[issue.Kind]++
+
+		if !opts.Fix || issue.Repair == "" {
+			continue
+		}
+		if err := c.repair(ctx, issue); err != nil {
+			issue.Error = err.Error()
+			continue
+		}
+		issue.Fixed = true
+	}
+
+	return report, nil
+}
+
+func (c *Checker) loadReferences(ctx context.Context, cutoff time.Time) ([]reference, error) {
+	// Reference yang masih punya operasi outbox berjalan sedang disinkronkan, bukan drift
+	query := `
+		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+			EXISTS (
+				SELECT 1 FROM achievement_outbox o
+				WHERE o.achievement_reference_id = ar.id AND o.status IN ('pending', 'processing')
+			),
+			ar.created_at >= $1
+		FROM achievement_references ar
+	`
+
+	rows, err := c.DB.QueryContext(ctx, query, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refs []reference
+	for rows.Next() {
+		var ref reference
+		if err := rows.Scan(&ref.ID, &ref.StudentID, &ref.MongoID, &ref.Status, &ref.InFlight, &ref.Recent); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, rows.Err()
+}
+
+// loadDocuments membaca dokumen yang dibuat sebelum cutoff (dari timestamp ObjectID)
+func (c *Checker) loadDocuments(ctx context.Context, cutoff time.Time) ([]document, error) {
+	filter := bson.M{"_id": bson.M{"$lt": primitive.NewObjectIDFromTimestamp(cutoff)}}
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "studentId": 1, "isDeleted": 1})
+
+	cursor, err := c.MongoDB.Collection("achievements").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []document
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+// compare mencari ketidaksesuaian antara reference dan dokumen. Reference yang baru dibuat
+// hanya dipakai untuk mengenali dokumennya. Reference deleted tanpa dokumen dan dokumen
+// isDeleted tanpa reference tidak dilaporkan karena keduanya sudah tidak terlihat oleh user.
+func compare(refs []reference, docs []document) (issues []Issue, inFlight int) {
+	issues = []Issue{}
+	docsByID := make(map[string]document, len(docs))
+	for _, doc := range docs {
+		docsByID[doc.ID.Hex()] = doc
+	}
+
+	referenced := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		referenced[ref.MongoID] = true
+		if ref.Recent {
+			continue
+		}
+		if ref.InFlight {
+			inFlight++
+			continue
+		}
+
+		refID := ref.ID
+		doc, ok := docsByID[ref.MongoID]
+		if !ok {
+			if ref.Status == "deleted" {
+				continue
+			}
+
+			issue := Issue{
+				Kind:               IssueMissingDocument,
+				ReferenceID:        &refID,
+				MongoAchievementID: ref.MongoID,
+				Detail:             fmt.Sprintf("reference berstatus %s tidak memiliki dokumen MongoDB", ref.Status),
+			}
+			// Hanya draft yang aman ditandai deleted; prestasi yang sudah diajukan perlu ditangani manual
+			if ref.Status == "draft" {
+				issue.Repair = RepairMarkReferenceDeleted
+			}
+			issues = append(issues, issue)
+			continue
+		}
+
+		switch {
+		case ref.Status == "deleted" && !doc.IsDeleted:
+			issues = append(issues, Issue{
+				Kind:               IssueDeletedMismatch,
+				ReferenceID:        &refID,
+				MongoAchievementID: ref.MongoID,
+				Detail:             "reference deleted tetapi dokumen belum isDeleted",
+				Repair:             RepairSoftDeleteDocument,
+			})
+		case ref.Status != "deleted" && doc.IsDeleted:
+			issues = append(issues, Issue{
+				Kind:               IssueDeletedMismatch,
+				ReferenceID:        &refID,
+				MongoAchievementID: ref.MongoID,
+				Detail:             fmt.Sprintf("dokumen isDeleted tetapi reference berstatus %s", ref.Status),
+				Repair:             RepairRestoreDocument,
+			})
+		}
+
+		if doc.StudentID != ref.StudentID {
+			issues = append(issues, Issue{
+				Kind:               IssueStudentMismatch,
+				ReferenceID:        &refID,
+				MongoAchievementID: ref.MongoID,
+				Detail:             fmt.Sprintf("studentId dokumen %s, student_id reference %s", doc.StudentID, ref.StudentID),
+				Repair:             RepairSetDocumentStudent,
+				studentID:          ref.StudentID,
+			})
+		}
+	}
+
+	for _, doc := range docs {
+		if referenced[doc.ID.Hex()] || doc.IsDeleted {
+			continue
+		}
+		issues = append(issues, Issue{
+			Kind:               IssueOrphanDocument,
+			MongoAchievementID: doc.ID.Hex(),
+			Detail:             "dokumen MongoDB tidak memiliki reference",
+			Repair:             RepairSoftDeleteDocument,
+		})
+	}
+
+	return issues, inFlight
+}
+
+// repair menjalankan perbaikan issue. Setiap perbaikan bersyarat pada kondisi saat diperiksa,
+// sehingga data yang berubah di antara pemeriksaan dan perbaikan tidak ikut diubah.
+func (c *Checker) repair(ctx context.Context, issue *Issue) error {
+	collection := c.MongoDB.Collection("achievements")
+
+	if issue.Repair == RepairMarkReferenceDeleted {
+		// Pastikan dokumen memang tidak ada, bukan baru dibuat setelah dibaca
+		if objectID, err := primitive.ObjectIDFromHex(issue.MongoAchievementID); err == nil {
+			count, err := collection.CountDocuments(ctx, bson.M{"_id": objectID})
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return errChangedSinceCheck
+			}
+		}
+		return c.markReferenceDeleted(ctx, *issue.ReferenceID)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(issue.MongoAchievementID)
+	if err != nil {
+		return err
+	}
+
+	if issue.Kind == IssueOrphanDocument {
+		var exists bool
+		err := c.DB.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM achievement_references WHERE mongo_achievement_id = $1)`,
+			issue.MongoAchievementID,
+		).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errChangedSinceCheck
+		}
+	}
+
+	var filter, update bson.M
+	switch issue.Repair {
+	case RepairSoftDeleteDocument:
+		filter = bson.M{"_id": objectID, "isDeleted": bson.M{"$ne": true}}
+		update = bson.M{"$set": bson.M{"isDeleted": true, "deletedAt": time.Now()}}
+	case RepairRestoreDocument:
+		filter = bson.M{"_id": objectID, "isDeleted": true}
+		update = bson.M{"$unset": bson.M{"isDeleted": "", "deletedAt": ""}}
+	case RepairSetDocumentStudent:
+		filter = bson.M{"_id": objectID, "studentId": bson.M{"$ne": issue.studentID}}
+		update = bson.M{"$set": bson.M{"studentId": issue.studentID}}
+	default:
+		return fmt.Errorf("unknown repair %q", issue.Repair)
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errChangedSinceCheck
+	}
+
+	return nil
+}
+
+// markReferenceDeleted menandai reference draft tanpa dokumen sebagai deleted dan mencatat history
+func (c *Checker) markReferenceDeleted(ctx context.Context, referenceID uuid.UUID) error {
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE achievement_references
+		SET status = 'deleted', updated_at = $1
+		WHERE id = $2 AND status = 'draft'
+		  AND NOT EXISTS (
+			SELECT 1 FROM achievement_outbox
+			WHERE achievement_reference_id = $2 AND status IN ('pending', 'processing')
+		  )
+	`, now, referenceID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errChangedSinceCheck
+	}
+
+	// changed_by NULL: perubahan oleh sistem
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO achievement_status_history
+		(id, achievement_reference_id, from_status, to_status, changed_by, note, created_at)
+		VALUES ($1, $2, 'draft', 'deleted', NULL, $3, $4)
+	`, uuid.New(), referenceID, "diperbaiki consistency check: dokumen MongoDB tidak ditemukan", now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,135 @@
+package consistency
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCompare(t *testing.T) {
+	studentID := uuid.New()
+	otherStudentID := uuid.New()
+
+	ok := primitive.NewObjectID()
+	missingDraft := primitive.NewObjectID()
+	missingVerified := primitive.NewObjectID()
+	missingDeleted := primitive.NewObjectID()
+	notSoftDeleted := primitive.NewObjectID()
+	softDeletedDraft := primitive.NewObjectID()
+	wrongStudent := primitive.NewObjectID()
+	orphan := primitive.NewObjectID()
+	deletedOrphan := primitive.NewObjectID()
+	inFlight := primitive.NewObjectID()
+	recent := primitive.NewObjectID()
+
+	refs := []reference{
+		{ID: uuid.New(), StudentID: studentID, MongoID: ok.Hex(), Status: "verified"},
+		{ID: uuid.New(), StudentID: studentID, MongoID: missingDraft.Hex(), Status: "draft"},
+		{ID: uuid.New(), StudentID: studentID, MongoID: missingVerified.Hex(), Status: "verified"},
+		{ID: uuid.New(), StudentID: studentID, MongoID: missingDeleted.Hex(), Status: "deleted"},
+		{ID: uuid.New(), StudentID: studentID, MongoID: notSoftDeleted.Hex(), Status: "deleted"},
+		{ID: uuid.New(), StudentID: studentID, MongoID: softDeletedDraft.Hex(), Status: "draft"},
+		{ID: uuid.New(), StudentID: studentID, MongoID: wrongStudent.Hex(), Status: "submitted"},
+		{ID: uuid.New(), StudentID: studentID, MongoID: inFlight.Hex(), Status: "draft", InFlight: true},
+		{ID: uuid.New(), StudentID: studentID, MongoID: recent.Hex(), Status: "draft", Recent: true},
+	}
+	docs := []document{
+		{ID: ok, StudentID: studentID},
+		{ID: notSoftDeleted, StudentID: studentID},
+		{ID: softDeletedDraft, StudentID: studentID, IsDeleted: true},
+		{ID: wrongStudent, StudentID: otherStudentID},
+		{ID: orphan, StudentID: studentID},
+		{ID: deletedOrphan, StudentID: studentID, IsDeleted: true},
+		{ID: recent, StudentID: otherStudentID},
+	}
+
+	// Execute
+	issues, inFlightCount := compare(refs, docs)
+
+	// Assert
+	assert.Equal(t, 1, inFlightCount)
+
+	got := map[string][2]string{}
+	for _, issue := range issues {
+		got[issue.MongoAchievementID] = [2]string{issue.Kind, issue.Repair}
+	}
+	assert.Equal(t, map[string][2]string{
+		missingDraft.Hex():     {IssueMissingDocument, RepairMarkReferenceDeleted},
+		missingVerified.Hex():  {IssueMissingDocument, ""},
+		notSoftDeleted.Hex():   {IssueDeletedMismatch, RepairSoftDeleteDocument},
+		softDeletedDraft.Hex(): {IssueDeletedMismatch, RepairRestoreDocument},
+		wrongStudent.Hex():     {IssueStudentMismatch, RepairSetDocumentStudent},
+		orphan.Hex():           {IssueOrphanDocument, RepairSoftDeleteDocument},
+	}, got)
+}
+
+func TestCompare_NoIssues(t *testing.T) {
+	id := primitive.NewObjectID()
+	studentID := uuid.New()
+
+	// Execute
+	issues, _ := compare(
+		[]reference{{ID: uuid.New(), StudentID: studentID, MongoID: id.Hex(), Status: "draft"}},
+		[]document{{ID: id, StudentID: studentID}},
+	)
+
+	// Assert: slice kosong agar report JSON berisi [] bukan null
+	assert.NotNil(t, issues)
+	assert.Empty(t, issues)
+}
+
+func TestChecker_MarkReferenceDeleted(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	checker := NewChecker(db, nil)
+	referenceID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE achievement_references SET status = 'deleted'`).
+		WithArgs(sqlmock.AnyArg(), referenceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO achievement_status_history`).
+		WithArgs(sqlmock.AnyArg(), referenceID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	err = checker.markReferenceDeleted(t.Context(), referenceID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChecker_MarkReferenceDeleted_ChangedSinceCheck(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	checker := NewChecker(db, nil)
+
+	// Reference sudah bukan draft atau outbox-nya sedang berjalan
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE achievement_references SET status = 'deleted'`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// Execute
+	err = checker.markReferenceDeleted(t.Context(), uuid.New())
+
+	// Assert
+	assert.ErrorIs(t, err, errChangedSinceCheck)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReport_Unresolved(t *testing.T) {
+	report := Report{Issues: []Issue{{Fixed: true}, {Fixed: false}, {Repair: ""}}}
+	assert.Equal(t, 2, report.Unresolved())
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	config "POJECT_UAS/Config"
+	"POJECT_UAS/middleware"
+	"POJECT_UAS/model"
+	"POJECT_UAS/seed"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// demoTokenTTL masa berlaku token di mode demo
+const demoTokenTTL = 24 * time.Hour
+
+// demoUser user in-memory untuk mode --demo
+type demoUser struct {
+	Profile      model.UserProfile
+	PasswordHash []byte
+}
+
+// demoUserStore penyimpanan user in-memory (read-only setelah dibuat), dipakai hanya saat --demo
+type demoUserStore struct {
+	users map[string]*demoUser // key: username dan email
+}
+
+func newDemoUserStore() *demoUserStore {
+	store := &demoUserStore{users: make(map[string]*demoUser)}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(seed.DemoPassword), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+
+	// User dan permission demo sama dengan yang dibuat oleh "seed --demo-data"
+	for _, u := range seed.DemoUsers() {
+		store.add(hash, u)
+	}
+
+	return store
+}
+
+func (s *demoUserStore) add(passwordHash []byte, u seed.User) {
+	profile := model.UserProfile{
+		ID:       seed.UserID(u.Username),
+		Username: u.Username,
+		Email:    u.Email,
+		FullName: u.FullName,
+		Role: model.RoleInfo{
+			ID:   seed.RoleID(u.Role),
+			Name: u.Role,
+		},
+	}
+
+	for _, p := range seed.PermissionsForRole(u.Role) {
+		profile.Permissions = append(profile.Permissions, model.Permission{
+			ID:       seed.PermissionID(p.Name()),
+			Name:     p.Name(),
+			Resource: p.Resource,
+			Action:   p.Action,
+		})
+	}
+
+	user := &demoUser{Profile: profile, PasswordHash: passwordHash}
+	s.users[u.Username] = user
+	s.users[u.Email] = user
+}
+
+// Authenticate mencari user berdasarkan username/email dan memvalidasi password
+func (s *demoUserStore) Authenticate(credential, password string) (*demoUser, bool) {
+	user, ok := s.users[credential]
+	if !ok {
+		return nil, false
+	}
+
+	if bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)) != nil {
+		return nil, false
+	}
+
+	return user, true
+}
+
+// registerDemoRoutes mendaftarkan endpoint demo yang tidak membutuhkan database
+func registerDemoRoutes(app *fiber.App, store *demoUserStore) {
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "pong",
+			"status":  "API is running (demo mode)",
+			"version": "1.0.0",
+		})
+	})
+
+	v1 := app.Group("/api/v1")
+
+	auth := v1.Group("/auth")
+	auth.Post("/login", func(c *fiber.Ctx) error {
+		var req model.LoginRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		user, ok := store.Authenticate(req.Credential, req.Password)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "kredensial salah",
+			})
+		}
+
+		token, expiresAt, err := generateDemoToken(user.Profile)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to generate token",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "login berhasil",
+			"data": model.LoginResponse{
+				Token:     token,
+				ExpiresAt: expiresAt,
+				Profile:   user.Profile,
+			},
+		})
+	})
+
+	// Endpoint demo di bawah ini memakai JWTAuth yang sama dengan mode normal
+	protected := v1.Group("", middleware.JWTAuth(nil))
+
+	protected.Get("/auth/profile", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"message": "success",
+			"data": fiber.Map{
+				"user_id":  middleware.GetUserID(c),
+				"username": middleware.GetUsername(c),
+				"email":    middleware.GetEmail(c),
+			},
+		})
+	})
+
+	protected.Get("/achievements", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"message": "Achievement list (demo)",
+		})
+	})
+
+	protected.Get("/users", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"message": "Users list (admin demo)",
+		})
+	})
+
+	protected.Get("/reports/statistics", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"message": "Statistics demo",
+		})
+	})
+}
+
+// generateDemoToken membuat JWT dengan format klaim yang sama seperti AuthRepository
+func generateDemoToken(profile model.UserProfile) (string, time.Time, error) {
+	permList := make([]map[string]string, len(profile.Permissions))
+	for i, p := range profile.Permissions {
+		permList[i] = map[string]string{
+			"name":     p.Name,
+			"resource": p.Resource,
+			"action":   p.Action,
+		}
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(demoTokenTTL)
+
+	claims := jwt.MapClaims{
+		"user_id":     profile.ID.String(),
+		"username":    profile.Username,
+		"email":       profile.Email,
+		"role_id":     profile.Role.ID.String(),
+		"permissions": permList,
+		"jti":         uuid.New().String(),
+		"iat":         now.Unix(),
+		"exp":         expiresAt.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	signed, err := token.SignedString([]byte(config.GetJWTSecret()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, expiresAt, nil
+}
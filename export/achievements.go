@@ -0,0 +1,45 @@
+package export
+
+import "POJECT_UAS/model"
+
+// AchievementColumns header kolom ekspor daftar prestasi
+var AchievementColumns = []interface{}{
+	"No",
+	"NIM",
+	"Nama Mahasiswa",
+	"Program Studi",
+	"Angkatan",
+	"Tipe Prestasi",
+	"Judul",
+	"Deskripsi",
+	"Tingkat",
+	"Status",
+	"Dibuat",
+	"Diajukan",
+	"Diverifikasi",
+	"Diverifikasi Oleh",
+	"Catatan Penolakan",
+	"ID Prestasi",
+}
+
+// AchievementRow isi kolom satu prestasi sesuai AchievementColumns. no nomor urut mulai 1.
+func AchievementRow(no int64, row model.AchievementExportRow) []interface{} {
+	return []interface{}{
+		no,
+		row.StudentIDNumber,
+		row.StudentName,
+		row.ProgramStudy,
+		row.AcademicYear,
+		row.Achievement.AchievementType,
+		row.Achievement.Title,
+		row.Achievement.Description,
+		row.Achievement.DetailsLevel(),
+		row.Status,
+		row.CreatedAt,
+		row.SubmittedAt,
+		row.VerifiedAt,
+		row.VerifierName,
+		row.RejectionNote,
+		row.ID.String(),
+	}
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// utf8BOM membuat Excel membaca CSV sebagai UTF-8 (nama dengan huruf non-ASCII)
+const utf8BOM = "\ufeff"
+
+// CSVWriter RowWriter untuk CSV
+type CSVWriter struct {
+	w        *csv.Writer
+	out      io.Writer
+	wroteBOM bool
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w), out: w}
+}
+
+func (c *CSVWriter) WriteRow(cells ...interface{}) error {
+	if !c.wroteBOM {
+		c.wroteBOM = true
+		if _, err := io.WriteString(c.out, utf8BOM); err != nil {
+			return err
+		}
+	}
+
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		text, number := formatCell(cell)
+		if !number {
+			text = escapeFormula(text)
+		}
+		record[i] = text
+	}
+	return c.w.Write(record)
+}
+
+func (c *CSVWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula mencegah teks dari user (judul, deskripsi) dijalankan sebagai formula
+// ketika CSV dibuka di spreadsheet
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
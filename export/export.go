@@ -0,0 +1,92 @@
+// Package export menulis data tabular (daftar prestasi, statistik) ke CSV atau XLSX
+// secara streaming, baris demi baris, sehingga ekspor besar tidak perlu dimuat ke memori.
+package export
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Format file ekspor
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var Formats = []string{FormatCSV, FormatXLSX}
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// dateTimeLayout format tanggal di file ekspor
+const dateTimeLayout = "2006-01-02 15:04:05"
+
+// RowWriter penulis baris. Nilai sel boleh string, int, int64, float64, time.Time,
+// *time.Time atau nil (sel kosong). Close wajib dipanggil untuk menyelesaikan file,
+// tetapi tidak menutup writer di bawahnya.
+type RowWriter interface {
+	WriteRow(cells ...interface{}) error
+	Close() error
+}
+
+// NewWriter membuat RowWriter untuk format. sheet hanya dipakai XLSX.
+func NewWriter(format string, w io.Writer, sheet string) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatXLSX:
+		return NewXLSXWriter(w, sheet), nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// IsValidFormat true jika format didukung
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatXLSX
+}
+
+// ContentType MIME type file untuk format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FileName nama file unduhan, misalnya "prestasi-20240517-150405.xlsx"
+func FileName(prefix, format string, at time.Time) string {
+	return prefix + "-" + at.Format("20060102-150405") + "." + format
+}
+
+// formatCell mengubah nilai sel menjadi teks. number true jika sel berisi angka.
+func formatCell(value interface{}) (text string, number bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, false
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case time.Time:
+		if v.IsZero() {
+			return "", false
+		}
+		return v.Format(dateTimeLayout), false
+	case *time.Time:
+		if v == nil {
+			return "", false
+		}
+		return formatCell(*v)
+	case *string:
+		if v == nil {
+			return "", false
+		}
+		return *v, false
+	}
+	return "", false
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"POJECT_UAS/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, "")
+	assert.NoError(t, err)
+
+	at := time.Date(2024, 5, 17, 9, 30, 0, 0, time.UTC)
+	var missing *time.Time
+
+	// Execute
+	assert.NoError(t, w.WriteRow("NIM", "Judul", "Jumlah", "Tanggal", "Diverifikasi"))
+	assert.NoError(t, w.WriteRow("434221001", "=HYPERLINK(\"x\")", int64(3), at, missing))
+	assert.NoError(t, w.Close())
+
+	// Assert: BOM UTF-8, formula di-escape, angka tidak
+	assert.Equal(t, "\ufeffNIM,Judul,Jumlah,Tanggal,Diverifikasi\n"+
+		"434221001,\"'=HYPERLINK(\"\"x\"\")\",3,2024-05-17 09:30:00,\n", buf.String())
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf, "Prestasi [2024]")
+	assert.NoError(t, err)
+
+	// Execute
+	assert.NoError(t, w.WriteRow("NIM", "Judul", "Jumlah"))
+	assert.NoError(t, w.WriteRow("0434221001", "Juara <1> & \"terbaik\"", 2.5))
+	assert.NoError(t, w.Close())
+
+	// Assert
+	files := readZip(t, buf.Bytes())
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files, "xl/styles.xml")
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Prestasi -2024-" sheetId="1" r:id="rId1"/>`)
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row r="1"><c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">NIM</t></is></c>`)
+	// NIM tetap teks agar angka 0 di depan tidak hilang
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">0434221001</t></is></c>`)
+	assert.Contains(t, sheet, `Juara &lt;1&gt; &amp; &#34;terbaik&#34;`)
+	assert.Contains(t, sheet, `<c r="C2"><v>2.5</v></c>`)
+	assert.True(t, strings.HasSuffix(sheet, `</row></sheetData></worksheet>`))
+}
+
+func TestXLSXWriter_EmptyWorkbook(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewXLSXWriter(&buf, "")
+	assert.NoError(t, w.Close())
+
+	files := readZip(t, buf.Bytes())
+	assert.Contains(t, files["xl/workbook.xml"], `name="Sheet1"`)
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<sheetData></sheetData>`)
+}
+
+func TestColumnName(t *testing.T) {
+	for index, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, name, columnName(index))
+	}
+}
+
+func TestAchievementRow_MatchesColumns(t *testing.T) {
+	row := AchievementRow(1, model.AchievementExportRow{})
+	assert.Len(t, row, len(AchievementColumns))
+}
+
+func TestNewWriter_UnsupportedFormat(t *testing.T) {
+	_, err := NewWriter("pdf", io.Discard, "")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
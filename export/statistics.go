@@ -0,0 +1,46 @@
+package export
+
+import "POJECT_UAS/model"
+
+// WriteStatistics menulis statistik sebagai beberapa tabel berurutan yang dipisah baris
+// kosong: ringkasan, per tipe, per tingkat, per periode dan top mahasiswa
+func WriteStatistics(w RowWriter, stats *model.AchievementStatistics) error {
+	summary := stats.Summary
+	rows := [][]interface{}{
+		{"Ringkasan", "Jumlah"},
+		{"Total Prestasi", summary.TotalAchievements},
+		{"Terverifikasi", summary.VerifiedAchievements},
+		{"Menunggu Verifikasi", summary.PendingAchievements},
+		{"Ditolak", summary.RejectedAchievements},
+		{"Jumlah Mahasiswa", summary.TotalStudents},
+		{"Data Mulai", summary.DateRange.StartDate},
+		{"Data Sampai", summary.DateRange.EndDate},
+		nil,
+		{"Tipe Prestasi", "Jumlah", "Persentase"},
+	}
+	for _, t := range stats.TotalByType {
+		rows = append(rows, []interface{}{t.AchievementType, t.Count, t.Percentage})
+	}
+
+	rows = append(rows, nil, []interface{}{"Tingkat", "Jumlah", "Persentase"})
+	for _, l := range stats.CompetitionLevels {
+		rows = append(rows, []interface{}{l.Level, l.Count, l.Percentage})
+	}
+
+	rows = append(rows, nil, []interface{}{"Periode", "Jumlah", "Mulai", "Sampai"})
+	for _, p := range stats.TotalByPeriod {
+		rows = append(rows, []interface{}{p.Period, p.Count, p.StartDate.Format("2006-01-02"), p.EndDate.Format("2006-01-02")})
+	}
+
+	rows = append(rows, nil, []interface{}{"NIM", "Nama Mahasiswa", "Program Studi", "Angkatan", "Total Prestasi", "Terverifikasi"})
+	for _, s := range stats.TopStudents {
+		rows = append(rows, []interface{}{s.StudentIDNumber, s.FullName, s.ProgramStudy, s.AcademicYear, s.TotalCount, s.VerifiedCount})
+	}
+
+	for _, row := range rows {
+		if err := w.WriteRow(row...); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
@@ -0,0 +1,175 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Batas nama sheet dari Excel
+const maxSheetNameLength = 31
+
+// File tetap workbook XLSX dengan satu sheet. Isi sheet ditulis terakhir agar bisa
+// di-stream ke zip tanpa menyimpan baris di memori.
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	// Style 1: huruf tebal untuk header
+	{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+// XLSXWriter RowWriter untuk XLSX dengan satu sheet. Baris pertama ditulis tebal sebagai
+// header. Teks disimpan sebagai inline string sehingga tidak perlu shared string table.
+type XLSXWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	name  string
+	rows  int
+	err   error
+}
+
+func NewXLSXWriter(w io.Writer, sheet string) *XLSXWriter {
+	return &XLSXWriter{zip: zip.NewWriter(w), name: sheetName(sheet)}
+}
+
+// start menulis bagian tetap workbook lalu membuka sheet
+func (x *XLSXWriter) start() error {
+	for _, part := range xlsxParts {
+		if err := x.writePart(part.name, part.content); err != nil {
+			return err
+		}
+	}
+
+	// workbook.xml dibuat di sini karena memuat nama sheet
+	var name strings.Builder
+	xml.EscapeText(&name, []byte(x.name))
+	workbook := xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	if err := x.writePart("xl/workbook.xml", workbook); err != nil {
+		return err
+	}
+
+	w, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	x.sheet = bufio.NewWriter(w)
+	_, err = x.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return err
+}
+
+func (x *XLSXWriter) writePart(name, content string) error {
+	w, err := x.zip.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+	return err
+}
+
+func (x *XLSXWriter) WriteRow(cells ...interface{}) error {
+	if x.err != nil {
+		return x.err
+	}
+	if x.sheet == nil {
+		if x.err = x.start(); x.err != nil {
+			return x.err
+		}
+	}
+
+	x.rows++
+	row := strconv.Itoa(x.rows)
+	style := ""
+	if x.rows == 1 {
+		style = ` s="1"`
+	}
+
+	w := x.sheet
+	w.WriteString(`<row r="` + row + `">`)
+	for i, cell := range cells {
+		text, number := formatCell(cell)
+		if text == "" {
+			continue
+		}
+		ref := columnName(i) + row
+		if number {
+			w.WriteString(`<c r="` + ref + `"` + style + `><v>` + text + `</v></c>`)
+			continue
+		}
+		w.WriteString(`<c r="` + ref + `"` + style + ` t="inlineStr"><is><t xml:space="preserve">`)
+		if x.err = xml.EscapeText(w, []byte(text)); x.err != nil {
+			return x.err
+		}
+		w.WriteString(`</t></is></c>`)
+	}
+	_, x.err = w.WriteString(`</row>`)
+	return x.err
+}
+
+func (x *XLSXWriter) Close() error {
+	if x.err != nil {
+		return x.err
+	}
+	if x.sheet == nil {
+		if err := x.start(); err != nil {
+			return err
+		}
+	}
+	if _, err := x.sheet.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// columnName nama kolom Excel dari indeks 0: A, B, ..., Z, AA, ...
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// sheetName nama sheet yang valid: tanpa karakter yang dilarang Excel, maksimal 31 karakter
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > maxSheetNameLength {
+		name = string(runes[:maxSheetNameLength])
+	}
+	if name == "" {
+		name = "Sheet1"
+	}
+	return name
+}
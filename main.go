@@ -1,68 +1,71 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
+	config "POJECT_UAS/Config"
+	route "POJECT_UAS/Routes"
+	"POJECT_UAS/cache"
+	"POJECT_UAS/middleware"
+	"POJECT_UAS/notify"
+	"POJECT_UAS/realtime"
+	"POJECT_UAS/report"
+	"POJECT_UAS/repository"
+	"POJECT_UAS/service"
+	"POJECT_UAS/storage"
+	"POJECT_UAS/userimport"
+	"POJECT_UAS/webhook"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
 
-	fiberSwagger "github.com/swaggo/fiber-swagger"
 	_ "POJECT_UAS/docs"
+	fiberSwagger "github.com/swaggo/fiber-swagger"
 )
 
-// JWT Secret key untuk demo
-const jwtSecret = "your-super-secret-jwt-key-for-demo-presentation-2024"
-
-// JWT Claims structure
-type Claims struct {
-	UserID      string   `json:"sub"`
-	Username    string   `json:"username"`
-	Email       string   `json:"email"`
-	Role        string   `json:"role"`
-	Permissions []string `json:"permissions"`
-	jwt.RegisteredClaims
-}
+const (
+	shutdownTimeout    = 30 * time.Second // batas waktu menunggu request selesai saat server dimatikan
+	workerDrainTimeout = 30 * time.Second // batas waktu menunggu worker selesai setelah request berhenti
+)
 
-// Generate JWT Token dengan expiration
-func generateJWTToken(userID, username, email, role string, permissions []string) (string, error) {
-	// Set expiration time (24 hours from now)
-	expirationTime := time.Now().Add(24 * time.Hour)
-	
-	// Create claims
-	claims := &Claims{
-		UserID:      userID,
-		Username:    username,
-		Email:       email,
-		Role:        role,
-		Permissions: permissions,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "prestasi-api",
-			Subject:   userID,
-		},
-	}
-	
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	
-	// Sign token with secret
-	tokenString, err := token.SignedString([]byte(jwtSecret))
+func main() {
+	cfg, args, err := config.Load(os.Args[1:])
 	if err != nil {
-		return "", err
+		log.Fatal("Konfigurasi tidak valid:\n", err)
+	}
+
+	// Subcommand CLI (migrate, ...) dijalankan tanpa menyalakan server
+	if len(args) > 0 {
+		if err := runCommand(cfg, args); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	log.Println("Konfigurasi:", cfg)
+	if cfg.UsesDefaultJWTSecret() {
+		log.Println("⚠️  JWT_SECRET belum di-set, memakai secret bawaan (hanya untuk development)")
+	}
+	if cfg.Report.SigningKey == "" {
+		log.Println("⚠️  REPORT_SIGNING_KEY belum di-set, kunci tanda tangan laporan diturunkan dari JWT secret (hanya untuk development)")
 	}
-	
-	return tokenString, nil
-}
 
-func main() {
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		AppName: "Sistem Pelaporan Prestasi Mahasiswa API v1.0.0",
+		// Cukup untuk upload lampiran maksimal ditambah overhead multipart
+		BodyLimit: int(cfg.Storage.MaxFileSize)*cfg.Storage.MaxFiles + 1<<20,
 	})
 
 	// CORS middleware
@@ -71,336 +74,336 @@ func main() {
 		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
 		AllowHeaders: "Origin,Content-Type,Accept,Authorization",
 	}))
+	app.Use(config.LoggerMiddleware)
 
 	// =========================
 	// Swagger UI
 	// =========================
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 
-	// Health check
-	app.Get("/ping", func(c *fiber.Ctx) error {
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"message": "pong",
-			"status":  "API is running",
-			"version": "1.0.0",
-		})
-	})
+	// API info
+	app.Get("/api/v1", apiInfo(cfg.App.Demo))
 
-	// API v1 routes
-	v1 := app.Group("/api/v1")
+	// Worker latar belakang dihentikan dan ditunggu saat server dimatikan
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	startWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
 
-	// API info
-	v1.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"message": "Sistem Pelaporan Prestasi Mahasiswa API",
-			"version": "1.0.0",
-			"features": []string{
-				"JWT Authentication & Authorization",
-				"Role-Based Access Control (RBAC)",
-				"Achievement Management (CRUD)",
-				"Verification Workflow",
-				"Statistics & Reporting",
-				"Dual Database (PostgreSQL + MongoDB)",
-			},
-		})
-	})
+	var db *sql.DB
+	var mongoDB *mongo.Database
+	var hub *realtime.Hub
 
-	// =========================
-	// Authentication
-	// =========================
-	auth := v1.Group("/auth")
+	if cfg.App.Demo {
+		log.Println("⚠️  Demo mode: menggunakan user in-memory, data tidak disimpan")
+		registerDemoRoutes(app, newDemoUserStore())
+	} else {
+		db = config.InitDB(cfg.Postgres)
+		mongoDB = config.InitMongoDB(cfg.Mongo)
 
-	auth.Post("/login", func(c *fiber.Ctx) error {
-		// Parse request body
-		var loginReq struct {
-			Username string `json:"username"`
-			Password string `json:"password"`
-		}
-		
-		if err := c.BodyParser(&loginReq); err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "invalid request body",
-				"message": "Please provide valid JSON with username and password",
-			})
-		}
-		
-		// Demo user data (in real app, this would be from database)
-		var userID, username, email, role string
-		var permissions []string
-		
-		// Simple demo authentication (in real app, verify against database)
-		switch loginReq.Username {
-		case "student123", "student@example.com":
-			userID = "123e4567-e89b-12d3-a456-426614174000"
-			username = "student123"
-			email = "student@example.com"
-			role = "student"
-			permissions = []string{"achievements:create", "achievements:read", "achievements:update", "achievements:delete"}
-		case "lecturer123", "lecturer@example.com":
-			userID = "223e4567-e89b-12d3-a456-426614174001"
-			username = "lecturer123"
-			email = "lecturer@example.com"
-			role = "lecturer"
-			permissions = []string{"achievements:verify", "achievements:reject", "students:read", "reports:advisee"}
-		case "admin123", "admin@example.com":
-			userID = "323e4567-e89b-12d3-a456-426614174002"
-			username = "admin123"
-			email = "admin@example.com"
-			role = "admin"
-			permissions = []string{"users:*", "achievements:*", "reports:*", "system:*"}
-		default:
-			return c.Status(401).JSON(fiber.Map{
-				"error": "invalid credentials",
-				"message": "Username or password is incorrect",
-			})
-		}
-		
-		// Generate JWT token with expiration
-		token, err := generateJWTToken(userID, username, email, role, permissions)
+		// Repositories
+		authRepo := repository.NewAuthRepository(db, cfg.JWT.Secret)
+		authRepo.AccessTokenTTL = cfg.JWT.AccessTokenTTL
+		authRepo.RefreshTokenTTL = cfg.JWT.RefreshTokenTTL
+		userRepo := repository.NewUserRepository(db)
+		notificationRepo := repository.NewNotificationRepository(db)
+		achievementRepo := repository.NewAchievementRepository(db, mongoDB)
+		achievementRepo.Outbox.MaxAttempts = cfg.Outbox.MaxAttempts
+
+		attachmentStore, err := newAttachmentStore(cfg.Storage)
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "token generation failed",
-				"message": "Failed to generate authentication token",
-			})
+			log.Fatal("Gagal menyiapkan penyimpanan lampiran: ", err)
 		}
-		
-		// Calculate expiration time for response
-		expiresAt := time.Now().Add(24 * time.Hour)
-		
-		return c.JSON(fiber.Map{
-			"message": "Login successful",
-			"data": fiber.Map{
-				"token": token,
-				"expires_at": expiresAt.Format(time.RFC3339),
-				"expires_in": 86400, // 24 hours in seconds
-				"token_type": "Bearer",
-				"user": fiber.Map{
-					"id":          userID,
-					"username":    username,
-					"email":       email,
-					"full_name":   username + " User",
-					"role":        role,
-					"permissions": permissions,
-				},
-			},
+		attachmentPolicy := storage.DefaultPolicy()
+		attachmentPolicy.MaxFileSize = cfg.Storage.MaxFileSize
+		attachmentPolicy.MaxFiles = cfg.Storage.MaxFiles
+
+		// Middleware
+		revocations := middleware.NewRevocationStore(authRepo.TokenRepo, cfg.JWT.RevocationCacheTTL)
+		permMiddleware := middleware.NewPermissionMiddleware(db)
+		roleMiddleware := middleware.NewRoleMiddleware(db)
+
+		// Realtime: notifikasi baru dikirim ke koneksi SSE/WebSocket penerima
+		hub = realtime.NewHub(newRealtimeBroker(cfg, db))
+		startWorker(func(ctx context.Context) {
+			if err := hub.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				log.Println("Realtime hub berhenti:", err)
+			}
 		})
-	})
 
-	// Token validation endpoint
-	auth.Post("/validate", func(c *fiber.Ctx) error {
-		// Get token from Authorization header
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Status(401).JSON(fiber.Map{
-				"error": "missing authorization header",
-				"message": "Please provide Authorization header with Bearer token",
-			})
+		// Channel notifikasi: realtime selalu aktif, email jika email.enabled
+		channels := []notify.Channel{notify.NewRealtimeChannel(hub)}
+		if cfg.Email.Enabled {
+			emailChannel, emailWorker, err := newEmailChannel(cfg.Email, db)
+			if err != nil {
+				log.Fatal("Gagal menyiapkan email: ", err)
+			}
+			channels = append(channels, emailChannel)
+			startWorker(emailWorker.Run)
+		}
+		notifier := notify.NewDispatcher(notificationRepo, channels...)
+
+		// Services
+		authService := service.NewAuthService(authRepo, revocations)
+		achievementService := service.NewAchievementService(achievementRepo, attachmentStore, attachmentPolicy, notifier)
+		lecturerService := service.NewLecturerService(achievementRepo, notifier)
+		adminService := service.NewAdminService(userRepo, achievementRepo, revocations)
+		adminService.Importer = newUserImporter(cfg.Import, db)
+		roleRepo := repository.NewRoleRepository(db)
+		adminService.RoleRepo = roleRepo
+		roleService := service.NewRoleService(roleRepo)
+		calendar, err := cfg.Statistics.AcademicCalendar()
+		if err != nil {
+			log.Fatal("Kalender akademik tidak valid: ", err)
 		}
-		
-		// Extract token from "Bearer <token>"
-		tokenString := ""
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			tokenString = authHeader[7:]
-		} else {
-			return c.Status(401).JSON(fiber.Map{
-				"error": "invalid authorization format",
-				"message": "Authorization header must be in format: Bearer <token>",
+		statisticsService := service.NewStatisticsService(achievementRepo, calendar, newStatisticsCache(cfg.Statistics, db))
+		if statisticsService.Cache != nil {
+			startWorker(func(ctx context.Context) {
+				listenStatisticsInvalidation(ctx, cfg.Postgres.DSN(), statisticsService)
 			})
 		}
-		
-		// Parse and validate token
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtSecret), nil
-		})
-		
+		reportSeed, err := cfg.Report.SigningSeed(cfg.JWT.Secret)
 		if err != nil {
-			return c.Status(401).JSON(fiber.Map{
-				"error": "invalid token",
-				"message": "Token is invalid or expired",
-				"details": err.Error(),
-			})
+			log.Fatal("Kunci tanda tangan laporan tidak valid: ", err)
 		}
-		
-		if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-			return c.JSON(fiber.Map{
-				"message": "Token is valid",
-				"data": fiber.Map{
-					"user_id":     claims.UserID,
-					"username":    claims.Username,
-					"email":       claims.Email,
-					"role":        claims.Role,
-					"permissions": claims.Permissions,
-					"expires_at":  claims.ExpiresAt.Format(time.RFC3339),
-					"issued_at":   claims.IssuedAt.Format(time.RFC3339),
-				},
-			})
+		statisticsService.Signer, err = report.NewSigner(reportSeed)
+		if err != nil {
+			log.Fatal("Kunci tanda tangan laporan tidak valid: ", err)
 		}
-		
-		return c.Status(401).JSON(fiber.Map{
-			"error": "invalid token claims",
-			"message": "Token claims are invalid",
+		statisticsService.Reports = repository.NewStudentReportRepository(db)
+		statisticsService.PublicBaseURL = cfg.Report.PublicBaseURL
+		notificationService := service.NewNotificationService(notificationRepo, hub)
+		outboxService := service.NewOutboxService(achievementRepo.Outbox, cfg.Outbox.PollInterval, cfg.Outbox.BatchSize)
+		webhookRepo := repository.NewWebhookRepository(db)
+		webhookRepo.MaxAttempts = cfg.Webhook.MaxAttempts
+		webhookService := service.NewWebhookService(webhookRepo)
+		exportJobRepo := repository.NewExportJobRepository(db)
+		exportJobRepo.MaxAttempts = cfg.Export.MaxAttempts
+		exportService := service.NewExportService(achievementRepo, exportJobRepo, attachmentStore)
+		exportService.SyncLimit = cfg.Export.SyncLimit
+		exportService.BatchSize = cfg.Export.BatchSize
+		exportService.PollInterval = cfg.Export.PollInterval
+		exportService.Retention = cfg.Export.Retention
+
+		// Worker outbox: menyinkronkan MongoDB dengan achievement_references
+		startWorker(outboxService.RunWorker)
+
+		// Worker webhook: mengirim event prestasi ke langganan dengan retry
+		webhookWorker := webhook.NewWorker(webhookRepo, webhook.NewSender(cfg.Webhook.Timeout), cfg.Webhook.PollInterval, cfg.Webhook.BatchSize)
+		startWorker(webhookWorker.Run)
+
+		// Worker ekspor: menjalankan job ekspor besar dan menghapus file yang kedaluwarsa
+		startWorker(exportService.RunWorker)
+
+		// Worker revocation: membersihkan daftar token dicabut yang sudah kedaluwarsa
+		startWorker(func(ctx context.Context) {
+			revocations.RunCleanup(ctx, cfg.JWT.RevocationCleanupInterval)
 		})
-	})
 
-	// Refresh token endpoint
-	auth.Post("/refresh", func(c *fiber.Ctx) error {
-		// Get current token
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Status(401).JSON(fiber.Map{
-				"error": "missing authorization header",
-			})
+		route.SetupRoutes(
+			app,
+			authService,
+			achievementService,
+			lecturerService,
+			adminService,
+			statisticsService,
+			outboxService,
+			notificationService,
+			webhookService,
+			exportService,
+			roleService,
+			permMiddleware,
+			roleMiddleware,
+			revocations,
+		)
+	}
+
+	// =========================
+	// Start server
+	// =========================
+	port := cfg.App.Port
+
+	fmt.Println("🚀 Sistem Pelaporan Prestasi Mahasiswa API")
+	fmt.Println("📍 Server :", "http://localhost:"+port)
+	fmt.Println("📚 Swagger:", "http://localhost:"+port+"/swagger/index.html")
+	fmt.Println("💚 Health :", "http://localhost:"+port+"/ping")
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(":" + port)
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case err := <-listenErr:
+		log.Println("Server berhenti:", err)
+	case sig := <-quit:
+		log.Printf("Menerima %s, mematikan server...", sig)
+	}
+
+	shutdown(app, hub, stopWorkers, &workers, db, mongoDB)
+}
+
+// shutdown menutup stream realtime, berhenti menerima request, menunggu request yang berjalan,
+// lalu menghentikan worker dan menunggu pekerjaan yang sedang diproses sebelum koneksi database
+// ditutup. Stream SSE/WebSocket hanya berakhir saat subscription ditutup, jadi hub ditutup lebih
+// dulu agar tidak menghabiskan batas waktu shutdown.
+func shutdown(app *fiber.App, hub *realtime.Hub, stopWorkers context.CancelFunc, workers *sync.WaitGroup, db *sql.DB, mongoDB *mongo.Database) {
+	if hub != nil {
+		hub.Close()
+	}
+
+	if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+		log.Println("Gagal mematikan server dengan bersih:", err)
+	}
+
+	// Worker punya batas waktu sendiri, terpisah dari waktu yang dipakai request
+	stopWorkers()
+	drained := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(workerDrainTimeout):
+		log.Println("Worker belum selesai setelah", workerDrainTimeout, ", tetap dimatikan")
+	}
+
+	if mongoDB != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := mongoDB.Client().Disconnect(ctx); err != nil {
+			log.Println("Gagal menutup koneksi MongoDB:", err)
 		}
-		
-		tokenString := ""
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			tokenString = authHeader[7:]
+	}
+	if db != nil {
+		if err := db.Close(); err != nil {
+			log.Println("Gagal menutup koneksi PostgreSQL:", err)
 		}
-		
-		// Parse token (even if expired, we can still get claims)
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtSecret), nil
-		})
-		
-		if err != nil {
-			// For refresh, we try to parse even expired tokens
-			// In JWT v5, we handle this differently
-			parser := jwt.NewParser(jwt.WithoutClaimsValidation())
-			token, err := parser.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-				return []byte(jwtSecret), nil
-			})
-			
+	}
+
+	log.Println("Server berhenti")
+}
+
+// newRealtimeBroker membuat Broker sesuai realtime.broker
+func newRealtimeBroker(cfg *config.Config, db *sql.DB) realtime.Broker {
+	if cfg.Realtime.Broker == config.RealtimePostgres {
+		return realtime.NewPostgresBroker(db, cfg.Postgres.DSN())
+	}
+	return realtime.NewMemoryBroker()
+}
+
+// newStatisticsCache cache hasil statistik sesuai statistics.cache_backend, nil jika none
+func newStatisticsCache(cfg config.StatisticsConfig, db *sql.DB) *cache.Cache {
+	switch cfg.CacheBackend {
+	case config.StatsCacheMemory:
+		return cache.New(cache.NewLRUStore(cfg.CacheSize), cfg.CacheTTL, "stats:")
+	case config.StatsCachePostgres:
+		return cache.New(cache.NewPostgresStore(db), cfg.CacheTTL, "stats:")
+	}
+	return nil
+}
+
+// listenStatisticsInvalidation menghapus cache statistik setiap status prestasi berubah.
+// Event dikirim trigger achievement_references lewat NOTIFY sehingga semua replika ikut.
+func listenStatisticsInvalidation(ctx context.Context, dsn string, statisticsService *service.StatisticsService) {
+	err := cache.Listen(ctx, dsn, cache.StatusChangedChannel,
+		func(payload string) {
+			studentID, err := uuid.Parse(payload)
 			if err != nil {
-				return c.Status(401).JSON(fiber.Map{
-					"error": "invalid token",
-					"message": "Cannot refresh invalid token",
-				})
+				log.Printf("Cache statistik: payload tidak valid: %q", payload)
+				return
 			}
-			
-			// If we can get claims (even from expired token), generate new one
-			if claims, ok := token.Claims.(*Claims); ok {
-				// Generate new token
-				newToken, err := generateJWTToken(
-					claims.UserID,
-					claims.Username,
-					claims.Email,
-					claims.Role,
-					claims.Permissions,
-				)
-				if err != nil {
-					return c.Status(500).JSON(fiber.Map{
-						"error": "failed to generate new token",
-					})
-				}
-				
-				expiresAt := time.Now().Add(24 * time.Hour)
-				return c.JSON(fiber.Map{
-					"message": "Token refreshed successfully",
-					"data": fiber.Map{
-						"token":      newToken,
-						"expires_at": expiresAt.Format(time.RFC3339),
-						"expires_in": 86400,
-						"token_type": "Bearer",
-					},
-				})
+			if err := statisticsService.InvalidateStudent(ctx, studentID); err != nil {
+				log.Println("Cache statistik: gagal invalidasi:", err)
 			}
-			
-			return c.Status(401).JSON(fiber.Map{
-				"error": "invalid token",
-				"message": "Cannot refresh invalid token",
-			})
-		}
-		
-		// If token is still valid, return new one anyway
-		if claims, ok := token.Claims.(*Claims); ok {
-			newToken, err := generateJWTToken(
-				claims.UserID,
-				claims.Username,
-				claims.Email,
-				claims.Role,
-				claims.Permissions,
-			)
-			if err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"error": "failed to generate new token",
-				})
+		},
+		func() {
+			if err := statisticsService.InvalidateAll(ctx); err != nil {
+				log.Println("Cache statistik: gagal mengosongkan cache:", err)
 			}
-			
-			expiresAt := time.Now().Add(24 * time.Hour)
-			return c.JSON(fiber.Map{
-				"message": "Token refreshed successfully",
-				"data": fiber.Map{
-					"token":      newToken,
-					"expires_at": expiresAt.Format(time.RFC3339),
-					"expires_in": 86400,
-					"token_type": "Bearer",
-				},
-			})
-		}
-		
-		return c.Status(401).JSON(fiber.Map{
-			"error": "invalid token claims",
-		})
-	})
+		},
+	)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Println("Invalidasi cache statistik berhenti:", err)
+	}
+}
 
-	auth.Get("/profile", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"id":        "123",
-			"username":  "student123",
-			"email":     "student@example.com",
-			"full_name": "John Doe",
-			"role":      "student",
-		})
-	})
+// newEmailChannel menyiapkan channel email beserta worker yang mengirim antreannya lewat SMTP
+func newEmailChannel(cfg config.EmailConfig, db *sql.DB) (*notify.EmailChannel, *notify.EmailWorker, error) {
+	templates, err := notify.LoadTemplates()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// =========================
-	// Achievements
-	// =========================
-	achievements := v1.Group("/achievements")
+	queue := repository.NewEmailQueueRepository(db)
+	queue.MaxAttempts = cfg.MaxAttempts
 
-	achievements.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"message": "Achievement list (demo)",
-		})
-	})
+	mailer := &notify.SMTPMailer{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		From:     cfg.From,
+		Timeout:  cfg.Timeout,
+	}
 
-	achievements.Post("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"message": "Achievement created (demo)",
-		})
-	})
+	channel := notify.NewEmailChannel(queue, templates, cfg.LinkBaseURL)
+	worker := notify.NewEmailWorker(queue, mailer, cfg.PollInterval, cfg.BatchSize)
+	return channel, worker, nil
+}
 
-	// =========================
-	// Users (Admin)
-	// =========================
-	users := v1.Group("/users")
+// newUserImporter importer user dari CSV/XLSX, dipakai endpoint admin dan CLI import
+func newUserImporter(cfg config.ImportConfig, db *sql.DB) *userimport.Importer {
+	importer := userimport.NewImporter(db)
+	importer.ProgramStudies = cfg.ProgramStudies
+	importer.MaxRows = cfg.MaxRows
+	return importer
+}
 
-	users.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"message": "Users list (admin demo)",
+// newAttachmentStore membuat AttachmentStore sesuai storage.driver
+func newAttachmentStore(cfg config.StorageConfig) (storage.AttachmentStore, error) {
+	if cfg.Driver == config.StorageS3 {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		return storage.NewS3Store(ctx, storage.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			Bucket:    cfg.S3.Bucket,
+			Region:    cfg.S3.Region,
+			UseSSL:    cfg.S3.UseSSL,
 		})
-	})
+	}
 
-	// =========================
-	// Reports
-	// =========================
-	reports := v1.Group("/reports")
+	return storage.NewLocalStore(cfg.LocalDir)
+}
 
-	reports.Get("/statistics", func(c *fiber.Ctx) error {
+// apiInfo menampilkan informasi singkat API
+func apiInfo(demo bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
-			"message": "Statistics demo",
+			"message": "Sistem Pelaporan Prestasi Mahasiswa API",
+			"version": "1.0.0",
+			"demo":    demo,
+			"features": []string{
+				"JWT Authentication & Authorization",
+				"Role-Based Access Control (RBAC)",
+				"Achievement Management (CRUD)",
+				"Verification Workflow",
+				"Statistics & Reporting",
+				"Dual Database (PostgreSQL + MongoDB)",
+			},
 		})
-	})
-
-	// =========================
-	// Start server
-	// =========================
-	port := "8080"
-
-	fmt.Println("🚀 Sistem Pelaporan Prestasi Mahasiswa API")
-	fmt.Println("📍 Server :", "http://localhost:"+port)
-	fmt.Println("📚 Swagger:", "http://localhost:"+port+"/swagger/index.html")
-	fmt.Println("💚 Health :", "http://localhost:"+port+"/ping")
-
-	log.Fatal(app.Listen(":" + port))
+	}
 }
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -58,3 +60,15 @@ func GetPermissions(c *fiber.Ctx) []map[string]interface{} {
 	permissions, _ := c.Locals("permissions").([]map[string]interface{})
 	return permissions
 }
+
+// GetTokenID helper untuk ambil jti access token dari context
+func GetTokenID(c *fiber.Ctx) string {
+	jti, _ := c.Locals("jti").(string)
+	return jti
+}
+
+// GetTokenExpiresAt helper untuk ambil waktu kedaluwarsa access token dari context
+func GetTokenExpiresAt(c *fiber.Ctx) time.Time {
+	expiresAt, _ := c.Locals("token_expires_at").(time.Time)
+	return expiresAt
+}
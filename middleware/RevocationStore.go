@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"POJECT_UAS/repository"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRevocationCacheTTL lama hasil pengecekan revocation disimpan di memori
+const DefaultRevocationCacheTTL = 30 * time.Second
+
+// DefaultRevocationCleanupInterval jeda pembersihan token yang dicabut dan sudah kedaluwarsa
+const DefaultRevocationCleanupInterval = time.Hour
+
+// maxRevocationCacheEntries batas jumlah entry sebelum entry kedaluwarsa dibersihkan
+const maxRevocationCacheEntries = 10000
+
+// RevocationStore daftar pencabutan token di PostgreSQL dengan cache TTL in-process.
+// Pencabutan dari instance ini langsung berlaku; pencabutan dari replica lain
+// berlaku paling lambat setelah TTL cache habis.
+type RevocationStore struct {
+	TokenRepo *repository.TokenRepository
+	TTL       time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]tokenRevocationEntry
+	users  map[uuid.UUID]userRevocationEntry
+}
+
+type tokenRevocationEntry struct {
+	revoked     bool
+	cachedUntil time.Time
+}
+
+type userRevocationEntry struct {
+	revokedBefore *time.Time
+	cachedUntil   time.Time
+}
+
+func NewRevocationStore(tokenRepo *repository.TokenRepository, ttl time.Duration) *RevocationStore {
+	if ttl <= 0 {
+		ttl = DefaultRevocationCacheTTL
+	}
+	return &RevocationStore{
+		TokenRepo: tokenRepo,
+		TTL:       ttl,
+		tokens:    make(map[string]tokenRevocationEntry),
+		users:     make(map[uuid.UUID]userRevocationEntry),
+	}
+}
+
+// RevokeToken mencabut satu access token (logout)
+func (s *RevocationStore) RevokeToken(jti string, userID uuid.UUID, expiresAt time.Time) error {
+	if err := s.TokenRepo.RevokeAccessToken(jti, userID, expiresAt); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Token yang dicabut cukup di-cache sampai token itu sendiri kedaluwarsa
+	s.tokens[jti] = tokenRevocationEntry{revoked: true, cachedUntil: expiresAt}
+
+	return nil
+}
+
+// RevokeUser mencabut semua sesi milik user
+func (s *RevocationStore) RevokeUser(userID uuid.UUID) error {
+	revokedBefore, err := s.TokenRepo.RevokeUserSessions(userID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[userID] = userRevocationEntry{revokedBefore: &revokedBefore, cachedUntil: time.Now().Add(s.TTL)}
+
+	return nil
+}
+
+// IsRevoked mengecek apakah token dengan jti dan waktu terbit tertentu sudah dicabut
+func (s *RevocationStore) IsRevoked(jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	revokedBefore, err := s.userRevokedBefore(userID)
+	if err != nil {
+		return false, err
+	}
+	// iat berpresisi detik, token yang terbit pada detik yang sama dengan pencabutan ikut dicabut
+	if revokedBefore != nil && !issuedAt.After(*revokedBefore) {
+		return true, nil
+	}
+
+	if jti == "" {
+		return false, nil
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.tokens[jti]
+	s.mu.Unlock()
+	if ok && now.Before(entry.cachedUntil) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := s.TokenRepo.IsAccessTokenRevoked(jti)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepLocked(now)
+	s.tokens[jti] = tokenRevocationEntry{revoked: revoked, cachedUntil: now.Add(s.TTL)}
+
+	return revoked, nil
+}
+
+func (s *RevocationStore) userRevokedBefore(userID uuid.UUID) (*time.Time, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.users[userID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.cachedUntil) {
+		return entry.revokedBefore, nil
+	}
+
+	revokedBefore, err := s.TokenRepo.GetUserSessionsRevokedAt(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepLocked(now)
+	s.users[userID] = userRevocationEntry{revokedBefore: revokedBefore, cachedUntil: now.Add(s.TTL)}
+
+	return revokedBefore, nil
+}
+
+// RunCleanup menghapus token yang dicabut dan sudah kedaluwarsa setiap interval sampai ctx selesai.
+// Token kedaluwarsa sudah ditolak JWTAuth sehingga tidak perlu disimpan lagi.
+func (s *RevocationStore) RunCleanup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRevocationCleanupInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.TokenRepo.DeleteExpiredRevokedTokens(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("revocation: gagal membersihkan token kedaluwarsa: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweepLocked membuang entry kedaluwarsa jika cache sudah terlalu besar (mu harus sudah di-lock)
+func (s *RevocationStore) sweepLocked(now time.Time) {
+	if len(s.tokens)+len(s.users) < maxRevocationCacheEntries {
+		return
+	}
+	for jti, entry := range s.tokens {
+		if !now.Before(entry.cachedUntil) {
+			delete(s.tokens, jti)
+		}
+	}
+	for userID, entry := range s.users {
+		if !now.Before(entry.cachedUntil) {
+			delete(s.users, userID)
+		}
+	}
+}
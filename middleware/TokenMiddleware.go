@@ -2,18 +2,35 @@ package middleware
 
 import (
 	"strings"
+	"time"
 
 	config "POJECT_UAS/Config"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-// JWTAuth memvalidasi header Authorization Bearer dan menyimpan klaim ke Locals
-func JWTAuth() fiber.Handler {
+// JWTAuth memvalidasi header Authorization Bearer dan menyimpan klaim ke Locals.
+// Jika revocations tidak nil, token yang sudah dicabut (logout / revoke sesi) ditolak.
+func JWTAuth(revocations *RevocationStore) fiber.Handler {
+	return jwtAuth(revocations, false)
+}
+
+// JWTAuthStream sama dengan JWTAuth, tetapi jika header Authorization kosong token juga diambil
+// dari query access_token. Hanya untuk endpoint SSE/WebSocket karena EventSource dan WebSocket
+// di browser tidak bisa mengirim header.
+func JWTAuthStream(revocations *RevocationStore) fiber.Handler {
+	return jwtAuth(revocations, true)
+}
+
+func jwtAuth(revocations *RevocationStore, allowQueryToken bool) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// 1. Ekstrak JWT dari header
 		authHeader := c.Get("Authorization")
+		if authHeader == "" && allowQueryToken && c.Query("access_token") != "" {
+			authHeader = "Bearer " + c.Query("access_token")
+		}
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Authorization header required",
@@ -56,6 +73,37 @@ func JWTAuth() fiber.Handler {
 		username, _ := claims["username"].(string)
 		email, _ := claims["email"].(string)
 		roleID, _ := claims["role_id"].(string)
+		jti, _ := claims["jti"].(string)
+
+		var issuedAt, expiresAt time.Time
+		if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+			issuedAt = iat.Time
+		}
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			expiresAt = exp.Time
+		}
+
+		// Tolak token yang sudah dicabut
+		if revocations != nil {
+			userUUID, err := uuid.Parse(userID)
+			if err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "invalid token claims",
+				})
+			}
+
+			revoked, err := revocations.IsRevoked(jti, userUUID, issuedAt)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "failed to check token revocation",
+				})
+			}
+			if revoked {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "token has been revoked",
+				})
+			}
+		}
 
 		// Extract permissions dari token
 		var permissions []map[string]interface{}
@@ -73,6 +121,8 @@ func JWTAuth() fiber.Handler {
 		c.Locals("email", email)
 		c.Locals("role_id", roleID)
 		c.Locals("permissions", permissions)
+		c.Locals("jti", jti)
+		c.Locals("token_expires_at", expiresAt)
 
 		return c.Next()
 	}
@@ -0,0 +1,133 @@
+package migration
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+// Migration satu versi perubahan skema. Migrasi SQL berisi UpSQL/DownSQL,
+// migrasi MongoDB berisi MongoUp/MongoDown. Keduanya dicatat di tabel schema_migrations.
+type Migration struct {
+	Version int
+	Name    string
+
+	UpSQL   string
+	DownSQL string
+
+	MongoUp   func(ctx context.Context, db *mongo.Database) error
+	MongoDown func(ctx context.Context, db *mongo.Database) error
+}
+
+// IsMongo true jika migrasi dijalankan terhadap MongoDB
+func (m Migration) IsMongo() bool {
+	return m.MongoUp != nil
+}
+
+// Kind jenis migrasi untuk ditampilkan di status
+func (m Migration) Kind() string {
+	if m.IsMongo() {
+		return "mongo"
+	}
+	return "sql"
+}
+
+// All mengembalikan semua migrasi (SQL embedded + MongoDB) terurut berdasarkan versi
+func All() ([]Migration, error) {
+	migrations, err := loadSQL(sqlFiles, "sql")
+	if err != nil {
+		return nil, err
+	}
+	migrations = append(migrations, mongoMigrations...)
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d (%s, %s)",
+				migrations[i].Version, migrations[i-1].Name, migrations[i].Name)
+		}
+	}
+
+	return migrations, nil
+}
+
+// loadSQL membaca file <versi>_<nama>.up.sql dan <versi>_<nama>.down.sql
+func loadSQL(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		fileName := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("invalid migration file name %q: must end with .up.sql or .down.sql", fileName)
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		versionPart, name, ok := strings.Cut(base, "_")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid migration file name %q: expected <version>_<name>", fileName)
+		}
+		version, err := strconv.Atoi(versionPart)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", fileName)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, fileName))
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, m.Name, name)
+		}
+
+		if direction == "up" {
+			m.UpSQL = string(content)
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.UpSQL) == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up.sql", m.Version, m.Name)
+		}
+		if strings.TrimSpace(m.DownSQL) == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no down.sql", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	return migrations, nil
+}
@@ -0,0 +1,170 @@
+package migration
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAll_SortedAndComplete(t *testing.T) {
+	// Execute
+	migrations, err := All()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		if i > 0 {
+			assert.Greater(t, m.Version, migrations[i-1].Version)
+		}
+		if m.IsMongo() {
+			assert.NotNil(t, m.MongoDown)
+		} else {
+			assert.NotEmpty(t, m.UpSQL)
+			assert.NotEmpty(t, m.DownSQL)
+		}
+	}
+
+	// Semua tabel yang dipakai repository harus dibuat oleh migrasi
+	var upSQL string
+	for _, m := range migrations {
+		upSQL += m.UpSQL
+	}
+	for _, table := range []string{
+		"users", "roles", "permissions", "role_permissions", "students", "lecturers",
+		"achievement_references", "notifications",
+		"refresh_tokens", "revoked_tokens", "user_session_revocations",
+	} {
+		assert.Contains(t, upSQL, "CREATE TABLE IF NOT EXISTS "+table+" (")
+	}
+}
+
+func TestLoadSQL_MissingDown(t *testing.T) {
+	// Setup
+	fsys := fstest.MapFS{
+		"sql/0001_init.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+	}
+
+	// Execute
+	_, err := loadSQL(fsys, "sql")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "has no down.sql")
+}
+
+func TestLoadSQL_InvalidName(t *testing.T) {
+	// Setup
+	fsys := fstest.MapFS{
+		"sql/init.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+	}
+
+	// Execute
+	_, err := loadSQL(fsys, "sql")
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestMigrator_Up_AppliesPendingOnly(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	migrator := &Migrator{
+		DB: db,
+		Migrations: []Migration{
+			{Version: 1, Name: "create_a", UpSQL: "CREATE TABLE a (id INT)", DownSQL: "DROP TABLE a"},
+			{Version: 2, Name: "create_b", UpSQL: "CREATE TABLE b (id INT)", DownSQL: "DROP TABLE b"},
+		},
+	}
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WithArgs(advisoryLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, name, kind, applied_at FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "name", "kind", "applied_at"}).
+			AddRow(1, "create_a", "sql", time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE b`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).
+		WithArgs(2, "create_b", "sql", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WithArgs(advisoryLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute
+	done, err := migrator.Up(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+	assert.Equal(t, 2, done[0].Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down_RevertsLatest(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	migrator := &Migrator{
+		DB: db,
+		Migrations: []Migration{
+			{Version: 1, Name: "create_a", UpSQL: "CREATE TABLE a (id INT)", DownSQL: "DROP TABLE a"},
+			{Version: 2, Name: "create_b", UpSQL: "CREATE TABLE b (id INT)", DownSQL: "DROP TABLE b"},
+		},
+	}
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, name, kind, applied_at FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "name", "kind", "applied_at"}).
+			AddRow(1, "create_a", "sql", time.Now()).
+			AddRow(2, "create_b", "sql", time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DROP TABLE b`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM schema_migrations WHERE version = \$1`).
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute
+	done, err := migrator.Down(context.Background(), 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+	assert.Equal(t, 2, done[0].Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Up_MongoWithoutConnection(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	migrator := &Migrator{DB: db, Migrations: mongoMigrations}
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, name, kind, applied_at FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "name", "kind", "applied_at"}))
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute
+	done, err := migrator.Up(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "MongoDB connection required")
+	assert.Empty(t, done)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,276 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// advisoryLockID kunci pg_advisory_lock agar dua proses migrate tidak berjalan bersamaan
+const advisoryLockID int64 = 7240815001
+
+const createMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT PRIMARY KEY,
+		name       VARCHAR(255) NOT NULL,
+		kind       VARCHAR(10)  NOT NULL,
+		applied_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+	)
+`
+
+// Migrator menjalankan migrasi dan mencatat versi yang sudah diterapkan di schema_migrations
+type Migrator struct {
+	DB         *sql.DB
+	MongoDB    *mongo.Database
+	Migrations []Migration
+}
+
+// MigrationStatus status satu versi migrasi
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Kind      string
+	AppliedAt *time.Time
+	Missing   bool // tercatat di database tetapi tidak ada di binary ini
+}
+
+type appliedMigration struct {
+	Name      string
+	Kind      string
+	AppliedAt time.Time
+}
+
+func NewMigrator(db *sql.DB, mongoDB *mongo.Database) (*Migrator, error) {
+	migrations, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		DB:         db,
+		MongoDB:    mongoDB,
+		Migrations: migrations,
+	}, nil
+}
+
+// Up menerapkan semua migrasi yang belum diterapkan, berurutan dari versi terkecil
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.Migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig); err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down membatalkan sejumlah steps migrasi terakhir yang sudah diterapkan
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be greater than 0")
+	}
+
+	byVersion := make(map[int]Migration, len(m.Migrations))
+	for _, mig := range m.Migrations {
+		byVersion[mig.Version] = mig
+	}
+
+	var done []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+		for i := 0; i < steps && i < len(versions); i++ {
+			mig, ok := byVersion[versions[i]]
+			if !ok {
+				return fmt.Errorf("migration %04d_%s is applied but unknown to this binary",
+					versions[i], applied[versions[i]].Name)
+			}
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Status menampilkan semua migrasi beserta waktu diterapkan
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if _, err := m.DB.ExecContext(ctx, createMigrationsTable); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx, m.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.Migrations))
+	for _, mig := range m.Migrations {
+		status := MigrationStatus{Version: mig.Version, Name: mig.Name, Kind: mig.Kind()}
+		if a, ok := applied[mig.Version]; ok {
+			appliedAt := a.AppliedAt
+			status.AppliedAt = &appliedAt
+			delete(applied, mig.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Versi yang tercatat tetapi tidak dikenal (misalnya dari binary yang lebih baru)
+	for version, a := range applied {
+		appliedAt := a.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   version,
+			Name:      a.Name,
+			Kind:      a.Kind,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// withLock menjalankan fn di satu koneksi yang memegang advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockID)
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func (m *Migrator) applied(ctx context.Context, q queryer) (map[int]appliedMigration, error) {
+	rows, err := q.QueryContext(ctx, `SELECT version, name, kind, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.Name, &a.Kind, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = a
+	}
+
+	return applied, rows.Err()
+}
+
+// apply menerapkan satu migrasi. Migrasi SQL dan pencatatan versinya berada dalam satu transaksi.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	if mig.IsMongo() {
+		if m.MongoDB == nil {
+			return fmt.Errorf("MongoDB connection required")
+		}
+		if err := mig.MongoUp(ctx, m.MongoDB); err != nil {
+			return err
+		}
+		_, err := conn.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name, kind, applied_at) VALUES ($1, $2, $3, $4)`,
+			mig.Version, mig.Name, mig.Kind(), time.Now())
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, mig.UpSQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name, kind, applied_at) VALUES ($1, $2, $3, $4)`,
+		mig.Version, mig.Name, mig.Kind(), time.Now()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// revert membatalkan satu migrasi dan menghapus catatan versinya
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	if mig.IsMongo() {
+		if m.MongoDB == nil {
+			return fmt.Errorf("MongoDB connection required")
+		}
+		if mig.MongoDown != nil {
+			if err := mig.MongoDown(ctx, m.MongoDB); err != nil {
+				return err
+			}
+		}
+		_, err := conn.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, mig.DownSQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
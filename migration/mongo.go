@@ -0,0 +1,35 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoMigrations migrasi MongoDB, versinya berbagi urutan dengan file SQL
+var mongoMigrations = []Migration{
+	{
+		Version:   6,
+		Name:      "create_achievements_indexes",
+		MongoUp:   createAchievementsIndexes,
+		MongoDown: dropAchievementsIndexes,
+	},
+}
+
+const achievementsStudentIndex = "idx_achievements_student_id_is_deleted"
+
+// createAchievementsIndexes index untuk query prestasi per mahasiswa yang belum dihapus
+func createAchievementsIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("achievements").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "studentId", Value: 1}, {Key: "isDeleted", Value: 1}},
+		Options: options.Index().SetName(achievementsStudentIndex),
+	})
+	return err
+}
+
+func dropAchievementsIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("achievements").Indexes().DropOne(ctx, achievementsStudentIndex)
+	return err
+}
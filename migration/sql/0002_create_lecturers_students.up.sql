@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS lecturers (
+    id          UUID PRIMARY KEY,
+    user_id     UUID         NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    lecturer_id VARCHAR(20)  NOT NULL UNIQUE,
+    department  VARCHAR(100) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS students (
+    id            UUID PRIMARY KEY,
+    user_id       UUID         NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    student_id    VARCHAR(20)  NOT NULL UNIQUE,
+    program_study VARCHAR(100) NOT NULL DEFAULT '',
+    academic_year VARCHAR(10)  NOT NULL DEFAULT '',
+    advisor_id    UUID         REFERENCES lecturers (id) ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_students_advisor_id ON students (advisor_id);
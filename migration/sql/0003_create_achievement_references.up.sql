@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS achievement_references (
+    id                   UUID PRIMARY KEY,
+    student_id           UUID        NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+    mongo_achievement_id VARCHAR(24) NOT NULL UNIQUE,
+    status               VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'submitted', 'verified', 'rejected', 'deleted')),
+    submitted_at         TIMESTAMPTZ,
+    verified_at          TIMESTAMPTZ,
+    verified_by          UUID REFERENCES users (id) ON DELETE SET NULL,
+    rejection_note       TEXT,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_references_student_id ON achievement_references (student_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_achievement_references_status ON achievement_references (status);
+CREATE INDEX IF NOT EXISTS idx_achievement_references_created_at ON achievement_references (created_at DESC);
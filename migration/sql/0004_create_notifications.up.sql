@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id         UUID PRIMARY KEY,
+    user_id    UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type       VARCHAR(50)  NOT NULL,
+    title      VARCHAR(200) NOT NULL,
+    message    TEXT         NOT NULL,
+    data       JSONB,
+    is_read    BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id, is_read, created_at DESC);
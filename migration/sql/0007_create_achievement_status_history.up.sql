@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS achievement_status_history (
+    id                       UUID PRIMARY KEY,
+    achievement_reference_id UUID        NOT NULL REFERENCES achievement_references (id),
+    from_status              VARCHAR(20),
+    to_status                VARCHAR(20) NOT NULL,
+    changed_by               UUID REFERENCES users (id) ON DELETE SET NULL,
+    note                     TEXT,
+    created_at               TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_status_history_reference
+    ON achievement_status_history (achievement_reference_id, created_at);
+
+-- History bersifat append-only: isi baris tidak boleh diubah atau dihapus.
+-- Pengecualian hanya untuk changed_by yang di-NULL-kan oleh ON DELETE SET NULL saat user dihapus.
+CREATE OR REPLACE FUNCTION achievement_status_history_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+       AND NEW.changed_by IS NULL
+       AND (NEW.id, NEW.achievement_reference_id, NEW.from_status, NEW.to_status, NEW.note, NEW.created_at)
+           IS NOT DISTINCT FROM
+           (OLD.id, OLD.achievement_reference_id, OLD.from_status, OLD.to_status, OLD.note, OLD.created_at) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'achievement_status_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_achievement_status_history_append_only
+    BEFORE UPDATE OR DELETE ON achievement_status_history
+    FOR EACH ROW EXECUTE FUNCTION achievement_status_history_append_only();
+
+-- Prestasi yang sudah ada dicatat dengan status terakhirnya sebagai titik awal history
+INSERT INTO achievement_status_history (id, achievement_reference_id, from_status, to_status, changed_by, note, created_at)
+SELECT md5(ar.id::text || ':history-baseline')::uuid, ar.id, NULL, ar.status, NULL,
+       'status awal saat history mulai dicatat', ar.updated_at
+FROM achievement_references ar
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_achievement_references_created_at ON achievement_references (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at DESC);
+
+DROP INDEX IF EXISTS idx_achievement_references_created_at_id;
+DROP INDEX IF EXISTS idx_users_created_at_id;
@@ -0,0 +1,6 @@
+-- Index (created_at, id) untuk keyset pagination; id sebagai tie-breaker agar urutan stabil
+CREATE INDEX IF NOT EXISTS idx_achievement_references_created_at_id ON achievement_references (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users (created_at DESC, id DESC);
+
+DROP INDEX IF EXISTS idx_achievement_references_created_at;
+DROP INDEX IF EXISTS idx_users_created_at;
@@ -0,0 +1,23 @@
+-- Outbox operasi MongoDB yang dicatat dalam transaksi yang sama dengan achievement_references,
+-- lalu dijalankan (atau dikompensasi) oleh worker
+CREATE TABLE IF NOT EXISTS achievement_outbox (
+    id                       UUID PRIMARY KEY,
+    achievement_reference_id UUID        NOT NULL REFERENCES achievement_references (id) ON DELETE CASCADE,
+    mongo_achievement_id     VARCHAR(24) NOT NULL,
+    operation                VARCHAR(30) NOT NULL
+        CHECK (operation IN ('create_achievement', 'delete_achievement')),
+    payload                  JSONB       NOT NULL DEFAULT '{}',
+    status                   VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'done', 'failed', 'compensated')),
+    attempts                 INT         NOT NULL DEFAULT 0,
+    last_error               TEXT,
+    next_attempt_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until             TIMESTAMPTZ,
+    created_at               TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at             TIMESTAMPTZ
+);
+
+-- Hanya operasi yang belum selesai yang dicari worker
+CREATE INDEX IF NOT EXISTS idx_achievement_outbox_due ON achievement_outbox (next_attempt_at)
+    WHERE status IN ('pending', 'processing');
+CREATE INDEX IF NOT EXISTS idx_achievement_outbox_reference ON achievement_outbox (achievement_reference_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS email_queue;
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,30 @@
+-- Preferensi notifikasi per user. Channel yang tidak ada di disabled_channels dianggap aktif,
+-- sehingga channel baru otomatis aktif untuk semua user.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id           UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    language          VARCHAR(2)  NOT NULL DEFAULT 'id' CHECK (language IN ('id', 'en')),
+    disabled_channels TEXT[]      NOT NULL DEFAULT '{}',
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Antrean email yang sudah dirender, dikirim oleh worker dengan retry
+CREATE TABLE IF NOT EXISTS email_queue (
+    id              UUID PRIMARY KEY,
+    notification_id UUID         REFERENCES notifications (id) ON DELETE SET NULL,
+    user_id         UUID         REFERENCES users (id) ON DELETE CASCADE,
+    to_address      VARCHAR(255) NOT NULL,
+    subject         VARCHAR(255) NOT NULL,
+    html_body       TEXT         NOT NULL,
+    text_body       TEXT         NOT NULL,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'sending', 'sent', 'failed')),
+    attempts        INT          NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    locked_until    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_queue_due ON email_queue (next_attempt_at)
+    WHERE status IN ('pending', 'sending');
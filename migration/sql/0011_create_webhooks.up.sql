@@ -0,0 +1,49 @@
+-- Langganan webhook yang dikelola admin. events kosong berarti semua event.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id         UUID PRIMARY KEY,
+    name       VARCHAR(100)  NOT NULL,
+    url        VARCHAR(2048) NOT NULL,
+    secret     VARCHAR(255)  NOT NULL,
+    events     TEXT[]        NOT NULL DEFAULT '{}',
+    is_active  BOOLEAN       NOT NULL DEFAULT TRUE,
+    created_by UUID          REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+-- Satu baris per event per langganan, dibuat dalam transaksi yang sama dengan perubahan status.
+-- id default gen_random_uuid() (PostgreSQL 13+) karena satu event bisa menjadi banyak delivery.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id  UUID        NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id         UUID        NOT NULL,
+    event_type       VARCHAR(50) NOT NULL,
+    payload          JSONB       NOT NULL,
+    status           VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'delivering', 'delivered', 'failed')),
+    attempts         INT         NOT NULL DEFAULT 0,
+    last_status_code INT,
+    last_error       TEXT,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until     TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at)
+    WHERE status IN ('pending', 'delivering');
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries (subscription_id, created_at DESC);
+
+-- Log setiap percobaan pengiriman
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id            UUID PRIMARY KEY,
+    delivery_id   UUID        NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+    attempt       INT         NOT NULL,
+    status_code   INT,
+    error         TEXT,
+    response_body TEXT,
+    duration_ms   INT         NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts (delivery_id, attempt);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_achievement_status_changed ON achievement_references;
+DROP FUNCTION IF EXISTS notify_achievement_status_changed();
+DROP TABLE IF EXISTS statistics_cache;
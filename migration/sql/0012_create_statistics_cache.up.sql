@@ -0,0 +1,29 @@
+-- Cache hasil statistik bersama untuk beberapa replika (statistics.cache_backend = postgres).
+-- UNLOGGED: isinya boleh hilang saat crash, tidak perlu ikut WAL/replikasi.
+CREATE UNLOGGED TABLE IF NOT EXISTS statistics_cache (
+    key        TEXT        PRIMARY KEY,
+    value      BYTEA       NOT NULL,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_statistics_cache_expires_at ON statistics_cache (expires_at)
+    WHERE expires_at IS NOT NULL;
+
+-- Setiap perubahan status prestasi dikirim lewat NOTIFY setelah transaksi commit,
+-- dipakai semua replika untuk menghapus cache statistik mahasiswa tersebut.
+CREATE OR REPLACE FUNCTION notify_achievement_status_changed() RETURNS trigger AS $$
+BEGIN
+    -- NOTIFY dengan payload yang sama dalam satu transaksi hanya dikirim sekali
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM pg_notify('achievement_status_changed', OLD.student_id::text);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM pg_notify('achievement_status_changed', NEW.student_id::text);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_achievement_status_changed
+    AFTER INSERT OR DELETE OR UPDATE OF status, student_id ON achievement_references
+    FOR EACH ROW EXECUTE FUNCTION notify_achievement_status_changed();
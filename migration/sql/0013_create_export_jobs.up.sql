@@ -0,0 +1,27 @@
+-- Job ekspor daftar prestasi yang terlalu besar untuk diunduh langsung. File hasil disimpan
+-- di storage lampiran (key file_key) dan dihapus worker setelah expires_at.
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id           UUID PRIMARY KEY,
+    requested_by UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    format       VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'xlsx')),
+    filter       JSONB       NOT NULL,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'completed', 'failed', 'expired')),
+    attempts     INT         NOT NULL DEFAULT 0,
+    row_count    BIGINT,
+    file_key     TEXT,
+    file_name    TEXT,
+    file_size    BIGINT,
+    last_error   TEXT,
+    locked_until TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at   TIMESTAMPTZ,
+    finished_at  TIMESTAMPTZ,
+    expires_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_jobs_due ON export_jobs (created_at)
+    WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_export_jobs_expires ON export_jobs (expires_at)
+    WHERE status = 'completed';
+CREATE INDEX IF NOT EXISTS idx_export_jobs_requested_by ON export_jobs (requested_by, created_at DESC);
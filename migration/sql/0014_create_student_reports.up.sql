@@ -0,0 +1,13 @@
+-- Laporan prestasi mahasiswa (PDF) yang sudah diterbitkan. content menyimpan JSON laporan
+-- byte demi byte (bukan JSONB) agar content_hash dan signature bisa diperiksa ulang.
+CREATE TABLE IF NOT EXISTS student_reports (
+    id           UUID PRIMARY KEY,
+    student_id   UUID        NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+    issued_by    UUID        REFERENCES users (id) ON DELETE SET NULL,
+    content      TEXT        NOT NULL,
+    content_hash CHAR(64)    NOT NULL,
+    signature    TEXT        NOT NULL,
+    issued_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_reports_student ON student_reports (student_id, issued_at DESC);
@@ -0,0 +1,7 @@
+DELETE FROM achievement_outbox WHERE operation IN ('update_achievement', 'add_attachments');
+
+ALTER TABLE achievement_outbox DROP CONSTRAINT IF EXISTS achievement_outbox_operation_check;
+ALTER TABLE achievement_outbox ADD CONSTRAINT achievement_outbox_operation_check
+    CHECK (operation IN ('create_achievement', 'delete_achievement'));
+
+ALTER TABLE achievement_references DROP COLUMN IF EXISTS version;
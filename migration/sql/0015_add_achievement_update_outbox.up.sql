@@ -0,0 +1,7 @@
+-- Versi prestasi dicatat di PostgreSQL agar pengecekan If-Match dan update MongoDB lewat outbox
+-- berada dalam satu transaksi. NULL berarti reference lama, versinya dibaca dari MongoDB.
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS version INT;
+
+ALTER TABLE achievement_outbox DROP CONSTRAINT IF EXISTS achievement_outbox_operation_check;
+ALTER TABLE achievement_outbox ADD CONSTRAINT achievement_outbox_operation_check
+    CHECK (operation IN ('create_achievement', 'delete_achievement', 'update_achievement', 'add_attachments'));
@@ -57,8 +57,10 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token   string      `json:"token"`
-	Profile UserProfile `json:"profile"`
+	Token        string      `json:"token"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+	RefreshToken string      `json:"refresh_token"`
+	Profile      UserProfile `json:"profile"`
 }
 
 type UserProfile struct {
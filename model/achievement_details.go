@@ -0,0 +1,418 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Tingkat prestasi, dipakai untuk statistik per level
+const (
+	LevelInternational = "international"
+	LevelNational      = "national"
+	LevelRegional      = "regional"
+	LevelLocal         = "local"
+)
+
+var AchievementLevels = []string{LevelInternational, LevelNational, LevelRegional, LevelLocal}
+
+var (
+	medalTypes       = []string{"gold", "silver", "bronze"}
+	publicationTypes = []string{"journal", "conference", "book"}
+	issnPattern      = regexp.MustCompile(`^\d{4}-\d{3}[\dX]$`)
+)
+
+// AchievementDetails detail prestasi sesuai achievement_type
+type AchievementDetails interface {
+	// Validate memeriksa isi detail, Field pada hasilnya relatif terhadap "details"
+	Validate() FieldErrors
+	// Level tingkat prestasi, kosong jika tidak diisi
+	Level() string
+}
+
+// achievementDetailTypes registry achievement_type -> struct detail
+var achievementDetailTypes = map[string]func() AchievementDetails{
+	"academic":      func() AchievementDetails { return &AcademicDetails{} },
+	"competition":   func() AchievementDetails { return &CompetitionDetails{} },
+	"organization":  func() AchievementDetails { return &OrganizationDetails{} },
+	"publication":   func() AchievementDetails { return &PublicationDetails{} },
+	"certification": func() AchievementDetails { return &CertificationDetails{} },
+	"research":      func() AchievementDetails { return &ResearchDetails{} },
+	"other":         func() AchievementDetails { return &OtherDetails{} },
+}
+
+// achievementLevelFields field bson tingkat prestasi di details per achievement_type,
+// dipakai pipeline statistik di MongoDB. Harus sejalan dengan Level() tiap struct detail.
+var achievementLevelFields = map[string]string{
+	"academic":      "academicLevel",
+	"competition":   "competitionLevel",
+	"organization":  "organizationLevel",
+	"publication":   "publicationLevel",
+	"certification": "certificationLevel",
+	"research":      "researchLevel",
+	"other":         "otherLevel",
+}
+
+// AchievementLevelField nama field bson tingkat prestasi di details untuk achievement_type
+func AchievementLevelField(achievementType string) (string, bool) {
+	field, ok := achievementLevelFields[achievementType]
+	return field, ok
+}
+
+// AchievementTypes daftar achievement_type yang valid, terurut
+func AchievementTypes() []string {
+	types := make([]string, 0, len(achievementDetailTypes))
+	for t := range achievementDetailTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func IsValidAchievementType(achievementType string) bool {
+	_, ok := achievementDetailTypes[achievementType]
+	return ok
+}
+
+// NewAchievementDetails struct detail kosong untuk achievement_type
+func NewAchievementDetails(achievementType string) (AchievementDetails, bool) {
+	newDetails, ok := achievementDetailTypes[achievementType]
+	if !ok {
+		return nil, false
+	}
+	return newDetails(), true
+}
+
+// FieldError kesalahan validasi pada satu field request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DecodeAchievementDetails decode details dari request secara strict: field yang tidak dikenal
+// dan tipe yang salah ditolak, lalu isi divalidasi sesuai achievement_type.
+// Field pada error diawali "details.".
+func DecodeAchievementDetails(achievementType string, raw interface{}) (AchievementDetails, FieldErrors) {
+	details, ok := NewAchievementDetails(achievementType)
+	if !ok {
+		return nil, FieldErrors{{Field: "achievement_type", Message: "achievement_type tidak valid"}}
+	}
+
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil || !bytes.HasPrefix(data, []byte("{")) {
+		return nil, FieldErrors{{Field: "details", Message: "harus berupa object"}}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(details); err != nil {
+		return nil, FieldErrors{decodeFieldError(err)}
+	}
+
+	if errs := details.Validate(); len(errs) > 0 {
+		for i := range errs {
+			errs[i].Field = "details." + errs[i].Field
+		}
+		return nil, errs
+	}
+
+	return details, nil
+}
+
+// decodeFieldError menerjemahkan error encoding/json menjadi FieldError
+func decodeFieldError(err error) FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return FieldError{
+			Field:   "details." + typeErr.Field,
+			Message: fmt.Sprintf("tipe tidak valid, seharusnya %s", jsonKind(typeErr.Type.String())),
+		}
+	}
+
+	// encoding/json tidak punya tipe error khusus untuk unknown field
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return FieldError{Field: "details." + strings.Trim(name, `"`), Message: "field tidak dikenal"}
+	}
+
+	return FieldError{Field: "details", Message: "format tidak valid"}
+}
+
+func jsonKind(goType string) string {
+	goType = strings.TrimLeft(goType, "*")
+	switch {
+	case goType == "string":
+		return "string"
+	case strings.HasPrefix(goType, "int"):
+		return "integer"
+	case strings.HasPrefix(goType, "[]"):
+		return "array"
+	default:
+		return goType
+	}
+}
+
+// DetailsLevel tingkat prestasi dari details, kosong jika tidak diketahui
+func (a *Achievement) DetailsLevel() string {
+	if details, ok := a.Details.(AchievementDetails); ok {
+		return details.Level()
+	}
+	return ""
+}
+
+// UnmarshalBSON decode details ke struct sesuai achievement_type. Dokumen lama yang
+// bentuknya tidak cocok tetap dibaca apa adanya sebagai map.
+func (a *Achievement) UnmarshalBSON(data []byte) error {
+	type achievementAlias Achievement
+	if err := bson.Unmarshal(data, (*achievementAlias)(a)); err != nil {
+		return err
+	}
+
+	value, err := bson.Raw(data).LookupErr("details")
+	if err != nil || value.Type != bson.TypeEmbeddedDocument {
+		return nil
+	}
+
+	if details, ok := NewAchievementDetails(a.AchievementType); ok {
+		if err := value.Unmarshal(details); err == nil {
+			a.Details = details
+			return nil
+		}
+	}
+
+	var m map[string]interface{}
+	if err := value.Unmarshal(&m); err == nil {
+		a.Details = m
+	}
+	return nil
+}
+
+// CompetitionDetails detail prestasi lomba
+type CompetitionDetails struct {
+	CompetitionName  *string `bson:"competitionName,omitempty" json:"competition_name,omitempty"`
+	CompetitionLevel *string `bson:"competitionLevel,omitempty" json:"competition_level,omitempty"`
+	Rank             *int    `bson:"rank,omitempty" json:"rank,omitempty"`
+	MedalType        *string `bson:"medalType,omitempty" json:"medal_type,omitempty"`
+	Organizer        *string `bson:"organizer,omitempty" json:"organizer,omitempty"`
+	EventDate        *string `bson:"eventDate,omitempty" json:"event_date,omitempty"` // YYYY-MM-DD
+}
+
+func (d *CompetitionDetails) Validate() FieldErrors {
+	var errs FieldErrors
+	errs.required("competition_name", d.CompetitionName)
+	errs.required("competition_level", d.CompetitionLevel)
+	errs.oneOf("competition_level", d.CompetitionLevel, AchievementLevels)
+	if d.Rank != nil && *d.Rank < 1 {
+		errs.add("rank", "harus lebih dari 0")
+	}
+	errs.oneOf("medal_type", d.MedalType, medalTypes)
+	errs.date("event_date", d.EventDate)
+	return errs
+}
+
+func (d *CompetitionDetails) Level() string { return deref(d.CompetitionLevel) }
+
+// PublicationDetails detail prestasi publikasi ilmiah
+type PublicationDetails struct {
+	PublicationType  *string   `bson:"publicationType,omitempty" json:"publication_type,omitempty"`
+	PublicationTitle *string   `bson:"publicationTitle,omitempty" json:"publication_title,omitempty"`
+	Authors          *[]string `bson:"authors,omitempty" json:"authors,omitempty"`
+	Publisher        *string   `bson:"publisher,omitempty" json:"publisher,omitempty"`
+	ISSN             *string   `bson:"issn,omitempty" json:"issn,omitempty"`
+	PublicationLevel *string   `bson:"publicationLevel,omitempty" json:"publication_level,omitempty"`
+}
+
+func (d *PublicationDetails) Validate() FieldErrors {
+	var errs FieldErrors
+	errs.required("publication_type", d.PublicationType)
+	errs.oneOf("publication_type", d.PublicationType, publicationTypes)
+	errs.required("publication_title", d.PublicationTitle)
+	if d.Authors == nil || len(*d.Authors) == 0 {
+		errs.add("authors", "minimal satu penulis")
+	} else {
+		for i, author := range *d.Authors {
+			if strings.TrimSpace(author) == "" {
+				errs.add(fmt.Sprintf("authors[%d]", i), "tidak boleh kosong")
+			}
+		}
+	}
+	if d.ISSN != nil && !issnPattern.MatchString(*d.ISSN) {
+		errs.add("issn", "format ISSN tidak valid (contoh: 1234-567X)")
+	}
+	errs.oneOf("publication_level", d.PublicationLevel, AchievementLevels)
+	return errs
+}
+
+func (d *PublicationDetails) Level() string { return deref(d.PublicationLevel) }
+
+// OrganizationDetails detail prestasi kepengurusan organisasi
+type OrganizationDetails struct {
+	OrganizationName  *string `bson:"organizationName,omitempty" json:"organization_name,omitempty"`
+	Position          *string `bson:"position,omitempty" json:"position,omitempty"`
+	OrganizationLevel *string `bson:"organizationLevel,omitempty" json:"organization_level,omitempty"`
+	PeriodStart       *string `bson:"periodStart,omitempty" json:"period_start,omitempty"` // YYYY-MM-DD
+	PeriodEnd         *string `bson:"periodEnd,omitempty" json:"period_end,omitempty"`     // YYYY-MM-DD
+}
+
+func (d *OrganizationDetails) Validate() FieldErrors {
+	var errs FieldErrors
+	errs.required("organization_name", d.OrganizationName)
+	errs.required("position", d.Position)
+	errs.required("organization_level", d.OrganizationLevel)
+	errs.oneOf("organization_level", d.OrganizationLevel, AchievementLevels)
+	start := errs.date("period_start", d.PeriodStart)
+	end := errs.date("period_end", d.PeriodEnd)
+	if start != nil && end != nil && end.Before(*start) {
+		errs.add("period_end", "tidak boleh sebelum period_start")
+	}
+	return errs
+}
+
+func (d *OrganizationDetails) Level() string { return deref(d.OrganizationLevel) }
+
+// CertificationDetails detail prestasi sertifikasi kompetensi
+type CertificationDetails struct {
+	CertificationName   *string `bson:"certificationName,omitempty" json:"certification_name,omitempty"`
+	IssuedBy            *string `bson:"issuedBy,omitempty" json:"issued_by,omitempty"`
+	CertificationNumber *string `bson:"certificationNumber,omitempty" json:"certification_number,omitempty"`
+	CertificationLevel  *string `bson:"certificationLevel,omitempty" json:"certification_level,omitempty"`
+	IssuedDate          *string `bson:"issuedDate,omitempty" json:"issued_date,omitempty"` // YYYY-MM-DD
+	ExpiryDate          *string `bson:"expiryDate,omitempty" json:"expiry_date,omitempty"` // YYYY-MM-DD
+}
+
+func (d *CertificationDetails) Validate() FieldErrors {
+	var errs FieldErrors
+	errs.required("certification_name", d.CertificationName)
+	errs.required("issued_by", d.IssuedBy)
+	errs.oneOf("certification_level", d.CertificationLevel, AchievementLevels)
+	issued := errs.date("issued_date", d.IssuedDate)
+	expiry := errs.date("expiry_date", d.ExpiryDate)
+	if issued != nil && expiry != nil && expiry.Before(*issued) {
+		errs.add("expiry_date", "tidak boleh sebelum issued_date")
+	}
+	return errs
+}
+
+func (d *CertificationDetails) Level() string { return deref(d.CertificationLevel) }
+
+// ResearchDetails detail prestasi penelitian/hibah
+type ResearchDetails struct {
+	ResearchTitle *string `bson:"researchTitle,omitempty" json:"research_title,omitempty"`
+	Role          *string `bson:"role,omitempty" json:"role,omitempty"`
+	FundingSource *string `bson:"fundingSource,omitempty" json:"funding_source,omitempty"`
+	ResearchLevel *string `bson:"researchLevel,omitempty" json:"research_level,omitempty"`
+	Year          *int    `bson:"year,omitempty" json:"year,omitempty"`
+}
+
+func (d *ResearchDetails) Validate() FieldErrors {
+	var errs FieldErrors
+	errs.required("research_title", d.ResearchTitle)
+	errs.required("role", d.Role)
+	errs.oneOf("research_level", d.ResearchLevel, AchievementLevels)
+	errs.year("year", d.Year)
+	return errs
+}
+
+func (d *ResearchDetails) Level() string { return deref(d.ResearchLevel) }
+
+// AcademicDetails detail prestasi akademik (beasiswa, mahasiswa berprestasi, ...)
+type AcademicDetails struct {
+	AwardName     *string `bson:"awardName,omitempty" json:"award_name,omitempty"`
+	Institution   *string `bson:"institution,omitempty" json:"institution,omitempty"`
+	AcademicLevel *string `bson:"academicLevel,omitempty" json:"academic_level,omitempty"`
+	Year          *int    `bson:"year,omitempty" json:"year,omitempty"`
+}
+
+func (d *AcademicDetails) Validate() FieldErrors {
+	var errs FieldErrors
+	errs.required("award_name", d.AwardName)
+	errs.oneOf("academic_level", d.AcademicLevel, AchievementLevels)
+	errs.year("year", d.Year)
+	return errs
+}
+
+func (d *AcademicDetails) Level() string { return deref(d.AcademicLevel) }
+
+// OtherDetails detail prestasi lainnya
+type OtherDetails struct {
+	Category   *string `bson:"category,omitempty" json:"category,omitempty"`
+	Organizer  *string `bson:"organizer,omitempty" json:"organizer,omitempty"`
+	OtherLevel *string `bson:"otherLevel,omitempty" json:"other_level,omitempty"`
+	EventDate  *string `bson:"eventDate,omitempty" json:"event_date,omitempty"` // YYYY-MM-DD
+}
+
+func (d *OtherDetails) Validate() FieldErrors {
+	var errs FieldErrors
+	errs.oneOf("other_level", d.OtherLevel, AchievementLevels)
+	errs.date("event_date", d.EventDate)
+	return errs
+}
+
+func (d *OtherDetails) Level() string { return deref(d.OtherLevel) }
+
+func (e *FieldErrors) add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+func (e *FieldErrors) required(field string, value *string) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		e.add(field, "harus diisi")
+	}
+}
+
+func (e *FieldErrors) oneOf(field string, value *string, allowed []string) {
+	if value == nil || *value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if *value == a {
+			return
+		}
+	}
+	e.add(field, "harus salah satu dari: "+strings.Join(allowed, ", "))
+}
+
+func (e *FieldErrors) date(field string, value *string) *time.Time {
+	if value == nil {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		e.add(field, "format tanggal harus YYYY-MM-DD")
+		return nil
+	}
+	return &t
+}
+
+func (e *FieldErrors) year(field string, value *int) {
+	if value != nil && (*value < 1900 || *value > time.Now().Year()+1) {
+		e.add(field, "tahun tidak valid")
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,161 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDecodeAchievementDetails_Valid(t *testing.T) {
+	raw := map[string]interface{}{
+		"competition_name":  "Gemastik",
+		"competition_level": "national",
+		"rank":              float64(1), // angka dari BodyParser selalu float64
+		"medal_type":        "gold",
+	}
+
+	// Execute
+	details, errs := DecodeAchievementDetails("competition", raw)
+
+	// Assert
+	assert.Nil(t, errs)
+	competition, ok := details.(*CompetitionDetails)
+	assert.True(t, ok)
+	assert.Equal(t, 1, *competition.Rank)
+	assert.Equal(t, LevelNational, details.Level())
+}
+
+func TestDecodeAchievementDetails_UnknownField(t *testing.T) {
+	raw := map[string]interface{}{
+		"competition_name":  "Gemastik",
+		"competition_level": "national",
+		"level":             "national",
+	}
+
+	// Execute
+	_, errs := DecodeAchievementDetails("competition", raw)
+
+	// Assert
+	assert.Equal(t, FieldErrors{{Field: "details.level", Message: "field tidak dikenal"}}, errs)
+}
+
+func TestDecodeAchievementDetails_WrongType(t *testing.T) {
+	raw := map[string]interface{}{
+		"competition_name":  "Gemastik",
+		"competition_level": "national",
+		"rank":              "satu",
+	}
+
+	// Execute
+	_, errs := DecodeAchievementDetails("competition", raw)
+
+	// Assert
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "details.rank", errs[0].Field)
+	assert.Contains(t, errs[0].Message, "integer")
+}
+
+func TestDecodeAchievementDetails_FieldErrors(t *testing.T) {
+	raw := map[string]interface{}{
+		"publication_type": "blog",
+		"authors":          []interface{}{"Budi", " "},
+		"issn":             "12345678",
+	}
+
+	// Execute
+	_, errs := DecodeAchievementDetails("publication", raw)
+
+	// Assert
+	fields := make([]string, len(errs))
+	for i, fe := range errs {
+		fields[i] = fe.Field
+	}
+	assert.ElementsMatch(t, []string{
+		"details.publication_type",
+		"details.publication_title",
+		"details.authors[1]",
+		"details.issn",
+	}, fields)
+}
+
+func TestDecodeAchievementDetails_MissingDetails(t *testing.T) {
+	// Execute
+	_, errs := DecodeAchievementDetails("organization", nil)
+
+	// Assert
+	assert.Len(t, errs, 3)
+
+	// Execute: details bukan object
+	_, errs = DecodeAchievementDetails("other", []interface{}{"x"})
+
+	// Assert
+	assert.Equal(t, FieldErrors{{Field: "details", Message: "harus berupa object"}}, errs)
+}
+
+func TestDecodeAchievementDetails_EveryTypeRegistered(t *testing.T) {
+	for _, achievementType := range AchievementTypes() {
+		details, ok := NewAchievementDetails(achievementType)
+		assert.True(t, ok, achievementType)
+		assert.NotNil(t, details, achievementType)
+	}
+	assert.False(t, IsValidAchievementType("hobby"))
+}
+
+func TestAchievement_UnmarshalBSON_TypedDetails(t *testing.T) {
+	data, err := bson.Marshal(bson.M{
+		"achievementType": "competition",
+		"title":           "Juara 1",
+		"details":         bson.M{"competitionName": "Hackathon", "competitionLevel": "international", "rank": 1},
+	})
+	assert.NoError(t, err)
+
+	// Execute
+	var achievement Achievement
+	err = bson.Unmarshal(data, &achievement)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Juara 1", achievement.Title)
+	assert.IsType(t, &CompetitionDetails{}, achievement.Details)
+	assert.Equal(t, LevelInternational, achievement.DetailsLevel())
+}
+
+func TestAchievement_UnmarshalBSON_LegacyDetails(t *testing.T) {
+	// Dokumen lama dengan bentuk details yang tidak sesuai schema tetap terbaca
+	data, err := bson.Marshal(bson.M{
+		"achievementType": "competition",
+		"details":         bson.M{"rank": "pertama"},
+	})
+	assert.NoError(t, err)
+
+	// Execute
+	var achievement Achievement
+	err = bson.Unmarshal(data, &achievement)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"rank": "pertama"}, achievement.Details)
+	assert.Equal(t, "", achievement.DetailsLevel())
+}
+
+func TestAchievementLevelField_MatchesDetailsLevel(t *testing.T) {
+	// Pipeline statistik membaca field ini langsung, harus sama dengan Level() tiap struct detail
+	for _, achievementType := range AchievementTypes() {
+		field, ok := AchievementLevelField(achievementType)
+		assert.True(t, ok, achievementType)
+
+		data, err := bson.Marshal(bson.M{
+			"achievementType": achievementType,
+			"details":         bson.M{field: LevelRegional},
+		})
+		assert.NoError(t, err)
+
+		var achievement Achievement
+		assert.NoError(t, bson.Unmarshal(data, &achievement))
+		assert.Equal(t, LevelRegional, achievement.DetailsLevel(), achievementType)
+	}
+
+	_, ok := AchievementLevelField("unknown")
+	assert.False(t, ok)
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AchievementStatusHistory satu perubahan status prestasi (append-only, untuk audit)
+type AchievementStatusHistory struct {
+	ID                     uuid.UUID  `json:"id"`
+	AchievementReferenceID uuid.UUID  `json:"achievement_reference_id"`
+	FromStatus             *string    `json:"from_status"` // nil saat prestasi pertama kali dibuat
+	ToStatus               string     `json:"to_status"`
+	ChangedBy              *uuid.UUID `json:"changed_by"`
+	ChangedByName          *string    `json:"changed_by_name"`
+	Note                   *string    `json:"note,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+}
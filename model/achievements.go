@@ -16,21 +16,31 @@ type Achievement struct {
 	Details         interface{}        `bson:"details" json:"details"`
 	IsDeleted       bool               `bson:"isDeleted,omitempty" json:"is_deleted,omitempty"`
 	DeletedAt       *time.Time         `bson:"deletedAt,omitempty" json:"deleted_at,omitempty"`
+	Version         int                `bson:"version" json:"version"` // naik setiap update, dokumen lama tanpa field ini dianggap versi 0
+	UpdatedAt       *time.Time         `bson:"updatedAt,omitempty" json:"updated_at,omitempty"`
+	Attachments     []Attachment       `bson:"attachments,omitempty" json:"attachments,omitempty"`
 }
 
-type CompetitionDetails struct {
-	CompetitionName  *string `bson:"competitionName,omitempty" json:"competition_name,omitempty"`
-	CompetitionLevel *string `bson:"competitionLevel,omitempty" json:"competition_level,omitempty"`
-	Rank             *int    `bson:"rank,omitempty" json:"rank,omitempty"`
-	MedalType        *string `bson:"medalType,omitempty" json:"medal_type,omitempty"`
+// Attachment metadata file bukti prestasi, isi file disimpan di AttachmentStore
+type Attachment struct {
+	ID          uuid.UUID `bson:"id" json:"id"`
+	FileName    string    `bson:"fileName" json:"file_name"`
+	ContentType string    `bson:"contentType" json:"content_type"` // hasil MIME sniffing
+	Size        int64     `bson:"size" json:"size"`
+	SHA256      string    `bson:"sha256" json:"sha256"`
+	StorageKey  string    `bson:"storageKey" json:"-"`
+	UploadedBy  uuid.UUID `bson:"uploadedBy" json:"uploaded_by"`
+	UploadedAt  time.Time `bson:"uploadedAt" json:"uploaded_at"`
 }
 
-type PublicationDetails struct {
-	PublicationType  *string   `bson:"publicationType,omitempty" json:"publication_type,omitempty"`
-	PublicationTitle *string   `bson:"publicationTitle,omitempty" json:"publication_title,omitempty"`
-	Authors          *[]string `bson:"authors,omitempty" json:"authors,omitempty"`
-	Publisher        *string   `bson:"publisher,omitempty" json:"publisher,omitempty"`
-	ISSN             *string   `bson:"issn,omitempty" json:"issn,omitempty"`
+// FindAttachment mencari lampiran berdasarkan ID
+func (a *Achievement) FindAttachment(id uuid.UUID) *Attachment {
+	for i := range a.Attachments {
+		if a.Attachments[i].ID == id {
+			return &a.Attachments[i]
+		}
+	}
+	return nil
 }
 
 // Request model untuk submit prestasi
@@ -41,6 +51,14 @@ type SubmitAchievementRequest struct {
 	Details         interface{} `json:"details"`
 }
 
+// Request model untuk update prestasi. Version wajib diisi jika header If-Match tidak dikirim.
+type UpdateAchievementRequest struct {
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Details     interface{} `json:"details"`
+	Version     *int        `json:"version,omitempty"`
+}
+
 // Response model untuk submit prestasi
 type SubmitAchievementResponse struct {
 	AchievementID          string    `json:"achievement_id"`
@@ -79,11 +97,41 @@ type PaginatedAchievementsResponse struct {
 	Pagination PaginationMeta           `json:"pagination"`
 }
 
+// PaginationMeta info pagination. current_page hanya ada pada mode page, next_cursor/prev_cursor
+// pada mode cursor; total_pages dan total_items tidak ada jika count dilewati.
 type PaginationMeta struct {
-	CurrentPage int   `json:"current_page"`
-	PerPage     int   `json:"per_page"`
-	TotalPages  int   `json:"total_pages"`
-	TotalItems  int64 `json:"total_items"`
+	CurrentPage int     `json:"current_page,omitempty"`
+	PerPage     int     `json:"per_page"`
+	TotalPages  *int    `json:"total_pages,omitempty"`
+	TotalItems  *int64  `json:"total_items,omitempty"`
+	NextCursor  *string `json:"next_cursor,omitempty"`
+	PrevCursor  *string `json:"prev_cursor,omitempty"`
+}
+
+// Field yang bisa dipakai untuk sort daftar prestasi
+var AchievementSortFields = []string{"created_at", "updated_at", "submitted_at", "verified_at", "status"}
+
+// AchievementListFilter filter daftar prestasi. StudentIDs nil berarti semua mahasiswa.
+type AchievementListFilter struct {
+	StudentIDs      []uuid.UUID
+	Status          string
+	AchievementType string
+	ProgramStudy    string
+	AcademicYear    string
+	Search          string     // dicari di title dan description
+	StartDate       *time.Time // created_at >= StartDate
+	EndDate         *time.Time // created_at sampai akhir hari EndDate
+	SortBy          string
+	SortOrder       string // asc | desc
+	PageRequest
+}
+
+// AchievementReferenceWithStudent reference prestasi beserta data mahasiswa pemiliknya
+type AchievementReferenceWithStudent struct {
+	AchievementReference
+	StudentName     string
+	StudentIDNumber string
+	ProgramStudy    string
 }
 
 
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status job ekspor
+const (
+	ExportPending   = "pending"
+	ExportRunning   = "running"
+	ExportCompleted = "completed"
+	ExportFailed    = "failed"
+	ExportExpired   = "expired" // file hasil sudah dihapus setelah masa simpan habis
+)
+
+// ExportJob job ekspor daftar prestasi yang dijalankan di latar belakang. File hasil
+// disimpan di AttachmentStore dengan key FileKey sampai ExpiresAt.
+type ExportJob struct {
+	ID          uuid.UUID       `json:"id"`
+	RequestedBy uuid.UUID       `json:"requested_by"`
+	Format      string          `json:"format"`
+	Filter      json.RawMessage `json:"-"` // AchievementListFilter, termasuk scope mahasiswa saat diminta
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	RowCount    *int64          `json:"row_count,omitempty"`
+	FileKey     *string         `json:"-"`
+	FileName    *string         `json:"file_name,omitempty"`
+	FileSize    *int64          `json:"file_size,omitempty"`
+	LastError   *string         `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	ExpiresAt   *time.Time      `json:"expires_at,omitempty"`
+	DownloadURL string          `json:"download_url,omitempty"` // diisi service jika completed
+}
+
+// AchievementExportRow satu baris ekspor prestasi: reference, data mahasiswa, nama
+// dosen yang memverifikasi atau menolak, dan dokumen MongoDB
+type AchievementExportRow struct {
+	AchievementReferenceWithStudent
+	AcademicYear string
+	VerifierName *string
+	Achievement  Achievement
+}
@@ -7,14 +7,14 @@ import (
 )
 
 type Notification struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"` // Penerima notifikasi (dosen wali)
-	Type      string    `json:"type"`    // achievement_submitted, achievement_verified, etc
-	Title     string    `json:"title"`
-	Message   string    `json:"message"`
-	Data      string    `json:"data"`       // JSON string untuk data tambahan
-	IsRead    bool      `json:"is_read"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID         `json:"id"`
+	UserID    uuid.UUID         `json:"user_id"` // Penerima notifikasi
+	Type      string            `json:"type"`    // achievement_submitted, achievement_verified, etc
+	Title     string            `json:"title"`
+	Message   string            `json:"message"`
+	Data      *NotificationData `json:"data,omitempty"` // disimpan sebagai JSONB
+	IsRead    bool              `json:"is_read"`
+	CreatedAt time.Time         `json:"created_at"`
 }
 
 type NotificationData struct {
@@ -23,4 +23,78 @@ type NotificationData struct {
 	StudentID              uuid.UUID `json:"student_id"`
 	StudentName            string    `json:"student_name"`
 	AchievementTitle       string    `json:"achievement_title"`
+	RejectionNote          string    `json:"rejection_note,omitempty"`
+}
+
+// NotificationListResponse response GET /notifications
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications"`
+	UnreadCount   int64          `json:"unread_count"`
+	Pagination    PaginationMeta `json:"pagination"`
+}
+
+// Channel pengiriman notifikasi selain inbox (inbox selalu aktif)
+const (
+	NotificationChannelRealtime = "realtime"
+	NotificationChannelEmail    = "email"
+)
+
+// NotificationChannels channel yang bisa diatur user
+var NotificationChannels = []string{NotificationChannelRealtime, NotificationChannelEmail}
+
+// Bahasa template notifikasi
+const (
+	LanguageID = "id"
+	LanguageEN = "en"
+)
+
+// NotificationPreferences pengaturan notifikasi user. Channels berisi semua channel yang dikenal.
+type NotificationPreferences struct {
+	Language string          `json:"language"`
+	Channels map[string]bool `json:"channels"`
+}
+
+// ChannelEnabled true jika channel aktif. Channel yang tidak tercatat dianggap aktif.
+func (p NotificationPreferences) ChannelEnabled(channel string) bool {
+	enabled, ok := p.Channels[channel]
+	return !ok || enabled
+}
+
+// UpdateNotificationPreferencesRequest request PUT /notifications/preferences, field kosong tidak diubah
+type UpdateNotificationPreferencesRequest struct {
+	Language *string         `json:"language"`
+	Channels map[string]bool `json:"channels"`
+}
+
+// NotificationRecipient data penerima untuk channel notifikasi
+type NotificationRecipient struct {
+	UserID      uuid.UUID
+	Email       string
+	FullName    string
+	Preferences NotificationPreferences
+}
+
+// Status email di antrean
+const (
+	EmailPending = "pending"
+	EmailSending = "sending"
+	EmailSent    = "sent"
+	EmailFailed  = "failed"
+)
+
+// EmailMessage email yang sudah dirender di antrean pengiriman
+type EmailMessage struct {
+	ID             uuid.UUID  `json:"id"`
+	NotificationID *uuid.UUID `json:"notification_id,omitempty"`
+	UserID         *uuid.UUID `json:"user_id,omitempty"`
+	To             string     `json:"to"`
+	Subject        string     `json:"subject"`
+	HTMLBody       string     `json:"-"`
+	TextBody       string     `json:"-"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastError      *string    `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
 }
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operasi outbox untuk sisi MongoDB
+const (
+	OutboxCreateAchievement = "create_achievement"
+	OutboxDeleteAchievement = "delete_achievement"
+	OutboxUpdateAchievement = "update_achievement"
+	OutboxAddAttachments    = "add_attachments"
+)
+
+// Status operasi outbox
+const (
+	OutboxPending     = "pending"
+	OutboxProcessing  = "processing"
+	OutboxDone        = "done"
+	OutboxFailed      = "failed"      // kompensasi juga gagal, perlu ditangani admin
+	OutboxCompensated = "compensated" // gagal diterapkan, perubahan PostgreSQL sudah dibatalkan
+)
+
+// OutboxOperation operasi MongoDB yang menunggu diterapkan oleh worker
+type OutboxOperation struct {
+	ID                     uuid.UUID       `json:"id"`
+	AchievementReferenceID uuid.UUID       `json:"achievement_reference_id"`
+	MongoAchievementID     string          `json:"mongo_achievement_id"`
+	Operation              string          `json:"operation"`
+	Payload                json.RawMessage `json:"-"`
+	Status                 string          `json:"status"`
+	Attempts               int             `json:"attempts"`
+	LastError              *string         `json:"last_error,omitempty"`
+	NextAttemptAt          time.Time       `json:"next_attempt_at"`
+	CreatedAt              time.Time       `json:"created_at"`
+	ProcessedAt            *time.Time      `json:"processed_at,omitempty"`
+}
+
+// DeleteAchievementPayload payload operasi delete_achievement
+type DeleteAchievementPayload struct {
+	DeletedAt      time.Time `json:"deleted_at"`
+	PreviousStatus string    `json:"previous_status"` // dipulihkan saat kompensasi
+}
+
+// UpdateAchievementPayload payload operasi update_achievement
+type UpdateAchievementPayload struct {
+	Title           string      `bson:"title"`
+	Description     string      `bson:"description"`
+	Details         interface{} `bson:"details"`
+	UpdatedAt       time.Time   `bson:"updatedAt"`
+	Version         int         `bson:"version"`
+	PreviousVersion int         `bson:"previousVersion"` // dipulihkan saat kompensasi
+	PreviousStatus  string      `bson:"previousStatus"`
+}
+
+// AddAttachmentsPayload payload operasi add_attachments
+type AddAttachmentsPayload struct {
+	Attachments []Attachment `bson:"attachments"`
+	UpdatedAt   time.Time    `bson:"updatedAt"`
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor posisi keyset pagination (created_at + id). Dikirim ke client dalam bentuk opaque string.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+	Backward  bool      `json:"b,omitempty"` // true untuk prev_cursor: ambil data sebelum posisi ini
+}
+
+// Encode cursor menjadi string base64 URL-safe
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor kebalikan dari Cursor.Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// PageRequest parameter pagination. Mode keyset dipakai jika UseCursor true;
+// Cursor nil berarti halaman pertama. Pada mode offset dipakai Page.
+type PageRequest struct {
+	Page      int
+	PerPage   int
+	UseCursor bool
+	Cursor    *Cursor
+	SkipCount bool // lewati COUNT(*), TotalItems tidak diisi
+}
+
+// Offset untuk LIMIT/OFFSET
+func (p PageRequest) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// PageInfo hasil pagination dari repository
+type PageInfo struct {
+	TotalItems *int64
+	HasNext    bool
+	HasPrev    bool
+	First      *Cursor // posisi item pertama dan terakhir pada halaman (mode keyset)
+	Last       *Cursor
+}
+
+// Meta PaginationMeta untuk response API
+func (p PageRequest) Meta(info PageInfo) PaginationMeta {
+	meta := PaginationMeta{PerPage: p.PerPage, TotalItems: info.TotalItems}
+
+	if info.TotalItems != nil {
+		totalPages := int((*info.TotalItems + int64(p.PerPage) - 1) / int64(p.PerPage))
+		meta.TotalPages = &totalPages
+	}
+
+	if !p.UseCursor {
+		meta.CurrentPage = p.Page
+		return meta
+	}
+
+	if info.HasNext && info.Last != nil {
+		next := Cursor{CreatedAt: info.Last.CreatedAt, ID: info.Last.ID}.Encode()
+		meta.NextCursor = &next
+	}
+	if info.HasPrev && info.First != nil {
+		prev := Cursor{CreatedAt: info.First.CreatedAt, ID: info.First.ID, Backward: true}.Encode()
+		meta.PrevCursor = &prev
+	}
+
+	return meta
+}
+
+// KeysetCondition kondisi WHERE dan ORDER BY keyset untuk kolom (createdAt, id).
+// desc adalah urutan tampilan; untuk cursor mundur urutan query dibalik dan
+// hasilnya harus dibalik lagi dengan reverse true.
+func (p PageRequest) KeysetCondition(createdAt, id string, desc bool, arg func(interface{}) string) (where, orderBy string, reverse bool) {
+	queryDesc := desc
+	if p.Cursor != nil && p.Cursor.Backward {
+		queryDesc = !desc
+		reverse = true
+	}
+
+	direction, op := "ASC", ">"
+	if queryDesc {
+		direction, op = "DESC", "<"
+	}
+
+	if p.Cursor != nil {
+		where = "(" + createdAt + ", " + id + ") " + op + " (" + arg(p.Cursor.CreatedAt) + ", " + arg(p.Cursor.ID) + ")"
+	}
+	orderBy = createdAt + " " + direction + ", " + id + " " + direction
+
+	return where, orderBy, reverse
+}
+
+// KeysetPageInfo mengisi HasNext/HasPrev dari jumlah baris hasil query (LIMIT PerPage+1).
+// fetched jumlah baris sebelum dipotong ke PerPage.
+func (p PageRequest) KeysetPageInfo(fetched int) PageInfo {
+	more := fetched > p.PerPage
+
+	switch {
+	case p.Cursor == nil:
+		return PageInfo{HasNext: more}
+	case p.Cursor.Backward:
+		return PageInfo{HasNext: true, HasPrev: more}
+	default:
+		return PageInfo{HasNext: more, HasPrev: true}
+	}
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 123456000, time.UTC), ID: uuid.New(), Backward: true}
+
+	// Execute
+	decoded, err := DecodeCursor(cursor.Encode())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+	assert.True(t, decoded.Backward)
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, raw := range []string{"bukan-cursor!", "e30", Cursor{CreatedAt: time.Now()}.Encode()} {
+		_, err := DecodeCursor(raw)
+		assert.ErrorIs(t, err, ErrInvalidCursor, raw)
+	}
+}
+
+func TestPageRequest_Meta(t *testing.T) {
+	total := int64(21)
+	last := Cursor{CreatedAt: time.Now(), ID: uuid.New()}
+
+	// Execute: mode offset
+	meta := PageRequest{Page: 2, PerPage: 10}.Meta(PageInfo{TotalItems: &total})
+
+	// Assert
+	assert.Equal(t, 2, meta.CurrentPage)
+	assert.Equal(t, 3, *meta.TotalPages)
+	assert.Nil(t, meta.NextCursor)
+
+	// Execute: mode cursor halaman pertama tanpa count
+	meta = PageRequest{PerPage: 10, UseCursor: true, SkipCount: true}.Meta(PageInfo{HasNext: true, First: &last, Last: &last})
+
+	// Assert
+	assert.Equal(t, 0, meta.CurrentPage)
+	assert.Nil(t, meta.TotalItems)
+	assert.Nil(t, meta.PrevCursor)
+	next, err := DecodeCursor(*meta.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, last.ID, next.ID)
+	assert.False(t, next.Backward)
+}
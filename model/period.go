@@ -0,0 +1,227 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Jenis periode statistik (period_type)
+const (
+	PeriodMonthly   = "monthly"
+	PeriodQuarterly = "quarterly"
+	PeriodYearly    = "yearly"
+	PeriodSemester  = "semester"
+)
+
+var PeriodTypes = []string{PeriodMonthly, PeriodQuarterly, PeriodYearly, PeriodSemester}
+
+// Semester tahun akademik
+const (
+	SemesterGanjil = "ganjil"
+	SemesterGenap  = "genap"
+)
+
+// maxZeroFilledPeriods batas jumlah periode kosong yang diisi nol. Jika rentang memuat
+// lebih banyak, hanya periode yang ada datanya yang dikembalikan.
+const maxZeroFilledPeriods = 600
+
+// MonthDay tanggal tanpa tahun, format "MM-DD"
+type MonthDay struct {
+	Month time.Month
+	Day   int
+}
+
+// ParseMonthDay membaca "MM-DD". 29 Februari ditolak karena tidak ada di setiap tahun.
+func ParseMonthDay(s string) (MonthDay, error) {
+	t, err := time.Parse("01-02", s)
+	if err != nil || (t.Month() == time.February && t.Day() == 29) {
+		return MonthDay{}, fmt.Errorf("tanggal %q harus berformat MM-DD", s)
+	}
+	return MonthDay{Month: t.Month(), Day: t.Day()}, nil
+}
+
+func (m MonthDay) String() string {
+	return fmt.Sprintf("%02d-%02d", int(m.Month), m.Day)
+}
+
+// in tanggal m pada tahun year
+func (m MonthDay) in(year int) time.Time {
+	return time.Date(year, m.Month, m.Day, 0, 0, 0, 0, time.UTC)
+}
+
+// AcademicCalendar tanggal mulai semester Ganjil dan Genap. Genap harus dimulai lebih
+// awal dalam tahun kalender, misalnya Genap 1 Februari dan Ganjil 1 Agustus.
+type AcademicCalendar struct {
+	GanjilStart MonthDay
+	GenapStart  MonthDay
+}
+
+// DefaultAcademicCalendar Ganjil mulai 1 Agustus, Genap mulai 1 Februari
+func DefaultAcademicCalendar() AcademicCalendar {
+	return AcademicCalendar{
+		GanjilStart: MonthDay{Month: time.August, Day: 1},
+		GenapStart:  MonthDay{Month: time.February, Day: 1},
+	}
+}
+
+// ParseAcademicCalendar membaca tanggal mulai semester dalam format "MM-DD"
+func ParseAcademicCalendar(ganjilStart, genapStart string) (AcademicCalendar, error) {
+	ganjil, err := ParseMonthDay(ganjilStart)
+	if err != nil {
+		return AcademicCalendar{}, fmt.Errorf("awal semester ganjil: %w", err)
+	}
+	genap, err := ParseMonthDay(genapStart)
+	if err != nil {
+		return AcademicCalendar{}, fmt.Errorf("awal semester genap: %w", err)
+	}
+	if !genap.in(2001).Before(ganjil.in(2001)) {
+		return AcademicCalendar{}, fmt.Errorf("awal semester genap (%s) harus sebelum awal semester ganjil (%s)", genap, ganjil)
+	}
+	return AcademicCalendar{GanjilStart: ganjil, GenapStart: genap}, nil
+}
+
+// Period satu rentang waktu statistik, End eksklusif
+type Period struct {
+	Key      string
+	Start    time.Time
+	End      time.Time
+	Year     int
+	Month    *int
+	Quarter  *int
+	Semester *string
+}
+
+// PeriodOf periode yang memuat tanggal date. Hanya tanggalnya yang dipakai, jam dan
+// zona waktu diabaikan.
+func PeriodOf(periodType string, calendar AcademicCalendar, date time.Time) Period {
+	year, month, day := date.Date()
+	date = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	switch periodType {
+	case PeriodYearly:
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return Period{Key: strconv.Itoa(year), Start: start, End: start.AddDate(1, 0, 0), Year: year}
+
+	case PeriodQuarterly:
+		quarter := (int(month)-1)/3 + 1
+		start := time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return Period{
+			Key:     fmt.Sprintf("%d-Q%d", year, quarter),
+			Start:   start,
+			End:     start.AddDate(0, 3, 0),
+			Year:    year,
+			Quarter: &quarter,
+		}
+
+	case PeriodSemester:
+		return semesterOf(calendar, date)
+
+	default:
+		m := int(month)
+		start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		return Period{Key: start.Format("2006-01"), Start: start, End: start.AddDate(0, 1, 0), Year: year, Month: &m}
+	}
+}
+
+// semesterOf semester Ganjil (GanjilStart sampai GenapStart tahun berikutnya) atau
+// Genap (GenapStart sampai GanjilStart). Year adalah tahun awal tahun akademik.
+func semesterOf(calendar AcademicCalendar, date time.Time) Period {
+	if calendar == (AcademicCalendar{}) {
+		calendar = DefaultAcademicCalendar()
+	}
+	year := date.Year()
+
+	var academicYear int
+	var semester string
+	var start, end time.Time
+	switch {
+	case !date.Before(calendar.GanjilStart.in(year)):
+		academicYear, semester = year, SemesterGanjil
+		start, end = calendar.GanjilStart.in(year), calendar.GenapStart.in(year+1)
+	case !date.Before(calendar.GenapStart.in(year)):
+		academicYear, semester = year-1, SemesterGenap
+		start, end = calendar.GenapStart.in(year), calendar.GanjilStart.in(year)
+	default:
+		academicYear, semester = year-1, SemesterGanjil
+		start, end = calendar.GanjilStart.in(year-1), calendar.GenapStart.in(year)
+	}
+
+	label := "Ganjil"
+	if semester == SemesterGenap {
+		label = "Genap"
+	}
+
+	return Period{
+		Key:      fmt.Sprintf("%d/%d %s", academicYear, academicYear+1, label),
+		Start:    start,
+		End:      end,
+		Year:     academicYear,
+		Semester: &semester,
+	}
+}
+
+// DailyCount jumlah prestasi pada satu tanggal
+type DailyCount struct {
+	Date  time.Time
+	Count int64
+}
+
+// BucketPeriods mengelompokkan jumlah harian ke periode, terurut kronologis. Periode
+// kosong antara from dan to (atau tanggal data pertama/terakhir jika nil) diisi nol.
+func BucketPeriods(periodType string, calendar AcademicCalendar, daily []DailyCount, from, to *time.Time) []PeriodStatistic {
+	counts := make(map[string]int64)
+	periods := make(map[string]Period)
+	var first, last time.Time
+	for i, d := range daily {
+		p := PeriodOf(periodType, calendar, d.Date)
+		counts[p.Key] += d.Count
+		periods[p.Key] = p
+		if i == 0 || p.Start.Before(first) {
+			first = p.Start
+		}
+		if i == 0 || p.Start.After(last) {
+			last = p.Start
+		}
+	}
+
+	if from != nil {
+		first = PeriodOf(periodType, calendar, *from).Start
+	}
+	if to != nil {
+		last = PeriodOf(periodType, calendar, *to).Start
+	}
+
+	// Zero-fill periode di antara first dan last
+	if (len(daily) > 0 || (from != nil && to != nil)) && !last.Before(first) {
+		var empty []Period
+		for p := PeriodOf(periodType, calendar, first); !p.Start.After(last); p = PeriodOf(periodType, calendar, p.End) {
+			if len(empty) > maxZeroFilledPeriods {
+				empty = nil
+				break
+			}
+			if _, ok := periods[p.Key]; !ok {
+				empty = append(empty, p)
+			}
+		}
+		for _, p := range empty {
+			periods[p.Key] = p
+		}
+	}
+
+	stats := make([]PeriodStatistic, 0, len(periods))
+	for key, p := range periods {
+		stats = append(stats, PeriodStatistic{
+			Period:    key,
+			Count:     counts[key],
+			Year:      p.Year,
+			Month:     p.Month,
+			Quarter:   p.Quarter,
+			Semester:  p.Semester,
+			StartDate: p.Start,
+			EndDate:   p.End.AddDate(0, 0, -1),
+		})
+	}
+	SortPeriodStatistics(stats)
+	return stats
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestPeriodOf(t *testing.T) {
+	calendar := DefaultAcademicCalendar()
+	day := date(2024, time.May, 17)
+
+	cases := []struct {
+		periodType string
+		key        string
+		start, end time.Time
+	}{
+		{PeriodMonthly, "2024-05", date(2024, time.May, 1), date(2024, time.June, 1)},
+		{PeriodQuarterly, "2024-Q2", date(2024, time.April, 1), date(2024, time.July, 1)},
+		{PeriodYearly, "2024", date(2024, time.January, 1), date(2025, time.January, 1)},
+		{PeriodSemester, "2023/2024 Genap", date(2024, time.February, 1), date(2024, time.August, 1)},
+	}
+
+	for _, tc := range cases {
+		p := PeriodOf(tc.periodType, calendar, day)
+		assert.Equal(t, tc.key, p.Key, tc.periodType)
+		assert.Equal(t, tc.start, p.Start, tc.periodType)
+		assert.Equal(t, tc.end, p.End, tc.periodType)
+	}
+}
+
+func TestPeriodOf_SemesterBoundaries(t *testing.T) {
+	calendar, err := ParseAcademicCalendar("09-01", "02-15")
+	assert.NoError(t, err)
+
+	cases := map[time.Time]string{
+		date(2024, time.January, 10):  "2023/2024 Ganjil", // Ganjil melewati tahun baru
+		date(2024, time.February, 14): "2023/2024 Ganjil",
+		date(2024, time.February, 15): "2023/2024 Genap",
+		date(2024, time.August, 31):   "2023/2024 Genap",
+		date(2024, time.September, 1): "2024/2025 Ganjil",
+	}
+
+	for day, key := range cases {
+		p := PeriodOf(PeriodSemester, calendar, day)
+		assert.Equal(t, key, p.Key, day.Format("2006-01-02"))
+	}
+
+	p := PeriodOf(PeriodSemester, calendar, date(2024, time.January, 10))
+	assert.Equal(t, 2023, p.Year)
+	assert.Equal(t, SemesterGanjil, *p.Semester)
+	assert.Equal(t, date(2023, time.September, 1), p.Start)
+	assert.Equal(t, date(2024, time.February, 15), p.End)
+}
+
+func TestParseAcademicCalendar_Invalid(t *testing.T) {
+	for _, tc := range [][2]string{
+		{"13-01", "02-01"},
+		{"08-01", "02-29"},
+		{"02-01", "08-01"}, // genap harus lebih awal dalam tahun kalender
+		{"08-01", "08-01"},
+	} {
+		_, err := ParseAcademicCalendar(tc[0], tc[1])
+		assert.Error(t, err, tc)
+	}
+}
+
+func TestBucketPeriods_ZeroFillsRange(t *testing.T) {
+	from, to := date(2024, time.January, 1), date(2024, time.December, 31)
+	daily := []DailyCount{
+		{Date: date(2024, time.November, 3), Count: 2},
+		{Date: date(2024, time.February, 20), Count: 1},
+		{Date: date(2024, time.November, 30), Count: 1},
+	}
+
+	// Execute
+	stats := BucketPeriods(PeriodQuarterly, DefaultAcademicCalendar(), daily, &from, &to)
+
+	// Assert: semua kuartal ada dan terurut kronologis
+	var keys []string
+	var counts []int64
+	for _, s := range stats {
+		keys = append(keys, s.Period)
+		counts = append(counts, s.Count)
+	}
+	assert.Equal(t, []string{"2024-Q1", "2024-Q2", "2024-Q3", "2024-Q4"}, keys)
+	assert.Equal(t, []int64{1, 0, 0, 3}, counts)
+	assert.Equal(t, 2, *stats[1].Quarter)
+	assert.Nil(t, stats[1].Month)
+	assert.Equal(t, date(2024, time.June, 30), stats[1].EndDate)
+}
+
+func TestBucketPeriods_SemesterSortedChronologically(t *testing.T) {
+	daily := []DailyCount{
+		{Date: date(2025, time.March, 1), Count: 1},
+		{Date: date(2023, time.September, 1), Count: 4},
+	}
+
+	// Execute: tanpa rentang, zero-fill dari data pertama sampai terakhir
+	stats := BucketPeriods(PeriodSemester, DefaultAcademicCalendar(), daily, nil, nil)
+
+	// Assert
+	var keys []string
+	for _, s := range stats {
+		keys = append(keys, s.Period)
+	}
+	assert.Equal(t, []string{"2023/2024 Ganjil", "2023/2024 Genap", "2024/2025 Ganjil", "2024/2025 Genap"}, keys)
+	assert.Equal(t, int64(4), stats[0].Count)
+	assert.Equal(t, int64(1), stats[3].Count)
+}
+
+func TestBucketPeriods_Empty(t *testing.T) {
+	assert.Empty(t, BucketPeriods(PeriodMonthly, DefaultAcademicCalendar(), nil, nil, nil))
+
+	// Rentang terlalu panjang tidak diisi nol
+	from, to := date(1900, time.January, 1), date(2100, time.January, 1)
+	stats := BucketPeriods(PeriodMonthly, DefaultAcademicCalendar(), []DailyCount{{Date: date(2024, time.May, 2), Count: 1}}, &from, &to)
+	assert.Len(t, stats, 1)
+}
@@ -0,0 +1,72 @@
+package model
+
+import "github.com/google/uuid"
+
+// RoleDetail role beserta permission dan jumlah user yang memakainya
+type RoleDetail struct {
+	Roles
+	System      bool          `json:"system"` // role bawaan: tidak bisa dihapus atau diganti nama
+	UserCount   int64         `json:"user_count"`
+	Permissions []Permissions `json:"permissions"`
+}
+
+// CreateRoleRequest request POST /admin/roles. Permissions berisi nama "resource:action".
+type CreateRoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest request PUT /admin/roles/:id, field kosong tidak diubah
+type UpdateRoleRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// RolePermissionsRequest request PUT /admin/roles/:id/permissions, menggantikan seluruh
+// permission role dengan daftar nama "resource:action" ini
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// CreatePermissionRequest request POST /admin/permissions. Pasangan resource dan action
+// harus salah satu yang dicek oleh route.
+type CreatePermissionRequest struct {
+	Resource    string `json:"resource"`
+	Action      string `json:"action"`
+	Description string `json:"description"`
+}
+
+// UpdatePermissionRequest request PUT /admin/permissions/:id. Resource dan action tidak bisa
+// diubah karena menentukan route mana yang bisa diakses.
+type UpdatePermissionRequest struct {
+	Description *string `json:"description"`
+}
+
+// UpdateUserRoleRequest request PUT /users/:id/role
+type UpdateUserRoleRequest struct {
+	RoleID uuid.UUID `json:"role_id"`
+}
+
+// PermissionMatrix semua permission dan permission yang dimiliki setiap role
+type PermissionMatrix struct {
+	Permissions []Permissions          `json:"permissions"`
+	Roles       []PermissionMatrixRole `json:"roles"`
+}
+
+// PermissionMatrixRole satu baris matriks permission
+type PermissionMatrixRole struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	System      bool      `json:"system"`
+	Permissions []string  `json:"permissions"` // nama "resource:action"
+}
+
+// AvailablePermission pasangan resource:action yang dicek route beserta status di database
+type AvailablePermission struct {
+	Name        string     `json:"name"`
+	Resource    string     `json:"resource"`
+	Action      string     `json:"action"`
+	Description string     `json:"description"`
+	ID          *uuid.UUID `json:"id,omitempty"` // kosong jika belum dibuat
+}
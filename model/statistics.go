@@ -1,6 +1,7 @@
 package model
 
 import (
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,12 +23,26 @@ type TypeStatistic struct {
 	Percentage      float64 `json:"percentage"`
 }
 
-// PeriodStatistic statistik berdasarkan periode (bulan/tahun)
+// PeriodStatistic statistik berdasarkan periode (bulan/kuartal/tahun/semester)
 type PeriodStatistic struct {
-	Period string `json:"period"` // Format: "2024-01" atau "2024"
-	Count  int64  `json:"count"`
-	Year   int    `json:"year"`
-	Month  *int   `json:"month,omitempty"` // null jika yearly
+	Period    string    `json:"period"` // Format: "2024-01", "2024-Q1", "2024" atau "2024/2025 Ganjil"
+	Count     int64     `json:"count"`
+	Year      int       `json:"year"` // tahun awal tahun akademik untuk semester
+	Month     *int      `json:"month,omitempty"`    // hanya monthly
+	Quarter   *int      `json:"quarter,omitempty"`  // hanya quarterly
+	Semester  *string   `json:"semester,omitempty"` // hanya semester: ganjil atau genap
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"` // hari terakhir periode
+}
+
+// SortPeriodStatistics mengurutkan periode secara kronologis
+func SortPeriodStatistics(periods []PeriodStatistic) {
+	sort.Slice(periods, func(i, j int) bool {
+		if !periods[i].StartDate.Equal(periods[j].StartDate) {
+			return periods[i].StartDate.Before(periods[j].StartDate)
+		}
+		return periods[i].Period < periods[j].Period
+	})
 }
 
 // TopStudent mahasiswa dengan prestasi terbanyak
@@ -70,6 +85,9 @@ type StatisticsRequest struct {
 	EndDate         *time.Time `json:"end_date,omitempty"`
 	AchievementType *string    `json:"achievement_type,omitempty"`
 	Status          *string    `json:"status,omitempty"`
-	PeriodType      string     `json:"period_type"` // "monthly" atau "yearly"
+	PeriodType      string     `json:"period_type"` // monthly, quarterly, yearly atau semester
 	TopLimit        int        `json:"top_limit"`   // limit untuk top students
+
+	// Calendar tanggal mulai semester untuk period_type semester, diisi dari konfigurasi
+	Calendar AcademicCalendar `json:"-"`
 }
\ No newline at end of file
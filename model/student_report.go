@@ -0,0 +1,119 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StudentReport laporan prestasi terverifikasi satu mahasiswa, dasar JSON dan PDF
+// laporan mahasiswa
+type StudentReport struct {
+	Student      StudentReportProfile       `json:"student"`
+	Summary      StudentReportSummary       `json:"summary"`
+	Achievements []StudentReportAchievement `json:"achievements"`
+}
+
+// StudentReportProfile identitas mahasiswa di laporan
+type StudentReportProfile struct {
+	ID           uuid.UUID `json:"id"`
+	StudentID    string    `json:"student_id"` // NIM
+	FullName     string    `json:"full_name"`
+	ProgramStudy string    `json:"program_study"`
+	AcademicYear string    `json:"academic_year"`
+	AdvisorName  *string   `json:"advisor_name,omitempty"`
+}
+
+// StudentReportSummary jumlah prestasi terverifikasi per tipe dan tingkat
+type StudentReportSummary struct {
+	VerifiedAchievements int64            `json:"verified_achievements"`
+	ByType               []TypeStatistic  `json:"by_type"`
+	ByLevel              []LevelStatistic `json:"by_level"`
+}
+
+// NewStudentReportSummary menghitung ringkasan dari daftar prestasi terverifikasi. Tipe dan
+// tingkat diurutkan dari jumlah terbanyak agar isi laporan (dan hash-nya) deterministik.
+func NewStudentReportSummary(achievements []StudentReportAchievement) StudentReportSummary {
+	types := map[string]int64{}
+	levels := map[string]int64{}
+	for _, achievement := range achievements {
+		types[achievement.AchievementType]++
+		level := achievement.Level
+		if level == "" {
+			level = "unknown"
+		}
+		levels[level]++
+	}
+
+	total := int64(len(achievements))
+	summary := StudentReportSummary{
+		VerifiedAchievements: total,
+		ByType:               []TypeStatistic{},
+		ByLevel:              []LevelStatistic{},
+	}
+	for achievementType, count := range types {
+		summary.ByType = append(summary.ByType, TypeStatistic{
+			AchievementType: achievementType,
+			Count:           count,
+			Percentage:      float64(count) / float64(total) * 100,
+		})
+	}
+	for level, count := range levels {
+		summary.ByLevel = append(summary.ByLevel, LevelStatistic{
+			Level:      level,
+			Count:      count,
+			Percentage: float64(count) / float64(total) * 100,
+		})
+	}
+
+	sort.Slice(summary.ByType, func(i, j int) bool {
+		if summary.ByType[i].Count != summary.ByType[j].Count {
+			return summary.ByType[i].Count > summary.ByType[j].Count
+		}
+		return summary.ByType[i].AchievementType < summary.ByType[j].AchievementType
+	})
+	sort.Slice(summary.ByLevel, func(i, j int) bool {
+		if summary.ByLevel[i].Count != summary.ByLevel[j].Count {
+			return summary.ByLevel[i].Count > summary.ByLevel[j].Count
+		}
+		return summary.ByLevel[i].Level < summary.ByLevel[j].Level
+	})
+
+	return summary
+}
+
+// StudentReportAchievement satu prestasi terverifikasi di laporan
+type StudentReportAchievement struct {
+	ID              uuid.UUID  `json:"id"`
+	AchievementType string     `json:"achievement_type"`
+	Title           string     `json:"title"`
+	Level           string     `json:"level,omitempty"`
+	VerifiedAt      *time.Time `json:"verified_at"`
+	VerifiedBy      *string    `json:"verified_by,omitempty"` // nama dosen yang memverifikasi
+}
+
+// IssuedStudentReport laporan PDF yang sudah diterbitkan. Content adalah JSON StudentReport
+// persis seperti saat ditandatangani sehingga hash-nya bisa dihitung ulang saat verifikasi.
+type IssuedStudentReport struct {
+	ID          uuid.UUID `json:"id"`
+	StudentID   uuid.UUID `json:"student_id"`
+	IssuedBy    uuid.UUID `json:"issued_by"`
+	Content     []byte    `json:"-"`
+	ContentHash string    `json:"content_hash"`
+	Signature   string    `json:"signature"`
+	IssuedAt    time.Time `json:"issued_at"`
+}
+
+// StudentReportVerification hasil verifikasi publik laporan yang dipindai dari QR code
+type StudentReportVerification struct {
+	Valid       bool           `json:"valid"`
+	Reason      string         `json:"reason,omitempty"` // alasan jika tidak valid
+	ReportID    uuid.UUID      `json:"report_id"`
+	IssuedAt    time.Time      `json:"issued_at"`
+	ContentHash string         `json:"content_hash"`
+	Signature   string         `json:"signature"`
+	Algorithm   string         `json:"algorithm"`
+	PublicKey   string         `json:"public_key"`
+	Report      *StudentReport `json:"report,omitempty"`
+}
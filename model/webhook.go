@@ -0,0 +1,138 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event webhook siklus hidup prestasi
+const (
+	WebhookAchievementCreated   = "achievement.created"
+	WebhookAchievementSubmitted = "achievement.submitted"
+	WebhookAchievementVerified  = "achievement.verified"
+	WebhookAchievementRejected  = "achievement.rejected"
+	WebhookAchievementDeleted   = "achievement.deleted"
+)
+
+// WebhookEvents semua event yang bisa dilanggan
+var WebhookEvents = []string{
+	WebhookAchievementCreated,
+	WebhookAchievementSubmitted,
+	WebhookAchievementVerified,
+	WebhookAchievementRejected,
+	WebhookAchievementDeleted,
+}
+
+// WebhookEventForStatus event untuk perubahan status prestasi, kosong jika perubahan
+// tersebut tidak dikirim (rejected -> draft saat prestasi diperbaiki). Prestasi yang
+// dipulihkan dari deleted (kompensasi outbox) dikirim sebagai created.
+func WebhookEventForStatus(fromStatus, toStatus string) string {
+	switch toStatus {
+	case "draft":
+		if fromStatus == "" || fromStatus == "deleted" {
+			return WebhookAchievementCreated
+		}
+	case "submitted":
+		return WebhookAchievementSubmitted
+	case "verified":
+		return WebhookAchievementVerified
+	case "rejected":
+		return WebhookAchievementRejected
+	case "deleted":
+		return WebhookAchievementDeleted
+	}
+	return ""
+}
+
+// Status pengiriman webhook
+const (
+	WebhookPending    = "pending"
+	WebhookDelivering = "delivering"
+	WebhookDelivered  = "delivered"
+	WebhookFailed     = "failed"
+)
+
+// WebhookSubscription langganan webhook. Secret hanya ditampilkan saat dibuat.
+type WebhookSubscription struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	URL       string     `json:"url"`
+	Secret    string     `json:"secret,omitempty"`
+	Events    []string   `json:"events"` // kosong berarti semua event
+	IsActive  bool       `json:"is_active"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// CreateWebhookRequest request POST /admin/webhooks. Secret dibuat otomatis jika kosong.
+type CreateWebhookRequest struct {
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+// UpdateWebhookRequest request PUT /admin/webhooks/:id, field kosong tidak diubah
+type UpdateWebhookRequest struct {
+	Name     *string   `json:"name"`
+	URL      *string   `json:"url"`
+	Secret   *string   `json:"secret"`
+	Events   *[]string `json:"events"`
+	IsActive *bool     `json:"is_active"`
+}
+
+// WebhookPayload body JSON yang dikirim ke penerima webhook
+type WebhookPayload struct {
+	ID        uuid.UUID          `json:"id"` // sama untuk semua langganan, dipakai penerima untuk deduplikasi
+	Type      string             `json:"type"`
+	CreatedAt time.Time          `json:"created_at"`
+	Data      WebhookAchievement `json:"data"`
+}
+
+// WebhookAchievement data prestasi di payload webhook
+type WebhookAchievement struct {
+	AchievementReferenceID uuid.UUID  `json:"achievement_reference_id"`
+	AchievementID          string     `json:"achievement_id"`
+	StudentID              uuid.UUID  `json:"student_id"`
+	Status                 string     `json:"status"`
+	PreviousStatus         *string    `json:"previous_status"`
+	ChangedBy              *uuid.UUID `json:"changed_by"` // null jika diubah oleh sistem
+	Note                   *string    `json:"note"`
+}
+
+// WebhookDelivery satu event untuk satu langganan
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+
+	// Diisi saat diambil worker
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+
+	// Diisi di detail delivery
+	AttemptLog []WebhookDeliveryAttempt `json:"attempt_log,omitempty"`
+}
+
+// WebhookDeliveryAttempt log satu percobaan pengiriman
+type WebhookDeliveryAttempt struct {
+	ID           uuid.UUID `json:"id"`
+	Attempt      int       `json:"attempt"`
+	StatusCode   *int      `json:"status_code,omitempty"`
+	Error        *string   `json:"error,omitempty"`
+	ResponseBody *string   `json:"response_body,omitempty"`
+	DurationMs   int       `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// EmailQueue antrean email, diimplementasikan EmailQueueRepository
+type EmailQueue interface {
+	Enqueue(ctx context.Context, message model.EmailMessage) error
+	ClaimDue(ctx context.Context, limit int) ([]model.EmailMessage, error)
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, message model.EmailMessage, cause error) (bool, error)
+}
+
+// EmailChannel merender notifikasi sesuai bahasa penerima lalu memasukkannya ke antrean.
+// Pengiriman SMTP dilakukan EmailWorker sehingga request tidak menunggu server SMTP.
+type EmailChannel struct {
+	Queue       EmailQueue
+	Templates   *Templates
+	LinkBaseURL string // tautan prestasi: <LinkBaseURL>/achievements/<id>
+}
+
+func NewEmailChannel(queue EmailQueue, templates *Templates, linkBaseURL string) *EmailChannel {
+	return &EmailChannel{
+		Queue:       queue,
+		Templates:   templates,
+		LinkBaseURL: strings.TrimRight(linkBaseURL, "/"),
+	}
+}
+
+func (c *EmailChannel) Name() string {
+	return model.NotificationChannelEmail
+}
+
+func (c *EmailChannel) Send(ctx context.Context, recipient model.NotificationRecipient, notification model.Notification) error {
+	// Penerima tanpa alamat email dilewati
+	if recipient.Email == "" {
+		return nil
+	}
+
+	rendered, err := c.Templates.Render(recipient.Preferences.Language, notification.Type, c.templateData(recipient, notification))
+	if err != nil {
+		return err
+	}
+
+	notificationID, userID := notification.ID, recipient.UserID
+	return c.Queue.Enqueue(ctx, model.EmailMessage{
+		ID:             uuid.New(),
+		NotificationID: &notificationID,
+		UserID:         &userID,
+		To:             recipient.Email,
+		Subject:        rendered.Subject,
+		HTMLBody:       rendered.HTML,
+		TextBody:       rendered.Text,
+	})
+}
+
+func (c *EmailChannel) templateData(recipient model.NotificationRecipient, notification model.Notification) TemplateData {
+	data := TemplateData{
+		RecipientName: recipient.FullName,
+		Title:         notification.Title,
+		Message:       notification.Message,
+	}
+
+	if notification.Data != nil {
+		data.StudentName = notification.Data.StudentName
+		data.AchievementTitle = notification.Data.AchievementTitle
+		data.RejectionNote = notification.Data.RejectionNote
+		if c.LinkBaseURL != "" && notification.Data.AchievementID != "" {
+			data.Link = c.LinkBaseURL + "/achievements/" + url.PathEscape(notification.Data.AchievementID)
+		}
+	}
+
+	return data
+}
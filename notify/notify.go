@@ -0,0 +1,68 @@
+// Package notify meneruskan notifikasi yang sudah disimpan di inbox ke channel lain
+// (realtime, email) sesuai preferensi penerima.
+package notify
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Channel tujuan pengiriman notifikasi selain inbox
+type Channel interface {
+	Name() string // sama dengan model.NotificationChannel*
+	Send(ctx context.Context, recipient model.NotificationRecipient, notification model.Notification) error
+}
+
+// RecipientStore sumber data penerima, diimplementasikan NotificationRepository
+type RecipientStore interface {
+	GetRecipient(userID uuid.UUID) (*model.NotificationRecipient, error)
+}
+
+// Dispatcher meneruskan notifikasi ke setiap channel yang tidak dimatikan penerima
+type Dispatcher struct {
+	Recipients RecipientStore
+	Channels   []Channel
+	Timeout    time.Duration // batas waktu semua channel untuk satu notifikasi
+}
+
+func NewDispatcher(recipients RecipientStore, channels ...Channel) *Dispatcher {
+	return &Dispatcher{
+		Recipients: recipients,
+		Channels:   channels,
+		Timeout:    5 * time.Second,
+	}
+}
+
+// Dispatch dipanggil setelah notifikasi tersimpan. Kegagalan channel hanya di-log
+// karena notifikasi tetap bisa dibaca lewat inbox. Dispatcher nil tidak melakukan apa-apa.
+func (d *Dispatcher) Dispatch(notification model.Notification) {
+	if d == nil || len(d.Channels) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout)
+	defer cancel()
+
+	recipient, err := d.Recipients.GetRecipient(notification.UserID)
+	if err != nil {
+		// Tanpa data penerima, tetap kirim dengan preferensi bawaan (channel tanpa alamat dilewati)
+		log.Printf("notify: gagal mengambil penerima %s: %v", notification.UserID, err)
+		recipient = &model.NotificationRecipient{
+			UserID:      notification.UserID,
+			Preferences: model.NotificationPreferences{Language: model.LanguageID},
+		}
+	}
+
+	for _, channel := range d.Channels {
+		if !recipient.Preferences.ChannelEnabled(channel.Name()) {
+			continue
+		}
+		if err := channel.Send(ctx, *recipient, notification); err != nil {
+			log.Printf("notify: channel %s gagal untuk notifikasi %s: %v", channel.Name(), notification.ID, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"POJECT_UAS/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRecipients struct {
+	recipient *model.NotificationRecipient
+	err       error
+}
+
+func (f fakeRecipients) GetRecipient(uuid.UUID) (*model.NotificationRecipient, error) {
+	return f.recipient, f.err
+}
+
+type recordingChannel struct {
+	name string
+	sent []model.Notification
+	err  error
+}
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Send(_ context.Context, _ model.NotificationRecipient, notification model.Notification) error {
+	c.sent = append(c.sent, notification)
+	return c.err
+}
+
+func TestDispatcher_SkipsDisabledChannels(t *testing.T) {
+	realtimeCh := &recordingChannel{name: model.NotificationChannelRealtime}
+	emailCh := &recordingChannel{name: model.NotificationChannelEmail}
+	dispatcher := NewDispatcher(fakeRecipients{recipient: &model.NotificationRecipient{
+		Email: "budi@example.com",
+		Preferences: model.NotificationPreferences{
+			Language: model.LanguageID,
+			Channels: map[string]bool{model.NotificationChannelEmail: false},
+		},
+	}}, realtimeCh, emailCh)
+
+	// Execute
+	dispatcher.Dispatch(model.Notification{ID: uuid.New()})
+
+	// Assert: realtime tidak tercatat di preferensi sehingga tetap aktif
+	assert.Len(t, realtimeCh.sent, 1)
+	assert.Empty(t, emailCh.sent)
+}
+
+func TestDispatcher_ChannelErrorDoesNotStopOthers(t *testing.T) {
+	failing := &recordingChannel{name: model.NotificationChannelRealtime, err: errors.New("broker down")}
+	emailCh := &recordingChannel{name: model.NotificationChannelEmail}
+	dispatcher := NewDispatcher(fakeRecipients{err: errors.New("db down")}, failing, emailCh)
+
+	// Execute: penerima gagal diambil dan channel pertama error
+	dispatcher.Dispatch(model.Notification{ID: uuid.New()})
+
+	// Assert
+	assert.Len(t, failing.sent, 1)
+	assert.Len(t, emailCh.sent, 1)
+}
+
+func TestDispatcher_NilIsNoop(t *testing.T) {
+	var dispatcher *Dispatcher
+	assert.NotPanics(t, func() { dispatcher.Dispatch(model.Notification{}) })
+}
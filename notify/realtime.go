@@ -0,0 +1,28 @@
+package notify
+
+import (
+	"POJECT_UAS/model"
+	"POJECT_UAS/realtime"
+	"context"
+)
+
+// RealtimeChannel mengirim notifikasi ke koneksi SSE/WebSocket penerima
+type RealtimeChannel struct {
+	Hub *realtime.Hub
+}
+
+func NewRealtimeChannel(hub *realtime.Hub) *RealtimeChannel {
+	return &RealtimeChannel{Hub: hub}
+}
+
+func (c *RealtimeChannel) Name() string {
+	return model.NotificationChannelRealtime
+}
+
+func (c *RealtimeChannel) Send(ctx context.Context, recipient model.NotificationRecipient, notification model.Notification) error {
+	event, err := realtime.NewEvent(notification.ID.String(), realtime.EventNotification, notification.UserID, notification)
+	if err != nil {
+		return err
+	}
+	return c.Hub.Publish(ctx, event)
+}
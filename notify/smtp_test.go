@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"POJECT_UAS/model"
+	"POJECT_UAS/notify/smtptest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryQueue EmailQueue in-memory untuk test worker
+type memoryQueue struct {
+	pending     []model.EmailMessage
+	sent        []uuid.UUID
+	maxAttempts int
+	failed      []uuid.UUID
+}
+
+func (q *memoryQueue) Enqueue(_ context.Context, message model.EmailMessage) error {
+	q.pending = append(q.pending, message)
+	return nil
+}
+
+func (q *memoryQueue) ClaimDue(_ context.Context, limit int) ([]model.EmailMessage, error) {
+	n := min(limit, len(q.pending))
+	claimed := q.pending[:n]
+	q.pending = q.pending[n:]
+	for i := range claimed {
+		claimed[i].Attempts++
+	}
+	return claimed, nil
+}
+
+func (q *memoryQueue) MarkSent(_ context.Context, id uuid.UUID) error {
+	q.sent = append(q.sent, id)
+	return nil
+}
+
+func (q *memoryQueue) MarkFailed(_ context.Context, message model.EmailMessage, _ error) (bool, error) {
+	if message.Attempts >= q.maxAttempts {
+		q.failed = append(q.failed, message.ID)
+		return true, nil
+	}
+	q.pending = append(q.pending, message)
+	return false, nil
+}
+
+func newTestMailer(server *smtptest.Server) *SMTPMailer {
+	return &SMTPMailer{
+		Host: server.Host(),
+		Port: server.Port(),
+		From: "Sistem Prestasi <no-reply@prestasi.test>",
+	}
+}
+
+func TestSMTPMailer_SendsMultipartEmail(t *testing.T) {
+	server := smtptest.NewServer()
+	defer server.Close()
+
+	// Execute
+	err := newTestMailer(server).Send(context.Background(), model.EmailMessage{
+		To:       "Budi <budi@example.com>",
+		Subject:  "Prestasi diverifikasi: Juara 1",
+		TextBody: "Halo Budi, prestasi Anda sudah diverifikasi.",
+		HTMLBody: "<p>Halo Budi, prestasi Anda sudah <strong>diverifikasi</strong>.</p>",
+	})
+	assert.NoError(t, err)
+
+	// Assert
+	messages := server.Messages()
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	assert.Equal(t, "no-reply@prestasi.test", messages[0].From)
+	assert.Equal(t, []string{"budi@example.com"}, messages[0].To)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(messages[0].Data)))
+	assert.NoError(t, err)
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	assert.Equal(t, "Prestasi diverifikasi: Juara 1", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	var types, bodies []string
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(part) // quoted-printable didekode otomatis
+		types = append(types, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, types)
+	assert.Contains(t, bodies[1], "<strong>diverifikasi</strong>")
+}
+
+func TestEmailWorker_RetriesTemporaryFailure(t *testing.T) {
+	server := smtptest.NewServer()
+	defer server.Close()
+	server.FailNext(1)
+
+	queue := &memoryQueue{maxAttempts: 3}
+	templates, err := LoadTemplates()
+	assert.NoError(t, err)
+	channel := NewEmailChannel(queue, templates, "http://localhost:3000/")
+	worker := NewEmailWorker(queue, newTestMailer(server), 0, 10)
+
+	recipient := model.NotificationRecipient{
+		UserID:      uuid.New(),
+		Email:       "siti@example.com",
+		FullName:    "Siti",
+		Preferences: model.NotificationPreferences{Language: model.LanguageEN},
+	}
+	notification := model.Notification{
+		ID:   uuid.New(),
+		Type: "achievement_verified",
+		Data: &model.NotificationData{AchievementID: "abc", AchievementTitle: "Juara 1"},
+	}
+	assert.NoError(t, channel.Send(context.Background(), recipient, notification))
+
+	// Execute: percobaan pertama ditolak server, kedua berhasil
+	assert.Equal(t, 0, worker.SendDue(context.Background()))
+	assert.Equal(t, 1, worker.SendDue(context.Background()))
+
+	// Assert
+	assert.Len(t, queue.sent, 1)
+	assert.Empty(t, queue.failed)
+	messages := server.Messages()
+	if assert.Len(t, messages, 1) {
+		assert.Contains(t, string(messages[0].Data), "Achievement verified: Juara 1")
+		assert.Contains(t, string(messages[0].Data), "http://localhost:3000/achievements/abc")
+	}
+}
+
+func TestEmailChannel_SkipsRecipientWithoutEmail(t *testing.T) {
+	queue := &memoryQueue{}
+	templates, err := LoadTemplates()
+	assert.NoError(t, err)
+
+	err = NewEmailChannel(queue, templates, "").Send(context.Background(), model.NotificationRecipient{}, model.Notification{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, queue.pending)
+}
@@ -0,0 +1,189 @@
+// Package smtptest server SMTP palsu untuk test, mirip httptest.Server.
+// Email yang diterima disimpan di memori dan tidak diteruskan ke mana pun.
+package smtptest
+
+import (
+	"bytes"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message email yang diterima server
+type Message struct {
+	From string
+	To   []string
+	Data []byte // isi DATA lengkap (header + body)
+}
+
+// Server SMTP palsu yang mendengarkan di 127.0.0.1 port acak
+type Server struct {
+	Addr string
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+	messages []Message
+	failNext int
+}
+
+// NewServer menjalankan server baru, tutup dengan Close
+func NewServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("smtptest: gagal listen: " + err.Error())
+	}
+
+	s := &Server{
+		Addr:     listener.Addr().String(),
+		listener: listener,
+		conns:    map[net.Conn]struct{}{},
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Host host server
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.Addr)
+	return host
+}
+
+// Port port server
+func (s *Server) Port() int {
+	_, port, _ := net.SplitHostPort(s.Addr)
+	n, _ := strconv.Atoi(port)
+	return n
+}
+
+// Messages salinan email yang sudah diterima
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// FailNext membuat n perintah DATA berikutnya ditolak dengan 451 (kegagalan sementara)
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = n
+}
+
+// Close menghentikan server, memutus koneksi yang masih terbuka, dan menunggu semuanya selesai
+func (s *Server) Close() {
+	s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+	tp := textproto.NewConn(conn)
+
+	reply := func(code int, msg string) {
+		tp.PrintfLine("%d %s", code, msg)
+	}
+	reply(220, "smtptest ready")
+
+	var current Message
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			tp.PrintfLine("250-smtptest")
+			tp.PrintfLine("250-8BITMIME")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "HELO":
+			reply(250, "smtptest")
+		case "AUTH":
+			reply(235, "authenticated")
+		case "MAIL":
+			current = Message{From: addressArg(arg)}
+			reply(250, "ok")
+		case "RCPT":
+			current.To = append(current.To, addressArg(arg))
+			reply(250, "ok")
+		case "DATA":
+			reply(354, "end data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			if s.shouldFail() {
+				reply(451, "temporary failure")
+				continue
+			}
+			current.Data = bytes.TrimSpace(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply(250, "queued")
+		case "RSET":
+			current = Message{}
+			reply(250, "ok")
+		case "NOOP":
+			reply(250, "ok")
+		case "QUIT":
+			reply(221, "bye")
+			return
+		default:
+			reply(502, "command not implemented")
+		}
+	}
+}
+
+func (s *Server) shouldFail() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failNext > 0 {
+		s.failNext--
+		return true
+	}
+	return false
+}
+
+// addressArg mengambil alamat dari "FROM:<a@b>" atau "TO:<a@b>"
+func addressArg(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	addr, _, _ = strings.Cut(strings.TrimSpace(addr), " ")
+	return strings.Trim(addr, "<>")
+}
@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"POJECT_UAS/model"
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Template per bahasa: templates/<lang>/<type>.txt berisi blok "subject" dan "text",
+// templates/<lang>/<type>.html berisi blok "content" yang dibungkus layout.html.
+// Tipe yang tidak punya template memakai default.
+//
+//go:embed templates
+var templateFS embed.FS
+
+const defaultTemplate = "default"
+
+// TemplateData data yang tersedia di template email
+type TemplateData struct {
+	Subject          string // diisi saat render HTML
+	RecipientName    string
+	Title            string
+	Message          string
+	StudentName      string
+	AchievementTitle string
+	RejectionNote    string
+	Link             string
+}
+
+// RenderedEmail hasil render template
+type RenderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Templates template email yang sudah di-parse, per bahasa dan tipe notifikasi
+type Templates struct {
+	byLanguage map[string]map[string]emailTemplate
+}
+
+// LoadTemplates mem-parse semua template bawaan
+func LoadTemplates() (*Templates, error) {
+	t := &Templates{byLanguage: map[string]map[string]emailTemplate{}}
+
+	for _, language := range []string{model.LanguageID, model.LanguageEN} {
+		dir := path.Join("templates", language)
+		layout, err := htmltemplate.ParseFS(templateFS, path.Join(dir, "layout.html"))
+		if err != nil {
+			return nil, err
+		}
+
+		files, err := fs.Glob(templateFS, path.Join(dir, "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+
+		t.byLanguage[language] = map[string]emailTemplate{}
+		for _, file := range files {
+			name := strings.TrimSuffix(path.Base(file), ".txt")
+
+			text, err := texttemplate.ParseFS(templateFS, file)
+			if err != nil {
+				return nil, err
+			}
+
+			html, err := htmltemplate.Must(layout.Clone()).ParseFS(templateFS, path.Join(dir, name+".html"))
+			if err != nil {
+				return nil, err
+			}
+
+			t.byLanguage[language][name] = emailTemplate{text: text, html: html}
+		}
+
+		if _, ok := t.byLanguage[language][defaultTemplate]; !ok {
+			return nil, fmt.Errorf("template %s/%s tidak ada", language, defaultTemplate)
+		}
+	}
+
+	return t, nil
+}
+
+// Render merender email untuk tipe notifikasi dalam bahasa tertentu.
+// Bahasa yang tidak dikenal memakai bahasa Indonesia.
+func (t *Templates) Render(language, notificationType string, data TemplateData) (RenderedEmail, error) {
+	templates, ok := t.byLanguage[language]
+	if !ok {
+		templates = t.byLanguage[model.LanguageID]
+	}
+	tmpl, ok := templates[notificationType]
+	if !ok {
+		tmpl = templates[defaultTemplate]
+	}
+
+	var out RenderedEmail
+	var buf bytes.Buffer
+
+	if err := tmpl.text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return out, err
+	}
+	// Header subject tidak boleh berisi baris baru
+	out.Subject = strings.Join(strings.Fields(buf.String()), " ")
+	data.Subject = out.Subject
+
+	buf.Reset()
+	if err := tmpl.text.ExecuteTemplate(&buf, "text", data); err != nil {
+		return out, err
+	}
+	out.Text = strings.TrimSpace(buf.String()) + "\n"
+
+	buf.Reset()
+	if err := tmpl.html.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return out, err
+	}
+	out.HTML = buf.String()
+
+	return out, nil
+}
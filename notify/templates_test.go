@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"testing"
+
+	"POJECT_UAS/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplates_RenderInBothLanguages(t *testing.T) {
+	templates, err := LoadTemplates()
+	assert.NoError(t, err)
+
+	data := TemplateData{
+		RecipientName:    "Budi",
+		AchievementTitle: "Juara 1 <Hackathon>",
+		RejectionNote:    "Sertifikat tidak terbaca",
+		Link:             "http://localhost:3000/achievements/abc",
+	}
+
+	// Execute
+	id, err := templates.Render(model.LanguageID, "achievement_rejected", data)
+	assert.NoError(t, err)
+	en, err := templates.Render(model.LanguageEN, "achievement_rejected", data)
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "Prestasi ditolak: Juara 1 <Hackathon>", id.Subject)
+	assert.Contains(t, id.Text, "Alasan: Sertifikat tidak terbaca")
+	assert.Contains(t, id.HTML, `lang="id"`)
+
+	assert.Equal(t, "Achievement rejected: Juara 1 <Hackathon>", en.Subject)
+	assert.Contains(t, en.Text, "Reason: Sertifikat tidak terbaca")
+	assert.Contains(t, en.HTML, "View Achievement")
+
+	// HTML di-escape, teks tidak
+	assert.Contains(t, en.HTML, "Juara 1 &lt;Hackathon&gt;")
+	assert.Contains(t, en.Text, `"Juara 1 <Hackathon>"`)
+}
+
+func TestTemplates_Fallbacks(t *testing.T) {
+	templates, err := LoadTemplates()
+	assert.NoError(t, err)
+
+	// Execute: bahasa tidak dikenal dan tipe tanpa template
+	rendered, err := templates.Render("fr", "account_locked", TemplateData{
+		Title:   "Akun\nDikunci",
+		Message: "Akun Anda dikunci sementara",
+	})
+
+	// Assert: bahasa Indonesia, template default, subject satu baris
+	assert.NoError(t, err)
+	assert.Equal(t, "Akun Dikunci", rendered.Subject)
+	assert.Contains(t, rendered.Text, "Akun Anda dikunci sementara")
+	assert.NotContains(t, rendered.HTML, "Lihat Prestasi")
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// EmailWorker mengirim email di antrean setiap PollInterval. Email yang gagal dijadwalkan
+// ulang dengan backoff oleh antrean sampai batas percobaan habis.
+type EmailWorker struct {
+	Queue        EmailQueue
+	Mailer       Mailer
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+func NewEmailWorker(queue EmailQueue, mailer Mailer, pollInterval time.Duration, batchSize int) *EmailWorker {
+	return &EmailWorker{
+		Queue:        queue,
+		Mailer:       mailer,
+		PollInterval: pollInterval,
+		BatchSize:    batchSize,
+	}
+}
+
+// Run berjalan sampai ctx dibatalkan
+func (w *EmailWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.SendDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue mengirim satu batch email yang jatuh tempo, mengembalikan jumlah yang terkirim
+func (w *EmailWorker) SendDue(ctx context.Context) int {
+	messages, err := w.Queue.ClaimDue(ctx, w.BatchSize)
+	if err != nil {
+		log.Printf("email: gagal mengambil antrean: %v", err)
+		return 0
+	}
+
+	sent := 0
+	for _, message := range messages {
+		sendErr := w.Mailer.Send(ctx, message)
+		if sendErr == nil {
+			sent++
+			if err := w.Queue.MarkSent(ctx, message.ID); err != nil {
+				log.Printf("email %s: terkirim tetapi gagal ditandai: %v", message.ID, err)
+			}
+			continue
+		}
+
+		final, err := w.Queue.MarkFailed(ctx, message, sendErr)
+		if err != nil {
+			log.Printf("email %s: gagal menyimpan status: %v", message.ID, err)
+		}
+		if final {
+			log.Printf("email %s ke %s gagal permanen setelah %d percobaan: %v", message.ID, message.To, message.Attempts, sendErr)
+		} else {
+			log.Printf("email %s ke %s gagal (percobaan %d), dicoba lagi: %v", message.ID, message.To, message.Attempts, sendErr)
+		}
+	}
+
+	return sent
+}
@@ -0,0 +1,168 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Jenis event yang dikirim ke client
+const (
+	EventNotification = "notification"
+)
+
+// subscriptionBuffer jumlah event yang ditampung per koneksi sebelum event baru dibuang
+const subscriptionBuffer = 16
+
+// Event pesan untuk satu user. Data sudah dalam bentuk JSON agar bisa dikirim lewat broker.
+type Event struct {
+	ID     string          `json:"id"`
+	Type   string          `json:"type"`
+	UserID uuid.UUID       `json:"user_id"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// NewEvent membuat Event dengan data di-encode ke JSON
+func NewEvent(id, eventType string, userID uuid.UUID, data interface{}) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{ID: id, Type: eventType, UserID: userID, Data: raw}, nil
+}
+
+// Broker meneruskan event ke semua replika aplikasi. Setiap event yang di-Publish
+// (oleh replika mana pun) diterima lewat channel dari Subscribe, termasuk oleh pengirimnya.
+type Broker interface {
+	Publish(ctx context.Context, event Event) error
+	// Subscribe channel ditutup saat ctx dibatalkan
+	Subscribe(ctx context.Context) (<-chan Event, error)
+}
+
+// Hub membagikan event dari broker ke koneksi SSE/WebSocket milik user di replika ini
+type Hub struct {
+	Broker Broker
+
+	mu     sync.RWMutex
+	subs   map[uuid.UUID]map[*Subscription]struct{}
+	closed bool
+}
+
+func NewHub(broker Broker) *Hub {
+	return &Hub{
+		Broker: broker,
+		subs:   make(map[uuid.UUID]map[*Subscription]struct{}),
+	}
+}
+
+// Subscription satu koneksi yang menerima event milik UserID
+type Subscription struct {
+	UserID uuid.UUID
+	C      <-chan Event
+
+	hub  *Hub
+	ch   chan Event
+	once sync.Once
+}
+
+// Run menerima event dari broker sampai ctx dibatalkan
+func (h *Hub) Run(ctx context.Context) error {
+	events, err := h.Broker.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+
+	for event := range events {
+		h.deliver(event)
+	}
+
+	return ctx.Err()
+}
+
+// Publish mengirim event ke semua koneksi milik event.UserID di semua replika
+func (h *Hub) Publish(ctx context.Context, event Event) error {
+	return h.Broker.Publish(ctx, event)
+}
+
+// Subscribe mendaftarkan koneksi baru. Close harus dipanggil saat koneksi berakhir.
+func (h *Hub) Subscribe(userID uuid.UUID) *Subscription {
+	ch := make(chan Event, subscriptionBuffer)
+	sub := &Subscription{UserID: userID, C: ch, hub: h, ch: ch}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Hub yang sudah ditutup langsung mengakhiri koneksi baru
+	if h.closed {
+		sub.once.Do(func() { close(ch) })
+		return sub
+	}
+
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+
+	return sub
+}
+
+// Close melepas subscription dan menutup channel C
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		h := s.hub
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.subs[s.UserID], s)
+		if len(h.subs[s.UserID]) == 0 {
+			delete(h.subs, s.UserID)
+		}
+		close(s.ch)
+	})
+}
+
+// Close menutup channel C semua subscription sehingga stream SSE/WebSocket selesai,
+// dipanggil saat server dimatikan agar tidak menunggu koneksi yang tidak pernah berakhir
+func (h *Hub) Close() {
+	h.mu.Lock()
+	subs := h.subs
+	h.subs = make(map[uuid.UUID]map[*Subscription]struct{})
+	h.closed = true
+	h.mu.Unlock()
+
+	for _, userSubs := range subs {
+		for sub := range userSubs {
+			sub.once.Do(func() { close(sub.ch) })
+		}
+	}
+}
+
+// Connections jumlah koneksi aktif di replika ini
+func (h *Hub) Connections() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	n := 0
+	for _, subs := range h.subs {
+		n += len(subs)
+	}
+	return n
+}
+
+// deliver mengirim event ke koneksi lokal. Koneksi yang lambat tidak menahan yang lain:
+// jika buffer penuh event dibuang, client bisa mengambil ulang lewat GET /notifications.
+func (h *Hub) deliver(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subs[event.UserID] {
+		select {
+		case sub.ch <- event:
+		default:
+			log.Printf("realtime: buffer penuh, event %s untuk user %s dibuang", event.ID, event.UserID)
+		}
+	}
+}
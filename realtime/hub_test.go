@@ -0,0 +1,131 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// startHub menjalankan hub dengan MemoryBroker sampai test selesai
+func startHub(t *testing.T, broker Broker) *Hub {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	hub := NewHub(broker)
+	go hub.Run(ctx)
+
+	return hub
+}
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event := <-sub.C:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("event tidak diterima")
+		return Event{}
+	}
+}
+
+func TestHub_DeliversOnlyToRecipient(t *testing.T) {
+	hub := startHub(t, NewMemoryBroker())
+	time.Sleep(10 * time.Millisecond) // tunggu hub subscribe ke broker
+
+	lecturer, student := uuid.New(), uuid.New()
+	lecturerTab1 := hub.Subscribe(lecturer)
+	lecturerTab2 := hub.Subscribe(lecturer)
+	studentSub := hub.Subscribe(student)
+	defer lecturerTab1.Close()
+	defer lecturerTab2.Close()
+	defer studentSub.Close()
+
+	event, err := NewEvent("n-1", EventNotification, lecturer, map[string]string{"title": "Prestasi Baru"})
+	assert.NoError(t, err)
+
+	// Execute
+	assert.NoError(t, hub.Publish(context.Background(), event))
+
+	// Assert: semua koneksi milik penerima mendapat event, user lain tidak
+	assert.JSONEq(t, `{"title":"Prestasi Baru"}`, string(receive(t, lecturerTab1).Data))
+	assert.Equal(t, "n-1", receive(t, lecturerTab2).ID)
+	select {
+	case <-studentSub.C:
+		t.Fatal("event terkirim ke user lain")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHub_SharedBrokerAcrossReplicas(t *testing.T) {
+	// Dua hub dengan broker yang sama mensimulasikan dua replika
+	broker := NewMemoryBroker()
+	replicaA := startHub(t, broker)
+	replicaB := startHub(t, broker)
+	time.Sleep(10 * time.Millisecond)
+
+	userID := uuid.New()
+	sub := replicaB.Subscribe(userID)
+	defer sub.Close()
+
+	// Execute: publish dari replika lain
+	event, _ := NewEvent("n-2", EventNotification, userID, nil)
+	assert.NoError(t, replicaA.Publish(context.Background(), event))
+
+	// Assert
+	assert.Equal(t, "n-2", receive(t, sub).ID)
+}
+
+func TestHub_SlowSubscriberDropsEvents(t *testing.T) {
+	hub := NewHub(NewMemoryBroker())
+	userID := uuid.New()
+	sub := hub.Subscribe(userID)
+	defer sub.Close()
+
+	// Execute: kirim lebih banyak dari buffer tanpa dibaca
+	for i := 0; i < subscriptionBuffer+5; i++ {
+		hub.deliver(Event{UserID: userID})
+	}
+
+	// Assert: deliver tidak pernah blocking, kelebihan event dibuang
+	assert.Len(t, sub.C, subscriptionBuffer)
+}
+
+func TestSubscription_Close(t *testing.T) {
+	hub := NewHub(NewMemoryBroker())
+	sub := hub.Subscribe(uuid.New())
+	assert.Equal(t, 1, hub.Connections())
+
+	// Execute: Close aman dipanggil lebih dari sekali
+	sub.Close()
+	sub.Close()
+
+	// Assert
+	assert.Equal(t, 0, hub.Connections())
+	_, ok := <-sub.C
+	assert.False(t, ok)
+}
+
+func TestHub_CloseEndsSubscriptions(t *testing.T) {
+	hub := NewHub(NewMemoryBroker())
+	userID := uuid.New()
+	sub := hub.Subscribe(userID)
+
+	// Execute
+	hub.Close()
+
+	// Assert: channel tertutup sehingga stream berakhir, Close milik koneksi tetap aman dipanggil
+	_, ok := <-sub.C
+	assert.False(t, ok)
+	assert.Equal(t, 0, hub.Connections())
+	sub.Close()
+
+	// Koneksi baru setelah hub ditutup langsung berakhir
+	late := hub.Subscribe(userID)
+	_, ok = <-late.C
+	assert.False(t, ok)
+	late.Close()
+	assert.Equal(t, 0, hub.Connections())
+}
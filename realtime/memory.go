@@ -0,0 +1,54 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBroker broker dalam satu proses, untuk satu replika dan untuk test
+type MemoryBroker struct {
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	buffer int
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subs:   make(map[chan Event]struct{}),
+		buffer: 256,
+	}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, event Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context) (<-chan Event, error) {
+	ch := make(chan Event, b.buffer)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+		close(ch)
+	}()
+
+	return ch, nil
+}
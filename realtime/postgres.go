@@ -0,0 +1,90 @@
+package realtime
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DefaultChannel channel LISTEN/NOTIFY untuk event realtime
+const DefaultChannel = "realtime_events"
+
+// PostgresBroker broker antar replika memakai LISTEN/NOTIFY PostgreSQL.
+// Payload NOTIFY dibatasi 8000 byte, cukup untuk notifikasi prestasi.
+type PostgresBroker struct {
+	DB      *sql.DB
+	DSN     string // koneksi khusus untuk LISTEN, terpisah dari pool DB
+	Channel string
+}
+
+func NewPostgresBroker(db *sql.DB, dsn string) *PostgresBroker {
+	return &PostgresBroker{
+		DB:      db,
+		DSN:     dsn,
+		Channel: DefaultChannel,
+	}
+}
+
+func (b *PostgresBroker) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.DB.ExecContext(ctx, `SELECT pg_notify($1, $2)`, b.Channel, string(payload))
+	return err
+}
+
+func (b *PostgresBroker) Subscribe(ctx context.Context) (<-chan Event, error) {
+	listener := pq.NewListener(b.DSN, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("realtime: koneksi LISTEN: %v", err)
+		}
+	})
+	if err := listener.Listen(b.Channel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	out := make(chan Event, 256)
+	go func() {
+		defer close(out)
+		defer listener.Close()
+
+		// Ping berkala agar koneksi yang putus terdeteksi dan disambung ulang
+		ticker := time.NewTicker(90 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				go listener.Ping()
+			case n := <-listener.Notify:
+				// nil dikirim setelah reconnect; event selama koneksi putus hilang
+				if n == nil {
+					continue
+				}
+
+				var event Event
+				if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+					log.Printf("realtime: payload tidak valid: %v", err)
+					continue
+				}
+
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,301 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ukuran kertas A4 dalam point (1/72 inci)
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Font standar PDF, tidak perlu di-embed. Teks dikodekan WinAnsi (CP1252).
+type Font int
+
+const (
+	FontRegular Font = iota
+	FontBold
+)
+
+var fontResources = [...]string{FontRegular: "F1", FontBold: "F2"}
+var fontNames = [...]string{FontRegular: "Helvetica", FontBold: "Helvetica-Bold"}
+
+// Document PDF 1.4 sederhana: teks Helvetica, garis dan kotak terisi. Cukup untuk laporan
+// tabel tanpa library eksternal.
+type Document struct {
+	pages []*Page
+	info  map[string]string
+}
+
+// Page satu halaman A4 potret. Koordinat dari kiri bawah.
+type Page struct {
+	content bytes.Buffer
+}
+
+func NewDocument() *Document {
+	return &Document{info: map[string]string{}}
+}
+
+// SetInfo mengisi entry dictionary Info dokumen (Title, Author, Subject, atau key khusus)
+func (d *Document) SetInfo(key, value string) {
+	d.info[key] = value
+}
+
+// AddPage menambahkan halaman kosong di akhir dokumen
+func (d *Document) AddPage() *Page {
+	page := &Page{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Pages halaman dokumen sesuai urutan
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// Text menulis satu baris teks dengan baseline di (x, y)
+func (p *Page) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td %s Tj ET\n",
+		fontResources[font], num(size), num(x), num(y), pdfString(text))
+}
+
+// TextRight menulis teks rata kanan dengan ujung kanan di x
+func (p *Page) TextRight(x, y float64, font Font, size float64, text string) {
+	p.Text(x-TextWidth(font, size, text), y, font, size, text)
+}
+
+// Line menggambar garis lurus
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Rect menggambar kotak terisi hitam dengan sudut kiri bawah di (x, y)
+func (p *Page) Rect(x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n", num(x), num(y), num(w), num(h))
+}
+
+// QR menggambar QR code dengan sudut kiri atas di (x, top) dan lebar tiap modul moduleSize.
+// Modul gelap yang berurutan dalam satu baris digabung menjadi satu kotak.
+func (p *Page) QR(qr *QRCode, x, top, moduleSize float64) {
+	for row := 0; row < qr.Size(); row++ {
+		y := top - float64(row+1)*moduleSize
+		for col := 0; col < qr.Size(); {
+			if !qr.Module(col, row) {
+				col++
+				continue
+			}
+			start := col
+			for col < qr.Size() && qr.Module(col, row) {
+				col++
+			}
+			p.Rect(x+float64(start)*moduleSize, y, float64(col-start)*moduleSize, moduleSize)
+		}
+	}
+}
+
+// WriteTo menulis dokumen lengkap beserta tabel xref
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	// Objek 1 catalog, 2 pages, 3-4 font, 5 info, lalu pasangan page/content
+	begin := func() int {
+		offsets = append(offsets, buf.Len())
+		id := len(offsets)
+		fmt.Fprintf(&buf, "%d 0 obj\n", id)
+		return id
+	}
+	end := func() { buf.WriteString("endobj\n") }
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	pageIDs := make([]string, len(d.pages))
+	for i := range d.pages {
+		pageIDs[i] = fmt.Sprintf("%d 0 R", 6+i*2)
+	}
+
+	begin()
+	buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>\n")
+	end()
+
+	begin()
+	fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>\n", strings.Join(pageIDs, " "), len(d.pages))
+	end()
+
+	for _, font := range []Font{FontRegular, FontBold} {
+		begin()
+		fmt.Fprintf(&buf, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>\n", fontNames[font])
+		end()
+	}
+
+	begin()
+	keys := make([]string, 0, len(d.info))
+	for key := range d.info {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	buf.WriteString("<<")
+	for _, key := range keys {
+		fmt.Fprintf(&buf, " /%s %s", key, pdfString(d.info[key]))
+	}
+	buf.WriteString(" >>\n")
+	end()
+
+	for _, page := range d.pages {
+		pageID := begin()
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>\n",
+			num(PageWidth), num(PageHeight), pageID+1)
+		end()
+
+		begin()
+		fmt.Fprintf(&buf, "<< /Length %d >>\nstream\n", page.content.Len())
+		buf.Write(page.content.Bytes())
+		buf.WriteString("endstream\n")
+		end()
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// PDFDate format tanggal PDF (D:YYYYMMDDHHmmSSZ) untuk CreationDate
+func PDFDate(t time.Time) string {
+	return "D:" + t.UTC().Format("20060102150405") + "Z"
+}
+
+// TextWidth lebar teks dalam point
+func TextWidth(font Font, size float64, text string) float64 {
+	widths := &helveticaWidths
+	if font == FontBold {
+		widths = &helveticaBoldWidths
+	}
+
+	total := 0
+	for _, b := range winAnsi(text) {
+		if b >= 32 && b <= 126 {
+			total += int(widths[b-32])
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// WrapText memecah teks per kata agar setiap baris tidak lebih lebar dari maxWidth.
+// Kata yang lebih lebar dari maxWidth dipotong per karakter.
+func WrapText(font Font, size float64, text string, maxWidth float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if TextWidth(font, size, candidate) <= maxWidth {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = word
+		for TextWidth(font, size, line) > maxWidth {
+			runes := []rune(line)
+			cut := len(runes) - 1
+			for cut > 1 && TextWidth(font, size, string(runes[:cut])) > maxWidth {
+				cut--
+			}
+			lines = append(lines, string(runes[:cut]))
+			line = string(runes[cut:])
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfString literal string PDF dalam WinAnsi dengan karakter khusus di-escape
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, c := range winAnsi(text) {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 32 || c > 126:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// Karakter Unicode di luar Latin-1 yang ada di WinAnsi (CP1252)
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// winAnsi mengubah teks UTF-8 ke WinAnsi. Karakter yang tidak ada diganti '?'.
+func winAnsi(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			out = append(out, ' ')
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			out = append(out, byte(r))
+		default:
+			if b, ok := winAnsiExtra[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Lebar glyph Helvetica dan Helvetica-Bold (AFM standar) untuk karakter 32..126, per 1000 unit
+var helveticaWidths = [95]uint16{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]uint16{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,184 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// Contoh "HELLO WORLD" versi 1-M dari tutorial QR code Thonky
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	expected := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	assert.Equal(t, expected, rsRemainder(data, rsDivisor(10)))
+}
+
+func TestQRFormatAndVersionBits(t *testing.T) {
+	// Tabel format string tingkat M di ISO/IEC 18004
+	expected := []int{0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0}
+	for mask, bits := range expected {
+		assert.Equal(t, bits, qrFormatBits(mask), "mask %d", mask)
+	}
+
+	assert.Equal(t, 0x07C94, qrVersionBits(7))
+	assert.Equal(t, 0x0A4D3, qrVersionBits(10))
+}
+
+func TestQRAlignmentPositions(t *testing.T) {
+	assert.Empty(t, qrAlignmentPositions(1))
+	assert.Equal(t, []int{6, 18}, qrAlignmentPositions(2))
+	assert.Equal(t, []int{6, 22, 38}, qrAlignmentPositions(7))
+	assert.Equal(t, []int{6, 28, 50}, qrAlignmentPositions(10))
+}
+
+func TestEncodeQR_VersionSelection(t *testing.T) {
+	for length, version := range map[int]int{14: 1, 15: 2, 106: 6, 122: 7, 213: 10} {
+		qr, err := EncodeQR([]byte(strings.Repeat("a", length)))
+		assert.NoError(t, err)
+		assert.Equal(t, version*4+17, qr.Size(), "panjang %d", length)
+	}
+
+	_, err := EncodeQR([]byte(strings.Repeat("a", 214)))
+	assert.ErrorIs(t, err, ErrQRTooLong)
+}
+
+func TestEncodeQR_RoundTrip(t *testing.T) {
+	for _, data := range []string{
+		"HELLO",
+		"http://localhost:8080/api/v1/public/reports/5f0c8c3e-8f1d-4b7a-9a51-2f4f7c1f9d10?h=9f86d081884c7d65",
+		strings.Repeat("prestasi-", 23),
+	} {
+		qr, err := EncodeQR([]byte(data))
+		assert.NoError(t, err)
+		assert.Equal(t, data, readQR(t, qr))
+	}
+}
+
+func TestEncodeQR_FunctionPatterns(t *testing.T) {
+	qr, err := EncodeQR([]byte("https://example.ac.id"))
+	assert.NoError(t, err)
+	size := qr.Size()
+
+	// Finder pattern di tiga sudut: cincin luar gelap, pemisah terang
+	for _, corner := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+		assert.True(t, qr.Module(corner[0], corner[1]))
+		assert.True(t, qr.Module(corner[0]+6, corner[1]+6))
+		assert.False(t, qr.Module(corner[0]+1, corner[1]+1))
+		assert.True(t, qr.Module(corner[0]+3, corner[1]+3))
+	}
+	assert.False(t, qr.Module(7, 7))
+
+	// Timing pattern dan dark module
+	for i := 8; i < size-8; i++ {
+		assert.Equal(t, i%2 == 0, qr.Module(i, 6))
+		assert.Equal(t, i%2 == 0, qr.Module(6, i))
+	}
+	assert.True(t, qr.Module(8, size-8))
+}
+
+// readQR membaca ulang isi QR code: format dari salinan pertama, buka mask, ambil codeword
+// secara zig-zag, pisahkan blok, cek ECC lalu urai segment byte mode
+func readQR(t *testing.T, qr *QRCode) string {
+	t.Helper()
+	size := qr.Size()
+	version := (size - 17) / 4
+
+	format := 0
+	for i := 0; i <= 5; i++ {
+		format |= boolBit(qr.Module(8, i)) << uint(i)
+	}
+	format |= boolBit(qr.Module(8, 7)) << 6
+	format |= boolBit(qr.Module(8, 8)) << 7
+	format |= boolBit(qr.Module(7, 8)) << 8
+	for i := 9; i < 15; i++ {
+		format |= boolBit(qr.Module(14-i, 8)) << uint(i)
+	}
+	format ^= 0x5412
+	assert.Equal(t, 0, format>>13, "tingkat koreksi harus M")
+	mask := (format >> 10) & 7
+
+	unmasked := newQRCode(version)
+	copyModules := &QRCode{size: size, modules: make([][]bool, size), isFunction: unmasked.isFunction}
+	for y := range copyModules.modules {
+		copyModules.modules[y] = append([]bool(nil), qr.modules[y]...)
+	}
+	copyModules.applyMask(mask)
+
+	raw := make([]byte, qrRawModules(version)/8)
+	i := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = size - 1 - vert
+				}
+				if unmasked.isFunction[y][x] || i >= len(raw)*8 {
+					continue
+				}
+				if copyModules.modules[y][x] {
+					raw[i>>3] |= 1 << (7 - uint(i&7))
+				}
+				i++
+			}
+		}
+	}
+
+	// Interleave dibalik: codeword data dibaca per kolom, blok panjang punya satu tambahan
+	numBlocks, eccLen := qrNumBlocks[version], qrECCPerBlock[version]
+	numShort := numBlocks - len(raw)%numBlocks
+	shortData := len(raw)/numBlocks - eccLen
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for col := 0; col <= shortData; col++ {
+		for b := range blocks {
+			if col == shortData && b < numShort {
+				continue
+			}
+			blocks[b] = append(blocks[b], raw[k])
+			k++
+		}
+	}
+	var data []byte
+	divisor := rsDivisor(eccLen)
+	for b := range blocks {
+		var ecc []byte
+		for col := 0; col < eccLen; col++ {
+			ecc = append(ecc, raw[k+col*numBlocks+b])
+		}
+		assert.Equal(t, rsRemainder(blocks[b], divisor), ecc, "ECC blok %d", b)
+		data = append(data, blocks[b]...)
+	}
+
+	assert.Equal(t, byte(0x4), data[0]>>4, "mode harus byte")
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	bitAt := func(n int) int { return int(data[n>>3]>>(7-uint(n&7))) & 1 }
+	read := func(offset, length int) int {
+		v := 0
+		for n := 0; n < length; n++ {
+			v = v<<1 | bitAt(offset+n)
+		}
+		return v
+	}
+	length := read(4, countBits)
+	out := make([]byte, length)
+	for n := range out {
+		out[n] = byte(read(4+countBits+n*8, 8))
+	}
+	return string(out)
+}
+
+func boolBit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,121 @@
+package report
+
+import (
+	"POJECT_UAS/model"
+	"bytes"
+	"crypto/sha256"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	seed := sha256.Sum256([]byte("report-test"))
+	signer, err := NewSigner(seed[:])
+	assert.NoError(t, err)
+
+	reportID, studentID := uuid.New(), uuid.New()
+	issuedAt := time.Date(2024, 5, 17, 9, 30, 0, 123456000, time.FixedZone("WIB", 7*3600))
+	hash := ContentHash([]byte(`{"student":{}}`))
+	payload := SigningPayload(reportID, studentID, hash, issuedAt)
+
+	// Execute
+	signature := signer.Sign(payload)
+
+	// Assert: zona waktu tidak mengubah payload, perubahan apa pun membatalkan tanda tangan
+	assert.True(t, signer.Verify(SigningPayload(reportID, studentID, hash, issuedAt.UTC()), signature))
+	assert.False(t, signer.Verify(SigningPayload(reportID, studentID, ContentHash([]byte("{}")), issuedAt), signature))
+	assert.False(t, signer.Verify(SigningPayload(uuid.New(), studentID, hash, issuedAt), signature))
+	assert.False(t, signer.Verify(payload, "bukan-base64"))
+
+	_, err = NewSigner([]byte("pendek"))
+	assert.ErrorIs(t, err, ErrInvalidSigningKey)
+}
+
+func TestWrapText(t *testing.T) {
+	lines := WrapText(FontRegular, 10, "Juara 1 Lomba Karya Tulis Ilmiah Nasional", 100)
+	assert.Greater(t, len(lines), 1)
+	for _, line := range lines {
+		assert.LessOrEqual(t, TextWidth(FontRegular, 10, line), 100.0)
+	}
+	assert.Equal(t, "Juara 1 Lomba Karya Tulis Ilmiah Nasional", strings.Join(lines, " "))
+
+	// Kata yang terlalu panjang dipotong
+	long := WrapText(FontRegular, 10, strings.Repeat("W", 40), 100)
+	assert.Greater(t, len(long), 1)
+	assert.Equal(t, strings.Repeat("W", 40), strings.Join(long, ""))
+
+	assert.Equal(t, []string{""}, WrapText(FontRegular, 10, "", 100))
+}
+
+func TestPDFString_EscapesAndEncodes(t *testing.T) {
+	assert.Equal(t, `(Nilai \(A\) \\ 100)`, pdfString(`Nilai (A) \ 100`))
+	assert.Equal(t, `(Caf\351 \226 \200)`, pdfString("Café – €"))
+	assert.Equal(t, `(?)`, pdfString("日"))
+}
+
+func TestRenderStudentReport(t *testing.T) {
+	verifiedAt := time.Date(2024, 5, 17, 9, 30, 0, 0, time.UTC)
+	advisor := "Dr. Budi"
+	data := model.StudentReport{
+		Student: model.StudentReportProfile{
+			ID:           uuid.New(),
+			StudentID:    "434221001",
+			FullName:     "Siti (Ani) Rahma",
+			ProgramStudy: "Teknik Informatika",
+			AcademicYear: "2022",
+			AdvisorName:  &advisor,
+		},
+		Summary: model.StudentReportSummary{VerifiedAchievements: 60},
+	}
+	for i := 0; i < 60; i++ {
+		data.Achievements = append(data.Achievements, model.StudentReportAchievement{
+			ID:              uuid.New(),
+			AchievementType: "competition",
+			Title:           "Juara " + strconv.Itoa(i+1) + " Lomba Pemrograman Tingkat Nasional dengan judul yang cukup panjang",
+			Level:           "nasional",
+			VerifiedAt:      &verifiedAt,
+		})
+	}
+
+	doc := StudentReportDocument{
+		ID:          uuid.New(),
+		Report:      data,
+		IssuedAt:    verifiedAt,
+		ContentHash: strings.Repeat("ab", 32),
+		Signature:   "c2lnbmF0dXJl",
+		VerifyURL:   "http://localhost:8080/api/v1/public/reports/x?h=abababababababab",
+	}
+
+	// Execute
+	var buf bytes.Buffer
+	assert.NoError(t, RenderStudentReport(&buf, doc))
+	out := buf.String()
+
+	// Assert: struktur PDF dan offset xref menunjuk ke objek yang benar
+	assert.True(t, strings.HasPrefix(out, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(out, "%%EOF\n"))
+	assert.Contains(t, out, "/DocumentID ("+doc.ID.String()+")")
+	assert.Contains(t, out, "/ContentHash ("+doc.ContentHash+")")
+	assert.Contains(t, out, `(: Siti \(Ani\) Rahma)`)
+
+	pages := regexp.MustCompile(`/Count (\d+)`).FindStringSubmatch(out)
+	assert.NotNil(t, pages)
+	count, _ := strconv.Atoi(pages[1])
+	assert.Greater(t, count, 1)
+	assert.Contains(t, out, "(Halaman "+pages[1]+" dari "+pages[1]+")")
+
+	xref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out)
+	assert.NotNil(t, xref)
+	start, _ := strconv.Atoi(xref[1])
+	assert.True(t, strings.HasPrefix(out[start:], "xref\n"))
+	for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(out, -1) {
+		offset, _ := strconv.Atoi(entry[1])
+		assert.True(t, strings.HasPrefix(out[offset:], strconv.Itoa(i+1)+" 0 obj\n"), "objek %d", i+1)
+	}
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SignatureAlgorithm algoritma tanda tangan laporan
+const SignatureAlgorithm = "Ed25519"
+
+// ErrInvalidSigningKey seed kunci bukan 32 byte
+var ErrInvalidSigningKey = errors.New("kunci tanda tangan laporan harus 32 byte (seed Ed25519)")
+
+// Signer menandatangani laporan dengan kunci Ed25519 milik server. Kunci publik dibagikan
+// lewat endpoint verifikasi agar pihak luar bisa memeriksa tanda tangan sendiri.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSigner membuat signer dari seed Ed25519 32 byte
+func NewSigner(seed []byte) (*Signer, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, ErrInvalidSigningKey
+	}
+	return &Signer{key: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// PublicKey kunci publik dalam base64 standar
+func (s *Signer) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// Sign tanda tangan payload dalam base64 standar
+func (s *Signer) Sign(payload []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, payload))
+}
+
+// Verify true jika signature adalah tanda tangan payload oleh kunci ini
+func (s *Signer) Verify(payload []byte, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(s.key.Public().(ed25519.PublicKey), payload, sig)
+}
+
+// ContentHash SHA-256 (hex) dari isi laporan yang diterbitkan
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// SigningPayload data yang ditandatangani untuk satu laporan. Mengikat ID dokumen,
+// mahasiswa, hash isi dan waktu terbit sehingga tidak ada yang bisa diganti tanpa
+// membatalkan tanda tangan.
+func SigningPayload(reportID, studentID uuid.UUID, contentHash string, issuedAt time.Time) []byte {
+	return []byte("prestasi-student-report/v1\n" +
+		reportID.String() + "\n" +
+		studentID.String() + "\n" +
+		contentHash + "\n" +
+		issuedAt.UTC().Format(time.RFC3339Nano))
+}
@@ -0,0 +1,271 @@
+package report
+
+import (
+	"POJECT_UAS/model"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StudentReportDocument data cetak laporan prestasi mahasiswa beserta tanda tangannya
+type StudentReportDocument struct {
+	ID          uuid.UUID
+	Report      model.StudentReport
+	IssuedAt    time.Time
+	ContentHash string
+	Signature   string
+	VerifyURL   string // dikodekan ke QR code
+}
+
+// Tata letak halaman laporan, dalam point
+const (
+	margin       = 50.0
+	footerHeight = 30.0
+	qrWidth      = 90.0 // termasuk quiet zone
+	qrQuietZone  = 4    // modul kosong di sekeliling QR code
+	rowPadding   = 4.0
+	lineHeight   = 11.0
+	tableFont    = 9.0
+)
+
+// Kolom tabel prestasi. Lebar total = lebar halaman dikurangi margin.
+var studentReportColumns = []struct {
+	title string
+	width float64
+}{
+	{"No", 25},
+	{"Judul Prestasi", 215},
+	{"Tipe", 75},
+	{"Tingkat", 75},
+	{"Diverifikasi", 105.28},
+}
+
+var achievementTypeLabels = map[string]string{
+	"competition":   "Kompetisi",
+	"organization":  "Organisasi",
+	"publication":   "Publikasi",
+	"certification": "Sertifikasi",
+	"research":      "Penelitian",
+	"other":         "Lainnya",
+}
+
+var indonesianMonths = [...]string{"", "Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// RenderStudentReport menulis laporan prestasi mahasiswa dalam format PDF A4. Halaman
+// pertama memuat QR code berisi VerifyURL, halaman terakhir memuat ID dokumen, hash isi
+// dan tanda tangan. Nilai yang sama juga disimpan di dictionary Info PDF.
+func RenderStudentReport(w io.Writer, doc StudentReportDocument) error {
+	qr, err := EncodeQR([]byte(doc.VerifyURL))
+	if err != nil {
+		return err
+	}
+
+	pdf := NewDocument()
+	student := doc.Report.Student
+	pdf.SetInfo("Title", "Laporan Prestasi Mahasiswa - "+student.FullName+" ("+student.StudentID+")")
+	pdf.SetInfo("Subject", "Laporan prestasi terverifikasi")
+	pdf.SetInfo("Producer", "Sistem Pelaporan Prestasi Mahasiswa")
+	pdf.SetInfo("CreationDate", PDFDate(doc.IssuedAt))
+	pdf.SetInfo("DocumentID", doc.ID.String())
+	pdf.SetInfo("ContentHash", doc.ContentHash)
+	pdf.SetInfo("Signature", doc.Signature)
+	pdf.SetInfo("SignatureAlgorithm", SignatureAlgorithm)
+	pdf.SetInfo("VerifyURL", doc.VerifyURL)
+
+	r := &studentReportRenderer{pdf: pdf}
+	r.newPage()
+
+	// Judul dan QR code verifikasi
+	page := r.page
+	top := PageHeight - margin
+	page.Text(margin, top-16, FontBold, 16, "LAPORAN PRESTASI MAHASISWA")
+	page.Text(margin, top-30, FontRegular, 9, "Sistem Pelaporan Prestasi Mahasiswa")
+
+	// Quiet zone di kanan dan atas QR code boleh masuk ke margin halaman
+	moduleSize := qrWidth / float64(qr.Size()+2*qrQuietZone)
+	qrSide := float64(qr.Size()) * moduleSize
+	page.QR(qr, PageWidth-margin-qrSide, top, moduleSize)
+	page.TextRight(PageWidth-margin, top-qrSide-9, FontRegular, 7, "Pindai untuk verifikasi")
+
+	// Identitas mahasiswa
+	r.y = top - qrWidth - 10
+	advisor := "-"
+	if student.AdvisorName != nil && *student.AdvisorName != "" {
+		advisor = *student.AdvisorName
+	}
+	for _, field := range [][2]string{
+		{"Nama", student.FullName},
+		{"NIM", student.StudentID},
+		{"Program Studi", student.ProgramStudy},
+		{"Angkatan", student.AcademicYear},
+		{"Dosen Wali", advisor},
+	} {
+		page.Text(margin, r.y, FontRegular, 10, field[0])
+		page.Text(margin+85, r.y, FontRegular, 10, ": "+field[1])
+		r.y -= 14
+	}
+
+	// Ringkasan
+	r.y -= 20
+	summary := doc.Report.Summary
+	page.Text(margin, r.y, FontBold, 11, "Prestasi Terverifikasi: "+strconv.FormatInt(summary.VerifiedAchievements, 10))
+	r.y -= 14
+	if len(summary.ByType) > 0 {
+		parts := make([]string, 0, len(summary.ByType))
+		for _, t := range summary.ByType {
+			parts = append(parts, typeLabel(t.AchievementType)+" "+strconv.FormatInt(t.Count, 10))
+		}
+		r.paragraph(FontRegular, 9, strings.Join(parts, ", "))
+	}
+
+	// Tabel prestasi
+	r.y -= 10
+	if len(doc.Report.Achievements) == 0 {
+		r.paragraph(FontRegular, 10, "Belum ada prestasi yang terverifikasi.")
+	} else {
+		r.tableHeader()
+		for i, achievement := range doc.Report.Achievements {
+			r.tableRow(i+1, achievement)
+		}
+	}
+
+	// Blok verifikasi
+	r.y -= 24
+	r.ensure(110)
+	r.page.Text(margin, r.y, FontBold, 11, "Verifikasi Dokumen")
+	r.y -= 16
+	for _, field := range [][2]string{
+		{"ID Dokumen", doc.ID.String()},
+		{"Diterbitkan", formatDateTime(doc.IssuedAt)},
+		{"Hash isi (SHA-256)", doc.ContentHash},
+		{"Tanda tangan (" + SignatureAlgorithm + ")", doc.Signature},
+	} {
+		r.page.Text(margin, r.y, FontRegular, 8, field[0])
+		r.page.Text(margin+100, r.y, FontRegular, 7, field[1])
+		r.y -= 12
+	}
+	r.y -= 4
+	r.paragraph(FontRegular, 8, "Keaslian dokumen ini dapat diperiksa dengan memindai QR code di halaman pertama "+
+		"atau membuka "+doc.VerifyURL+". Data prestasi yang ditampilkan di halaman verifikasi harus sama "+
+		"dengan isi dokumen ini.")
+
+	// Footer setiap halaman
+	pages := pdf.Pages()
+	for i, p := range pages {
+		p.Line(margin, margin+footerHeight-10, PageWidth-margin, margin+footerHeight-10, 0.5)
+		p.Text(margin, margin+footerHeight-22, FontRegular, 7, "Dokumen "+doc.ID.String())
+		p.TextRight(PageWidth-margin, margin+footerHeight-22, FontRegular, 7,
+			fmt.Sprintf("Halaman %d dari %d", i+1, len(pages)))
+	}
+
+	_, err = pdf.WriteTo(w)
+	return err
+}
+
+type studentReportRenderer struct {
+	pdf  *Document
+	page *Page
+	y    float64 // baseline baris berikutnya
+}
+
+func (r *studentReportRenderer) newPage() {
+	r.page = r.pdf.AddPage()
+	r.y = PageHeight - margin
+}
+
+// ensure pindah ke halaman baru jika sisa ruang kurang dari height
+func (r *studentReportRenderer) ensure(height float64) bool {
+	if r.y-height >= margin+footerHeight {
+		return false
+	}
+	r.newPage()
+	r.y -= 10
+	return true
+}
+
+func (r *studentReportRenderer) paragraph(font Font, size float64, text string) {
+	for _, line := range WrapText(font, size, text, PageWidth-2*margin) {
+		r.ensure(size + 3)
+		r.page.Text(margin, r.y, font, size, line)
+		r.y -= size + 3
+	}
+}
+
+func (r *studentReportRenderer) tableHeader() {
+	r.ensure(2 * (lineHeight + 2*rowPadding))
+	r.y -= rowPadding
+	x := margin
+	for _, col := range studentReportColumns {
+		r.page.Text(x+rowPadding, r.y-tableFont+1, FontBold, tableFont, col.title)
+		x += col.width
+	}
+	r.y -= lineHeight + rowPadding
+	r.page.Line(margin, r.y, PageWidth-margin, r.y, 0.8)
+}
+
+func (r *studentReportRenderer) tableRow(no int, achievement model.StudentReportAchievement) {
+	verifiedAt := "-"
+	if achievement.VerifiedAt != nil {
+		verifiedAt = formatDate(*achievement.VerifiedAt)
+	}
+	level := achievement.Level
+	if level == "" {
+		level = "-"
+	}
+	cells := [][]string{
+		{strconv.Itoa(no)},
+		WrapText(FontRegular, tableFont, achievement.Title, studentReportColumns[1].width-2*rowPadding),
+		WrapText(FontRegular, tableFont, typeLabel(achievement.AchievementType), studentReportColumns[2].width-2*rowPadding),
+		WrapText(FontRegular, tableFont, capitalize(level), studentReportColumns[3].width-2*rowPadding),
+		{verifiedAt},
+	}
+
+	lines := 1
+	for _, cell := range cells {
+		lines = max(lines, len(cell))
+	}
+	height := float64(lines)*lineHeight + 2*rowPadding
+	if r.ensure(height) {
+		r.tableHeader()
+	}
+
+	top := r.y - rowPadding
+	x := margin
+	for i, cell := range cells {
+		for j, line := range cell {
+			r.page.Text(x+rowPadding, top-float64(j)*lineHeight-tableFont+1, FontRegular, tableFont, line)
+		}
+		x += studentReportColumns[i].width
+	}
+	r.y -= height
+	r.page.Line(margin, r.y, PageWidth-margin, r.y, 0.3)
+}
+
+func typeLabel(achievementType string) string {
+	if label, ok := achievementTypeLabels[achievementType]; ok {
+		return label
+	}
+	return achievementType
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// formatDate tanggal dalam bahasa Indonesia, misalnya 17 Mei 2024
+func formatDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), indonesianMonths[t.Month()], t.Year())
+}
+
+func formatDateTime(t time.Time) string {
+	t = t.UTC()
+	return formatDate(t) + " " + t.Format("15:04") + " UTC"
+}
@@ -4,74 +4,113 @@ import (
 	"POJECT_UAS/model"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	ErrAchievementNotEditable     = errors.New("achievement can only be updated while draft or rejected")
+	ErrAchievementVersionConflict = errors.New("achievement has been modified by another request")
+	ErrAchievementSearchTooBroad  = errors.New("achievement search matches too many documents")
+)
+
+// DefaultSearchIDLimit batas jumlah ID MongoDB hasil filter achievement_type/search yang
+// diteruskan ke PostgreSQL dalam satu query
+const DefaultSearchIDLimit = 5000
+
 type AchievementRepository struct {
-	PostgresDB *sql.DB
-	MongoDB    *mongo.Database
+	PostgresDB    *sql.DB
+	MongoDB       *mongo.Database
+	Outbox        *OutboxRepository // nil berarti operasi outbox hanya dijalankan oleh worker
+	SearchIDLimit int               // lebih dari ini, filter MongoDB ditolak dengan ErrAchievementSearchTooBroad
 }
 
 func NewAchievementRepository(postgresDB *sql.DB, mongoDB *mongo.Database) *AchievementRepository {
 	return &AchievementRepository{
-		PostgresDB: postgresDB,
-		MongoDB:    mongoDB,
+		PostgresDB:    postgresDB,
+		MongoDB:       mongoDB,
+		Outbox:        NewOutboxRepository(postgresDB, mongoDB),
+		SearchIDLimit: DefaultSearchIDLimit,
 	}
 }
 
-// SubmitAchievement menyimpan prestasi ke MongoDB dan reference ke PostgreSQL
-func (r *AchievementRepository) SubmitAchievement(studentID uuid.UUID, req model.SubmitAchievementRequest) (*model.SubmitAchievementResponse, error) {
+// SubmitAchievement menyimpan reference ke PostgreSQL dan mencatat pembuatan dokumen MongoDB
+// di outbox dalam transaksi yang sama, lalu langsung mencoba menjalankannya.
+// createdBy adalah user yang membuat prestasi, dicatat sebagai history pertama.
+func (r *AchievementRepository) SubmitAchievement(studentID uuid.UUID, createdBy uuid.UUID, req model.SubmitAchievementRequest) (*model.SubmitAchievementResponse, error) {
 	ctx := context.Background()
 
-	// 1. Simpan ke MongoDB
+	// 1. Siapkan dokumen MongoDB dengan ID yang sudah ditentukan agar insert idempotent
+	mongoID := primitive.NewObjectID()
 	achievement := model.Achievement{
+		ID:              mongoID,
 		StudentID:       studentID,
 		AchievementType: req.AchievementType,
 		Title:           req.Title,
 		Description:     req.Description,
 		Details:         req.Details,
+		Version:         1,
 	}
 
-	collection := r.MongoDB.Collection("achievements")
-	result, err := collection.InsertOne(ctx, achievement)
+	payload, err := bson.MarshalExtJSON(achievement, true, false)
 	if err != nil {
 		return nil, err
 	}
 
-	mongoID := result.InsertedID.(primitive.ObjectID)
-
-	// 2. Simpan reference ke PostgreSQL
+	// 2. Simpan reference, history awal dan operasi outbox ke PostgreSQL
 	referenceID := uuid.New()
 	now := time.Now()
+	var outboxID uuid.UUID
 
-	query := `
-		INSERT INTO achievement_references 
-		(id, student_id, mongo_achievement_id, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
+	err = r.withTx(ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO achievement_references 
+			(id, student_id, mongo_achievement_id, status, version, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
 
-	_, err = r.PostgresDB.Exec(
-		query,
-		referenceID,
-		studentID,
-		mongoID.Hex(),
-		"draft", // Status awal: draft
-		now,
-		now,
-	)
+		_, err := tx.ExecContext(
+			ctx,
+			query,
+			referenceID,
+			studentID,
+			mongoID.Hex(),
+			"draft", // Status awal: draft
+			achievement.Version,
+			now,
+			now,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := insertStatusHistory(ctx, tx, referenceID, "", "draft", createdBy, nil, now); err != nil {
+			return err
+		}
+
+		outboxID, err = enqueueOutbox(ctx, tx, referenceID, mongoID.Hex(), model.OutboxCreateAchievement, payload, now)
+		return err
+	})
 	if err != nil {
-		// Rollback: hapus dari MongoDB jika gagal insert ke PostgreSQL
-		collection.DeleteOne(ctx, primitive.M{"_id": mongoID})
 		return nil, err
 	}
 
-	// 3. Return response
+	// 3. Simpan ke MongoDB. Jika gagal, worker outbox akan mengulanginya.
+	r.processOutbox(ctx, outboxID)
+
+	// 4. Return response
 	return &model.SubmitAchievementResponse{
 		AchievementID:          mongoID.Hex(),
 		AchievementReferenceID: referenceID,
@@ -84,6 +123,17 @@ func (r *AchievementRepository) SubmitAchievement(studentID uuid.UUID, req model
 	}, nil
 }
 
+// processOutbox menjalankan operasi outbox yang baru dicatat tanpa menunggu worker
+func (r *AchievementRepository) processOutbox(ctx context.Context, outboxID uuid.UUID) {
+	if r.Outbox == nil {
+		return
+	}
+
+	if err := r.Outbox.ProcessByID(ctx, outboxID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("outbox %s: %v (akan diulang oleh worker)", outboxID, err)
+	}
+}
+
 // GetStudentByUserID mengambil data student berdasarkan user_id
 func (r *AchievementRepository) GetStudentByUserID(userID uuid.UUID) (*model.Student, error) {
 	var student model.Student
@@ -192,52 +242,34 @@ func (r *AchievementRepository) GetAchievementReferenceByID(referenceID uuid.UUI
 }
 
 // SubmitForVerification update status achievement dari draft ke submitted
-func (r *AchievementRepository) SubmitForVerification(referenceID uuid.UUID) error {
+func (r *AchievementRepository) SubmitForVerification(referenceID uuid.UUID, submittedBy uuid.UUID) error {
+	ctx := context.Background()
 	now := time.Now()
 
-	query := `
-		UPDATE achievement_references
-		SET status = 'submitted', submitted_at = $1, updated_at = $2
-		WHERE id = $3 AND status = 'draft'
-	`
-
-	result, err := r.PostgresDB.Exec(query, now, now, referenceID)
-	if err != nil {
-		return err
-	}
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE achievement_references
+			SET status = 'submitted', submitted_at = $1, updated_at = $2
+			WHERE id = $3 AND status = 'draft'
+		`
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+		result, err := tx.ExecContext(ctx, query, now, now, referenceID)
+		if err != nil {
+			return err
+		}
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows // Tidak ada row yang diupdate (mungkin status bukan draft)
-	}
+		// Tidak ada row yang diupdate (mungkin status bukan draft)
+		if err := requireRowsAffected(result); err != nil {
+			return err
+		}
 
-	return nil
+		return insertStatusHistory(ctx, tx, referenceID, "draft", "submitted", submittedBy, nil, now)
+	})
 }
 
 // CreateNotification membuat notifikasi baru
 func (r *AchievementRepository) CreateNotification(notification model.Notification) error {
-	query := `
-		INSERT INTO notifications (id, user_id, type, title, message, data, is_read, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`
-
-	_, err := r.PostgresDB.Exec(
-		query,
-		notification.ID,
-		notification.UserID,
-		notification.Type,
-		notification.Title,
-		notification.Message,
-		notification.Data,
-		notification.IsRead,
-		notification.CreatedAt,
-	)
-
-	return err
+	return insertNotification(r.PostgresDB, notification)
 }
 
 // GetAdvisorByStudentID mengambil advisor_id dari student
@@ -254,6 +286,25 @@ func (r *AchievementRepository) GetAdvisorByStudentID(studentID uuid.UUID) (uuid
 	return advisorID, nil
 }
 
+// GetAdvisorUserIDByStudentID mengambil user_id dosen wali dari student, penerima notifikasi
+func (r *AchievementRepository) GetAdvisorUserIDByStudentID(studentID uuid.UUID) (uuid.UUID, error) {
+	var userID uuid.UUID
+
+	query := `
+		SELECT l.user_id
+		FROM students s
+		JOIN lecturers l ON l.id = s.advisor_id
+		WHERE s.id = $1
+	`
+
+	err := r.PostgresDB.QueryRow(query, studentID).Scan(&userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
+
 // GetUserByID mengambil user data berdasarkan ID
 func (r *AchievementRepository) GetUserByID(userID uuid.UUID) (*model.Users, error) {
 	var user model.Users
@@ -283,76 +334,213 @@ func (r *AchievementRepository) GetUserByID(userID uuid.UUID) (*model.Users, err
 	return &user, nil
 }
 
-// DeleteAchievement soft delete achievement (FR-005)
-func (r *AchievementRepository) DeleteAchievement(referenceID uuid.UUID, mongoAchievementID string) error {
+// UpdateAchievement update title, description dan details prestasi draft/rejected dengan
+// optimistic concurrency: update hanya berhasil jika versi prestasi masih expectedVersion.
+// Versi, reference dan history diubah dalam transaksi bersama operasi outbox untuk MongoDB.
+// Prestasi rejected yang diupdate kembali menjadi draft agar bisa disubmit ulang.
+func (r *AchievementRepository) UpdateAchievement(referenceID uuid.UUID, updatedBy uuid.UUID, expectedVersion int, req model.UpdateAchievementRequest) (*model.Achievement, error) {
 	ctx := context.Background()
 
-	// 1. Soft delete di MongoDB
-	objectID, err := primitive.ObjectIDFromHex(mongoAchievementID)
-	if err != nil {
+	now := time.Now()
+	var mongoAchievementID string
+	var payload model.UpdateAchievementPayload
+	var outboxID uuid.UUID
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		// 1. Kunci reference agar status dan versi tidak berubah (submit/delete/update lain) selama update
+		var status string
+		var version sql.NullInt64
+		err := tx.QueryRowContext(ctx, `
+			SELECT mongo_achievement_id, status, version
+			FROM achievement_references
+			WHERE id = $1
+			FOR UPDATE
+		`, referenceID).Scan(&mongoAchievementID, &status, &version)
+		if err != nil {
+			return err
+		}
+
+		if status != "draft" && status != "rejected" {
+			return ErrAchievementNotEditable
+		}
+
+		// 2. Reference lama belum menyimpan versi, versinya dibaca dari dokumen MongoDB
+		currentVersion := int(version.Int64)
+		if !version.Valid {
+			current, err := r.GetAchievementByID(mongoAchievementID)
+			if err != nil {
+				return err
+			}
+			currentVersion = current.Version
+		}
+
+		if currentVersion != expectedVersion {
+			return ErrAchievementVersionConflict
+		}
+
+		payload = model.UpdateAchievementPayload{
+			Title:           req.Title,
+			Description:     req.Description,
+			Details:         req.Details,
+			UpdatedAt:       now,
+			Version:         currentVersion + 1,
+			PreviousVersion: currentVersion,
+			PreviousStatus:  status,
+		}
+		data, err := bson.MarshalExtJSON(payload, true, false)
+		if err != nil {
+			return err
+		}
+
+		// 3. Update reference, history dan operasi outbox di PostgreSQL
+		_, err = tx.ExecContext(ctx, `
+			UPDATE achievement_references
+			SET status = 'draft', version = $1, updated_at = $2
+			WHERE id = $3
+		`, payload.Version, now, referenceID)
+		if err != nil {
+			return err
+		}
+
+		if status == "rejected" {
+			if err := insertStatusHistory(ctx, tx, referenceID, "rejected", "draft", updatedBy, nil, now); err != nil {
+				return err
+			}
+		}
+
+		outboxID, err = enqueueOutbox(ctx, tx, referenceID, mongoAchievementID, model.OutboxUpdateAchievement, data, now)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	now := time.Now()
-	collection := r.MongoDB.Collection("achievements")
+	// 4. Update MongoDB. Jika gagal, worker outbox akan mengulanginya.
+	r.processOutbox(ctx, outboxID)
 
-	update := primitive.M{
-		"$set": primitive.M{
-			"isDeleted": true,
-			"deletedAt": now,
-		},
+	return r.updatedAchievement(mongoAchievementID, payload), nil
+}
+
+// updatedAchievement dokumen prestasi setelah update. Jika operasi outbox masih antre,
+// perubahan diterapkan ke dokumen yang terakhir tersimpan di MongoDB.
+func (r *AchievementRepository) updatedAchievement(mongoAchievementID string, payload model.UpdateAchievementPayload) *model.Achievement {
+	achievement := &model.Achievement{}
+	if r.MongoDB != nil {
+		if current, err := r.GetAchievementByID(mongoAchievementID); err == nil {
+			achievement = current
+		}
+	}
+
+	achievement.ID, _ = primitive.ObjectIDFromHex(mongoAchievementID)
+	achievement.Title = payload.Title
+	achievement.Description = payload.Description
+	achievement.Details = payload.Details
+	achievement.Version = payload.Version
+	achievement.UpdatedAt = &payload.UpdatedAt
+
+	return achievement
+}
+
+// AddAttachments menambahkan metadata lampiran ke prestasi draft/rejected. Reference dikunci
+// dan operasi MongoDB dicatat di outbox dalam transaksi yang sama.
+func (r *AchievementRepository) AddAttachments(referenceID uuid.UUID, mongoAchievementID string, attachments []model.Attachment) error {
+	ctx := context.Background()
+
+	if _, err := primitive.ObjectIDFromHex(mongoAchievementID); err != nil {
+		return err
 	}
 
-	_, err = collection.UpdateOne(ctx, primitive.M{"_id": objectID}, update)
+	now := time.Now()
+	payload, err := bson.MarshalExtJSON(model.AddAttachmentsPayload{Attachments: attachments, UpdatedAt: now}, true, false)
 	if err != nil {
 		return err
 	}
 
-	// 2. Update reference di PostgreSQL (soft delete)
-	query := `
-		UPDATE achievement_references
-		SET status = 'deleted', updated_at = $1
-		WHERE id = $2 AND status = 'draft'
-	`
+	var outboxID uuid.UUID
+	err = r.withTx(ctx, func(tx *sql.Tx) error {
+		var status string
+		err := tx.QueryRowContext(ctx, `
+			SELECT status
+			FROM achievement_references
+			WHERE id = $1 AND mongo_achievement_id = $2
+			FOR UPDATE
+		`, referenceID, mongoAchievementID).Scan(&status)
+		if err != nil {
+			return err
+		}
+
+		if status != "draft" && status != "rejected" {
+			return ErrAchievementNotEditable
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE achievement_references
+			SET updated_at = $1
+			WHERE id = $2
+		`, now, referenceID)
+		if err != nil {
+			return err
+		}
 
-	result, err := r.PostgresDB.Exec(query, now, referenceID)
+		outboxID, err = enqueueOutbox(ctx, tx, referenceID, mongoAchievementID, model.OutboxAddAttachments, payload, now)
+		return err
+	})
 	if err != nil {
-		// Rollback MongoDB jika gagal update PostgreSQL
-		rollbackUpdate := primitive.M{
-			"$set": primitive.M{
-				"isDeleted": false,
-			},
-			"$unset": primitive.M{
-				"deletedAt": "",
-			},
-		}
-		collection.UpdateOne(ctx, primitive.M{"_id": objectID}, rollbackUpdate)
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	r.processOutbox(ctx, outboxID)
+	return nil
+}
+
+// DeleteAchievement soft delete achievement (FR-005).
+// Reference dan history diubah di dalam transaksi bersama operasi outbox untuk MongoDB,
+// sehingga keduanya tidak bisa tidak sinkron jika MongoDB sedang bermasalah.
+func (r *AchievementRepository) DeleteAchievement(referenceID uuid.UUID, mongoAchievementID string, deletedBy uuid.UUID) error {
+	ctx := context.Background()
+
+	if _, err := primitive.ObjectIDFromHex(mongoAchievementID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(model.DeleteAchievementPayload{DeletedAt: now, PreviousStatus: "draft"})
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		// Rollback MongoDB jika tidak ada row yang diupdate
-		rollbackUpdate := primitive.M{
-			"$set": primitive.M{
-				"isDeleted": false,
-			},
-			"$unset": primitive.M{
-				"deletedAt": "",
-			},
-		}
-		collection.UpdateOne(ctx, primitive.M{"_id": objectID}, rollbackUpdate)
-		return sql.ErrNoRows
+	var outboxID uuid.UUID
+	err = r.withTx(ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE achievement_references
+			SET status = 'deleted', updated_at = $1
+			WHERE id = $2 AND status = 'draft'
+		`
+
+		result, err := tx.ExecContext(ctx, query, now, referenceID)
+		if err != nil {
+			return err
+		}
+
+		if err := requireRowsAffected(result); err != nil {
+			return err
+		}
+
+		if err := insertStatusHistory(ctx, tx, referenceID, "draft", "deleted", deletedBy, nil, now); err != nil {
+			return err
+		}
+
+		outboxID, err = enqueueOutbox(ctx, tx, referenceID, mongoAchievementID, model.OutboxDeleteAchievement, payload, now)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
+	r.processOutbox(ctx, outboxID)
 	return nil
 }
 
-
 // GetLecturerByUserID mengambil data lecturer berdasarkan user_id
 func (r *AchievementRepository) GetLecturerByUserID(userID uuid.UUID) (*model.Lecturers, error) {
 	var lecturer model.Lecturers
@@ -402,91 +590,6 @@ func (r *AchievementRepository) GetStudentIDsByAdvisor(advisorID uuid.UUID) ([]u
 	return studentIDs, nil
 }
 
-// GetAchievementReferencesWithPagination mengambil achievement references dengan pagination
-func (r *AchievementRepository) GetAchievementReferencesWithPagination(
-	studentIDs []uuid.UUID,
-	status string,
-	page int,
-	perPage int,
-) ([]model.AchievementReference, int64, error) {
-	// Build query dengan filter
-	query := `
-		SELECT id, student_id, mongo_achievement_id, status, submitted_at, 
-		       verified_at, verified_by, rejection_note, created_at, updated_at
-		FROM achievement_references
-		WHERE student_id = ANY($1)
-	`
-
-	countQuery := `
-		SELECT COUNT(*) FROM achievement_references WHERE student_id = ANY($1)
-	`
-
-	// Add status filter if provided
-	if status != "" {
-		query += ` AND status = $2`
-		countQuery += ` AND status = $2`
-	}
-
-	// Add ordering
-	query += ` ORDER BY created_at DESC`
-
-	// Add pagination
-	offset := (page - 1) * perPage
-	query += ` LIMIT $3 OFFSET $4`
-
-	// Get total count
-	var totalCount int64
-	if status != "" {
-		err := r.PostgresDB.QueryRow(countQuery, pq.Array(studentIDs), status).Scan(&totalCount)
-		if err != nil {
-			return nil, 0, err
-		}
-	} else {
-		err := r.PostgresDB.QueryRow(countQuery, pq.Array(studentIDs)).Scan(&totalCount)
-		if err != nil {
-			return nil, 0, err
-		}
-	}
-
-	// Get paginated data
-	var rows *sql.Rows
-	var err error
-
-	if status != "" {
-		rows, err = r.PostgresDB.Query(query, pq.Array(studentIDs), status, perPage, offset)
-	} else {
-		rows, err = r.PostgresDB.Query(query, pq.Array(studentIDs), perPage, offset)
-	}
-
-	if err != nil {
-		return nil, 0, err
-	}
-	defer rows.Close()
-
-	var references []model.AchievementReference
-	for rows.Next() {
-		var ref model.AchievementReference
-		err := rows.Scan(
-			&ref.ID,
-			&ref.StudentID,
-			&ref.MongoAchievementID,
-			&ref.Status,
-			&ref.SubmittedAt,
-			&ref.VerifiedAt,
-			&ref.VerifiedBy,
-			&ref.RejectionNote,
-			&ref.CreatedAt,
-			&ref.UpdatedAt,
-		)
-		if err != nil {
-			return nil, 0, err
-		}
-		references = append(references, ref)
-	}
-
-	return references, totalCount, nil
-}
-
 // GetStudentByID mengambil student berdasarkan ID
 func (r *AchievementRepository) GetStudentByID(studentID uuid.UUID) (*model.Student, error) {
 	var student model.Student
@@ -514,6 +617,33 @@ func (r *AchievementRepository) GetStudentByID(studentID uuid.UUID) (*model.Stud
 	return &student, nil
 }
 
+// GetStudentReportProfile mengambil identitas mahasiswa untuk laporan beserta nama dosen wali
+func (r *AchievementRepository) GetStudentReportProfile(ctx context.Context, studentID uuid.UUID) (*model.StudentReportProfile, error) {
+	var profile model.StudentReportProfile
+
+	query := `
+		SELECT s.id, s.student_id, u.full_name, s.program_study, s.academic_year,
+		       (SELECT lu.full_name FROM lecturers l JOIN users lu ON lu.id = l.user_id WHERE l.id = s.advisor_id)
+		FROM students s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1
+	`
+
+	err := r.PostgresDB.QueryRowContext(ctx, query, studentID).Scan(
+		&profile.ID,
+		&profile.StudentID,
+		&profile.FullName,
+		&profile.ProgramStudy,
+		&profile.AcademicYear,
+		&profile.AdvisorName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
 // GetAchievementsByIDs mengambil multiple achievements dari MongoDB
 func (r *AchievementRepository) GetAchievementsByIDs(achievementIDs []string) (map[string]model.Achievement, error) {
 	ctx := context.Background()
@@ -557,50 +687,6 @@ func (r *AchievementRepository) GetAchievementsByIDs(achievementIDs []string) (m
 }
 
 
-// VerifyAchievement approve atau reject prestasi (FR-007)
-func (r *AchievementRepository) VerifyAchievement(referenceID uuid.UUID, verifierID uuid.UUID, action string, note *string) error {
-	now := time.Now()
-
-	var query string
-	var args []interface{}
-
-	if action == "approve" {
-		// Update status ke 'verified'
-		query = `
-			UPDATE achievement_references
-			SET status = 'verified', verified_by = $1, verified_at = $2, updated_at = $3
-			WHERE id = $4 AND status = 'submitted'
-		`
-		args = []interface{}{verifierID, now, now, referenceID}
-	} else if action == "reject" {
-		// Update status ke 'rejected'
-		query = `
-			UPDATE achievement_references
-			SET status = 'rejected', verified_by = $1, verified_at = $2, rejection_note = $3, updated_at = $4
-			WHERE id = $5 AND status = 'submitted'
-		`
-		args = []interface{}{verifierID, now, note, now, referenceID}
-	} else {
-		return sql.ErrNoRows
-	}
-
-	result, err := r.PostgresDB.Exec(query, args...)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return sql.ErrNoRows // Tidak ada row yang diupdate (mungkin status bukan submitted)
-	}
-
-	return nil
-}
-
 // CheckLecturerOwnsStudent mengecek apakah lecturer adalah advisor dari student
 func (r *AchievementRepository) CheckLecturerOwnsStudent(lecturerID uuid.UUID, studentID uuid.UUID) (bool, error) {
 	var count int
@@ -617,46 +703,138 @@ func (r *AchievementRepository) CheckLecturerOwnsStudent(lecturerID uuid.UUID, s
 
 // VerifyAchievement approve prestasi (FR-007)
 func (r *AchievementRepository) VerifyAchievement(referenceID uuid.UUID, verifiedBy uuid.UUID) error {
+	ctx := context.Background()
 	now := time.Now()
 
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE achievement_references
+			SET status = 'verified', verified_at = $1, verified_by = $2, updated_at = $3
+			WHERE id = $4 AND status = 'submitted'
+		`
+
+		result, err := tx.ExecContext(ctx, query, now, verifiedBy, now, referenceID)
+		if err != nil {
+			return err
+		}
+
+		if err := requireRowsAffected(result); err != nil {
+			return err
+		}
+
+		return insertStatusHistory(ctx, tx, referenceID, "submitted", "verified", verifiedBy, nil, now)
+	})
+}
+
+// RejectAchievement reject prestasi dengan rejection note (FR-007)
+func (r *AchievementRepository) RejectAchievement(referenceID uuid.UUID, verifiedBy uuid.UUID, rejectionNote string) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE achievement_references
+			SET status = 'rejected', verified_at = $1, verified_by = $2, rejection_note = $3, updated_at = $4
+			WHERE id = $5 AND status = 'submitted'
+		`
+
+		result, err := tx.ExecContext(ctx, query, now, verifiedBy, rejectionNote, now, referenceID)
+		if err != nil {
+			return err
+		}
+
+		if err := requireRowsAffected(result); err != nil {
+			return err
+		}
+
+		return insertStatusHistory(ctx, tx, referenceID, "submitted", "rejected", verifiedBy, &rejectionNote, now)
+	})
+}
+
+// GetAchievementStatusHistory mengambil riwayat perubahan status prestasi, urut dari yang paling lama
+func (r *AchievementRepository) GetAchievementStatusHistory(referenceID uuid.UUID) ([]model.AchievementStatusHistory, error) {
 	query := `
-		UPDATE achievement_references
-		SET status = 'verified', verified_at = $1, verified_by = $2, updated_at = $3
-		WHERE id = $4 AND status = 'submitted'
+		SELECT h.id, h.achievement_reference_id, h.from_status, h.to_status,
+		       h.changed_by, u.full_name, h.note, h.created_at
+		FROM achievement_status_history h
+		LEFT JOIN users u ON u.id = h.changed_by
+		WHERE h.achievement_reference_id = $1
+		ORDER BY h.created_at ASC, h.id ASC
 	`
 
-	result, err := r.PostgresDB.Exec(query, now, verifiedBy, now, referenceID)
+	rows, err := r.PostgresDB.Query(query, referenceID)
 	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	history := []model.AchievementStatusHistory{}
+	for rows.Next() {
+		var h model.AchievementStatusHistory
+		err := rows.Scan(
+			&h.ID,
+			&h.AchievementReferenceID,
+			&h.FromStatus,
+			&h.ToStatus,
+			&h.ChangedBy,
+			&h.ChangedByName,
+			&h.Note,
+			&h.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, h)
 	}
 
-	return nil
+	return history, rows.Err()
 }
 
-// RejectAchievement reject prestasi dengan rejection note (FR-007)
-func (r *AchievementRepository) RejectAchievement(referenceID uuid.UUID, verifiedBy uuid.UUID, rejectionNote string) error {
-	now := time.Now()
+// insertStatusHistory mencatat perubahan status prestasi di transaksi yang sama dengan perubahannya,
+// sekaligus mengantrekan event webhook-nya sehingga event hanya terkirim jika perubahan di-commit.
+// fromStatus kosong berarti prestasi baru dibuat, changedBy uuid.Nil berarti perubahan oleh sistem.
+func insertStatusHistory(ctx context.Context, tx *sql.Tx, referenceID uuid.UUID, fromStatus, toStatus string, changedBy uuid.UUID, note *string, at time.Time) error {
+	var from *string
+	if fromStatus != "" {
+		from = &fromStatus
+	}
+
+	var by *uuid.UUID
+	if changedBy != uuid.Nil {
+		by = &changedBy
+	}
 
 	query := `
-		UPDATE achievement_references
-		SET status = 'rejected', verified_at = $1, verified_by = $2, rejection_note = $3, updated_at = $4
-		WHERE id = $5 AND status = 'submitted'
+		INSERT INTO achievement_status_history
+		(id, achievement_reference_id, from_status, to_status, changed_by, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
-	result, err := r.PostgresDB.Exec(query, now, verifiedBy, rejectionNote, now, referenceID)
+	_, err := tx.ExecContext(ctx, query, uuid.New(), referenceID, from, toStatus, by, note, at)
+	if err != nil {
+		return err
+	}
+
+	return enqueueWebhookEvent(ctx, tx, referenceID, fromStatus, toStatus, changedBy, note, at)
+}
+
+// withTx menjalankan fn dalam transaksi PostgreSQL, commit jika fn tidak mengembalikan error
+func (r *AchievementRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.PostgresDB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
 
+// requireRowsAffected mengembalikan sql.ErrNoRows jika tidak ada row yang diupdate
+func requireRowsAffected(result sql.Result) error {
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return err
@@ -669,66 +847,91 @@ func (r *AchievementRepository) RejectAchievement(referenceID uuid.UUID, verifie
 	return nil
 }
 
-// GetAllAchievementReferencesWithPagination mengambil semua achievement references untuk admin (FR-010)
-func (r *AchievementRepository) GetAllAchievementReferencesWithPagination(
-	status string,
-	achievementType string,
-	page int,
-	perPage int,
-) ([]model.AchievementReference, int64, error) {
-	// Build base query
-	baseWhere := "WHERE 1=1"
-	var args []interface{}
-	argIndex := 1
+// achievementSortColumns kolom untuk sort daftar prestasi
+var achievementSortColumns = map[string]string{
+	"created_at":   "ar.created_at",
+	"updated_at":   "ar.updated_at",
+	"submitted_at": "ar.submitted_at",
+	"verified_at":  "ar.verified_at",
+	"status":       "ar.status",
+}
 
-	// Add status filter if provided
-	if status != "" {
-		baseWhere += " AND ar.status = $" + strconv.Itoa(argIndex)
-		args = append(args, status)
-		argIndex++
+// ListAchievementReferences mengambil reference prestasi beserta data mahasiswa dengan filter,
+// sort dan pagination (offset atau keyset created_at + id). Prestasi yang sudah dihapus tidak
+// ikut kecuali difilter status=deleted.
+func (r *AchievementRepository) ListAchievementReferences(filter model.AchievementListFilter) ([]model.AchievementReferenceWithStudent, model.PageInfo, error) {
+	ctx := context.Background()
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
 	}
 
-	// Count query
-	countQuery := `
-		SELECT COUNT(*)
-		FROM achievement_references ar
-		LEFT JOIN students s ON ar.student_id = s.id
-		LEFT JOIN users u ON s.user_id = u.id
-	` + baseWhere
-
-	// Select query
-	selectQuery := `
-		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, 
-		       ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note, 
-		       ar.created_at, ar.updated_at
-		FROM achievement_references ar
-		LEFT JOIN students s ON ar.student_id = s.id
-		LEFT JOIN users u ON s.user_id = u.id
-	` + baseWhere + `
-		ORDER BY ar.created_at DESC
-		LIMIT $` + strconv.Itoa(argIndex) + ` OFFSET $` + strconv.Itoa(argIndex+1)
-
-	// Add pagination args
-	offset := (page - 1) * perPage
-	paginationArgs := append(args, perPage, offset)
-
-	// Get total count
-	var totalCount int64
-	err := r.PostgresDB.QueryRow(countQuery, args...).Scan(&totalCount)
+	from, found, err := r.achievementListFrom(ctx, filter, arg)
 	if err != nil {
-		return nil, 0, err
+		return nil, model.PageInfo{}, err
+	}
+	if !found {
+		var info model.PageInfo
+		if !filter.SkipCount {
+			info.TotalItems = new(int64)
+		}
+		return []model.AchievementReferenceWithStudent{}, info, nil
+	}
+
+	var info model.PageInfo
+	if !filter.SkipCount {
+		var totalCount int64
+		err := r.PostgresDB.QueryRowContext(ctx, "SELECT COUNT(*)"+from, args...).Scan(&totalCount)
+		if err != nil {
+			return nil, info, err
+		}
+		info.TotalItems = &totalCount
+	}
+
+	desc := !strings.EqualFold(filter.SortOrder, "asc")
+
+	var orderBy, limit string
+	reverse := false
+	if filter.UseCursor {
+		// Keyset pagination hanya untuk urutan created_at
+		var where string
+		where, orderBy, reverse = filter.KeysetCondition("ar.created_at", "ar.id", desc, arg)
+		if where != "" {
+			from += " AND " + where
+		}
+		limit = arg(filter.PerPage + 1)
+	} else {
+		column, ok := achievementSortColumns[filter.SortBy]
+		if !ok {
+			column = "ar.created_at"
+		}
+		direction := "DESC"
+		if !desc {
+			direction = "ASC"
+		}
+		orderBy = column + " " + direction + " NULLS LAST, ar.id " + direction
+		limit = arg(filter.PerPage) + " OFFSET " + arg(filter.Offset())
 	}
 
-	// Get paginated data
-	rows, err := r.PostgresDB.Query(selectQuery, paginationArgs...)
+	query := `
+		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+		       ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+		       ar.created_at, ar.updated_at,
+		       u.full_name, s.student_id, s.program_study` + from + `
+		ORDER BY ` + orderBy + `
+		LIMIT ` + limit
+
+	rows, err := r.PostgresDB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, info, err
 	}
 	defer rows.Close()
 
-	var references []model.AchievementReference
+	references := []model.AchievementReferenceWithStudent{}
 	for rows.Next() {
-		var ref model.AchievementReference
+		var ref model.AchievementReferenceWithStudent
 		err := rows.Scan(
 			&ref.ID,
 			&ref.StudentID,
@@ -740,249 +943,259 @@ func (r *AchievementRepository) GetAllAchievementReferencesWithPagination(
 			&ref.RejectionNote,
 			&ref.CreatedAt,
 			&ref.UpdatedAt,
+			&ref.StudentName,
+			&ref.StudentIDNumber,
+			&ref.ProgramStudy,
 		)
 		if err != nil {
-			return nil, 0, err
+			return nil, info, err
 		}
 		references = append(references, ref)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, info, err
+	}
+
+	if !filter.UseCursor {
+		return references, info, nil
+	}
 
-	return references, totalCount, nil
+	references, keyset := keysetPage(filter.PageRequest, references, reverse, func(ref model.AchievementReferenceWithStudent) model.Cursor {
+		return model.Cursor{CreatedAt: ref.CreatedAt, ID: ref.ID}
+	})
+	keyset.TotalItems = info.TotalItems
+	return references, keyset, nil
 }
-// GetAchievementStatistics mengambil statistik prestasi (FR-011)
-func (r *AchievementRepository) GetAchievementStatistics(
-	studentIDs []uuid.UUID, // kosong jika admin (all students)
-	startDate *time.Time,
-	endDate *time.Time,
-	achievementType *string,
-	status *string,
-) (*model.AchievementStatistics, error) {
-	ctx := context.Background()
 
-	// Build base filter untuk PostgreSQL
-	baseWhere := "WHERE 1=1"
+// CountAchievementReferences jumlah prestasi yang cocok dengan filter (tanpa pagination)
+func (r *AchievementRepository) CountAchievementReferences(ctx context.Context, filter model.AchievementListFilter) (int64, error) {
 	var args []interface{}
-	argIndex := 1
-
-	// Filter by student IDs (untuk dosen wali atau mahasiswa)
-	if len(studentIDs) > 0 {
-		baseWhere += " AND ar.student_id = ANY($" + strconv.Itoa(argIndex) + ")"
-		args = append(args, pq.Array(studentIDs))
-		argIndex++
-	}
-
-	// Filter by date range
-	if startDate != nil {
-		baseWhere += " AND ar.created_at >= $" + strconv.Itoa(argIndex)
-		args = append(args, *startDate)
-		argIndex++
-	}
-	if endDate != nil {
-		baseWhere += " AND ar.created_at <= $" + strconv.Itoa(argIndex)
-		args = append(args, *endDate)
-		argIndex++
-	}
-
-	// Filter by status
-	if status != nil {
-		baseWhere += " AND ar.status = $" + strconv.Itoa(argIndex)
-		args = append(args, *status)
-		argIndex++
-	}
-
-	// 1. Get summary statistics
-	summaryQuery := `
-		SELECT 
-			COUNT(*) as total,
-			COUNT(CASE WHEN ar.status = 'verified' THEN 1 END) as verified,
-			COUNT(CASE WHEN ar.status = 'submitted' THEN 1 END) as pending,
-			COUNT(CASE WHEN ar.status = 'rejected' THEN 1 END) as rejected,
-			COUNT(DISTINCT ar.student_id) as total_students,
-			MIN(ar.created_at) as min_date,
-			MAX(ar.created_at) as max_date
-		FROM achievement_references ar
-	` + baseWhere
-
-	var summary model.StatisticSummary
-	var minDate, maxDate sql.NullTime
-	err := r.PostgresDB.QueryRow(summaryQuery, args...).Scan(
-		&summary.TotalAchievements,
-		&summary.VerifiedAchievements,
-		&summary.PendingAchievements,
-		&summary.RejectedAchievements,
-		&summary.TotalStudents,
-		&minDate,
-		&maxDate,
-	)
-	if err != nil {
-		return nil, err
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
 	}
 
-	if minDate.Valid && maxDate.Valid {
-		summary.DateRange = model.DateRange{
-			StartDate: minDate.Time,
-			EndDate:   maxDate.Time,
-		}
+	from, found, err := r.achievementListFrom(ctx, filter, arg)
+	if err != nil || !found {
+		return 0, err
 	}
 
-	// 2. Get achievement references untuk analisis lebih lanjut
-	referencesQuery := `
-		SELECT ar.mongo_achievement_id, ar.student_id, ar.status, ar.created_at,
-		       s.student_id as student_number, u.full_name, s.program_study, s.academic_year
-		FROM achievement_references ar
-		LEFT JOIN students s ON ar.student_id = s.id
-		LEFT JOIN users u ON s.user_id = u.id
-	` + baseWhere + ` ORDER BY ar.created_at DESC`
+	var total int64
+	err = r.PostgresDB.QueryRowContext(ctx, "SELECT COUNT(*)"+from, args...).Scan(&total)
+	return total, err
+}
+
+// StreamAchievementExport membaca semua prestasi yang cocok dengan filter (tanpa pagination)
+// sesuai urutan sort filter, lalu memanggil fn per batchSize baris beserta dokumen MongoDB-nya.
+// Baris tidak ditampung seluruhnya di memori. Prestasi yang dokumennya tidak ada di MongoDB
+// dilewati seperti pada daftar prestasi. Slice yang diterima fn dipakai ulang untuk batch
+// berikutnya. Mengembalikan jumlah baris yang dikirim ke fn.
+func (r *AchievementRepository) StreamAchievementExport(ctx context.Context, filter model.AchievementListFilter, batchSize int, fn func([]model.AchievementExportRow) error) (int64, error) {
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	from, found, err := r.achievementListFrom(ctx, filter, arg)
+	if err != nil || !found {
+		return 0, err
+	}
+
+	column, ok := achievementSortColumns[filter.SortBy]
+	if !ok {
+		column = "ar.created_at"
+	}
+	direction := "DESC"
+	if strings.EqualFold(filter.SortOrder, "asc") {
+		direction = "ASC"
+	}
 
-	rows, err := r.PostgresDB.Query(referencesQuery, args...)
+	query := `
+		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+		       ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+		       ar.created_at, ar.updated_at,
+		       u.full_name, s.student_id, s.program_study, s.academic_year,
+		       (SELECT v.full_name FROM users v WHERE v.id = ar.verified_by)` + from + `
+		ORDER BY ` + column + " " + direction + " NULLS LAST, ar.id " + direction
+
+	rows, err := r.PostgresDB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 	defer rows.Close()
 
-	var mongoIDs []string
-	var studentMap = make(map[uuid.UUID]*model.TopStudent)
-	var periodMap = make(map[string]int64)
-
-	for rows.Next() {
-		var mongoID string
-		var studentID uuid.UUID
-		var status string
-		var createdAt time.Time
-		var studentNumber, fullName, programStudy, academicYear sql.NullString
+	var written int64
+	batch := make([]model.AchievementExportRow, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
 
-		err := rows.Scan(&mongoID, &studentID, &status, &createdAt,
-			&studentNumber, &fullName, &programStudy, &academicYear)
+		mongoIDs := make([]string, len(batch))
+		for i, row := range batch {
+			mongoIDs[i] = row.MongoAchievementID
+		}
+		achievements, err := r.GetAchievementsByIDs(mongoIDs)
 		if err != nil {
-			continue
+			return err
 		}
 
-		mongoIDs = append(mongoIDs, mongoID)
-
-		// Build student statistics
-		if studentNumber.Valid && fullName.Valid {
-			if student, exists := studentMap[studentID]; exists {
-				student.TotalCount++
-				if status == "verified" {
-					student.VerifiedCount++
-				}
-			} else {
-				studentMap[studentID] = &model.TopStudent{
-					StudentID:       studentID,
-					StudentIDNumber: studentNumber.String,
-					FullName:        fullName.String,
-					ProgramStudy:    programStudy.String,
-					AcademicYear:    academicYear.String,
-					TotalCount:      1,
-					VerifiedCount:   0,
-				}
-				if status == "verified" {
-					studentMap[studentID].VerifiedCount = 1
-				}
+		complete := batch[:0]
+		for _, row := range batch {
+			achievement, exists := achievements[row.MongoAchievementID]
+			if !exists {
+				continue
 			}
+			row.Achievement = achievement
+			complete = append(complete, row)
 		}
+		batch = batch[:0]
 
-		// Build period statistics (monthly)
-		period := createdAt.Format("2006-01")
-		periodMap[period]++
+		if len(complete) == 0 {
+			return nil
+		}
+		written += int64(len(complete))
+		return fn(complete)
 	}
 
-	// 3. Get achievements from MongoDB untuk type dan level statistics
-	var typeMap = make(map[string]int64)
-	var levelMap = make(map[string]int64)
-
-	if len(mongoIDs) > 0 {
-		// Convert to ObjectIDs
-		var objectIDs []primitive.ObjectID
-		for _, id := range mongoIDs {
-			if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
-				objectIDs = append(objectIDs, objectID)
-			}
+	for rows.Next() {
+		var row model.AchievementExportRow
+		err := rows.Scan(
+			&row.ID,
+			&row.StudentID,
+			&row.MongoAchievementID,
+			&row.Status,
+			&row.SubmittedAt,
+			&row.VerifiedAt,
+			&row.VerifiedBy,
+			&row.RejectionNote,
+			&row.CreatedAt,
+			&row.UpdatedAt,
+			&row.StudentName,
+			&row.StudentIDNumber,
+			&row.ProgramStudy,
+			&row.AcademicYear,
+			&row.VerifierName,
+		)
+		if err != nil {
+			return written, err
 		}
 
-		if len(objectIDs) > 0 {
-			collection := r.MongoDB.Collection("achievements")
-			cursor, err := collection.Find(ctx, primitive.M{
-				"_id": primitive.M{"$in": objectIDs},
-			})
-			if err == nil {
-				defer cursor.Close(ctx)
-
-				for cursor.Next(ctx) {
-					var achievement model.Achievement
-					if err := cursor.Decode(&achievement); err == nil {
-						// Filter by achievement type if specified
-						if achievementType == nil || *achievementType == "" || achievement.AchievementType == *achievementType {
-							typeMap[achievement.AchievementType]++
-
-							// Extract level from details (assuming it's stored in details)
-							if level, ok := achievement.Details["level"].(string); ok {
-								levelMap[level]++
-							} else {
-								levelMap["unknown"]++
-							}
-						}
-					}
-				}
+		batch = append(batch, row)
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return written, err
 			}
 		}
 	}
-
-	// 4. Build response
-	statistics := &model.AchievementStatistics{
-		Summary: summary,
+	if err := rows.Err(); err != nil {
+		return written, err
 	}
 
-	// Convert type map to slice
-	var totalTypes int64
-	for _, count := range typeMap {
-		totalTypes += count
+	return written, flush()
+}
+
+// achievementListFrom klausa FROM ... WHERE daftar prestasi untuk filter (tanpa sort dan
+// pagination). found false jika filter MongoDB tidak cocok dengan dokumen apa pun.
+func (r *AchievementRepository) achievementListFrom(ctx context.Context, filter model.AchievementListFilter, arg func(interface{}) string) (from string, found bool, err error) {
+	var conditions []string
+
+	if filter.StudentIDs != nil {
+		conditions = append(conditions, "ar.student_id = ANY("+arg(pq.Array(filter.StudentIDs))+")")
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "ar.status = "+arg(filter.Status))
+	} else {
+		conditions = append(conditions, "ar.status <> 'deleted'")
+	}
+	if filter.ProgramStudy != "" {
+		conditions = append(conditions, "s.program_study = "+arg(filter.ProgramStudy))
+	}
+	if filter.AcademicYear != "" {
+		conditions = append(conditions, "s.academic_year = "+arg(filter.AcademicYear))
 	}
-	for achType, count := range typeMap {
-		percentage := float64(0)
-		if totalTypes > 0 {
-			percentage = float64(count) / float64(totalTypes) * 100
+	if filter.StartDate != nil {
+		conditions = append(conditions, "ar.created_at >= "+arg(*filter.StartDate))
+	}
+	if filter.EndDate != nil {
+		conditions = append(conditions, "ar.created_at < "+arg(filter.EndDate.AddDate(0, 0, 1)))
+	}
+
+	// achievement_type, title dan description hanya ada di MongoDB
+	if filter.AchievementType != "" || filter.Search != "" {
+		mongoIDs, err := r.findAchievementIDs(ctx, filter)
+		if err != nil {
+			return "", false, err
+		}
+		if len(mongoIDs) == 0 {
+			return "", false, nil
 		}
-		statistics.TotalByType = append(statistics.TotalByType, model.TypeStatistic{
-			AchievementType: achType,
-			Count:           count,
-			Percentage:      percentage,
-		})
+		conditions = append(conditions, "ar.mongo_achievement_id = ANY("+arg(pq.Array(mongoIDs))+")")
 	}
 
-	// Convert period map to slice
-	for period, count := range periodMap {
-		year, _ := strconv.Atoi(period[:4])
-		month, _ := strconv.Atoi(period[5:7])
-		statistics.TotalByPeriod = append(statistics.TotalByPeriod, model.PeriodStatistic{
-			Period: period,
-			Count:  count,
-			Year:   year,
-			Month:  &month,
-		})
+	from = `
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		JOIN users u ON u.id = s.user_id
+		WHERE ` + strings.Join(conditions, " AND ")
+	return from, true, nil
+}
+
+// achievementSearchQuery menyusun filter MongoDB untuk findAchievementIDs. Dokumen yang sudah
+// di-soft-delete hanya ikut dicari saat daftar memang meminta status deleted.
+func achievementSearchQuery(filter model.AchievementListFilter) primitive.M {
+	query := primitive.M{}
+	if filter.Status != "deleted" {
+		query["isDeleted"] = primitive.M{"$ne": true}
+	}
+	if filter.StudentIDs != nil {
+		query["studentId"] = primitive.M{"$in": filter.StudentIDs}
+	}
+	if filter.AchievementType != "" {
+		query["achievementType"] = filter.AchievementType
+	}
+	if filter.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(filter.Search), Options: "i"}
+		query["$or"] = primitive.A{
+			primitive.M{"title": pattern},
+			primitive.M{"description": pattern},
+		}
 	}
+	return query
+}
+
+// findAchievementIDs ID dokumen MongoDB yang cocok dengan filter achievement_type dan search.
+// Hasilnya dibatasi SearchIDLimit agar array ANY() di PostgreSQL tidak tumbuh tanpa batas;
+// filter yang cocok dengan lebih banyak dokumen ditolak dengan ErrAchievementSearchTooBroad.
+func (r *AchievementRepository) findAchievementIDs(ctx context.Context, filter model.AchievementListFilter) ([]string, error) {
+	query := achievementSearchQuery(filter)
 
-	// Convert student map to top students slice (sorted by total count)
-	for _, student := range studentMap {
-		statistics.TopStudents = append(statistics.TopStudents, *student)
+	limit := r.SearchIDLimit
+	if limit <= 0 {
+		limit = DefaultSearchIDLimit
 	}
 
-	// Convert level map to slice
-	var totalLevels int64
-	for _, count := range levelMap {
-		totalLevels += count
+	cursor, err := r.MongoDB.Collection("achievements").Find(ctx, query,
+		options.Find().SetProjection(primitive.M{"_id": 1}).SetLimit(int64(limit)+1))
+	if err != nil {
+		return nil, err
 	}
-	for level, count := range levelMap {
-		percentage := float64(0)
-		if totalLevels > 0 {
-			percentage = float64(count) / float64(totalLevels) * 100
+	defer cursor.Close(ctx)
+
+	var ids []string
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID.Hex())
+		if len(ids) > limit {
+			return nil, ErrAchievementSearchTooBroad
 		}
-		statistics.CompetitionLevels = append(statistics.CompetitionLevels, model.LevelStatistic{
-			Level:      level,
-			Count:      count,
-			Percentage: percentage,
-		})
 	}
 
-	return statistics, nil
-}
\ No newline at end of file
+	return ids, cursor.Err()
+}
@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"POJECT_UAS/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAchievementRepository_UpdateAchievement_NotEditable(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	referenceID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT mongo_achievement_id, status, version FROM achievement_references WHERE id = \$1 FOR UPDATE`).
+		WithArgs(referenceID).
+		WillReturnRows(sqlmock.NewRows([]string{"mongo_achievement_id", "status", "version"}).
+			AddRow(primitive.NewObjectID().Hex(), "submitted", 1))
+	mock.ExpectRollback()
+
+	// Execute
+	achievement, err := achievementRepo.UpdateAchievement(referenceID, uuid.New(), 1, model.UpdateAchievementRequest{Title: "Judul"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrAchievementNotEditable)
+	assert.Nil(t, achievement)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_UpdateAchievement_NotFound(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	referenceID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT mongo_achievement_id, status, version FROM achievement_references`).
+		WithArgs(referenceID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	// Execute
+	_, err = achievementRepo.UpdateAchievement(referenceID, uuid.New(), 1, model.UpdateAchievementRequest{Title: "Judul"})
+
+	// Assert
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_VerifyAchievement_WritesHistory(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	referenceID := uuid.New()
+	verifierID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE achievement_references SET status = 'verified'`).
+		WithArgs(sqlmock.AnyArg(), verifierID, sqlmock.AnyArg(), referenceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO achievement_status_history`).
+		WithArgs(sqlmock.AnyArg(), referenceID, "submitted", "verified", verifierID, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO webhook_deliveries`).
+		WithArgs(sqlmock.AnyArg(), model.WebhookAchievementVerified, sqlmock.AnyArg(), "verified", "submitted", verifierID, nil, referenceID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	// Execute
+	err = achievementRepo.VerifyAchievement(referenceID, verifierID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_RejectAchievement_NotSubmitted(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	referenceID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE achievement_references SET status = 'rejected'`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// Execute
+	err = achievementRepo.RejectAchievement(referenceID, uuid.New(), "bukti kurang")
+
+	// Assert: tidak ada history yang ditulis jika status tidak berubah
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_GetAchievementStatusHistory(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	referenceID := uuid.New()
+	lecturerID := uuid.New()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "achievement_reference_id", "from_status", "to_status", "changed_by", "full_name", "note", "created_at"}).
+		AddRow(uuid.New(), referenceID, nil, "draft", uuid.New(), "Demo Student", nil, now.Add(-2*time.Hour)).
+		AddRow(uuid.New(), referenceID, "draft", "submitted", uuid.New(), "Demo Student", nil, now.Add(-time.Hour)).
+		AddRow(uuid.New(), referenceID, "submitted", "rejected", lecturerID, "Demo Lecturer", "bukti kurang", now)
+
+	mock.ExpectQuery(`SELECT (.+) FROM achievement_status_history h LEFT JOIN users u (.+) ORDER BY h.created_at ASC`).
+		WithArgs(referenceID).
+		WillReturnRows(rows)
+
+	// Execute
+	history, err := achievementRepo.GetAchievementStatusHistory(referenceID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, history, 3)
+	assert.Nil(t, history[0].FromStatus)
+	assert.Equal(t, "rejected", history[2].ToStatus)
+	assert.Equal(t, lecturerID, *history[2].ChangedBy)
+	assert.Equal(t, "bukti kurang", *history[2].Note)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_ListAchievementReferences_Filters(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	studentID := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	filter := model.AchievementListFilter{
+		StudentIDs:   []uuid.UUID{studentID},
+		Status:       "verified",
+		ProgramStudy: "Teknik Informatika",
+		AcademicYear: "2022",
+		StartDate:    &start,
+		EndDate:      &end,
+		SortBy:       "verified_at",
+		SortOrder:    "asc",
+		PageRequest:  model.PageRequest{Page: 2, PerPage: 5},
+	}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM achievement_references ar JOIN students s ON s.id = ar.student_id JOIN users u ON u.id = s.user_id WHERE ar.student_id = ANY\(\$1\) AND ar.status = \$2 AND s.program_study = \$3 AND s.academic_year = \$4 AND ar.created_at >= \$5 AND ar.created_at < \$6`).
+		WithArgs(sqlmock.AnyArg(), "verified", "Teknik Informatika", "2022", start, end.AddDate(0, 0, 1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(6))
+
+	now := time.Now()
+	mock.ExpectQuery(`ORDER BY ar.verified_at ASC NULLS LAST, ar.id ASC LIMIT \$7 OFFSET \$8`).
+		WithArgs(sqlmock.AnyArg(), "verified", "Teknik Informatika", "2022", start, end.AddDate(0, 0, 1), 5, 5).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "student_id", "mongo_achievement_id", "status", "submitted_at", "verified_at", "verified_by",
+			"rejection_note", "created_at", "updated_at", "full_name", "student_id", "program_study",
+		}).AddRow(uuid.New(), studentID, primitive.NewObjectID().Hex(), "verified", now, now, uuid.New(),
+			nil, now, now, "Demo Student", "434221001", "Teknik Informatika"))
+
+	// Execute
+	references, info, err := achievementRepo.ListAchievementReferences(filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), *info.TotalItems)
+	assert.Len(t, references, 1)
+	assert.Equal(t, "Demo Student", references[0].StudentName)
+	assert.Equal(t, "434221001", references[0].StudentIDNumber)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_ListAchievementReferences_ExcludesDeletedByDefault(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) .* WHERE ar.status <> 'deleted'$`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`ORDER BY ar.created_at DESC NULLS LAST, ar.id DESC LIMIT \$1 OFFSET \$2`).
+		WithArgs(10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	// Execute
+	references, info, err := achievementRepo.ListAchievementReferences(model.AchievementListFilter{
+		PageRequest: model.PageRequest{Page: 1, PerPage: 10},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), *info.TotalItems)
+	assert.Empty(t, references)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementSearchQuery_DeletedStatus(t *testing.T) {
+	// Daftar biasa tidak mencari dokumen yang sudah di-soft-delete
+	query := achievementSearchQuery(model.AchievementListFilter{
+		AchievementType: "competition",
+		Search:          "lomba",
+	})
+	assert.Equal(t, primitive.M{"$ne": true}, query["isDeleted"])
+	assert.Equal(t, "competition", query["achievementType"])
+	assert.Contains(t, query, "$or")
+
+	// status=deleted harus tetap menemukan dokumen yang sudah dihapus
+	query = achievementSearchQuery(model.AchievementListFilter{
+		Status:          "deleted",
+		AchievementType: "competition",
+		Search:          "lomba",
+	})
+	assert.NotContains(t, query, "isDeleted")
+	assert.Equal(t, "competition", query["achievementType"])
+	assert.Contains(t, query, "$or")
+}
+
+func TestAchievementRepository_ListAchievementReferences_KeysetBackward(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	cursor := &model.Cursor{CreatedAt: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), ID: uuid.New(), Backward: true}
+
+	// Tanpa COUNT(*), query dibalik (ASC) dan mengambil PerPage+1 baris
+	mock.ExpectQuery(`WHERE ar.status <> 'deleted' AND \(ar.created_at, ar.id\) > \(\$1, \$2\) ORDER BY ar.created_at ASC, ar.id ASC LIMIT \$3`).
+		WithArgs(cursor.CreatedAt, cursor.ID, 3).
+		WillReturnRows(referenceRows(
+			time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		))
+
+	// Execute
+	references, info, err := achievementRepo.ListAchievementReferences(model.AchievementListFilter{
+		PageRequest: model.PageRequest{PerPage: 2, UseCursor: true, Cursor: cursor, SkipCount: true},
+	})
+
+	// Assert: dua item terdekat dengan cursor, ditampilkan kembali dalam urutan DESC
+	assert.NoError(t, err)
+	assert.Nil(t, info.TotalItems)
+	assert.True(t, info.HasNext)
+	assert.True(t, info.HasPrev)
+	assert.Len(t, references, 2)
+	assert.Equal(t, 3, references[0].CreatedAt.Day())
+	assert.Equal(t, 2, references[1].CreatedAt.Day())
+	assert.Equal(t, references[0].ID, info.First.ID)
+	assert.Equal(t, references[1].ID, info.Last.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func referenceRows(createdAt ...time.Time) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "student_id", "mongo_achievement_id", "status", "submitted_at", "verified_at", "verified_by",
+		"rejection_note", "created_at", "updated_at", "full_name", "student_id", "program_study",
+	})
+	for _, t := range createdAt {
+		rows.AddRow(uuid.New(), uuid.New(), primitive.NewObjectID().Hex(), "draft", nil, nil, nil,
+			nil, t, t, "Demo Student", "434221001", "Teknik Informatika")
+	}
+	return rows
+}
+
+func TestAchievementRepository_StreamAchievementExport_QueryWithoutPagination(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+
+	mock.ExpectQuery(`s.academic_year,\s+\(SELECT v.full_name FROM users v WHERE v.id = ar.verified_by\)\s+FROM achievement_references ar .* WHERE ar.status = \$1\s+ORDER BY ar.submitted_at ASC NULLS LAST, ar.id ASC$`).
+		WithArgs("verified").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	// Execute
+	calls := 0
+	written, err := achievementRepo.StreamAchievementExport(context.Background(), model.AchievementListFilter{
+		Status:    "verified",
+		SortBy:    "submitted_at",
+		SortOrder: "asc",
+	}, 100, func([]model.AchievementExportRow) error {
+		calls++
+		return nil
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), written)
+	assert.Equal(t, 0, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_CountAchievementReferences(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) .* WHERE ar.student_id = ANY\(\$1\) AND ar.status <> 'deleted'$`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12000))
+
+	// Execute
+	total, err := achievementRepo.CountAchievementReferences(context.Background(), model.AchievementListFilter{
+		StudentIDs: []uuid.UUID{uuid.New()},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12000), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -7,12 +7,33 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	// DefaultAccessTokenTTL masa berlaku access token (JWT) jika tidak diatur
+	DefaultAccessTokenTTL = 15 * time.Minute
+	// DefaultRefreshTokenTTL masa berlaku refresh token jika tidak diatur
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
 type AuthRepository struct {
-	DB        *sql.DB
-	JWTSecret string
+	DB              *sql.DB
+	JWTSecret       string
+	TokenRepo       *TokenRepository
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+func NewAuthRepository(db *sql.DB, jwtSecret string) *AuthRepository {
+	return &AuthRepository{
+		DB:              db,
+		JWTSecret:       jwtSecret,
+		TokenRepo:       NewTokenRepository(db),
+		AccessTokenTTL:  DefaultAccessTokenTTL,
+		RefreshTokenTTL: DefaultRefreshTokenTTL,
+	}
 }
 
 func (r *AuthRepository) Login(req model.LoginRequest) (*model.LoginResponse, error) {
@@ -43,7 +64,7 @@ func (r *AuthRepository) Login(req model.LoginRequest) (*model.LoginResponse, er
 
 	// Cek status aktif user
 	if !user.IsActive {
-		return nil, errors.New("akun anda dinonaktifkan")
+		return nil, ErrAccountDisabled
 	}
 
 	// Validasi password
@@ -59,17 +80,103 @@ func (r *AuthRepository) Login(req model.LoginRequest) (*model.LoginResponse, er
 	}
 
 	// Generate JWT token dengan role dan permissions
-	token, err := r.generateJWT(user, profile.Permissions)
+	token, expiresAt, err := r.generateJWT(user, profile.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Buat refresh token untuk sesi baru
+	refreshToken, err := r.tokenRepo().CreateRefreshToken(user.ID, r.refreshTokenTTL())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResponse{
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+		Profile:      *profile,
+	}, nil
+}
+
+// RefreshToken merotasi refresh token dan menerbitkan access token baru
+func (r *AuthRepository) RefreshToken(refreshToken string) (*model.LoginResponse, error) {
+	newRefreshToken, userID, err := r.tokenRepo().RotateRefreshToken(refreshToken, r.refreshTokenTTL())
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.Users
+	query := `
+		SELECT id, username, email, full_name, role_id, is_active
+		FROM users
+		WHERE id = $1
+	`
+
+	err = r.DB.QueryRow(query, userID).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.FullName,
+		&user.RoleID,
+		&user.IsActive,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+
+	// Role dan permissions diambil ulang agar perubahan role langsung berlaku
+	profile, err := r.getUserProfile(user)
+	if err != nil {
+		return nil, err
+	}
+
+	token, expiresAt, err := r.generateJWT(user, profile.Permissions)
 	if err != nil {
 		return nil, err
 	}
 
 	return &model.LoginResponse{
-		Token:   token,
-		Profile: *profile,
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: newRefreshToken,
+		Profile:      *profile,
 	}, nil
 }
 
+// RevokeRefreshToken mencabut sesi refresh token milik userID (dipakai saat logout)
+func (r *AuthRepository) RevokeRefreshToken(refreshToken string, userID uuid.UUID) error {
+	return r.tokenRepo().RevokeRefreshToken(refreshToken, userID)
+}
+
+func (r *AuthRepository) tokenRepo() *TokenRepository {
+	if r.TokenRepo == nil {
+		r.TokenRepo = NewTokenRepository(r.DB)
+	}
+	return r.TokenRepo
+}
+
+func (r *AuthRepository) accessTokenTTL() time.Duration {
+	if r.AccessTokenTTL <= 0 {
+		return DefaultAccessTokenTTL
+	}
+	return r.AccessTokenTTL
+}
+
+func (r *AuthRepository) refreshTokenTTL() time.Duration {
+	if r.RefreshTokenTTL <= 0 {
+		return DefaultRefreshTokenTTL
+	}
+	return r.RefreshTokenTTL
+}
+
 func (r *AuthRepository) getUserProfile(user model.Users) (*model.UserProfile, error) {
 	// Ambil role info
 	var role model.RoleInfo
@@ -116,7 +223,7 @@ func (r *AuthRepository) getUserProfile(user model.Users) (*model.UserProfile, e
 	}, nil
 }
 
-func (r *AuthRepository) generateJWT(user model.Users, permissions []model.Permission) (string, error) {
+func (r *AuthRepository) generateJWT(user model.Users, permissions []model.Permission) (string, time.Time, error) {
 	// Convert permissions ke format untuk JWT
 	permList := make([]map[string]string, len(permissions))
 	for i, p := range permissions {
@@ -127,16 +234,26 @@ func (r *AuthRepository) generateJWT(user model.Users, permissions []model.Permi
 		}
 	}
 
+	now := time.Now()
+	expiresAt := now.Add(r.accessTokenTTL())
+
 	claims := jwt.MapClaims{
 		"user_id":     user.ID.String(),
 		"username":    user.Username,
 		"email":       user.Email,
 		"role_id":     user.RoleID.String(),
 		"permissions": permList,
-		"exp":         time.Now().Add(72 * time.Hour).Unix(),
+		"jti":         uuid.New().String(),
+		"iat":         now.Unix(),
+		"exp":         expiresAt.Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	return token.SignedString([]byte(r.JWTSecret))
+	signed, err := token.SignedString([]byte(r.JWTSecret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, expiresAt, nil
 }
\ No newline at end of file
@@ -4,7 +4,6 @@ import (
 	"POJECT_UAS/model"
 	"database/sql"
 	"testing"
-	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -12,61 +11,63 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-func TestAuthRepository_Login_Success_WithUsername(t *testing.T) {
-	// Setup mock database
-	db, mock, err := sqlmock.New()
-	assert.NoError(t, err)
-	defer db.Close()
+const loginUserQuery = `SELECT id, username, email, password_hash, full_name, role_id, is_active\s+FROM users\s+WHERE username = \$1 OR email = \$1`
 
-	authRepo := &AuthRepository{
-		DB:        db,
-		JWTSecret: "test-secret",
-	}
-
-	// Mock data
-	userID := uuid.New()
-	roleID := uuid.New()
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
-	
-	// Mock query expectations
-	userRows := sqlmock.NewRows([]string{
-		"id", "username", "email", "password_hash", "full_name", "role_id", "is_active", "created_at", "updated_at",
+func loginUserRows(userID, roleID uuid.UUID, password string, isActive bool) *sqlmock.Rows {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	return sqlmock.NewRows([]string{
+		"id", "username", "email", "password_hash", "full_name", "role_id", "is_active",
 	}).AddRow(
-		userID, "testuser", "test@example.com", string(hashedPassword), "Test User", roleID, true, time.Now(), time.Now(),
+		userID, "testuser", "test@example.com", string(hashedPassword), "Test User", roleID, isActive,
 	)
+}
 
-	mock.ExpectQuery(`SELECT (.+) FROM users WHERE \(username = \$1 OR email = \$1\) AND is_active = true`).
-		WithArgs("testuser").
-		WillReturnRows(userRows)
-
-	// Mock role query
-	roleRows := sqlmock.NewRows([]string{"name"}).AddRow("student")
-	mock.ExpectQuery(`SELECT name FROM roles WHERE id = \$1`).
+// expectUserProfile query role, permissions dan pembuatan refresh token setelah password valid
+func expectUserProfile(mock sqlmock.Sqlmock, userID, roleID uuid.UUID, permissions ...string) {
+	mock.ExpectQuery(`SELECT id, name, description\s+FROM roles\s+WHERE id = \$1`).
 		WithArgs(roleID).
-		WillReturnRows(roleRows)
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(roleID, "student", "Mahasiswa"))
 
-	// Mock permissions query
-	permRows := sqlmock.NewRows([]string{"resource", "action"}).
-		AddRow("achievements", "create").
-		AddRow("achievements", "read")
-	mock.ExpectQuery(`SELECT p.resource, p.action FROM permissions p`).
+	permRows := sqlmock.NewRows([]string{"id", "name", "resource", "action"})
+	for _, name := range permissions {
+		permRows.AddRow(uuid.New(), "achievements:"+name, "achievements", name)
+	}
+	mock.ExpectQuery(`SELECT p.id, p.name, p.resource, p.action\s+FROM permissions p`).
 		WithArgs(roleID).
 		WillReturnRows(permRows)
 
+	mock.ExpectExec(`INSERT INTO refresh_tokens`).
+		WithArgs(sqlmock.AnyArg(), userID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestAuthRepository_Login_Success_WithUsername(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	authRepo := NewAuthRepository(db, "test-secret")
+	userID, roleID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(loginUserQuery).
+		WithArgs("testuser").
+		WillReturnRows(loginUserRows(userID, roleID, "password123", true))
+	expectUserProfile(mock, userID, roleID, "create", "read")
+
 	// Execute
-	result, err := authRepo.Login("testuser", "password123")
+	result, err := authRepo.Login(model.LoginRequest{Credential: "testuser", Password: "password123"})
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, "testuser", result.User.Username)
-	assert.Equal(t, "test@example.com", result.User.Email)
-	assert.Equal(t, "Test User", result.User.FullName)
-	assert.Equal(t, "student", result.User.Role)
+	assert.Equal(t, "testuser", result.Profile.Username)
+	assert.Equal(t, "test@example.com", result.Profile.Email)
+	assert.Equal(t, "Test User", result.Profile.FullName)
+	assert.Equal(t, "student", result.Profile.Role.Name)
 	assert.NotEmpty(t, result.Token)
-	assert.Len(t, result.User.Permissions, 2)
-
-	// Verify all expectations were met
+	assert.NotEmpty(t, result.RefreshToken)
+	assert.Len(t, result.Profile.Permissions, 2)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -76,49 +77,22 @@ func TestAuthRepository_Login_Success_WithEmail(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	authRepo := &AuthRepository{
-		DB:        db,
-		JWTSecret: "test-secret",
-	}
-
-	// Mock data
-	userID := uuid.New()
-	roleID := uuid.New()
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
-	
-	// Mock query expectations
-	userRows := sqlmock.NewRows([]string{
-		"id", "username", "email", "password_hash", "full_name", "role_id", "is_active", "created_at", "updated_at",
-	}).AddRow(
-		userID, "testuser", "test@example.com", string(hashedPassword), "Test User", roleID, true, time.Now(), time.Now(),
-	)
+	authRepo := NewAuthRepository(db, "test-secret")
+	userID, roleID := uuid.New(), uuid.New()
 
-	mock.ExpectQuery(`SELECT (.+) FROM users WHERE \(username = \$1 OR email = \$1\) AND is_active = true`).
+	mock.ExpectQuery(loginUserQuery).
 		WithArgs("test@example.com").
-		WillReturnRows(userRows)
-
-	// Mock role query
-	roleRows := sqlmock.NewRows([]string{"name"}).AddRow("student")
-	mock.ExpectQuery(`SELECT name FROM roles WHERE id = \$1`).
-		WithArgs(roleID).
-		WillReturnRows(roleRows)
-
-	// Mock permissions query
-	permRows := sqlmock.NewRows([]string{"resource", "action"})
-	mock.ExpectQuery(`SELECT p.resource, p.action FROM permissions p`).
-		WithArgs(roleID).
-		WillReturnRows(permRows)
+		WillReturnRows(loginUserRows(userID, roleID, "password123", true))
+	expectUserProfile(mock, userID, roleID)
 
 	// Execute
-	result, err := authRepo.Login("test@example.com", "password123")
+	result, err := authRepo.Login(model.LoginRequest{Credential: "test@example.com", Password: "password123"})
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, "testuser", result.User.Username)
-	assert.Equal(t, "test@example.com", result.User.Email)
-
-	// Verify all expectations were met
+	assert.Equal(t, "testuser", result.Profile.Username)
+	assert.Equal(t, "test@example.com", result.Profile.Email)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -128,24 +102,19 @@ func TestAuthRepository_Login_UserNotFound(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	authRepo := &AuthRepository{
-		DB:        db,
-		JWTSecret: "test-secret",
-	}
+	authRepo := NewAuthRepository(db, "test-secret")
 
-	// Mock query expectations - no user found
-	mock.ExpectQuery(`SELECT (.+) FROM users WHERE \(username = \$1 OR email = \$1\) AND is_active = true`).
+	mock.ExpectQuery(loginUserQuery).
 		WithArgs("nonexistent").
 		WillReturnError(sql.ErrNoRows)
 
 	// Execute
-	result, err := authRepo.Login("nonexistent", "password123")
+	result, err := authRepo.Login(model.LoginRequest{Credential: "nonexistent", Password: "password123"})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, result)
-
-	// Verify all expectations were met
+	assert.Contains(t, err.Error(), "kredensial salah")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -155,36 +124,19 @@ func TestAuthRepository_Login_WrongPassword(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	authRepo := &AuthRepository{
-		DB:        db,
-		JWTSecret: "test-secret",
-	}
-
-	// Mock data
-	userID := uuid.New()
-	roleID := uuid.New()
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.DefaultCost)
-	
-	// Mock query expectations
-	userRows := sqlmock.NewRows([]string{
-		"id", "username", "email", "password_hash", "full_name", "role_id", "is_active", "created_at", "updated_at",
-	}).AddRow(
-		userID, "testuser", "test@example.com", string(hashedPassword), "Test User", roleID, true, time.Now(), time.Now(),
-	)
+	authRepo := NewAuthRepository(db, "test-secret")
 
-	mock.ExpectQuery(`SELECT (.+) FROM users WHERE \(username = \$1 OR email = \$1\) AND is_active = true`).
+	mock.ExpectQuery(loginUserQuery).
 		WithArgs("testuser").
-		WillReturnRows(userRows)
+		WillReturnRows(loginUserRows(uuid.New(), uuid.New(), "correctpassword", true))
 
 	// Execute with wrong password
-	result, err := authRepo.Login("testuser", "wrongpassword")
+	result, err := authRepo.Login(model.LoginRequest{Credential: "testuser", Password: "wrongpassword"})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "invalid credentials")
-
-	// Verify all expectations were met
+	assert.Contains(t, err.Error(), "kredensial salah")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -194,23 +146,18 @@ func TestAuthRepository_Login_InactiveUser(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	authRepo := &AuthRepository{
-		DB:        db,
-		JWTSecret: "test-secret",
-	}
+	authRepo := NewAuthRepository(db, "test-secret")
 
-	// Mock query expectations - no active user found
-	mock.ExpectQuery(`SELECT (.+) FROM users WHERE \(username = \$1 OR email = \$1\) AND is_active = true`).
+	mock.ExpectQuery(loginUserQuery).
 		WithArgs("inactiveuser").
-		WillReturnError(sql.ErrNoRows)
+		WillReturnRows(loginUserRows(uuid.New(), uuid.New(), "password123", false))
 
 	// Execute
-	result, err := authRepo.Login("inactiveuser", "password123")
+	result, err := authRepo.Login(model.LoginRequest{Credential: "inactiveuser", Password: "password123"})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, result)
-
-	// Verify all expectations were met
+	assert.Contains(t, err.Error(), "dinonaktifkan")
 	assert.NoError(t, mock.ExpectationsWereMet())
-}
\ No newline at end of file
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultEmailMaxAttempts = 8
+	emailLease              = 2 * time.Minute  // email sending yang melewati lease diambil ulang
+	emailBaseBackoff        = 30 * time.Second // jeda retry: 30s, 1m, 2m, ...
+	emailMaxBackoff         = time.Hour
+)
+
+// EmailQueueRepository antrean email notifikasi. Email dirender saat dimasukkan ke antrean
+// sehingga worker cukup mengirim ulang isi yang sama ketika retry.
+type EmailQueueRepository struct {
+	DB          *sql.DB
+	MaxAttempts int // setelah gagal sebanyak ini, email ditandai failed
+}
+
+func NewEmailQueueRepository(db *sql.DB) *EmailQueueRepository {
+	return &EmailQueueRepository{
+		DB:          db,
+		MaxAttempts: defaultEmailMaxAttempts,
+	}
+}
+
+const emailColumns = `id, notification_id, user_id, to_address, subject, html_body, text_body,
+	status, attempts, last_error, next_attempt_at, created_at, sent_at`
+
+// Enqueue memasukkan email ke antrean, langsung jatuh tempo
+func (r *EmailQueueRepository) Enqueue(ctx context.Context, message model.EmailMessage) error {
+	if message.ID == uuid.Nil {
+		message.ID = uuid.New()
+	}
+	now := time.Now()
+
+	query := `
+		INSERT INTO email_queue
+		(id, notification_id, user_id, to_address, subject, html_body, text_body, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'pending', $8, $8)
+	`
+
+	_, err := r.DB.ExecContext(
+		ctx,
+		query,
+		message.ID,
+		message.NotificationID,
+		message.UserID,
+		message.To,
+		message.Subject,
+		message.HTMLBody,
+		message.TextBody,
+		now,
+	)
+	return err
+}
+
+// ClaimDue mengambil email yang jatuh tempo (atau lease-nya habis) dan menandainya sending
+func (r *EmailQueueRepository) ClaimDue(ctx context.Context, limit int) ([]model.EmailMessage, error) {
+	now := time.Now()
+
+	query := `
+		UPDATE email_queue
+		SET status = 'sending', attempts = attempts + 1, locked_until = $1
+		WHERE id IN (
+			SELECT id FROM email_queue
+			WHERE (status = 'pending' AND next_attempt_at <= $2)
+			   OR (status = 'sending' AND locked_until < $2)
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + emailColumns
+
+	rows, err := r.DB.QueryContext(ctx, query, now.Add(emailLease), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []model.EmailMessage{}
+	for rows.Next() {
+		var message model.EmailMessage
+		err := rows.Scan(
+			&message.ID,
+			&message.NotificationID,
+			&message.UserID,
+			&message.To,
+			&message.Subject,
+			&message.HTMLBody,
+			&message.TextBody,
+			&message.Status,
+			&message.Attempts,
+			&message.LastError,
+			&message.NextAttemptAt,
+			&message.CreatedAt,
+			&message.SentAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// MarkSent menandai email terkirim
+func (r *EmailQueueRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE email_queue
+		SET status = 'sent', sent_at = $1, locked_until = NULL, last_error = NULL
+		WHERE id = $2
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, time.Now(), id)
+	return err
+}
+
+// MarkFailed menjadwalkan ulang email dengan backoff, atau menandainya failed
+// jika percobaan sudah mencapai MaxAttempts. Mengembalikan true jika email tidak akan dicoba lagi.
+func (r *EmailQueueRepository) MarkFailed(ctx context.Context, message model.EmailMessage, cause error) (bool, error) {
+	if message.Attempts >= r.MaxAttempts {
+		query := `UPDATE email_queue SET status = 'failed', locked_until = NULL, last_error = $1 WHERE id = $2`
+		_, err := r.DB.ExecContext(ctx, query, cause.Error(), message.ID)
+		return true, err
+	}
+
+	query := `
+		UPDATE email_queue
+		SET status = 'pending', locked_until = NULL, next_attempt_at = $1, last_error = $2
+		WHERE id = $3
+	`
+
+	next := time.Now().Add(retryBackoff(message.Attempts, emailBaseBackoff, emailMaxBackoff))
+	_, err := r.DB.ExecContext(ctx, query, next, cause.Error(), message.ID)
+	return false, err
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"POJECT_UAS/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailQueueRepository_MarkFailed_Reschedules(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	queue := NewEmailQueueRepository(db)
+	message := model.EmailMessage{ID: uuid.New(), Attempts: 2}
+
+	mock.ExpectExec(`UPDATE email_queue\s+SET status = 'pending'`).
+		WithArgs(sqlmock.AnyArg(), "connection refused", message.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute
+	final, err := queue.MarkFailed(context.Background(), message, errors.New("connection refused"))
+
+	// Assert: masih ada sisa percobaan
+	assert.NoError(t, err)
+	assert.False(t, final)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEmailQueueRepository_MarkFailed_GivesUpAfterMaxAttempts(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	queue := NewEmailQueueRepository(db)
+	queue.MaxAttempts = 3
+	message := model.EmailMessage{ID: uuid.New(), Attempts: 3}
+
+	mock.ExpectExec(`UPDATE email_queue SET status = 'failed'`).
+		WithArgs("mailbox unavailable", message.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute
+	final, err := queue.MarkFailed(context.Background(), message, errors.New("mailbox unavailable"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, final)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, emailBaseBackoff, retryBackoff(1, emailBaseBackoff, emailMaxBackoff))
+	assert.Equal(t, 2*time.Minute, retryBackoff(3, emailBaseBackoff, emailMaxBackoff))
+	assert.Equal(t, emailMaxBackoff, retryBackoff(20, emailBaseBackoff, emailMaxBackoff))
+}
+
+func TestNotificationRepository_GetRecipient_DefaultPreferences(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewNotificationRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectQuery(`FROM users u\s+LEFT JOIN notification_preferences`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "full_name", "language", "disabled_channels"}).
+			AddRow(userID, "budi@example.com", "Budi", "en", "{email}"))
+
+	// Execute
+	recipient, err := repo.GetRecipient(userID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "budi@example.com", recipient.Email)
+	assert.Equal(t, "en", recipient.Preferences.Language)
+	assert.False(t, recipient.Preferences.ChannelEnabled(model.NotificationChannelEmail))
+	assert.True(t, recipient.Preferences.ChannelEnabled(model.NotificationChannelRealtime))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_SavePreferences_StoresDisabledChannels(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewNotificationRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectExec(`INSERT INTO notification_preferences`).
+		WithArgs(userID, "id", pq.Array([]string{model.NotificationChannelRealtime}), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute
+	err = repo.SavePreferences(userID, model.NotificationPreferences{
+		Language: "id",
+		Channels: map[string]bool{model.NotificationChannelRealtime: false, model.NotificationChannelEmail: true},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultExportMaxAttempts = 3
+	exportLease              = 30 * time.Minute // job running yang melewati lease diambil ulang
+)
+
+// ExportJobRepository antrean job ekspor prestasi
+type ExportJobRepository struct {
+	DB          *sql.DB
+	MaxAttempts int // setelah gagal sebanyak ini, job ditandai failed
+}
+
+func NewExportJobRepository(db *sql.DB) *ExportJobRepository {
+	return &ExportJobRepository{
+		DB:          db,
+		MaxAttempts: defaultExportMaxAttempts,
+	}
+}
+
+const exportJobColumns = `id, requested_by, format, filter, status, attempts, row_count,
+	file_key, file_name, file_size, last_error, created_at, started_at, finished_at, expires_at`
+
+func scanExportJob(row interface{ Scan(...interface{}) error }) (*model.ExportJob, error) {
+	var job model.ExportJob
+	err := row.Scan(
+		&job.ID,
+		&job.RequestedBy,
+		&job.Format,
+		&job.Filter,
+		&job.Status,
+		&job.Attempts,
+		&job.RowCount,
+		&job.FileKey,
+		&job.FileName,
+		&job.FileSize,
+		&job.LastError,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Create memasukkan job baru berstatus pending
+func (r *ExportJobRepository) Create(ctx context.Context, job *model.ExportJob) error {
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	job.Status = model.ExportPending
+	job.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO export_jobs (id, requested_by, format, filter, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, job.ID, job.RequestedBy, job.Format, []byte(job.Filter), job.Status, job.CreatedAt)
+	return err
+}
+
+// GetByID mengambil job, sql.ErrNoRows jika tidak ada
+func (r *ExportJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.ExportJob, error) {
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs WHERE id = $1`
+	return scanExportJob(r.DB.QueryRowContext(ctx, query, id))
+}
+
+// ClaimNext mengambil satu job pending (atau running yang lease-nya habis) dan menandainya
+// running. nil jika tidak ada job.
+func (r *ExportJobRepository) ClaimNext(ctx context.Context) (*model.ExportJob, error) {
+	now := time.Now()
+
+	query := `
+		UPDATE export_jobs
+		SET status = 'running', attempts = attempts + 1, locked_until = $1, started_at = $2
+		WHERE id = (
+			SELECT id FROM export_jobs
+			WHERE status = 'pending'
+			   OR (status = 'running' AND locked_until < $2)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + exportJobColumns
+
+	job, err := scanExportJob(r.DB.QueryRowContext(ctx, query, now.Add(exportLease), now))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+// MarkCompleted menyimpan hasil job. File boleh dihapus setelah expiresAt.
+func (r *ExportJobRepository) MarkCompleted(ctx context.Context, id uuid.UUID, fileKey, fileName string, fileSize, rowCount int64, expiresAt time.Time) error {
+	query := `
+		UPDATE export_jobs
+		SET status = 'completed', file_key = $1, file_name = $2, file_size = $3, row_count = $4,
+		    expires_at = $5, finished_at = $6, locked_until = NULL, last_error = NULL
+		WHERE id = $7
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, fileKey, fileName, fileSize, rowCount, expiresAt, time.Now(), id)
+	return err
+}
+
+// MarkFailed mengembalikan job ke antrean, atau menandainya failed jika percobaan sudah
+// mencapai MaxAttempts. Mengembalikan true jika job tidak akan dicoba lagi.
+func (r *ExportJobRepository) MarkFailed(ctx context.Context, job model.ExportJob, cause error) (bool, error) {
+	if job.Attempts >= r.MaxAttempts {
+		query := `
+			UPDATE export_jobs
+			SET status = 'failed', locked_until = NULL, last_error = $1, finished_at = $2
+			WHERE id = $3
+		`
+		_, err := r.DB.ExecContext(ctx, query, cause.Error(), time.Now(), job.ID)
+		return true, err
+	}
+
+	query := `UPDATE export_jobs SET status = 'pending', locked_until = NULL, last_error = $1 WHERE id = $2`
+	_, err := r.DB.ExecContext(ctx, query, cause.Error(), job.ID)
+	return false, err
+}
+
+// ListExpired job selesai yang masa simpan filenya sudah habis
+func (r *ExportJobRepository) ListExpired(ctx context.Context, limit int) ([]model.ExportJob, error) {
+	query := `
+		SELECT ` + exportJobColumns + ` FROM export_jobs
+		WHERE status = 'completed' AND expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+
+	rows, err := r.DB.QueryContext(ctx, query, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []model.ExportJob{}
+	for rows.Next() {
+		job, err := scanExportJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// MarkExpired menandai file hasil job sudah dihapus
+func (r *ExportJobRepository) MarkExpired(ctx context.Context, id uuid.UUID) error {
+	_, err := r.DB.ExecContext(ctx, `UPDATE export_jobs SET status = 'expired' WHERE id = $1`, id)
+	return err
+}
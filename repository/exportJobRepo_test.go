@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"POJECT_UAS/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportJobRepository_ClaimNext_Empty(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	jobs := NewExportJobRepository(db)
+
+	mock.ExpectQuery(`UPDATE export_jobs\s+SET status = 'running'.*FOR UPDATE SKIP LOCKED`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	// Execute
+	job, err := jobs.ClaimNext(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, job)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportJobRepository_ClaimNext(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	jobs := NewExportJobRepository(db)
+	jobID, userID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(`UPDATE export_jobs`).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "requested_by", "format", "filter", "status", "attempts", "row_count",
+			"file_key", "file_name", "file_size", "last_error", "created_at", "started_at", "finished_at", "expires_at",
+		}).AddRow(jobID, userID, "xlsx", []byte(`{"Status":"verified"}`), "running", 1, nil,
+			nil, nil, nil, nil, now, now, nil, nil))
+
+	// Execute
+	job, err := jobs.ClaimNext(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, jobID, job.ID)
+	assert.Equal(t, "xlsx", job.Format)
+	assert.JSONEq(t, `{"Status":"verified"}`, string(job.Filter))
+	assert.Equal(t, 1, job.Attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportJobRepository_MarkFailed(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	jobs := NewExportJobRepository(db)
+	jobs.MaxAttempts = 2
+	job := model.ExportJob{ID: uuid.New(), Attempts: 1}
+
+	mock.ExpectExec(`UPDATE export_jobs SET status = 'pending'`).
+		WithArgs("mongo timeout", job.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE export_jobs\s+SET status = 'failed'`).
+		WithArgs("mongo timeout", sqlmock.AnyArg(), job.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute: percobaan pertama dikembalikan ke antrean, kedua gagal permanen
+	final, err := jobs.MarkFailed(context.Background(), job, errors.New("mongo timeout"))
+	assert.NoError(t, err)
+	assert.False(t, final)
+
+	job.Attempts = 2
+	final, err = jobs.MarkFailed(context.Background(), job, errors.New("mongo timeout"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, final)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"POJECT_UAS/model"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type NotificationRepository struct {
+	DB *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{DB: db}
+}
+
+// insertNotification menyimpan notifikasi, Data disimpan sebagai JSONB
+func insertNotification(db *sql.DB, notification model.Notification) error {
+	var data []byte
+	if notification.Data != nil {
+		var err error
+		data, err = json.Marshal(notification.Data)
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO notifications (id, user_id, type, title, message, data, is_read, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := db.Exec(
+		query,
+		notification.ID,
+		notification.UserID,
+		notification.Type,
+		notification.Title,
+		notification.Message,
+		data,
+		notification.IsRead,
+		notification.CreatedAt,
+	)
+
+	return err
+}
+
+// ListNotifications mengambil notifikasi milik userID, terbaru lebih dulu.
+// unreadOnly true hanya mengambil yang belum dibaca.
+func (r *NotificationRepository) ListNotifications(userID uuid.UUID, unreadOnly bool, page model.PageRequest) ([]model.Notification, model.PageInfo, error) {
+	var info model.PageInfo
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	where := `user_id = ` + arg(userID)
+	if unreadOnly {
+		where += ` AND is_read = FALSE`
+	}
+
+	if !page.SkipCount {
+		var totalCount int64
+		err := r.DB.QueryRow(`SELECT COUNT(*) FROM notifications WHERE `+where, args...).Scan(&totalCount)
+		if err != nil {
+			return nil, info, err
+		}
+		info.TotalItems = &totalCount
+	}
+
+	query := `
+		SELECT id, user_id, type, title, message, data, is_read, created_at
+		FROM notifications
+		WHERE ` + where
+
+	reverse := false
+	if page.UseCursor {
+		var keyset, orderBy string
+		keyset, orderBy, reverse = page.KeysetCondition("created_at", "id", true, arg)
+		if keyset != "" {
+			query += ` AND ` + keyset
+		}
+		query += ` ORDER BY ` + orderBy + ` LIMIT ` + arg(page.PerPage+1)
+	} else {
+		query += ` ORDER BY created_at DESC, id DESC LIMIT ` + arg(page.PerPage) + ` OFFSET ` + arg(page.Offset())
+	}
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, info, err
+	}
+	defer rows.Close()
+
+	notifications := []model.Notification{}
+	for rows.Next() {
+		var notification model.Notification
+		var data []byte
+		err := rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.Type,
+			&notification.Title,
+			&notification.Message,
+			&data,
+			&notification.IsRead,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, info, err
+		}
+		notification.Data = decodeNotificationData(data)
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, info, err
+	}
+
+	if !page.UseCursor {
+		return notifications, info, nil
+	}
+
+	notifications, keyset := keysetPage(page, notifications, reverse, func(n model.Notification) model.Cursor {
+		return model.Cursor{CreatedAt: n.CreatedAt, ID: n.ID}
+	})
+	keyset.TotalItems = info.TotalItems
+	return notifications, keyset, nil
+}
+
+// decodeNotificationData mengubah kolom data (JSONB) menjadi NotificationData.
+// Data kosong atau yang bentuknya tidak dikenal menghasilkan nil.
+func decodeNotificationData(data []byte) *model.NotificationData {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var notificationData model.NotificationData
+	if err := json.Unmarshal(data, &notificationData); err != nil {
+		return nil
+	}
+
+	return &notificationData
+}
+
+// CountUnread jumlah notifikasi yang belum dibaca
+func (r *NotificationRepository) CountUnread(userID uuid.UUID) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND is_read = FALSE`
+
+	err := r.DB.QueryRow(query, userID).Scan(&count)
+	return count, err
+}
+
+// MarkAsRead menandai satu notifikasi sudah dibaca. sql.ErrNoRows jika bukan milik userID.
+func (r *NotificationRepository) MarkAsRead(userID uuid.UUID, notificationID uuid.UUID) error {
+	query := `UPDATE notifications SET is_read = TRUE WHERE id = $1 AND user_id = $2`
+
+	result, err := r.DB.Exec(query, notificationID, userID)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// MarkAllAsRead menandai semua notifikasi userID sudah dibaca, mengembalikan jumlah yang berubah
+func (r *NotificationRepository) MarkAllAsRead(userID uuid.UUID) (int64, error) {
+	query := `UPDATE notifications SET is_read = TRUE WHERE user_id = $1 AND is_read = FALSE`
+
+	result, err := r.DB.Exec(query, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteNotification menghapus notifikasi. sql.ErrNoRows jika bukan milik userID.
+func (r *NotificationRepository) DeleteNotification(userID uuid.UUID, notificationID uuid.UUID) error {
+	query := `DELETE FROM notifications WHERE id = $1 AND user_id = $2`
+
+	result, err := r.DB.Exec(query, notificationID, userID)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetRecipient mengambil email, nama, dan preferensi notifikasi user.
+// User yang belum pernah mengatur preferensi mendapat bahasa Indonesia dan semua channel aktif.
+func (r *NotificationRepository) GetRecipient(userID uuid.UUID) (*model.NotificationRecipient, error) {
+	query := `
+		SELECT u.id, u.email, u.full_name,
+		       COALESCE(p.language, 'id'), COALESCE(p.disabled_channels, '{}')
+		FROM users u
+		LEFT JOIN notification_preferences p ON p.user_id = u.id
+		WHERE u.id = $1
+	`
+
+	var recipient model.NotificationRecipient
+	var language string
+	var disabled []string
+	err := r.DB.QueryRow(query, userID).Scan(
+		&recipient.UserID,
+		&recipient.Email,
+		&recipient.FullName,
+		&language,
+		pq.Array(&disabled),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	recipient.Preferences = notificationPreferences(language, disabled)
+	return &recipient, nil
+}
+
+// GetPreferences preferensi notifikasi user, sql.ErrNoRows jika user tidak ada
+func (r *NotificationRepository) GetPreferences(userID uuid.UUID) (model.NotificationPreferences, error) {
+	recipient, err := r.GetRecipient(userID)
+	if err != nil {
+		return model.NotificationPreferences{}, err
+	}
+	return recipient.Preferences, nil
+}
+
+// SavePreferences menyimpan preferensi notifikasi user (insert atau update)
+func (r *NotificationRepository) SavePreferences(userID uuid.UUID, prefs model.NotificationPreferences) error {
+	disabled := []string{}
+	for _, channel := range model.NotificationChannels {
+		if !prefs.ChannelEnabled(channel) {
+			disabled = append(disabled, channel)
+		}
+	}
+
+	query := `
+		INSERT INTO notification_preferences (user_id, language, disabled_channels, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET language = EXCLUDED.language,
+		    disabled_channels = EXCLUDED.disabled_channels,
+		    updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.DB.Exec(query, userID, prefs.Language, pq.Array(disabled), time.Now())
+	return err
+}
+
+// notificationPreferences menyusun preferensi dari kolom language dan disabled_channels
+func notificationPreferences(language string, disabled []string) model.NotificationPreferences {
+	prefs := model.NotificationPreferences{
+		Language: language,
+		Channels: make(map[string]bool, len(model.NotificationChannels)),
+	}
+	for _, channel := range model.NotificationChannels {
+		prefs.Channels[channel] = !slices.Contains(disabled, channel)
+	}
+	return prefs
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"POJECT_UAS/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var notificationColumns = []string{"id", "user_id", "type", "title", "message", "data", "is_read", "created_at"}
+
+func TestNotificationRepository_ListNotifications_UnreadOnly(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	notificationRepo := NewNotificationRepository(db)
+	userID := uuid.New()
+	referenceID := uuid.New()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM notifications WHERE user_id = \$1 AND is_read = FALSE`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`FROM notifications WHERE user_id = \$1 AND is_read = FALSE ORDER BY created_at DESC, id DESC LIMIT \$2 OFFSET \$3`).
+		WithArgs(userID, 10, 0).
+		WillReturnRows(sqlmock.NewRows(notificationColumns).
+			AddRow(uuid.New(), userID, "achievement_submitted", "Prestasi Baru", "pesan",
+				[]byte(`{"achievement_id":"abc","achievement_reference_id":"`+referenceID.String()+`","achievement_title":"Juara 1"}`),
+				false, time.Now()).
+			AddRow(uuid.New(), userID, "system", "Info", "pesan", nil, false, time.Now()))
+
+	// Execute
+	notifications, info, err := notificationRepo.ListNotifications(userID, true, model.PageRequest{Page: 1, PerPage: 10})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *info.TotalItems)
+	assert.Len(t, notifications, 2)
+	assert.Equal(t, referenceID, notifications[0].Data.AchievementReferenceID)
+	assert.Equal(t, "Juara 1", notifications[0].Data.AchievementTitle)
+	assert.Nil(t, notifications[1].Data)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_MarkAsRead_OtherUser(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	notificationRepo := NewNotificationRepository(db)
+	userID := uuid.New()
+	notificationID := uuid.New()
+
+	mock.ExpectExec(`UPDATE notifications SET is_read = TRUE WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(notificationID, userID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute
+	err = notificationRepo.MarkAsRead(userID, notificationID)
+
+	// Assert
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_MarkAllAsRead(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	notificationRepo := NewNotificationRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectExec(`UPDATE notifications SET is_read = TRUE WHERE user_id = \$1 AND is_read = FALSE`).
+		WithArgs(userID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	// Execute
+	updated, err := notificationRepo.MarkAllAsRead(userID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_CreateNotification_StoresDataAsJSON(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	notification := model.Notification{
+		ID:     uuid.New(),
+		UserID: uuid.New(),
+		Type:   "achievement_verified",
+		Data:   &model.NotificationData{AchievementID: "abc"},
+	}
+
+	mock.ExpectExec(`INSERT INTO notifications`).
+		WithArgs(notification.ID, notification.UserID, "achievement_verified", "", "",
+			[]byte(`{"achievement_id":"abc","achievement_reference_id":"00000000-0000-0000-0000-000000000000","student_id":"00000000-0000-0000-0000-000000000000","student_name":"","achievement_title":""}`),
+			false, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// Execute
+	err = achievementRepo.CreateNotification(notification)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,481 @@
+package repository
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultOutboxMaxAttempts = 10
+	outboxLease              = time.Minute      // operasi processing yang melewati lease diambil ulang
+	outboxBaseBackoff        = 5 * time.Second  // jeda retry: 5s, 10s, 20s, ...
+	outboxMaxBackoff         = 10 * time.Minute // batas jeda retry
+)
+
+// ErrOutboxDocumentNotFound dokumen achievement belum ada di MongoDB. Operasi diulang
+// seperti error lain, misalnya saat operasi create sebelumnya belum berhasil.
+var ErrOutboxDocumentNotFound = errors.New("achievement document not found in MongoDB")
+
+// OutboxRepository menyimpan dan menjalankan operasi MongoDB yang dicatat bersama perubahan
+// PostgreSQL (transactional outbox). Setiap operasi idempotent sehingga aman dijalankan ulang.
+type OutboxRepository struct {
+	DB          *sql.DB
+	MongoDB     *mongo.Database
+	MaxAttempts int // setelah gagal sebanyak ini, perubahan PostgreSQL dikompensasi
+}
+
+func NewOutboxRepository(db *sql.DB, mongoDB *mongo.Database) *OutboxRepository {
+	return &OutboxRepository{
+		DB:          db,
+		MongoDB:     mongoDB,
+		MaxAttempts: defaultOutboxMaxAttempts,
+	}
+}
+
+// enqueueOutbox mencatat operasi di dalam transaksi yang sama dengan perubahan reference
+func enqueueOutbox(ctx context.Context, tx *sql.Tx, referenceID uuid.UUID, mongoAchievementID, operation string, payload []byte, at time.Time) (uuid.UUID, error) {
+	id := uuid.New()
+
+	query := `
+		INSERT INTO achievement_outbox
+		(id, achievement_reference_id, mongo_achievement_id, operation, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, 'pending', $6, $6)
+	`
+
+	_, err := tx.ExecContext(ctx, query, id, referenceID, mongoAchievementID, operation, payload, at)
+	return id, err
+}
+
+const outboxColumns = `id, achievement_reference_id, mongo_achievement_id, operation, payload,
+	status, attempts, last_error, next_attempt_at, created_at, processed_at`
+
+// outboxOldestUnfinished membatasi claim ke operasi tertua yang belum selesai per
+// achievement, sehingga operasi untuk satu achievement dijalankan berurutan.
+const outboxOldestUnfinished = `NOT EXISTS (
+	SELECT 1 FROM achievement_outbox earlier
+	WHERE earlier.achievement_reference_id = achievement_outbox.achievement_reference_id
+	  AND earlier.status IN ('pending', 'processing')
+	  AND (earlier.created_at, earlier.id) < (achievement_outbox.created_at, achievement_outbox.id)
+)`
+
+// ClaimDue mengambil operasi yang jatuh tempo (atau lease-nya habis) dan menandainya processing.
+// FOR UPDATE SKIP LOCKED membuat beberapa worker bisa berjalan bersamaan tanpa saling mengambil.
+// Operasi yang masih menunggu operasi lebih lama untuk achievement yang sama tidak diambil.
+func (r *OutboxRepository) ClaimDue(ctx context.Context, limit int) ([]model.OutboxOperation, error) {
+	now := time.Now()
+
+	query := `
+		UPDATE achievement_outbox
+		SET status = 'processing', attempts = attempts + 1, locked_until = $1
+		WHERE id IN (
+			SELECT id FROM achievement_outbox
+			WHERE ((status = 'pending' AND next_attempt_at <= $2)
+			   OR (status = 'processing' AND locked_until < $2))
+			  AND ` + outboxOldestUnfinished + `
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+
+	rows, err := r.DB.QueryContext(ctx, query, now.Add(outboxLease), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutboxOperations(rows)
+}
+
+// claim mengambil satu operasi pending berdasarkan ID, sql.ErrNoRows jika sudah diambil worker lain
+// atau masih ada operasi lebih lama untuk achievement yang sama
+func (r *OutboxRepository) claim(ctx context.Context, id uuid.UUID) (*model.OutboxOperation, error) {
+	query := `
+		UPDATE achievement_outbox
+		SET status = 'processing', attempts = attempts + 1, locked_until = $1
+		WHERE id = $2 AND status = 'pending'
+		  AND ` + outboxOldestUnfinished + `
+		RETURNING ` + outboxColumns
+
+	rows, err := r.DB.QueryContext(ctx, query, time.Now().Add(outboxLease), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ops, err := scanOutboxOperations(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &ops[0], nil
+}
+
+// ProcessByID langsung menjalankan operasi yang baru dicatat. Jika gagal, operasi tetap
+// pending dan akan diulang oleh worker.
+func (r *OutboxRepository) ProcessByID(ctx context.Context, id uuid.UUID) error {
+	op, err := r.claim(ctx, id)
+	if err != nil {
+		return err
+	}
+	return r.Process(ctx, *op)
+}
+
+// Process menerapkan operasi yang sudah di-claim lalu mencatat hasilnya.
+// Jika gagal dan percobaan sudah habis, perubahan di PostgreSQL dikompensasi.
+func (r *OutboxRepository) Process(ctx context.Context, op model.OutboxOperation) error {
+	applyErr := r.apply(ctx, op)
+	if applyErr == nil {
+		_, err := r.DB.ExecContext(ctx, `
+			UPDATE achievement_outbox
+			SET status = 'done', processed_at = $1, locked_until = NULL, last_error = NULL
+			WHERE id = $2
+		`, time.Now(), op.ID)
+		return err
+	}
+
+	if op.Attempts < r.MaxAttempts {
+		_, err := r.DB.ExecContext(ctx, `
+			UPDATE achievement_outbox
+			SET status = 'pending', next_attempt_at = $1, last_error = $2, locked_until = NULL
+			WHERE id = $3
+		`, time.Now().Add(outboxBackoff(op.Attempts)), applyErr.Error(), op.ID)
+		if err != nil {
+			return err
+		}
+		return applyErr
+	}
+
+	if err := r.compensate(ctx, op, applyErr); err != nil {
+		lastError := fmt.Sprintf("%v; kompensasi gagal: %v", applyErr, err)
+		_, markErr := r.DB.ExecContext(ctx, `
+			UPDATE achievement_outbox
+			SET status = 'failed', last_error = $1, locked_until = NULL
+			WHERE id = $2
+		`, lastError, op.ID)
+		return errors.Join(applyErr, err, markErr)
+	}
+
+	return applyErr
+}
+
+// apply menjalankan operasi di MongoDB secara idempotent
+func (r *OutboxRepository) apply(ctx context.Context, op model.OutboxOperation) error {
+	if r.MongoDB == nil {
+		return errors.New("MongoDB connection required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(op.MongoAchievementID)
+	if err != nil {
+		return err
+	}
+	collection := r.MongoDB.Collection("achievements")
+
+	switch op.Operation {
+	case model.OutboxCreateAchievement:
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON(op.Payload, true, &doc); err != nil {
+			return err
+		}
+
+		// Dokumen yang sudah ada berarti operasi ini pernah berhasil
+		_, err := collection.InsertOne(ctx, doc)
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return err
+
+	case model.OutboxDeleteAchievement:
+		var payload model.DeleteAchievementPayload
+		if err := json.Unmarshal(op.Payload, &payload); err != nil {
+			return err
+		}
+
+		update := primitive.M{
+			"$set": primitive.M{
+				"isDeleted": true,
+				"deletedAt": payload.DeletedAt,
+			},
+		}
+		result, err := collection.UpdateOne(ctx, primitive.M{"_id": objectID}, update)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return ErrOutboxDocumentNotFound
+		}
+		return nil
+
+	case model.OutboxUpdateAchievement:
+		var payload model.UpdateAchievementPayload
+		if err := bson.UnmarshalExtJSON(op.Payload, true, &payload); err != nil {
+			return err
+		}
+
+		// Dokumen dengan versi yang sama atau lebih baru berarti operasi ini pernah berhasil
+		filter := primitive.M{
+			"_id":     objectID,
+			"version": primitive.M{"$not": primitive.M{"$gte": payload.Version}},
+		}
+		update := primitive.M{
+			"$set": primitive.M{
+				"title":       payload.Title,
+				"description": payload.Description,
+				"details":     payload.Details,
+				"updatedAt":   payload.UpdatedAt,
+				"version":     payload.Version,
+			},
+		}
+		result, err := collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+		return requireOutboxDocument(ctx, collection, objectID, result)
+
+	case model.OutboxAddAttachments:
+		var payload model.AddAttachmentsPayload
+		if err := bson.UnmarshalExtJSON(op.Payload, true, &payload); err != nil {
+			return err
+		}
+
+		// Lampiran yang sudah ada berarti operasi ini pernah berhasil
+		ids := make([]uuid.UUID, 0, len(payload.Attachments))
+		for _, attachment := range payload.Attachments {
+			ids = append(ids, attachment.ID)
+		}
+		filter := primitive.M{
+			"_id":            objectID,
+			"attachments.id": primitive.M{"$nin": ids},
+		}
+		update := primitive.M{
+			"$push": primitive.M{"attachments": primitive.M{"$each": payload.Attachments}},
+			"$set":  primitive.M{"updatedAt": payload.UpdatedAt},
+		}
+		result, err := collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+		return requireOutboxDocument(ctx, collection, objectID, result)
+	}
+
+	return fmt.Errorf("unknown outbox operation %q", op.Operation)
+}
+
+// requireOutboxDocument memeriksa hasil update yang filternya juga tidak cocok setelah operasi
+// pernah berhasil. Tidak ada yang cocok tetapi dokumen ada berarti operasi sudah diterapkan.
+func requireOutboxDocument(ctx context.Context, collection *mongo.Collection, objectID primitive.ObjectID, result *mongo.UpdateResult) error {
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	count, err := collection.CountDocuments(ctx, primitive.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrOutboxDocumentNotFound
+	}
+	return nil
+}
+
+// compensate membatalkan perubahan PostgreSQL untuk operasi yang tidak bisa diterapkan
+func (r *OutboxRepository) compensate(ctx context.Context, op model.OutboxOperation, cause error) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	note := "dibatalkan otomatis: gagal menyimpan ke MongoDB (" + cause.Error() + ")"
+
+	switch op.Operation {
+	case model.OutboxCreateAchievement:
+		// Prestasi yang tidak pernah tersimpan di MongoDB dihapus dari daftar
+		result, err := tx.ExecContext(ctx, `
+			UPDATE achievement_references
+			SET status = 'deleted', updated_at = $1
+			WHERE id = $2 AND status = 'draft'
+		`, now, op.AchievementReferenceID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			if err := insertStatusHistory(ctx, tx, op.AchievementReferenceID, "draft", "deleted", uuid.Nil, &note, now); err != nil {
+				return err
+			}
+		}
+
+	case model.OutboxDeleteAchievement:
+		var payload model.DeleteAchievementPayload
+		if err := json.Unmarshal(op.Payload, &payload); err != nil {
+			return err
+		}
+
+		// Status sebelum dihapus dipulihkan
+		result, err := tx.ExecContext(ctx, `
+			UPDATE achievement_references
+			SET status = $1, updated_at = $2
+			WHERE id = $3 AND status = 'deleted'
+		`, payload.PreviousStatus, now, op.AchievementReferenceID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			if err := insertStatusHistory(ctx, tx, op.AchievementReferenceID, "deleted", payload.PreviousStatus, uuid.Nil, &note, now); err != nil {
+				return err
+			}
+		}
+
+	case model.OutboxUpdateAchievement:
+		var payload model.UpdateAchievementPayload
+		if err := bson.UnmarshalExtJSON(op.Payload, true, &payload); err != nil {
+			return err
+		}
+
+		// Versi dan status sebelum update dipulihkan selama belum ada update lain sesudahnya
+		result, err := tx.ExecContext(ctx, `
+			UPDATE achievement_references
+			SET status = $1, version = $2, updated_at = $3
+			WHERE id = $4 AND version = $5 AND status = 'draft'
+		`, payload.PreviousStatus, payload.PreviousVersion, now, op.AchievementReferenceID, payload.Version)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n > 0 && payload.PreviousStatus != "draft" {
+			if err := insertStatusHistory(ctx, tx, op.AchievementReferenceID, "draft", payload.PreviousStatus, uuid.Nil, &note, now); err != nil {
+				return err
+			}
+		}
+
+	case model.OutboxAddAttachments:
+		// Lampiran tidak mengubah data PostgreSQL selain updated_at, tidak ada yang perlu dipulihkan
+
+	default:
+		return fmt.Errorf("unknown outbox operation %q", op.Operation)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE achievement_outbox
+		SET status = 'compensated', processed_at = $1, last_error = $2, locked_until = NULL
+		WHERE id = $3
+	`, now, cause.Error(), op.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// outboxBackoff jeda sebelum percobaan berikutnya (exponential, dibatasi outboxMaxBackoff)
+func outboxBackoff(attempts int) time.Duration {
+	return retryBackoff(attempts, outboxBaseBackoff, outboxMaxBackoff)
+}
+
+// retryBackoff jeda exponential base, 2*base, 4*base, ... dibatasi max
+func retryBackoff(attempts int, base, max time.Duration) time.Duration {
+	backoff := base
+	for i := 1; i < attempts && backoff < max; i++ {
+		backoff *= 2
+	}
+	return min(backoff, max)
+}
+
+// List operasi outbox untuk admin. status kosong berarti semua yang belum done.
+func (r *OutboxRepository) List(ctx context.Context, status string, limit int) ([]model.OutboxOperation, error) {
+	query := `SELECT ` + outboxColumns + ` FROM achievement_outbox`
+
+	var rows *sql.Rows
+	var err error
+	if status != "" {
+		rows, err = r.DB.QueryContext(ctx, query+` WHERE status = $1 ORDER BY created_at DESC LIMIT $2`, status, limit)
+	} else {
+		rows, err = r.DB.QueryContext(ctx, query+` WHERE status <> 'done' ORDER BY created_at DESC LIMIT $1`, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutboxOperations(rows)
+}
+
+// CountByStatus jumlah operasi per status
+func (r *OutboxRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT status, COUNT(*) FROM achievement_outbox GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int64{
+		model.OutboxPending:     0,
+		model.OutboxProcessing:  0,
+		model.OutboxDone:        0,
+		model.OutboxFailed:      0,
+		model.OutboxCompensated: 0,
+	}
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// Retry mengembalikan operasi failed ke antrean dengan jumlah percobaan direset
+func (r *OutboxRepository) Retry(ctx context.Context, id uuid.UUID) error {
+	result, err := r.DB.ExecContext(ctx, `
+		UPDATE achievement_outbox
+		SET status = 'pending', attempts = 0, next_attempt_at = $1, locked_until = NULL
+		WHERE id = $2 AND status = 'failed'
+	`, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+func scanOutboxOperations(rows *sql.Rows) ([]model.OutboxOperation, error) {
+	ops := []model.OutboxOperation{}
+	for rows.Next() {
+		var op model.OutboxOperation
+		err := rows.Scan(
+			&op.ID,
+			&op.AchievementReferenceID,
+			&op.MongoAchievementID,
+			&op.Operation,
+			&op.Payload,
+			&op.Status,
+			&op.Attempts,
+			&op.LastError,
+			&op.NextAttemptAt,
+			&op.CreatedAt,
+			&op.ProcessedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+
+	return ops, rows.Err()
+}
@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"POJECT_UAS/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAchievementRepository_SubmitAchievement_EnqueuesOutbox(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	achievementRepo.Outbox = nil // hanya worker yang menjalankan operasi
+	studentID := uuid.New()
+	createdBy := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO achievement_references`).
+		WithArgs(sqlmock.AnyArg(), studentID, sqlmock.AnyArg(), "draft", 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO achievement_status_history`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "draft", createdBy, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO webhook_deliveries`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO achievement_outbox`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), model.OutboxCreateAchievement, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	resp, err := achievementRepo.SubmitAchievement(studentID, createdBy, model.SubmitAchievementRequest{
+		AchievementType: "other",
+		Title:           "Juara",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, primitive.IsValidObjectID(resp.AchievementID))
+	assert.Equal(t, "draft", resp.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_DeleteAchievement_EnqueuesOutbox(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	achievementRepo.Outbox = nil
+	referenceID := uuid.New()
+	mongoID := primitive.NewObjectID().Hex()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE achievement_references SET status = 'deleted'`).
+		WithArgs(sqlmock.AnyArg(), referenceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO achievement_status_history`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO webhook_deliveries`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO achievement_outbox`).
+		WithArgs(sqlmock.AnyArg(), referenceID, mongoID, model.OutboxDeleteAchievement, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	err = achievementRepo.DeleteAchievement(referenceID, mongoID, uuid.New())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_DeleteAchievement_NotDraftSkipsOutbox(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE achievement_references SET status = 'deleted'`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// Execute
+	err = achievementRepo.DeleteAchievement(uuid.New(), primitive.NewObjectID().Hex(), uuid.New())
+
+	// Assert
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_UpdateAchievement_EnqueuesOutbox(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	achievementRepo.Outbox = nil
+	referenceID := uuid.New()
+	updatedBy := uuid.New()
+	mongoID := primitive.NewObjectID().Hex()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT mongo_achievement_id, status, version FROM achievement_references WHERE id = \$1 FOR UPDATE`).
+		WithArgs(referenceID).
+		WillReturnRows(sqlmock.NewRows([]string{"mongo_achievement_id", "status", "version"}).
+			AddRow(mongoID, "rejected", 2))
+	mock.ExpectExec(`UPDATE achievement_references SET status = 'draft', version = \$1`).
+		WithArgs(3, sqlmock.AnyArg(), referenceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO achievement_status_history`).
+		WithArgs(sqlmock.AnyArg(), referenceID, "rejected", "draft", updatedBy, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO achievement_outbox`).
+		WithArgs(sqlmock.AnyArg(), referenceID, mongoID, model.OutboxUpdateAchievement, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	achievement, err := achievementRepo.UpdateAchievement(referenceID, updatedBy, 2, model.UpdateAchievementRequest{Title: "Judul Baru"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, mongoID, achievement.ID.Hex())
+	assert.Equal(t, "Judul Baru", achievement.Title)
+	assert.Equal(t, 3, achievement.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_UpdateAchievement_VersionConflictSkipsOutbox(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	referenceID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT mongo_achievement_id, status, version FROM achievement_references`).
+		WithArgs(referenceID).
+		WillReturnRows(sqlmock.NewRows([]string{"mongo_achievement_id", "status", "version"}).
+			AddRow(primitive.NewObjectID().Hex(), "draft", 4))
+	mock.ExpectRollback()
+
+	// Execute
+	_, err = achievementRepo.UpdateAchievement(referenceID, uuid.New(), 3, model.UpdateAchievementRequest{Title: "Judul"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrAchievementVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_AddAttachments_EnqueuesOutbox(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	achievementRepo.Outbox = nil
+	referenceID := uuid.New()
+	mongoID := primitive.NewObjectID().Hex()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT status FROM achievement_references WHERE id = \$1 AND mongo_achievement_id = \$2 FOR UPDATE`).
+		WithArgs(referenceID, mongoID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectExec(`UPDATE achievement_references SET updated_at = \$1`).
+		WithArgs(sqlmock.AnyArg(), referenceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO achievement_outbox`).
+		WithArgs(sqlmock.AnyArg(), referenceID, mongoID, model.OutboxAddAttachments, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	err = achievementRepo.AddAttachments(referenceID, mongoID, []model.Attachment{{ID: uuid.New(), FileName: "sertifikat.pdf"}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_AddAttachments_NotEditable(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT status FROM achievement_references`).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("verified"))
+	mock.ExpectRollback()
+
+	// Execute
+	err = achievementRepo.AddAttachments(uuid.New(), primitive.NewObjectID().Hex(), []model.Attachment{{ID: uuid.New()}})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrAchievementNotEditable)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_Process_SchedulesRetry(t *testing.T) {
+	// Setup mock database, tanpa MongoDB sehingga operasi selalu gagal
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	outboxRepo := NewOutboxRepository(db, nil)
+	op := model.OutboxOperation{
+		ID:                 uuid.New(),
+		MongoAchievementID: primitive.NewObjectID().Hex(),
+		Operation:          model.OutboxCreateAchievement,
+		Attempts:           1,
+	}
+
+	mock.ExpectExec(`UPDATE achievement_outbox SET status = 'pending'`).
+		WithArgs(sqlmock.AnyArg(), "MongoDB connection required", op.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute
+	err = outboxRepo.Process(t.Context(), op)
+
+	// Assert
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_Process_CompensatesDelete(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	outboxRepo := NewOutboxRepository(db, nil)
+	outboxRepo.MaxAttempts = 3
+	payload, _ := json.Marshal(model.DeleteAchievementPayload{DeletedAt: time.Now(), PreviousStatus: "draft"})
+	op := model.OutboxOperation{
+		ID:                     uuid.New(),
+		AchievementReferenceID: uuid.New(),
+		MongoAchievementID:     primitive.NewObjectID().Hex(),
+		Operation:              model.OutboxDeleteAchievement,
+		Payload:                payload,
+		Attempts:               3,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE achievement_references SET status = \$1`).
+		WithArgs("draft", sqlmock.AnyArg(), op.AchievementReferenceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO achievement_status_history`).
+		WithArgs(sqlmock.AnyArg(), op.AchievementReferenceID, "deleted", "draft", nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO webhook_deliveries`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE achievement_outbox SET status = 'compensated'`).
+		WithArgs(sqlmock.AnyArg(), "MongoDB connection required", op.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	err = outboxRepo.Process(t.Context(), op)
+
+	// Assert: error asli tetap dikembalikan untuk dicatat worker
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_ClaimDue_OldestPerAchievement(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	outboxRepo := NewOutboxRepository(db, nil)
+	opID := uuid.New()
+	referenceID := uuid.New()
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{
+		"id", "achievement_reference_id", "mongo_achievement_id", "operation", "payload",
+		"status", "attempts", "last_error", "next_attempt_at", "created_at", "processed_at",
+	}).AddRow(opID, referenceID, primitive.NewObjectID().Hex(), model.OutboxUpdateAchievement, []byte(`{}`),
+		"processing", 1, nil, now, now, nil)
+
+	// Operasi yang masih menunggu operasi lebih lama untuk achievement yang sama tidak diambil
+	mock.ExpectQuery(`UPDATE achievement_outbox SET status = 'processing'(.+)NOT EXISTS \(\s*SELECT 1 FROM achievement_outbox earlier\s*WHERE earlier.achievement_reference_id = achievement_outbox.achievement_reference_id\s*AND earlier.status IN \('pending', 'processing'\)`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 10).
+		WillReturnRows(rows)
+
+	// Execute
+	ops, err := outboxRepo.ClaimDue(t.Context(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, ops, 1)
+	assert.Equal(t, opID, ops[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_ProcessByID_WaitsForEarlierOperation(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	outboxRepo := NewOutboxRepository(db, nil)
+	opID := uuid.New()
+
+	// Operasi lebih lama untuk achievement yang sama masih pending, jadi tidak ada yang di-claim
+	mock.ExpectQuery(`UPDATE achievement_outbox SET status = 'processing'(.+)WHERE id = \$2 AND status = 'pending'\s*AND NOT EXISTS`).
+		WithArgs(sqlmock.AnyArg(), opID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	// Execute
+	err = outboxRepo.ProcessByID(t.Context(), opID)
+
+	// Assert
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_Retry_OnlyFailed(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	outboxRepo := NewOutboxRepository(db, nil)
+
+	mock.ExpectExec(`UPDATE achievement_outbox SET status = 'pending', attempts = 0`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute
+	err = outboxRepo.Retry(t.Context(), uuid.New())
+
+	// Assert
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, outboxBackoff(1))
+	assert.Equal(t, 20*time.Second, outboxBackoff(3))
+	assert.Equal(t, outboxMaxBackoff, outboxBackoff(30))
+}
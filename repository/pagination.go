@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"POJECT_UAS/model"
+	"slices"
+)
+
+// keysetPage memotong hasil query keyset (LIMIT PerPage+1) menjadi satu halaman,
+// membalik urutan untuk cursor mundur, lalu mengisi posisi item pertama dan terakhir
+func keysetPage[T any](page model.PageRequest, items []T, reverse bool, cursorOf func(T) model.Cursor) ([]T, model.PageInfo) {
+	info := page.KeysetPageInfo(len(items))
+
+	if len(items) > page.PerPage {
+		items = items[:page.PerPage]
+	}
+	if reverse {
+		slices.Reverse(items)
+	}
+
+	if len(items) > 0 {
+		first, last := cursorOf(items[0]), cursorOf(items[len(items)-1])
+		info.First, info.Last = &first, &last
+	}
+
+	return items, info
+}
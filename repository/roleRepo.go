@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrRoleExists          = errors.New("role with this name already exists")
+	ErrRoleInUse           = errors.New("role is still assigned to users")
+	ErrPermissionExists    = errors.New("permission already exists")
+	ErrRoleNeedsPermission = errors.New("system role must keep at least one permission")
+)
+
+const permissionColumns = `p.id, p.name, p.resource, p.action, p.description`
+
+// RoleRepository tabel RBAC: roles, permissions dan role_permissions
+type RoleRepository struct {
+	DB *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) *RoleRepository {
+	return &RoleRepository{DB: db}
+}
+
+// ListRoles semua role beserta jumlah user-nya, tanpa permission
+func (r *RoleRepository) ListRoles(ctx context.Context) ([]model.RoleDetail, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT r.id, r.name, r.description, r.created_at,
+			(SELECT COUNT(*) FROM users u WHERE u.role_id = r.id)
+		FROM roles r
+		ORDER BY r.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []model.RoleDetail{}
+	for rows.Next() {
+		var role model.RoleDetail
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &role.UserCount); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+// GetRole role beserta permission dan jumlah user-nya, sql.ErrNoRows jika tidak ada
+func (r *RoleRepository) GetRole(ctx context.Context, id uuid.UUID) (*model.RoleDetail, error) {
+	query := `
+		SELECT r.id, r.name, r.description, r.created_at,
+			(SELECT COUNT(*) FROM users u WHERE u.role_id = r.id)
+		FROM roles r
+		WHERE r.id = $1
+	`
+
+	var role model.RoleDetail
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(
+		&role.ID,
+		&role.Name,
+		&role.Description,
+		&role.CreatedAt,
+		&role.UserCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT `+permissionColumns+`
+		FROM permissions p
+		INNER JOIN role_permissions rp ON rp.permission_id = p.id
+		WHERE rp.role_id = $1
+		ORDER BY p.resource, p.action
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	role.Permissions, err = scanPermissions(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// CreateRole membuat role beserta permission-nya dalam satu transaksi
+func (r *RoleRepository) CreateRole(ctx context.Context, role model.Roles, permissionIDs []uuid.UUID) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO roles (id, name, description, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, role.ID, role.Name, role.Description, role.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrRoleExists
+		}
+		return err
+	}
+
+	if err := insertRolePermissions(ctx, tx, role.ID, permissionIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateRole menyimpan nama dan deskripsi role, sql.ErrNoRows jika tidak ada
+func (r *RoleRepository) UpdateRole(ctx context.Context, role model.Roles) error {
+	result, err := r.DB.ExecContext(ctx, `
+		UPDATE roles SET name = $1, description = $2 WHERE id = $3
+	`, role.Name, role.Description, role.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrRoleExists
+		}
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// DeleteRole menghapus role beserta permission-nya. ErrRoleInUse jika masih ada user
+// dengan role ini.
+func (r *RoleRepository) DeleteRole(ctx context.Context, id uuid.UUID) error {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM roles WHERE id = $1`, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return ErrRoleInUse
+		}
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// SetRolePermissions mengganti seluruh permission role, sql.ErrNoRows jika role tidak ada
+func (r *RoleRepository) SetRolePermissions(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Mengunci role agar dua perubahan matriks yang bersamaan tidak saling menimpa sebagian
+	var id uuid.UUID
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM roles WHERE id = $1 FOR UPDATE`, roleID).Scan(&id); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+		return err
+	}
+	if err := insertRolePermissions(ctx, tx, roleID, permissionIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddRolePermission memberikan satu permission ke role. Tidak error jika sudah dimiliki,
+// sql.ErrNoRows jika role atau permission tidak ada.
+func (r *RoleRepository) AddRolePermission(ctx context.Context, roleID, permissionID uuid.UUID) error {
+	_, err := r.DB.ExecContext(ctx, `
+		INSERT INTO role_permissions (role_id, permission_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, roleID, permissionID)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// RemoveRolePermission mencabut satu permission dari role, sql.ErrNoRows jika tidak dimiliki.
+// keepLast true menolak pencabutan permission terakhir dengan ErrRoleNeedsPermission (role bawaan).
+func (r *RoleRepository) RemoveRolePermission(ctx context.Context, roleID, permissionID uuid.UUID, keepLast bool) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Dikunci seperti SetRolePermissions agar jumlah permission yang tersisa tidak berubah
+	var id uuid.UUID
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM roles WHERE id = $1 FOR UPDATE`, roleID).Scan(&id); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2
+	`, roleID, permissionID)
+	if err != nil {
+		return err
+	}
+	if err := requireRowsAffected(result); err != nil {
+		return err
+	}
+
+	if keepLast {
+		var remaining int
+		err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM role_permissions WHERE role_id = $1`, roleID).Scan(&remaining)
+		if err != nil {
+			return err
+		}
+		if remaining == 0 {
+			return ErrRoleNeedsPermission
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetRoleGrants semua pasangan role_permissions, dikelompokkan per role
+func (r *RoleRepository) GetRoleGrants(ctx context.Context) (map[uuid.UUID][]uuid.UUID, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT role_id, permission_id FROM role_permissions`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := map[uuid.UUID][]uuid.UUID{}
+	for rows.Next() {
+		var roleID, permissionID uuid.UUID
+		if err := rows.Scan(&roleID, &permissionID); err != nil {
+			return nil, err
+		}
+		grants[roleID] = append(grants[roleID], permissionID)
+	}
+
+	return grants, rows.Err()
+}
+
+// GetUserRoleName nama role user, sql.ErrNoRows jika user tidak ada
+func (r *RoleRepository) GetUserRoleName(ctx context.Context, userID uuid.UUID) (string, error) {
+	var name string
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT r.name FROM users u INNER JOIN roles r ON r.id = u.role_id WHERE u.id = $1
+	`, userID).Scan(&name)
+	return name, err
+}
+
+// AssignUserRole mengganti role user, sql.ErrNoRows jika user tidak ada
+func (r *RoleRepository) AssignUserRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	result, err := r.DB.ExecContext(ctx, `
+		UPDATE users SET role_id = $1, updated_at = NOW() WHERE id = $2
+	`, roleID, userID)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// ListPermissions semua permission, urut resource lalu action
+func (r *RoleRepository) ListPermissions(ctx context.Context) ([]model.Permissions, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT `+permissionColumns+`
+		FROM permissions p
+		ORDER BY p.resource, p.action
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPermissions(rows)
+}
+
+// GetPermission mengambil satu permission, sql.ErrNoRows jika tidak ada
+func (r *RoleRepository) GetPermission(ctx context.Context, id uuid.UUID) (*model.Permissions, error) {
+	var p model.Permissions
+	err := r.DB.QueryRowContext(ctx, `SELECT `+permissionColumns+` FROM permissions p WHERE p.id = $1`, id).
+		Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreatePermission membuat permission, ErrPermissionExists jika nama atau resource:action sudah ada
+func (r *RoleRepository) CreatePermission(ctx context.Context, p model.Permissions) error {
+	_, err := r.DB.ExecContext(ctx, `
+		INSERT INTO permissions (id, name, resource, action, description)
+		VALUES ($1, $2, $3, $4, $5)
+	`, p.ID, p.Name, p.Resource, p.Action, p.Description)
+	if isUniqueViolation(err) {
+		return ErrPermissionExists
+	}
+	return err
+}
+
+// UpdatePermission menyimpan deskripsi permission, sql.ErrNoRows jika tidak ada
+func (r *RoleRepository) UpdatePermission(ctx context.Context, p model.Permissions) error {
+	result, err := r.DB.ExecContext(ctx, `UPDATE permissions SET description = $1 WHERE id = $2`, p.Description, p.ID)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// DeletePermission menghapus permission dan mencabutnya dari semua role
+func (r *RoleRepository) DeletePermission(ctx context.Context, id uuid.UUID) error {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM permissions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+func insertRolePermissions(ctx context.Context, tx *sql.Tx, roleID uuid.UUID, permissionIDs []uuid.UUID) error {
+	if len(permissionIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(permissionIDs))
+	for i, id := range permissionIDs {
+		ids[i] = id.String()
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING
+	`, roleID, pq.Array(ids))
+	return err
+}
+
+func scanPermissions(rows *sql.Rows) ([]model.Permissions, error) {
+	permissions := []model.Permissions{}
+	for rows.Next() {
+		var p model.Permissions
+		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, p)
+	}
+
+	return permissions, rows.Err()
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
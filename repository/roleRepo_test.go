@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"POJECT_UAS/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleRepository_GetRole(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoleRepository(db)
+	roleID, permissionID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`SELECT r.id, r.name, r.description, r.created_at,\s+\(SELECT COUNT\(\*\) FROM users u WHERE u.role_id = r.id\)\s+FROM roles r\s+WHERE r.id = \$1`).
+		WithArgs(roleID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "count"}).
+			AddRow(roleID, "kaprodi", "Ketua program studi", time.Now(), 3))
+	mock.ExpectQuery(`FROM permissions p\s+INNER JOIN role_permissions rp`).
+		WithArgs(roleID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "resource", "action", "description"}).
+			AddRow(permissionID, "reports:read", "reports", "read", "Melihat laporan dan statistik"))
+
+	// Execute
+	role, err := repo.GetRole(context.Background(), roleID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "kaprodi", role.Name)
+	assert.Equal(t, int64(3), role.UserCount)
+	assert.Len(t, role.Permissions, 1)
+	assert.Equal(t, "reports:read", role.Permissions[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_CreateRole(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoleRepository(db)
+	role := model.Roles{ID: uuid.New(), Name: "kaprodi", CreatedAt: time.Now()}
+	permissionID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO roles`).
+		WithArgs(role.ID, role.Name, role.Description, role.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO role_permissions \(role_id, permission_id\)\s+SELECT \$1, unnest\(\$2::uuid\[\]\)`).
+		WithArgs(role.ID, pq.Array([]string{permissionID.String()})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	err = repo.CreateRole(context.Background(), role, []uuid.UUID{permissionID})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_CreateRole_Duplicate(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoleRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO roles`).WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	// Execute
+	err = repo.CreateRole(context.Background(), model.Roles{ID: uuid.New(), Name: "admin"}, nil)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrRoleExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_DeleteRole_InUse(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoleRepository(db)
+
+	mock.ExpectExec(`DELETE FROM roles WHERE id = \$1`).WillReturnError(&pq.Error{Code: "23503"})
+	mock.ExpectExec(`DELETE FROM roles WHERE id = \$1`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute
+	inUseErr := repo.DeleteRole(context.Background(), uuid.New())
+	missingErr := repo.DeleteRole(context.Background(), uuid.New())
+
+	// Assert
+	assert.ErrorIs(t, inUseErr, ErrRoleInUse)
+	assert.ErrorIs(t, missingErr, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_SetRolePermissions(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoleRepository(db)
+	roleID := uuid.New()
+
+	// Role ada: permission lama dihapus, daftar kosong berarti tanpa permission
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM roles WHERE id = \$1 FOR UPDATE`).
+		WithArgs(roleID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(roleID))
+	mock.ExpectExec(`DELETE FROM role_permissions WHERE role_id = \$1`).
+		WithArgs(roleID).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectCommit()
+
+	// Role tidak ada
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM roles WHERE id = \$1 FOR UPDATE`).WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	// Execute
+	err = repo.SetRolePermissions(context.Background(), roleID, nil)
+	missingErr := repo.SetRolePermissions(context.Background(), uuid.New(), nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.ErrorIs(t, missingErr, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_RemoveRolePermission_KeepsLastPermission(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoleRepository(db)
+	roleID, permissionID := uuid.New(), uuid.New()
+
+	// Permission terakhir role bawaan: penghapusan dibatalkan
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM roles WHERE id = \$1 FOR UPDATE`).
+		WithArgs(roleID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(roleID))
+	mock.ExpectExec(`DELETE FROM role_permissions WHERE role_id = \$1 AND permission_id = \$2`).
+		WithArgs(roleID, permissionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM role_permissions WHERE role_id = \$1`).
+		WithArgs(roleID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+
+	// Role custom boleh tanpa permission
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM roles WHERE id = \$1 FOR UPDATE`).
+		WithArgs(roleID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(roleID))
+	mock.ExpectExec(`DELETE FROM role_permissions`).
+		WithArgs(roleID, permissionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	systemErr := repo.RemoveRolePermission(context.Background(), roleID, permissionID, true)
+	customErr := repo.RemoveRolePermission(context.Background(), roleID, permissionID, false)
+
+	// Assert
+	assert.ErrorIs(t, systemErr, ErrRoleNeedsPermission)
+	assert.NoError(t, customErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_AddRolePermission_MissingReference(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoleRepository(db)
+
+	mock.ExpectExec(`INSERT INTO role_permissions`).WillReturnError(&pq.Error{Code: "23503"})
+	mock.ExpectExec(`INSERT INTO permissions`).WillReturnError(&pq.Error{Code: "23505"})
+
+	// Execute
+	addErr := repo.AddRolePermission(context.Background(), uuid.New(), uuid.New())
+	createErr := repo.CreatePermission(context.Background(), model.Permissions{ID: uuid.New(), Name: "users:read"})
+
+	// Assert
+	assert.ErrorIs(t, addErr, sql.ErrNoRows)
+	assert.ErrorIs(t, createErr, ErrPermissionExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// statisticsMongoBatch jumlah ObjectID per $in di pipeline statistik, menjaga ukuran
+// command di bawah batas 16MB dokumen BSON
+const statisticsMongoBatch = 10000
+
+// levelUnknown level untuk prestasi yang details-nya tidak memuat tingkat
+const levelUnknown = "unknown"
+
+// GetAchievementStatistics mengambil statistik prestasi (FR-011). Penghitungan dilakukan
+// di database: ringkasan, periode dan top students dengan GROUP BY di PostgreSQL, tipe
+// dan level dengan pipeline $group di MongoDB. Filter achievement_type dipakai di $match
+// sebelum dihitung, sehingga hanya memengaruhi total_by_type dan competition_levels.
+func (r *AchievementRepository) GetAchievementStatistics(
+	studentIDs []uuid.UUID, // kosong jika admin (all students)
+	filter model.StatisticsRequest,
+) (*model.AchievementStatistics, error) {
+	ctx := context.Background()
+	where, args := statisticsWhere(studentIDs, filter)
+
+	summary, err := r.statisticsSummary(ctx, where, args)
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := r.statisticsByPeriod(ctx, where, args, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	topStudents, err := r.statisticsTopStudents(ctx, where, args)
+	if err != nil {
+		return nil, err
+	}
+
+	typeCounts, levelCounts, err := r.statisticsByTypeAndLevel(ctx, where, args, filter.AchievementType)
+	if err != nil {
+		// Sama seperti sebelumnya, statistik tetap dikembalikan tanpa tipe/level jika MongoDB gagal
+		log.Printf("statistik: gagal menghitung tipe dan level: %v", err)
+		typeCounts, levelCounts = nil, nil
+	}
+
+	return &model.AchievementStatistics{
+		Summary:           *summary,
+		TotalByType:       typeStatistics(typeCounts),
+		TotalByPeriod:     periods,
+		TopStudents:       topStudents,
+		CompetitionLevels: levelStatistics(levelCounts),
+	}, nil
+}
+
+// statisticsWhere filter achievement_references (alias ar) untuk semua query statistik
+func statisticsWhere(studentIDs []uuid.UUID, filter model.StatisticsRequest) (string, []interface{}) {
+	where := "WHERE 1=1"
+	var args []interface{}
+
+	// Filter by student IDs (untuk dosen wali atau mahasiswa)
+	if len(studentIDs) > 0 {
+		args = append(args, pq.Array(studentIDs))
+		where += " AND ar.student_id = ANY($" + strconv.Itoa(len(args)) + ")"
+	}
+	if filter.StartDate != nil {
+		args = append(args, *filter.StartDate)
+		where += " AND ar.created_at >= $" + strconv.Itoa(len(args))
+	}
+	if filter.EndDate != nil {
+		args = append(args, *filter.EndDate)
+		where += " AND ar.created_at <= $" + strconv.Itoa(len(args))
+	}
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		where += " AND ar.status = $" + strconv.Itoa(len(args))
+	}
+
+	return where, args
+}
+
+func (r *AchievementRepository) statisticsSummary(ctx context.Context, where string, args []interface{}) (*model.StatisticSummary, error) {
+	query := `
+		SELECT
+			COUNT(*) as total,
+			COUNT(CASE WHEN ar.status = 'verified' THEN 1 END) as verified,
+			COUNT(CASE WHEN ar.status = 'submitted' THEN 1 END) as pending,
+			COUNT(CASE WHEN ar.status = 'rejected' THEN 1 END) as rejected,
+			COUNT(DISTINCT ar.student_id) as total_students,
+			MIN(ar.created_at) as min_date,
+			MAX(ar.created_at) as max_date
+		FROM achievement_references ar
+	` + where
+
+	var summary model.StatisticSummary
+	var minDate, maxDate sql.NullTime
+	err := r.PostgresDB.QueryRowContext(ctx, query, args...).Scan(
+		&summary.TotalAchievements,
+		&summary.VerifiedAchievements,
+		&summary.PendingAchievements,
+		&summary.RejectedAchievements,
+		&summary.TotalStudents,
+		&minDate,
+		&maxDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if minDate.Valid && maxDate.Valid {
+		summary.DateRange = model.DateRange{
+			StartDate: minDate.Time,
+			EndDate:   maxDate.Time,
+		}
+	}
+
+	return &summary, nil
+}
+
+// statisticsByPeriod jumlah prestasi per periode. PostgreSQL menghitung per tanggal
+// (zona waktu sesi), lalu tanggal dikelompokkan ke periode sesuai filter.PeriodType.
+func (r *AchievementRepository) statisticsByPeriod(ctx context.Context, where string, args []interface{}, filter model.StatisticsRequest) ([]model.PeriodStatistic, error) {
+	rows, err := r.PostgresDB.QueryContext(ctx, `
+		SELECT ar.created_at::date as day, COUNT(*)
+		FROM achievement_references ar
+	`+where+`
+		GROUP BY day
+		ORDER BY day`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var daily []model.DailyCount
+	for rows.Next() {
+		var day model.DailyCount
+		if err := rows.Scan(&day.Date, &day.Count); err != nil {
+			return nil, err
+		}
+		daily = append(daily, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return model.BucketPeriods(filter.PeriodType, filter.Calendar, daily, filter.StartDate, filter.EndDate), nil
+}
+
+// statisticsTopStudents jumlah prestasi per mahasiswa. Mahasiswa tanpa NIM atau nama
+// tidak ikut dihitung. Urutan dan limit diatur oleh service.
+func (r *AchievementRepository) statisticsTopStudents(ctx context.Context, where string, args []interface{}) ([]model.TopStudent, error) {
+	rows, err := r.PostgresDB.QueryContext(ctx, `
+		SELECT ar.student_id, s.student_id, u.full_name,
+		       COALESCE(s.program_study, ''), COALESCE(s.academic_year, ''),
+		       COUNT(*) as total,
+		       COUNT(*) FILTER (WHERE ar.status = 'verified') as verified
+		FROM achievement_references ar
+		JOIN students s ON ar.student_id = s.id
+		JOIN users u ON s.user_id = u.id
+	`+where+` AND s.student_id IS NOT NULL AND u.full_name IS NOT NULL
+		GROUP BY ar.student_id, s.student_id, u.full_name, s.program_study, s.academic_year
+		ORDER BY total DESC, verified DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var students []model.TopStudent
+	for rows.Next() {
+		var student model.TopStudent
+		err := rows.Scan(&student.StudentID, &student.StudentIDNumber, &student.FullName,
+			&student.ProgramStudy, &student.AcademicYear, &student.TotalCount, &student.VerifiedCount)
+		if err != nil {
+			return nil, err
+		}
+		students = append(students, student)
+	}
+
+	return students, rows.Err()
+}
+
+// statisticsByTypeAndLevel jumlah prestasi per tipe dan per level. ID prestasi dibaca
+// dari PostgreSQL secara streaming lalu dihitung per batch dengan pipeline $group,
+// sehingga dokumen prestasi tidak pernah dimuat ke aplikasi.
+func (r *AchievementRepository) statisticsByTypeAndLevel(
+	ctx context.Context,
+	where string,
+	args []interface{},
+	achievementType *string,
+) (map[string]int64, map[string]int64, error) {
+	rows, err := r.PostgresDB.QueryContext(ctx, `
+		SELECT ar.mongo_achievement_id
+		FROM achievement_references ar
+	`+where, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	typeCounts := make(map[string]int64)
+	levelCounts := make(map[string]int64)
+	batch := make([]primitive.ObjectID, 0, statisticsMongoBatch)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := r.countTypesAndLevels(ctx, batch, achievementType, typeCounts, levelCounts)
+		batch = batch[:0]
+		return err
+	}
+
+	for rows.Next() {
+		var mongoID string
+		if err := rows.Scan(&mongoID); err != nil {
+			return nil, nil, err
+		}
+		objectID, err := primitive.ObjectIDFromHex(mongoID)
+		if err != nil {
+			continue
+		}
+		batch = append(batch, objectID)
+		if len(batch) == statisticsMongoBatch {
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+
+	return typeCounts, levelCounts, nil
+}
+
+// countTypesAndLevels menjalankan pipeline untuk satu batch dan menambahkan hasilnya
+func (r *AchievementRepository) countTypesAndLevels(
+	ctx context.Context,
+	objectIDs []primitive.ObjectID,
+	achievementType *string,
+	typeCounts, levelCounts map[string]int64,
+) error {
+	if r.MongoDB == nil {
+		return errors.New("MongoDB connection required")
+	}
+
+	cursor, err := r.MongoDB.Collection("achievements").Aggregate(ctx, typeLevelPipeline(objectIDs, achievementType))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var group struct {
+			ID struct {
+				Type  string `bson:"type"`
+				Level string `bson:"level"`
+			} `bson:"_id"`
+			Count int64 `bson:"count"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return err
+		}
+		typeCounts[group.ID.Type] += group.Count
+		levelCounts[group.ID.Level] += group.Count
+	}
+
+	return cursor.Err()
+}
+
+// typeLevelPipeline pipeline $group per (achievementType, level). Level dibaca dari field
+// details sesuai achievement_type (model.AchievementLevelField), selain string tidak
+// kosong dihitung sebagai "unknown".
+func typeLevelPipeline(objectIDs []primitive.ObjectID, achievementType *string) primitive.A {
+	match := primitive.M{"_id": primitive.M{"$in": objectIDs}}
+	if achievementType != nil && *achievementType != "" {
+		match["achievementType"] = *achievementType
+	}
+
+	var branches primitive.A
+	for _, t := range model.AchievementTypes() {
+		field, _ := model.AchievementLevelField(t)
+		branches = append(branches, primitive.M{
+			"case": primitive.M{"$eq": primitive.A{"$achievementType", t}},
+			"then": "$details." + field,
+		})
+	}
+
+	return primitive.A{
+		primitive.M{"$match": match},
+		primitive.M{"$project": primitive.M{
+			"type": primitive.M{"$ifNull": primitive.A{"$achievementType", ""}},
+			"level": primitive.M{"$switch": primitive.M{
+				"branches": branches,
+				"default":  nil,
+			}},
+		}},
+		primitive.M{"$group": primitive.M{
+			"_id": primitive.M{
+				"type": "$type",
+				"level": primitive.M{"$cond": primitive.A{
+					primitive.M{"$and": primitive.A{
+						primitive.M{"$eq": primitive.A{primitive.M{"$type": "$level"}, "string"}},
+						primitive.M{"$ne": primitive.A{"$level", ""}},
+					}},
+					"$level",
+					levelUnknown,
+				}},
+			},
+			"count": primitive.M{"$sum": 1},
+		}},
+	}
+}
+
+func typeStatistics(counts map[string]int64) []model.TypeStatistic {
+	var stats []model.TypeStatistic
+	total := sumCounts(counts)
+	for achievementType, count := range counts {
+		stats = append(stats, model.TypeStatistic{
+			AchievementType: achievementType,
+			Count:           count,
+			Percentage:      percentage(count, total),
+		})
+	}
+	return stats
+}
+
+func levelStatistics(counts map[string]int64) []model.LevelStatistic {
+	var stats []model.LevelStatistic
+	total := sumCounts(counts)
+	for level, count := range counts {
+		stats = append(stats, model.LevelStatistic{
+			Level:      level,
+			Count:      count,
+			Percentage: percentage(count, total),
+		})
+	}
+	return stats
+}
+
+func sumCounts(counts map[string]int64) int64 {
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
+
+func percentage(count, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total) * 100
+}
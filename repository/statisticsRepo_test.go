@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"POJECT_UAS/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStatisticsWhere_NumbersArguments(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	status := "verified"
+	studentIDs := []uuid.UUID{uuid.New()}
+
+	// Execute
+	where, args := statisticsWhere(studentIDs, model.StatisticsRequest{StartDate: &start, Status: &status})
+
+	// Assert
+	assert.Equal(t, "WHERE 1=1 AND ar.student_id = ANY($1) AND ar.created_at >= $2 AND ar.status = $3", where)
+	assert.Equal(t, []interface{}{pq.Array(studentIDs), start, status}, args)
+}
+
+func TestAchievementRepository_GetAchievementStatistics_GroupsInSQL(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	status := "verified"
+	studentID := uuid.New()
+	minDate := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	maxDate := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT\s+COUNT\(\*\) as total,.+FROM achievement_references ar\s+WHERE 1=1 AND ar.status = \$1`).
+		WithArgs(status).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "verified", "pending", "rejected", "total_students", "min_date", "max_date"}).
+			AddRow(3, 3, 0, 0, 1, minDate, maxDate))
+	mock.ExpectQuery(`SELECT ar.created_at::date as day, COUNT\(\*\).+GROUP BY day`).
+		WithArgs(status).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "count"}).
+			AddRow(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), 1).
+			AddRow(time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), 1).
+			AddRow(time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC), 1))
+	mock.ExpectQuery(`FROM achievement_references ar\s+JOIN students s .+ AND s.student_id IS NOT NULL AND u.full_name IS NOT NULL\s+GROUP BY ar.student_id`).
+		WithArgs(status).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "student_id", "full_name", "program_study", "academic_year", "total", "verified"}).
+			AddRow(studentID, "434221001", "Demo Student", "Teknik Informatika", "2022", 3, 3))
+	mock.ExpectQuery(`SELECT ar.mongo_achievement_id\s+FROM achievement_references ar`).
+		WithArgs(status).
+		WillReturnRows(sqlmock.NewRows([]string{"mongo_achievement_id"}))
+
+	// Execute
+	statistics, err := achievementRepo.GetAchievementStatistics(nil, model.StatisticsRequest{Status: &status})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), statistics.Summary.TotalAchievements)
+	assert.Equal(t, minDate, statistics.Summary.DateRange.StartDate)
+
+	// Bulan tanpa data diisi nol
+	assert.Len(t, statistics.TotalByPeriod, 3)
+	assert.Equal(t, "2024-01", statistics.TotalByPeriod[0].Period)
+	assert.Equal(t, int64(2), statistics.TotalByPeriod[0].Count)
+	assert.Equal(t, "2024-02", statistics.TotalByPeriod[1].Period)
+	assert.Equal(t, int64(0), statistics.TotalByPeriod[1].Count)
+	assert.Equal(t, "2024-03", statistics.TotalByPeriod[2].Period)
+	assert.Equal(t, 3, *statistics.TotalByPeriod[2].Month)
+	assert.Equal(t, []model.TopStudent{{
+		StudentID:       studentID,
+		StudentIDNumber: "434221001",
+		FullName:        "Demo Student",
+		ProgramStudy:    "Teknik Informatika",
+		AcademicYear:    "2022",
+		TotalCount:      3,
+		VerifiedCount:   3,
+	}}, statistics.TopStudents)
+	assert.Nil(t, statistics.TotalByType)
+	assert.Nil(t, statistics.CompetitionLevels)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementRepository_GetAchievementStatistics_MongoUnavailable(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+
+	mock.ExpectQuery(`COUNT\(DISTINCT ar.student_id\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "verified", "pending", "rejected", "total_students", "min_date", "max_date"}).
+			AddRow(1, 0, 1, 0, 1, time.Now(), time.Now()))
+	mock.ExpectQuery(`GROUP BY day`).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "count"}).AddRow(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), 1))
+	mock.ExpectQuery(`GROUP BY ar.student_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "student_id", "full_name", "program_study", "academic_year", "total", "verified"}))
+	mock.ExpectQuery(`SELECT ar.mongo_achievement_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"mongo_achievement_id"}).AddRow(primitive.NewObjectID().Hex()))
+
+	// Execute
+	statistics, err := achievementRepo.GetAchievementStatistics(nil, model.StatisticsRequest{})
+
+	// Assert: tipe dan level kosong, bagian lain tetap dikembalikan
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), statistics.Summary.TotalAchievements)
+	assert.Len(t, statistics.TotalByPeriod, 1)
+	assert.Nil(t, statistics.TotalByType)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTypeLevelPipeline(t *testing.T) {
+	objectIDs := []primitive.ObjectID{primitive.NewObjectID()}
+	achievementType := "competition"
+
+	// Execute
+	pipeline := typeLevelPipeline(objectIDs, &achievementType)
+
+	// Assert: filter tipe ada di $match, sebelum $group
+	assert.Len(t, pipeline, 3)
+	match := pipeline[0].(primitive.M)["$match"].(primitive.M)
+	assert.Equal(t, primitive.M{"$in": objectIDs}, match["_id"])
+	assert.Equal(t, "competition", match["achievementType"])
+
+	level := pipeline[1].(primitive.M)["$project"].(primitive.M)["level"].(primitive.M)["$switch"].(primitive.M)
+	branches := level["branches"].(primitive.A)
+	assert.Len(t, branches, len(model.AchievementTypes()))
+	assert.Contains(t, branches, primitive.M{
+		"case": primitive.M{"$eq": primitive.A{"$achievementType", "competition"}},
+		"then": "$details.competitionLevel",
+	})
+
+	_, grouped := pipeline[2].(primitive.M)["$group"]
+	assert.True(t, grouped)
+}
+
+func TestTypeLevelPipeline_NoTypeFilter(t *testing.T) {
+	empty := ""
+
+	pipeline := typeLevelPipeline(nil, &empty)
+
+	match := pipeline[0].(primitive.M)["$match"].(primitive.M)
+	_, filtered := match["achievementType"]
+	assert.False(t, filtered)
+}
+
+func TestTypeAndLevelStatistics_Percentage(t *testing.T) {
+	// Execute
+	types := typeStatistics(map[string]int64{"competition": 3, "academic": 1})
+	levels := levelStatistics(map[string]int64{})
+
+	// Assert
+	assert.ElementsMatch(t, []model.TypeStatistic{
+		{AchievementType: "competition", Count: 3, Percentage: 75},
+		{AchievementType: "academic", Count: 1, Percentage: 25},
+	}, types)
+	assert.Nil(t, levels)
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+// StudentReportRepository arsip laporan prestasi mahasiswa yang sudah diterbitkan
+type StudentReportRepository struct {
+	DB *sql.DB
+}
+
+func NewStudentReportRepository(db *sql.DB) *StudentReportRepository {
+	return &StudentReportRepository{DB: db}
+}
+
+// Create menyimpan laporan yang sudah ditandatangani
+func (r *StudentReportRepository) Create(ctx context.Context, report *model.IssuedStudentReport) error {
+	query := `
+		INSERT INTO student_reports (id, student_id, issued_by, content, content_hash, signature, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.DB.ExecContext(ctx, query,
+		report.ID,
+		report.StudentID,
+		report.IssuedBy,
+		string(report.Content),
+		report.ContentHash,
+		report.Signature,
+		report.IssuedAt,
+	)
+	return err
+}
+
+// GetByID mengambil laporan, sql.ErrNoRows jika tidak ada
+func (r *StudentReportRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.IssuedStudentReport, error) {
+	var report model.IssuedStudentReport
+	var issuedBy uuid.NullUUID
+	var content string
+
+	query := `
+		SELECT id, student_id, issued_by, content, content_hash, signature, issued_at
+		FROM student_reports
+		WHERE id = $1
+	`
+
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(
+		&report.ID,
+		&report.StudentID,
+		&issuedBy,
+		&content,
+		&report.ContentHash,
+		&report.Signature,
+		&report.IssuedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	report.IssuedBy = issuedBy.UUID
+	report.Content = []byte(content)
+	return &report, nil
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token tidak valid")
+	ErrRefreshTokenExpired = errors.New("refresh token sudah kedaluwarsa")
+	ErrRefreshTokenReused  = errors.New("refresh token sudah pernah digunakan, semua sesi terkait dicabut")
+	ErrAccountDisabled     = errors.New("akun anda dinonaktifkan")
+)
+
+type TokenRepository struct {
+	DB *sql.DB
+}
+
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{DB: db}
+}
+
+// CreateRefreshToken membuat refresh token baru untuk family baru (dipakai saat login)
+func (r *TokenRepository) CreateRefreshToken(userID uuid.UUID, ttl time.Duration) (string, error) {
+	rawToken, tokenHash, err := generateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = r.DB.Exec(query, uuid.New(), userID, uuid.New(), tokenHash, now.Add(ttl), now)
+	if err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// RotateRefreshToken menukar refresh token lama dengan yang baru dalam family yang sama.
+// Jika token lama ternyata sudah pernah dirotasi/dicabut, seluruh family dicabut (reuse detection).
+func (r *TokenRepository) RotateRefreshToken(rawToken string, ttl time.Duration) (string, uuid.UUID, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	var current model.RefreshToken
+	query := `
+		SELECT id, user_id, family_id, expires_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+
+	err = tx.QueryRow(query, hashRefreshToken(rawToken)).Scan(
+		&current.ID,
+		&current.UserID,
+		&current.FamilyID,
+		&current.ExpiresAt,
+		&current.RevokedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", uuid.Nil, ErrInvalidRefreshToken
+		}
+		return "", uuid.Nil, err
+	}
+
+	now := time.Now()
+
+	// Token yang sudah dirotasi muncul lagi: kemungkinan dicuri, cabut seluruh family
+	if current.RevokedAt != nil {
+		revokeQuery := `
+			UPDATE refresh_tokens
+			SET revoked_at = $1
+			WHERE family_id = $2 AND revoked_at IS NULL
+		`
+		if _, err := tx.Exec(revokeQuery, now, current.FamilyID); err != nil {
+			return "", uuid.Nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return "", uuid.Nil, err
+		}
+		return "", uuid.Nil, ErrRefreshTokenReused
+	}
+
+	if now.After(current.ExpiresAt) {
+		return "", uuid.Nil, ErrRefreshTokenExpired
+	}
+
+	// User yang dinonaktifkan tidak boleh mendapat refresh token baru
+	var isActive bool
+	err = tx.QueryRow(`SELECT is_active FROM users WHERE id = $1`, current.UserID).Scan(&isActive)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", uuid.Nil, ErrInvalidRefreshToken
+		}
+		return "", uuid.Nil, err
+	}
+	if !isActive {
+		return "", uuid.Nil, ErrAccountDisabled
+	}
+
+	newRawToken, newTokenHash, err := generateRefreshToken()
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	newID := uuid.New()
+
+	insertQuery := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err = tx.Exec(insertQuery, newID, current.UserID, current.FamilyID, newTokenHash, now.Add(ttl), now)
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+
+	rotateQuery := `
+		UPDATE refresh_tokens
+		SET revoked_at = $1, replaced_by = $2
+		WHERE id = $3
+	`
+	_, err = tx.Exec(rotateQuery, now, newID, current.ID)
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", uuid.Nil, err
+	}
+
+	return newRawToken, current.UserID, nil
+}
+
+// generateRefreshToken membuat token acak (opaque) beserta hash yang disimpan di database
+func generateRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	rawToken := base64.RawURLEncoding.EncodeToString(buf)
+	return rawToken, hashRefreshToken(rawToken), nil
+}
+
+// hashRefreshToken menghasilkan SHA-256 hex dari refresh token; token asli tidak pernah disimpan
+func hashRefreshToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// RevokeAccessToken memasukkan jti access token ke daftar pencabutan sampai token tersebut kedaluwarsa
+func (r *TokenRepository) RevokeAccessToken(jti string, userID uuid.UUID, expiresAt time.Time) error {
+	now := time.Now()
+
+	query := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (jti) DO NOTHING
+	`
+
+	_, err := r.DB.Exec(query, jti, userID, expiresAt, now)
+	return err
+}
+
+// DeleteExpiredRevokedTokens menghapus entry yang tokennya sudah kedaluwarsa, tidak perlu dicek lagi
+func (r *TokenRepository) DeleteExpiredRevokedTokens(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// IsAccessTokenRevoked mengecek apakah jti ada di daftar pencabutan
+func (r *TokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM revoked_tokens WHERE jti = $1`
+
+	err := r.DB.QueryRow(query, jti).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// RevokeUserSessions mencabut semua sesi user: access token yang terbit sebelum saat ini
+// dan semua refresh token yang masih aktif
+func (r *TokenRepository) RevokeUserSessions(userID uuid.UUID) (time.Time, error) {
+	now := time.Now()
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	cutoffQuery := `
+		INSERT INTO user_session_revocations (user_id, revoked_before)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET revoked_before = EXCLUDED.revoked_before
+	`
+	if _, err := tx.Exec(cutoffQuery, userID, now); err != nil {
+		return time.Time{}, err
+	}
+
+	refreshQuery := `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL
+	`
+	if _, err := tx.Exec(refreshQuery, now, userID); err != nil {
+		return time.Time{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, err
+	}
+
+	return now, nil
+}
+
+// GetUserSessionsRevokedAt mengambil batas waktu pencabutan sesi user (nil jika belum pernah dicabut)
+func (r *TokenRepository) GetUserSessionsRevokedAt(userID uuid.UUID) (*time.Time, error) {
+	var revokedBefore time.Time
+	query := `SELECT revoked_before FROM user_session_revocations WHERE user_id = $1`
+
+	err := r.DB.QueryRow(query, userID).Scan(&revokedBefore)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &revokedBefore, nil
+}
+
+// RevokeRefreshToken mencabut family dari refresh token yang diberikan (dipakai saat logout).
+// Hanya token milik userID yang dicabut agar user tidak bisa mencabut sesi user lain.
+func (r *TokenRepository) RevokeRefreshToken(rawToken string, userID uuid.UUID) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE revoked_at IS NULL AND user_id = $3 AND family_id = (
+			SELECT family_id FROM refresh_tokens WHERE token_hash = $2 AND user_id = $3
+		)
+	`
+
+	_, err := r.DB.Exec(query, time.Now(), hashRefreshToken(rawToken), userID)
+	return err
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenRepository_CreateRefreshToken_StoresHashOnly(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectExec(`INSERT INTO refresh_tokens`).
+		WithArgs(sqlmock.AnyArg(), userID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// Execute
+	rawToken, err := tokenRepo.CreateRefreshToken(userID, time.Hour)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, rawToken)
+	assert.NotEqual(t, rawToken, hashRefreshToken(rawToken))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_RotateRefreshToken_Success(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+
+	// Mock data
+	tokenID := uuid.New()
+	userID := uuid.New()
+	familyID := uuid.New()
+	rawToken := "old-refresh-token"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, family_id, expires_at, revoked_at FROM refresh_tokens WHERE token_hash = \$1 FOR UPDATE`).
+		WithArgs(hashRefreshToken(rawToken)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "family_id", "expires_at", "revoked_at"}).
+			AddRow(tokenID, userID, familyID, time.Now().Add(time.Hour), nil))
+	mock.ExpectQuery(`SELECT is_active FROM users WHERE id = \$1`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"is_active"}).AddRow(true))
+	mock.ExpectExec(`INSERT INTO refresh_tokens`).
+		WithArgs(sqlmock.AnyArg(), userID, familyID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at = \$1, replaced_by = \$2 WHERE id = \$3`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), tokenID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	newToken, gotUserID, err := tokenRepo.RotateRefreshToken(rawToken, time.Hour)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, newToken)
+	assert.NotEqual(t, rawToken, newToken)
+	assert.Equal(t, userID, gotUserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_RotateRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+
+	// Mock data - token sudah pernah dirotasi
+	familyID := uuid.New()
+	revokedAt := time.Now().Add(-time.Minute)
+	rawToken := "rotated-refresh-token"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, family_id, expires_at, revoked_at FROM refresh_tokens`).
+		WithArgs(hashRefreshToken(rawToken)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "family_id", "expires_at", "revoked_at"}).
+			AddRow(uuid.New(), uuid.New(), familyID, time.Now().Add(time.Hour), revokedAt))
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at = \$1 WHERE family_id = \$2 AND revoked_at IS NULL`).
+		WithArgs(sqlmock.AnyArg(), familyID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// Execute
+	newToken, _, err := tokenRepo.RotateRefreshToken(rawToken, time.Hour)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Empty(t, newToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_RotateRefreshToken_Expired(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+	rawToken := "expired-refresh-token"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, family_id, expires_at, revoked_at FROM refresh_tokens`).
+		WithArgs(hashRefreshToken(rawToken)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "family_id", "expires_at", "revoked_at"}).
+			AddRow(uuid.New(), uuid.New(), uuid.New(), time.Now().Add(-time.Hour), nil))
+	mock.ExpectRollback()
+
+	// Execute
+	_, _, err = tokenRepo.RotateRefreshToken(rawToken, time.Hour)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrRefreshTokenExpired)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_RotateRefreshToken_AccountDisabled(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+	userID := uuid.New()
+	rawToken := "disabled-user-refresh-token"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, family_id, expires_at, revoked_at FROM refresh_tokens`).
+		WithArgs(hashRefreshToken(rawToken)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "family_id", "expires_at", "revoked_at"}).
+			AddRow(uuid.New(), userID, uuid.New(), time.Now().Add(time.Hour), nil))
+	mock.ExpectQuery(`SELECT is_active FROM users WHERE id = \$1`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"is_active"}).AddRow(false))
+	mock.ExpectRollback()
+
+	// Execute: token lama tidak dirotasi
+	newToken, _, err := tokenRepo.RotateRefreshToken(rawToken, time.Hour)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrAccountDisabled)
+	assert.Empty(t, newToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_RotateRefreshToken_Unknown(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, family_id, expires_at, revoked_at FROM refresh_tokens`).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	// Execute
+	_, _, err = tokenRepo.RotateRefreshToken("unknown-token", time.Hour)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_RevokeUserSessions_Success(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO user_session_revocations`).
+		WithArgs(userID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at = \$1 WHERE user_id = \$2 AND revoked_at IS NULL`).
+		WithArgs(sqlmock.AnyArg(), userID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	// Execute
+	revokedBefore, err := tokenRepo.RevokeUserSessions(userID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, revokedBefore.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_GetUserSessionsRevokedAt_NeverRevoked(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+	userID := uuid.New()
+
+	mock.ExpectQuery(`SELECT revoked_before FROM user_session_revocations WHERE user_id = \$1`).
+		WithArgs(userID).
+		WillReturnError(sql.ErrNoRows)
+
+	// Execute
+	revokedBefore, err := tokenRepo.GetUserSessionsRevokedAt(userID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, revokedBefore)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_IsAccessTokenRevoked(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM revoked_tokens WHERE jti = \$1`).
+		WithArgs("revoked-jti").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	// Execute
+	revoked, err := tokenRepo.IsAccessTokenRevoked("revoked-jti")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_RevokeAccessToken_DoesNotCleanup(t *testing.T) {
+	// Setup mock database: pembersihan dijalankan worker, bukan setiap logout
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+	userID := uuid.New()
+	expiresAt := time.Now().Add(time.Minute)
+
+	mock.ExpectExec(`INSERT INTO revoked_tokens`).
+		WithArgs("logout-jti", userID, expiresAt, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute
+	err = tokenRepo.RevokeAccessToken("logout-jti", userID, expiresAt)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_DeleteExpiredRevokedTokens(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec(`DELETE FROM revoked_tokens WHERE expires_at < \$1`).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	// Execute
+	deleted, err := tokenRepo.DeleteExpiredRevokedTokens(t.Context(), now)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_RevokeRefreshToken_OnlyOwnSessions(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tokenRepo := NewTokenRepository(db)
+	userID := uuid.New()
+	rawToken := "logout-refresh-token"
+
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at = \$1 WHERE revoked_at IS NULL AND user_id = \$3 AND family_id = \( SELECT family_id FROM refresh_tokens WHERE token_hash = \$2 AND user_id = \$3 \)`).
+		WithArgs(sqlmock.AnyArg(), hashRefreshToken(rawToken), userID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute: token milik user lain tidak ikut dicabut
+	err = tokenRepo.RevokeRefreshToken(rawToken, userID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"POJECT_UAS/model"
 	"database/sql"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -128,28 +129,46 @@ func (r *UserRepository) DeleteUser(userID uuid.UUID) error {
 	return nil
 }
 
-// GetAllUsers get all users with pagination (FR-009)
-func (r *UserRepository) GetAllUsers(page, perPage int) ([]model.Users, int64, error) {
-	// Count total
-	var totalCount int64
-	countQuery := `SELECT COUNT(*) FROM users`
-	err := r.DB.QueryRow(countQuery).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, err
+// GetAllUsers get all users with pagination (FR-009). Mendukung offset dan keyset (created_at + id).
+func (r *UserRepository) GetAllUsers(page model.PageRequest) ([]model.Users, model.PageInfo, error) {
+	var info model.PageInfo
+
+	if !page.SkipCount {
+		var totalCount int64
+		countQuery := `SELECT COUNT(*) FROM users`
+		err := r.DB.QueryRow(countQuery).Scan(&totalCount)
+		if err != nil {
+			return nil, info, err
+		}
+		info.TotalItems = &totalCount
+	}
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
 	}
 
-	// Get paginated data
-	offset := (page - 1) * perPage
 	query := `
 		SELECT id, username, email, full_name, role_id, is_active, created_at, updated_at
 		FROM users
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.DB.Query(query, perPage, offset)
+	reverse := false
+	if page.UseCursor {
+		var where, orderBy string
+		where, orderBy, reverse = page.KeysetCondition("created_at", "id", true, arg)
+		if where != "" {
+			query += ` WHERE ` + where
+		}
+		query += ` ORDER BY ` + orderBy + ` LIMIT ` + arg(page.PerPage+1)
+	} else {
+		query += ` ORDER BY created_at DESC, id DESC LIMIT ` + arg(page.PerPage) + ` OFFSET ` + arg(page.Offset())
+	}
+
+	rows, err := r.DB.Query(query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, info, err
 	}
 	defer rows.Close()
 
@@ -167,12 +186,23 @@ func (r *UserRepository) GetAllUsers(page, perPage int) ([]model.Users, int64, e
 			&user.UpdatedAt,
 		)
 		if err != nil {
-			return nil, 0, err
+			return nil, info, err
 		}
 		users = append(users, user)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, info, err
+	}
+
+	if !page.UseCursor {
+		return users, info, nil
+	}
 
-	return users, totalCount, nil
+	users, keyset := keysetPage(page, users, reverse, func(user model.Users) model.Cursor {
+		return model.Cursor{CreatedAt: user.CreatedAt, ID: user.ID}
+	})
+	keyset.TotalItems = info.TotalItems
+	return users, keyset, nil
 }
 
 // CreateStudentProfile membuat profile student (FR-009)
@@ -173,17 +173,17 @@ func TestUserRepository_GetAllUsers_Success(t *testing.T) {
 		uuid.New(), "user2", "user2@example.com", "User 2", uuid.New(), true, time.Now(), time.Now(),
 	)
 
-	mock.ExpectQuery(`SELECT (.+) FROM users ORDER BY created_at DESC LIMIT \$1 OFFSET \$2`).
+	mock.ExpectQuery(`SELECT (.+) FROM users ORDER BY created_at DESC, id DESC LIMIT \$1 OFFSET \$2`).
 		WithArgs(10, 0).
 		WillReturnRows(userRows)
 
 	// Execute
-	users, totalCount, err := userRepo.GetAllUsers(1, 10)
+	users, info, err := userRepo.GetAllUsers(model.PageRequest{Page: 1, PerPage: 10})
 
 	// Assert
 	assert.NoError(t, err)
 	assert.Len(t, users, 2)
-	assert.Equal(t, int64(2), totalCount)
+	assert.Equal(t, int64(2), *info.TotalItems)
 	assert.Equal(t, "user1", users[0].Username)
 	assert.Equal(t, "user2", users[1].Username)
 
@@ -191,6 +191,43 @@ func TestUserRepository_GetAllUsers_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUserRepository_GetAllUsers_KeysetFirstPage(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	userRepo := NewUserRepository(db)
+	newest := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	userRows := sqlmock.NewRows([]string{
+		"id", "username", "email", "full_name", "role_id", "is_active", "created_at", "updated_at",
+	}).AddRow(
+		uuid.New(), "user2", "user2@example.com", "User 2", uuid.New(), true, newest, newest,
+	).AddRow(
+		uuid.New(), "user1", "user1@example.com", "User 1", uuid.New(), true, newest.AddDate(0, 0, -1), newest,
+	)
+
+	mock.ExpectQuery(`SELECT (.+) FROM users ORDER BY created_at DESC, id DESC LIMIT \$1$`).
+		WithArgs(2).
+		WillReturnRows(userRows)
+
+	// Execute
+	users, info, err := userRepo.GetAllUsers(model.PageRequest{PerPage: 1, UseCursor: true, SkipCount: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, "user2", users[0].Username)
+	assert.Nil(t, info.TotalItems)
+	assert.True(t, info.HasNext)
+	assert.False(t, info.HasPrev)
+	assert.Equal(t, newest, info.Last.CreatedAt)
+
+	// Verify all expectations were met
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserRepository_CreateStudentProfile_Success(t *testing.T) {
 	// Setup mock database
 	db, mock, err := sqlmock.New()
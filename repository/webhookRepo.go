@@ -0,0 +1,398 @@
+package repository
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultWebhookMaxAttempts = 10
+	webhookLease              = 2 * time.Minute  // delivery yang melewati lease diambil ulang
+	webhookBaseBackoff        = 30 * time.Second // jeda retry: 30s, 1m, 2m, ...
+	webhookMaxBackoff         = 6 * time.Hour
+)
+
+// WebhookRepository langganan webhook dan antrean pengirimannya
+type WebhookRepository struct {
+	DB          *sql.DB
+	MaxAttempts int // setelah gagal sebanyak ini, delivery ditandai failed
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{
+		DB:          db,
+		MaxAttempts: defaultWebhookMaxAttempts,
+	}
+}
+
+// enqueueWebhookEvent membuat delivery untuk setiap langganan aktif yang mendengarkan event
+// perubahan status ini, di transaksi yang sama dengan perubahannya. Payload disusun dari
+// achievement_references sehingga berisi data setelah perubahan. Bentuknya sama dengan model.WebhookPayload.
+func enqueueWebhookEvent(ctx context.Context, tx *sql.Tx, referenceID uuid.UUID, fromStatus, toStatus string, changedBy uuid.UUID, note *string, at time.Time) error {
+	eventType := model.WebhookEventForStatus(fromStatus, toStatus)
+	if eventType == "" {
+		return nil
+	}
+
+	var from *string
+	if fromStatus != "" {
+		from = &fromStatus
+	}
+
+	var by *uuid.UUID
+	if changedBy != uuid.Nil {
+		by = &changedBy
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries
+		(id, subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		SELECT gen_random_uuid(), s.id, $1, $2,
+		       jsonb_build_object(
+		           'id', $1::uuid,
+		           'type', $2::text,
+		           'created_at', $3::timestamptz,
+		           'data', jsonb_build_object(
+		               'achievement_reference_id', r.id,
+		               'achievement_id', r.mongo_achievement_id,
+		               'student_id', r.student_id,
+		               'status', $4::text,
+		               'previous_status', $5::text,
+		               'changed_by', $6::uuid,
+		               'note', $7::text
+		           )
+		       ),
+		       'pending', $3, $3
+		FROM webhook_subscriptions s
+		JOIN achievement_references r ON r.id = $8
+		WHERE s.is_active AND (cardinality(s.events) = 0 OR $2 = ANY(s.events))
+	`
+
+	_, err := tx.ExecContext(ctx, query, uuid.New(), eventType, at, toStatus, from, by, note, referenceID)
+	return err
+}
+
+const webhookSubscriptionColumns = `id, name, url, secret, events, is_active, created_by, created_at, updated_at`
+
+// CreateSubscription menyimpan langganan baru
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub model.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (` + webhookSubscriptionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.DB.ExecContext(
+		ctx,
+		query,
+		sub.ID,
+		sub.Name,
+		sub.URL,
+		sub.Secret,
+		pq.Array(sub.Events),
+		sub.IsActive,
+		sub.CreatedBy,
+		sub.CreatedAt,
+		sub.UpdatedAt,
+	)
+	return err
+}
+
+// ListSubscriptions semua langganan, terbaru lebih dulu
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions ORDER BY created_at DESC`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []model.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// GetSubscription mengambil satu langganan, sql.ErrNoRows jika tidak ada
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id uuid.UUID) (*model.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	sub, err := scanWebhookSubscription(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// UpdateSubscription menyimpan perubahan langganan, sql.ErrNoRows jika tidak ada
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, sub model.WebhookSubscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET name = $1, url = $2, secret = $3, events = $4, is_active = $5, updated_at = $6
+		WHERE id = $7
+	`
+
+	result, err := r.DB.ExecContext(ctx, query, sub.Name, sub.URL, sub.Secret, pq.Array(sub.Events), sub.IsActive, sub.UpdatedAt, sub.ID)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// DeleteSubscription menghapus langganan beserta riwayat pengirimannya
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+func scanWebhookSubscription(row interface{ Scan(...interface{}) error }) (model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	err := row.Scan(
+		&sub.ID,
+		&sub.Name,
+		&sub.URL,
+		&sub.Secret,
+		pq.Array(&sub.Events),
+		&sub.IsActive,
+		&sub.CreatedBy,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	)
+	if sub.Events == nil {
+		sub.Events = []string{}
+	}
+	return sub, err
+}
+
+const webhookDeliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.last_status_code, d.last_error, d.next_attempt_at, d.created_at, d.delivered_at`
+
+// ListDeliveries log pengiriman untuk satu langganan, terbaru lebih dulu. status kosong berarti semua.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit int) ([]model.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1 AND ($2 = '' OR d.status = $2)
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.DB.QueryContext(ctx, query, subscriptionID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// GetDelivery mengambil delivery beserta log percobaannya, sql.ErrNoRows jika tidak ada
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries d WHERE d.id = $1`
+
+	delivery, err := scanWebhookDelivery(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, attempt, status_code, error, response_body, duration_ms, created_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempt ASC, created_at ASC
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	delivery.AttemptLog = []model.WebhookDeliveryAttempt{}
+	for rows.Next() {
+		var attempt model.WebhookDeliveryAttempt
+		err := rows.Scan(
+			&attempt.ID,
+			&attempt.Attempt,
+			&attempt.StatusCode,
+			&attempt.Error,
+			&attempt.ResponseBody,
+			&attempt.DurationMs,
+			&attempt.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		delivery.AttemptLog = append(delivery.AttemptLog, attempt)
+	}
+
+	return &delivery, rows.Err()
+}
+
+// ClaimDue mengambil delivery yang jatuh tempo (atau lease-nya habis) milik langganan aktif
+// dan menandainya delivering. Delivery milik langganan nonaktif menunggu sampai diaktifkan lagi.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int) ([]model.WebhookDelivery, error) {
+	now := time.Now()
+
+	query := `
+		UPDATE webhook_deliveries d
+		SET status = 'delivering', attempts = d.attempts + 1, locked_until = $1
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id IN (
+			SELECT wd.id FROM webhook_deliveries wd
+			JOIN webhook_subscriptions ws ON ws.id = wd.subscription_id
+			WHERE ws.is_active
+			  AND ((wd.status = 'pending' AND wd.next_attempt_at <= $2)
+			    OR (wd.status = 'delivering' AND wd.locked_until < $2))
+			ORDER BY wd.next_attempt_at
+			LIMIT $3
+			FOR UPDATE OF wd SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns + `, s.url, s.secret`
+
+	rows, err := r.DB.QueryContext(ctx, query, now.Add(webhookLease), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+	for rows.Next() {
+		var d model.WebhookDelivery
+		var payload []byte
+		err := rows.Scan(
+			&d.ID,
+			&d.SubscriptionID,
+			&d.EventID,
+			&d.EventType,
+			&payload,
+			&d.Status,
+			&d.Attempts,
+			&d.LastStatusCode,
+			&d.LastError,
+			&d.NextAttemptAt,
+			&d.CreatedAt,
+			&d.DeliveredAt,
+			&d.URL,
+			&d.Secret,
+		)
+		if err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt mencatat hasil satu percobaan dan memperbarui status delivery: delivered,
+// dijadwalkan ulang dengan backoff, atau failed jika percobaan sudah mencapai MaxAttempts.
+// Mengembalikan true jika delivery tidak akan dicoba lagi.
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, delivery model.WebhookDelivery, attempt model.WebhookDeliveryAttempt, delivered bool) (bool, error) {
+	now := time.Now()
+	final := delivered || delivery.Attempts >= r.MaxAttempts
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_delivery_attempts
+		(id, delivery_id, attempt, status_code, error, response_body, duration_ms, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, uuid.New(), delivery.ID, delivery.Attempts, attempt.StatusCode, attempt.Error, attempt.ResponseBody, attempt.DurationMs, now)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case delivered:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = 'delivered', delivered_at = $1, locked_until = NULL, last_status_code = $2, last_error = NULL
+			WHERE id = $3
+		`, now, attempt.StatusCode, delivery.ID)
+	case final:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = 'failed', locked_until = NULL, last_status_code = $1, last_error = $2
+			WHERE id = $3
+		`, attempt.StatusCode, attempt.Error, delivery.ID)
+	default:
+		next := now.Add(retryBackoff(delivery.Attempts, webhookBaseBackoff, webhookMaxBackoff))
+		_, err = tx.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = 'pending', locked_until = NULL, next_attempt_at = $1, last_status_code = $2, last_error = $3
+			WHERE id = $4
+		`, next, attempt.StatusCode, attempt.Error, delivery.ID)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return final, tx.Commit()
+}
+
+// Redeliver mengantrekan ulang delivery (termasuk yang sudah delivered) dengan percobaan direset.
+// sql.ErrNoRows jika delivery tidak ada atau sedang dikirim.
+func (r *WebhookRepository) Redeliver(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = $1, locked_until = NULL, delivered_at = NULL
+		WHERE id = $2 AND status <> 'delivering'
+	`
+
+	result, err := r.DB.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+func scanWebhookDelivery(row interface{ Scan(...interface{}) error }) (model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	var payload []byte
+	err := row.Scan(
+		&d.ID,
+		&d.SubscriptionID,
+		&d.EventID,
+		&d.EventType,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&d.LastStatusCode,
+		&d.LastError,
+		&d.NextAttemptAt,
+		&d.CreatedAt,
+		&d.DeliveredAt,
+	)
+	d.Payload = payload
+	return d, err
+}
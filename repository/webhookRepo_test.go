@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"POJECT_UAS/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAchievementRepository_RejectAchievement_EnqueuesWebhook(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	achievementRepo := NewAchievementRepository(db, nil)
+	referenceID := uuid.New()
+	verifierID := uuid.New()
+	note := "Sertifikat tidak terbaca"
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE achievement_references`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO achievement_status_history`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO webhook_deliveries (.+) FROM webhook_subscriptions s\s+JOIN achievement_references r`).
+		WithArgs(sqlmock.AnyArg(), model.WebhookAchievementRejected, sqlmock.AnyArg(), "rejected", "submitted", verifierID, note, referenceID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// Execute
+	err = achievementRepo.RejectAchievement(referenceID, verifierID, note)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookEventForStatus(t *testing.T) {
+	assert.Equal(t, model.WebhookAchievementCreated, model.WebhookEventForStatus("", "draft"))
+	assert.Equal(t, model.WebhookAchievementSubmitted, model.WebhookEventForStatus("draft", "submitted"))
+	assert.Equal(t, model.WebhookAchievementDeleted, model.WebhookEventForStatus("draft", "deleted"))
+	// Perbaikan prestasi yang ditolak bukan event
+	assert.Empty(t, model.WebhookEventForStatus("rejected", "draft"))
+}
+
+func TestWebhookRepository_RecordAttempt(t *testing.T) {
+	statusCode := 500
+	errMsg := "response 500"
+
+	tests := []struct {
+		name      string
+		attempts  int
+		delivered bool
+		update    string
+		final     bool
+	}{
+		{"delivered", 1, true, `SET status = 'delivered'`, true},
+		{"retry", 2, false, `SET status = 'pending'`, false},
+		{"gives up", 3, false, `SET status = 'failed'`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewWebhookRepository(db)
+			repo.MaxAttempts = 3
+			delivery := model.WebhookDelivery{ID: uuid.New(), Attempts: tt.attempts}
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`INSERT INTO webhook_delivery_attempts`).
+				WithArgs(sqlmock.AnyArg(), delivery.ID, tt.attempts, &statusCode, &errMsg, nil, 120, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(`UPDATE webhook_deliveries\s+` + tt.update).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			// Execute
+			final, err := repo.RecordAttempt(context.Background(), delivery, model.WebhookDeliveryAttempt{
+				StatusCode: &statusCode,
+				Error:      &errMsg,
+				DurationMs: 120,
+			}, tt.delivered)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.final, final)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWebhookRepository_Redeliver_InProgress(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	id := uuid.New()
+
+	mock.ExpectExec(`UPDATE webhook_deliveries\s+SET status = 'pending', attempts = 0`).
+		WithArgs(sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute: delivery yang sedang dikirim tidak ikut ter-update
+	err = repo.Redeliver(context.Background(), id)
+
+	// Assert
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
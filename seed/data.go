@@ -0,0 +1,294 @@
+package seed
+
+import "github.com/google/uuid"
+
+// DemoPassword password untuk semua user demo
+const DemoPassword = "password123"
+
+// namespace untuk ID deterministik, sehingga seed yang dijalankan ulang menghasilkan ID yang sama
+var namespace = uuid.NewSHA1(uuid.NameSpaceOID, []byte("POJECT_UAS/seed"))
+
+// Permission satu pasangan resource:action yang dicek RequirePermission/HasPermission
+type Permission struct {
+	Resource    string
+	Action      string
+	Description string
+}
+
+// Name nama permission dengan format "resource:action"
+func (p Permission) Name() string {
+	return p.Resource + ":" + p.Action
+}
+
+// Role role bawaan beserta daftar nama permission-nya
+type Role struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// Permissions matriks permission bawaan
+var Permissions = []Permission{
+	{"achievements", "create", "Membuat prestasi"},
+	{"achievements", "read", "Melihat prestasi sendiri / mahasiswa bimbingan"},
+	{"achievements", "update", "Mengubah prestasi"},
+	{"achievements", "delete", "Menghapus prestasi"},
+	{"achievements", "verify", "Memverifikasi prestasi mahasiswa bimbingan"},
+	{"achievements", "reject", "Menolak prestasi mahasiswa bimbingan"},
+	{"achievements", "read_all", "Melihat semua prestasi"},
+	{"users", "create", "Membuat user"},
+	{"users", "read", "Melihat user"},
+	{"users", "update", "Mengubah user"},
+	{"users", "delete", "Menghapus user"},
+	{"students", "read", "Melihat data mahasiswa"},
+	{"reports", "read", "Melihat laporan dan statistik"},
+	{"reports", "advisee", "Melihat laporan mahasiswa bimbingan"},
+}
+
+var lecturerPermissions = []string{
+	"achievements:read", "achievements:verify", "achievements:reject",
+	"students:read", "reports:advisee",
+}
+
+// Roles role bawaan. "dosen" adalah alias "lecturer" yang juga diterima RequireRole.
+var Roles = []Role{
+	{
+		Name:        "super_admin",
+		Description: "Super administrator, memiliki semua permission",
+		Permissions: allPermissionNames(),
+	},
+	{
+		Name:        "admin",
+		Description: "Administrator",
+		Permissions: []string{
+			"users:create", "users:read", "users:update", "users:delete",
+			"achievements:read", "achievements:read_all",
+			"students:read", "reports:read",
+		},
+	},
+	{
+		Name:        "lecturer",
+		Description: "Dosen wali",
+		Permissions: lecturerPermissions,
+	},
+	{
+		Name:        "dosen",
+		Description: "Dosen wali (alias lecturer)",
+		Permissions: lecturerPermissions,
+	},
+	{
+		Name:        "student",
+		Description: "Mahasiswa",
+		Permissions: []string{
+			"achievements:create", "achievements:read", "achievements:update", "achievements:delete",
+		},
+	},
+}
+
+// User data user demo
+type User struct {
+	Username string
+	Email    string
+	FullName string
+	Role     string
+}
+
+// Lecturer user demo dengan profil dosen
+type Lecturer struct {
+	User
+	LecturerID string
+	Department string
+}
+
+// Student user demo dengan profil mahasiswa. Advisor berisi username dosen wali.
+type Student struct {
+	User
+	StudentID    string
+	ProgramStudy string
+	AcademicYear string
+	Advisor      string
+	Achievements []Achievement
+}
+
+// Achievement contoh prestasi untuk mahasiswa demo
+type Achievement struct {
+	Type        string
+	Title       string
+	Description string
+	Details     map[string]interface{}
+	Status      string
+}
+
+// Admins user admin demo
+var Admins = []User{
+	{Username: "admin123", Email: "admin@example.com", FullName: "Demo Admin", Role: "admin"},
+}
+
+// Lecturers dosen demo
+var Lecturers = []Lecturer{
+	{
+		User:       User{Username: "lecturer123", Email: "lecturer@example.com", FullName: "Demo Lecturer", Role: "lecturer"},
+		LecturerID: "198701012015041001",
+		Department: "Teknik Informatika",
+	},
+	{
+		User:       User{Username: "lecturer456", Email: "lecturer456@example.com", FullName: "Demo Lecturer 2", Role: "lecturer"},
+		LecturerID: "198905122018032002",
+		Department: "Sistem Informasi",
+	},
+}
+
+// Students mahasiswa demo beserta dosen wali dan contoh prestasi
+var Students = []Student{
+	{
+		User:         User{Username: "student123", Email: "student@example.com", FullName: "Demo Student", Role: "student"},
+		StudentID:    "434221001",
+		ProgramStudy: "Teknik Informatika",
+		AcademicYear: "2022",
+		Advisor:      "lecturer123",
+		Achievements: []Achievement{
+			{
+				Type:        "competition",
+				Title:       "Juara 1 Hackathon Nasional",
+				Description: "Hackathon tingkat nasional bidang smart city",
+				Details: map[string]interface{}{
+					"competitionName":  "Hackathon Nasional",
+					"competitionLevel": "national",
+					"rank":             1,
+					"medalType":        "gold",
+				},
+				Status: "verified",
+			},
+			{
+				Type:        "publication",
+				Title:       "Deteksi Hoaks dengan IndoBERT",
+				Description: "Artikel jurnal nasional terakreditasi",
+				Details: map[string]interface{}{
+					"publicationType":  "journal",
+					"publicationTitle": "Deteksi Hoaks dengan IndoBERT",
+					"authors":          []string{"Demo Student", "Demo Lecturer"},
+					"publisher":        "Jurnal Informatika",
+				},
+				Status: "submitted",
+			},
+		},
+	},
+	{
+		User:         User{Username: "student456", Email: "student456@example.com", FullName: "Demo Student 2", Role: "student"},
+		StudentID:    "434221002",
+		ProgramStudy: "Teknik Informatika",
+		AcademicYear: "2022",
+		Advisor:      "lecturer123",
+		Achievements: []Achievement{
+			{
+				Type:        "competition",
+				Title:       "Finalis Gemastik",
+				Description: "Finalis kategori pengembangan perangkat lunak",
+				Details: map[string]interface{}{
+					"competitionName":  "Gemastik",
+					"competitionLevel": "national",
+					"rank":             4,
+				},
+				Status: "draft",
+			},
+		},
+	},
+	{
+		User:         User{Username: "student789", Email: "student789@example.com", FullName: "Demo Student 3", Role: "student"},
+		StudentID:    "434231003",
+		ProgramStudy: "Sistem Informasi",
+		AcademicYear: "2023",
+		Advisor:      "lecturer456",
+		Achievements: []Achievement{
+			{
+				Type:        "competition",
+				Title:       "Juara 2 Business Plan Regional",
+				Description: "Lomba rencana bisnis tingkat provinsi",
+				Details: map[string]interface{}{
+					"competitionName":  "Business Plan Competition",
+					"competitionLevel": "regional",
+					"rank":             2,
+					"medalType":        "silver",
+				},
+				Status: "rejected",
+			},
+		},
+	},
+}
+
+// PermissionsForRole daftar permission milik role bawaan
+func PermissionsForRole(role string) []Permission {
+	byName := make(map[string]Permission, len(Permissions))
+	for _, p := range Permissions {
+		byName[p.Name()] = p
+	}
+
+	for _, r := range Roles {
+		if r.Name != role {
+			continue
+		}
+		perms := make([]Permission, 0, len(r.Permissions))
+		for _, name := range r.Permissions {
+			perms = append(perms, byName[name])
+		}
+		return perms
+	}
+
+	return nil
+}
+
+// IsSystemRole true untuk role bawaan. Namanya dipakai RequireRole di route sehingga role
+// ini tidak boleh dihapus atau diganti nama.
+func IsSystemRole(name string) bool {
+	for _, r := range Roles {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LookupPermission mencari pasangan resource:action di daftar permission yang dicek route
+func LookupPermission(resource, action string) (Permission, bool) {
+	for _, p := range Permissions {
+		if p.Resource == resource && p.Action == action {
+			return p, true
+		}
+	}
+	return Permission{}, false
+}
+
+// DemoUsers semua user demo (admin, dosen, mahasiswa)
+func DemoUsers() []User {
+	users := append([]User{}, Admins...)
+	for _, l := range Lecturers {
+		users = append(users, l.User)
+	}
+	for _, s := range Students {
+		users = append(users, s.User)
+	}
+	return users
+}
+
+// RoleID ID deterministik untuk role bawaan
+func RoleID(name string) uuid.UUID {
+	return uuid.NewSHA1(namespace, []byte("role:"+name))
+}
+
+// PermissionID ID deterministik untuk permission bawaan
+func PermissionID(name string) uuid.UUID {
+	return uuid.NewSHA1(namespace, []byte("permission:"+name))
+}
+
+// UserID ID deterministik untuk user demo
+func UserID(username string) uuid.UUID {
+	return uuid.NewSHA1(namespace, []byte("user:"+username))
+}
+
+func allPermissionNames() []string {
+	names := make([]string, len(Permissions))
+	for i, p := range Permissions {
+		names[i] = p.Name()
+	}
+	return names
+}
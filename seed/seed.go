@@ -0,0 +1,307 @@
+package seed
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"POJECT_UAS/model"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Options pilihan data yang di-seed
+type Options struct {
+	Demo bool // ikut membuat user, dosen, mahasiswa dan prestasi demo
+}
+
+// Report jumlah baris/dokumen baru yang dibuat. Data yang sudah ada tidak dihitung.
+type Report struct {
+	Roles           int `json:"roles"`
+	Permissions     int `json:"permissions"`
+	RolePermissions int `json:"role_permissions"`
+	Users           int `json:"users"`
+	Lecturers       int `json:"lecturers"`
+	Students        int `json:"students"`
+	Achievements    int `json:"achievements"`
+}
+
+// Seeder mengisi data awal secara idempotent: menjalankan ulang tidak membuat duplikat
+type Seeder struct {
+	DB      *sql.DB
+	MongoDB *mongo.Database
+}
+
+func NewSeeder(db *sql.DB, mongoDB *mongo.Database) *Seeder {
+	return &Seeder{DB: db, MongoDB: mongoDB}
+}
+
+// Run menjalankan seed dalam satu transaksi PostgreSQL
+func (s *Seeder) Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Demo && s.MongoDB == nil {
+		return nil, fmt.Errorf("MongoDB connection required for demo data")
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	report := &Report{}
+
+	roleIDs, err := s.seedRBAC(ctx, tx, report)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Demo {
+		if err := s.seedDemo(ctx, tx, roleIDs, report); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// seedRBAC membuat role, permission dan relasi role_permissions. Mengembalikan ID role per nama.
+func (s *Seeder) seedRBAC(ctx context.Context, tx *sql.Tx, report *Report) (map[string]uuid.UUID, error) {
+	permissionIDs := make(map[string]uuid.UUID, len(Permissions))
+	for _, p := range Permissions {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO permissions (id, name, resource, action, description)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT DO NOTHING
+		`, PermissionID(p.Name()), p.Name(), p.Resource, p.Action, p.Description)
+		if err != nil {
+			return nil, fmt.Errorf("seed permission %s: %w", p.Name(), err)
+		}
+		report.Permissions += rowsAffected(result)
+
+		var id uuid.UUID
+		err = tx.QueryRowContext(ctx, `SELECT id FROM permissions WHERE resource = $1 AND action = $2`,
+			p.Resource, p.Action).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("seed permission %s: %w", p.Name(), err)
+		}
+		permissionIDs[p.Name()] = id
+	}
+
+	roleIDs := make(map[string]uuid.UUID, len(Roles))
+	for _, r := range Roles {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO roles (id, name, description, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (name) DO NOTHING
+		`, RoleID(r.Name), r.Name, r.Description, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("seed role %s: %w", r.Name, err)
+		}
+		report.Roles += rowsAffected(result)
+
+		var roleID uuid.UUID
+		if err := tx.QueryRowContext(ctx, `SELECT id FROM roles WHERE name = $1`, r.Name).Scan(&roleID); err != nil {
+			return nil, fmt.Errorf("seed role %s: %w", r.Name, err)
+		}
+		roleIDs[r.Name] = roleID
+
+		for _, name := range r.Permissions {
+			permissionID, ok := permissionIDs[name]
+			if !ok {
+				return nil, fmt.Errorf("seed role %s: unknown permission %q", r.Name, name)
+			}
+			result, err := tx.ExecContext(ctx, `
+				INSERT INTO role_permissions (role_id, permission_id)
+				VALUES ($1, $2)
+				ON CONFLICT DO NOTHING
+			`, roleID, permissionID)
+			if err != nil {
+				return nil, fmt.Errorf("seed role %s: %w", r.Name, err)
+			}
+			report.RolePermissions += rowsAffected(result)
+		}
+	}
+
+	return roleIDs, nil
+}
+
+// seedDemo membuat user demo, profil dosen/mahasiswa, dosen wali dan contoh prestasi
+func (s *Seeder) seedDemo(ctx context.Context, tx *sql.Tx, roleIDs map[string]uuid.UUID, report *Report) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(DemoPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	userIDs := make(map[string]uuid.UUID)
+	for _, u := range DemoUsers() {
+		id, err := s.upsertUser(ctx, tx, u, roleIDs[u.Role], string(hash), report)
+		if err != nil {
+			return fmt.Errorf("seed user %s: %w", u.Username, err)
+		}
+		userIDs[u.Username] = id
+	}
+
+	lecturerIDs := make(map[string]uuid.UUID)
+	for _, l := range Lecturers {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO lecturers (id, user_id, lecturer_id, department, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT DO NOTHING
+		`, uuid.NewSHA1(namespace, []byte("lecturer:"+l.Username)), userIDs[l.Username], l.LecturerID, l.Department, time.Now())
+		if err != nil {
+			return fmt.Errorf("seed lecturer %s: %w", l.Username, err)
+		}
+		report.Lecturers += rowsAffected(result)
+
+		var id uuid.UUID
+		if err := tx.QueryRowContext(ctx, `SELECT id FROM lecturers WHERE user_id = $1`, userIDs[l.Username]).Scan(&id); err != nil {
+			return fmt.Errorf("seed lecturer %s: %w", l.Username, err)
+		}
+		lecturerIDs[l.Username] = id
+	}
+
+	for _, st := range Students {
+		advisorID, ok := lecturerIDs[st.Advisor]
+		if !ok {
+			return fmt.Errorf("seed student %s: unknown advisor %q", st.Username, st.Advisor)
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO students (id, user_id, student_id, program_study, academic_year, advisor_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT DO NOTHING
+		`, uuid.NewSHA1(namespace, []byte("student:"+st.Username)), userIDs[st.Username],
+			st.StudentID, st.ProgramStudy, st.AcademicYear, advisorID, time.Now())
+		if err != nil {
+			return fmt.Errorf("seed student %s: %w", st.Username, err)
+		}
+		report.Students += rowsAffected(result)
+
+		var studentID uuid.UUID
+		if err := tx.QueryRowContext(ctx, `SELECT id FROM students WHERE user_id = $1`, userIDs[st.Username]).Scan(&studentID); err != nil {
+			return fmt.Errorf("seed student %s: %w", st.Username, err)
+		}
+
+		// Advisor ditentukan oleh seed, kecuali sudah diatur manual
+		if _, err := tx.ExecContext(ctx, `UPDATE students SET advisor_id = $1 WHERE id = $2 AND advisor_id IS NULL`,
+			advisorID, studentID); err != nil {
+			return fmt.Errorf("seed student %s: %w", st.Username, err)
+		}
+
+		for _, a := range st.Achievements {
+			if err := s.seedAchievement(ctx, tx, st, studentID, userIDs[st.Advisor], a, report); err != nil {
+				return fmt.Errorf("seed achievement %q: %w", a.Title, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Seeder) upsertUser(ctx context.Context, tx *sql.Tx, u User, roleID uuid.UUID, passwordHash string, report *Report) (uuid.UUID, error) {
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, true, $7, $7)
+		ON CONFLICT DO NOTHING
+	`, UserID(u.Username), u.Username, u.Email, passwordHash, u.FullName, roleID, now)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	report.Users += rowsAffected(result)
+
+	var id uuid.UUID
+	err = tx.QueryRowContext(ctx, `SELECT id FROM users WHERE username = $1`, u.Username).Scan(&id)
+	return id, err
+}
+
+// seedAchievement menyimpan prestasi ke MongoDB (upsert berdasarkan _id deterministik)
+// lalu reference-nya ke PostgreSQL
+func (s *Seeder) seedAchievement(ctx context.Context, tx *sql.Tx, st Student, studentID, advisorUserID uuid.UUID, a Achievement, report *Report) error {
+	mongoID := achievementObjectID(st.Username, a.Title)
+
+	achievement := model.Achievement{
+		ID:              mongoID,
+		StudentID:       studentID,
+		AchievementType: a.Type,
+		Title:           a.Title,
+		Description:     a.Description,
+		Details:         a.Details,
+		Version:         1,
+	}
+
+	_, err := s.MongoDB.Collection("achievements").UpdateOne(ctx,
+		bson.M{"_id": mongoID},
+		bson.M{"$setOnInsert": achievement},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var submittedAt, verifiedAt *time.Time
+	var verifiedBy *uuid.UUID
+	var rejectionNote *string
+	switch a.Status {
+	case "submitted":
+		submittedAt = &now
+	case "verified":
+		submittedAt, verifiedAt, verifiedBy = &now, &now, &advisorUserID
+	case "rejected":
+		note := "Bukti sertifikat belum dilampirkan"
+		submittedAt, rejectionNote = &now, &note
+	}
+
+	referenceID := uuid.NewSHA1(namespace, []byte("achievement:"+mongoID.Hex()))
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO achievement_references
+		(id, student_id, mongo_achievement_id, status, submitted_at, verified_at, verified_by, rejection_note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		ON CONFLICT (mongo_achievement_id) DO NOTHING
+	`, referenceID, studentID, mongoID.Hex(), a.Status,
+		submittedAt, verifiedAt, verifiedBy, rejectionNote, now)
+	if err != nil {
+		return err
+	}
+	if rowsAffected(result) == 0 {
+		return nil
+	}
+	report.Achievements++
+
+	// History awal untuk prestasi demo yang baru dibuat
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO achievement_status_history
+		(id, achievement_reference_id, from_status, to_status, changed_by, note, created_at)
+		VALUES ($1, $2, NULL, $3, NULL, $4, $5)
+	`, uuid.New(), referenceID, a.Status, "data demo dari seed", now)
+
+	return err
+}
+
+// achievementObjectID ObjectID deterministik dari username dan judul prestasi
+func achievementObjectID(username, title string) primitive.ObjectID {
+	sum := sha1.Sum([]byte("achievement:" + username + ":" + title))
+	var id primitive.ObjectID
+	copy(id[:], sum[:len(id)])
+	return id
+}
+
+func rowsAffected(result sql.Result) int {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return int(n)
+}
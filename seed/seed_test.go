@@ -0,0 +1,159 @@
+package seed
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestRoles_ReferenceKnownPermissions(t *testing.T) {
+	known := make(map[string]bool)
+	for _, p := range Permissions {
+		assert.False(t, known[p.Name()], "duplicate permission %s", p.Name())
+		known[p.Name()] = true
+	}
+
+	for _, r := range Roles {
+		for _, name := range r.Permissions {
+			assert.True(t, known[name], "role %s references unknown permission %s", r.Name, name)
+		}
+	}
+
+	// Permission yang dicek oleh route dan handler
+	assert.Contains(t, Roles[len(Roles)-1].Permissions, "achievements:create")
+	assert.Len(t, PermissionsForRole("super_admin"), len(Permissions))
+	assert.Equal(t, PermissionsForRole("lecturer"), PermissionsForRole("dosen"))
+	assert.Nil(t, PermissionsForRole("unknown"))
+}
+
+// Setiap pasangan resource:action yang dicek route atau handler harus ada di Permissions,
+// karena hanya pasangan itu yang bisa dibuat lewat API permission
+func TestPermissions_CoverCheckedPairs(t *testing.T) {
+	pattern := regexp.MustCompile(`(?:RequirePermission\w*\(|HasPermission\(c, )"(\w+)", "(\w+)"\)`)
+	files, err := filepath.Glob("../Routes/*.go")
+	assert.NoError(t, err)
+	serviceFiles, err := filepath.Glob("../service/*.go")
+	assert.NoError(t, err)
+
+	checked := 0
+	for _, file := range append(files, serviceFiles...) {
+		source, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		for _, match := range pattern.FindAllStringSubmatch(string(source), -1) {
+			_, ok := LookupPermission(match[1], match[2])
+			assert.True(t, ok, "%s checks unknown permission %s:%s", file, match[1], match[2])
+			checked++
+		}
+	}
+	assert.Greater(t, checked, 0)
+
+	assert.True(t, IsSystemRole("super_admin"))
+	assert.False(t, IsSystemRole("kaprodi"))
+}
+
+func TestDemoStudents_AdvisorIsDemoLecturer(t *testing.T) {
+	lecturers := make(map[string]bool)
+	for _, l := range Lecturers {
+		lecturers[l.Username] = true
+	}
+
+	for _, s := range Students {
+		assert.True(t, lecturers[s.Advisor], "student %s has unknown advisor %s", s.Username, s.Advisor)
+	}
+}
+
+func TestSeeder_Run_RBACOnly(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	seeder := NewSeeder(db, nil)
+
+	mock.ExpectBegin()
+	for _, p := range Permissions {
+		mock.ExpectExec(`INSERT INTO permissions`).
+			WithArgs(PermissionID(p.Name()), p.Name(), p.Resource, p.Action, p.Description).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`SELECT id FROM permissions WHERE resource = \$1 AND action = \$2`).
+			WithArgs(p.Resource, p.Action).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(PermissionID(p.Name())))
+	}
+	rolePermissions := 0
+	for _, r := range Roles {
+		// Role sudah ada: tidak dihitung sebagai baris baru
+		mock.ExpectExec(`INSERT INTO roles`).
+			WithArgs(RoleID(r.Name), r.Name, r.Description, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT id FROM roles WHERE name = \$1`).
+			WithArgs(r.Name).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(RoleID(r.Name)))
+		for _, name := range r.Permissions {
+			mock.ExpectExec(`INSERT INTO role_permissions`).
+				WithArgs(RoleID(r.Name), PermissionID(name)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			rolePermissions++
+		}
+	}
+	mock.ExpectCommit()
+
+	// Execute
+	report, err := seeder.Run(context.Background(), Options{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, len(Permissions), report.Permissions)
+	assert.Equal(t, 0, report.Roles)
+	assert.Equal(t, rolePermissions, report.RolePermissions)
+	assert.Equal(t, 0, report.Users)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeeder_Run_DemoRequiresMongo(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	seeder := NewSeeder(db, nil)
+
+	// Execute
+	report, err := seeder.Run(context.Background(), Options{Demo: true})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementObjectID_Deterministic(t *testing.T) {
+	assert.Equal(t, achievementObjectID("student123", "A"), achievementObjectID("student123", "A"))
+	assert.NotEqual(t, achievementObjectID("student123", "A"), achievementObjectID("student123", "B"))
+}
+
+func TestDemoAchievements_DetailsMatchSchema(t *testing.T) {
+	for _, student := range Students {
+		for _, a := range student.Achievements {
+			data, err := bson.Marshal(bson.M{"achievementType": a.Type, "details": a.Details})
+			assert.NoError(t, err)
+
+			// Execute
+			var achievement model.Achievement
+			assert.NoError(t, bson.Unmarshal(data, &achievement))
+
+			// Assert: details tersimpan sesuai struct yang terdaftar untuk type-nya
+			details, ok := achievement.Details.(model.AchievementDetails)
+			assert.True(t, ok, a.Title)
+			if ok {
+				assert.Empty(t, details.Validate(), a.Title)
+			}
+		}
+	}
+}
@@ -3,22 +3,63 @@ package service
 import (
 	"POJECT_UAS/middleware"
 	"POJECT_UAS/model"
+	"POJECT_UAS/notify"
 	"POJECT_UAS/repository"
-	"encoding/json"
+	"POJECT_UAS/storage"
+	"database/sql"
+	"errors"
 	"fmt"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// AchievementRepository method repository.AchievementRepository yang dipakai
+// AchievementService, berupa interface agar handler bisa diuji dengan mock
+type AchievementRepository interface {
+	achievementScopeRepository
+	GetUserByID(userID uuid.UUID) (*model.Users, error)
+	GetAdvisorUserIDByStudentID(studentID uuid.UUID) (uuid.UUID, error)
+	CheckLecturerOwnsStudent(lecturerID uuid.UUID, studentID uuid.UUID) (bool, error)
+	GetAchievementByID(achievementID string) (*model.Achievement, error)
+	GetAchievementsByIDs(achievementIDs []string) (map[string]model.Achievement, error)
+	GetAchievementReferenceByID(referenceID uuid.UUID) (*model.AchievementReference, error)
+	GetAchievementStatusHistory(referenceID uuid.UUID) ([]model.AchievementStatusHistory, error)
+	ListAchievementReferences(filter model.AchievementListFilter) ([]model.AchievementReferenceWithStudent, model.PageInfo, error)
+	SubmitAchievement(studentID uuid.UUID, createdBy uuid.UUID, req model.SubmitAchievementRequest) (*model.SubmitAchievementResponse, error)
+	UpdateAchievement(referenceID uuid.UUID, updatedBy uuid.UUID, expectedVersion int, req model.UpdateAchievementRequest) (*model.Achievement, error)
+	AddAttachments(referenceID uuid.UUID, mongoAchievementID string, attachments []model.Attachment) error
+	SubmitForVerification(referenceID uuid.UUID, submittedBy uuid.UUID) error
+	DeleteAchievement(referenceID uuid.UUID, mongoAchievementID string, deletedBy uuid.UUID) error
+	CreateNotification(notification model.Notification) error
+}
+
+// achievementScopeRepository method yang dipakai achievementScope
+type achievementScopeRepository interface {
+	GetStudentByUserID(userID uuid.UUID) (*model.Student, error)
+	GetLecturerByUserID(userID uuid.UUID) (*model.Lecturers, error)
+	GetStudentIDsByAdvisor(advisorID uuid.UUID) ([]uuid.UUID, error)
+}
+
 type AchievementService struct {
-	AchievementRepo *repository.AchievementRepository
+	AchievementRepo  AchievementRepository
+	Attachments      storage.AttachmentStore
+	AttachmentPolicy storage.Policy
+	Notifier         *notify.Dispatcher // nil berarti notifikasi hanya disimpan di inbox
 }
 
-func NewAchievementService(achievementRepo *repository.AchievementRepository) *AchievementService {
+func NewAchievementService(achievementRepo AchievementRepository, attachments storage.AttachmentStore, policy storage.Policy, notifier *notify.Dispatcher) *AchievementService {
 	return &AchievementService{
-		AchievementRepo: achievementRepo,
+		AchievementRepo:  achievementRepo,
+		Attachments:      attachments,
+		AttachmentPolicy: policy,
+		Notifier:         notifier,
 	}
 }
 
@@ -40,6 +81,13 @@ func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
 		})
 	}
 
+	// Validasi details sesuai achievement_type
+	details, fieldErrs := model.DecodeAchievementDetails(req.AchievementType, req.Details)
+	if fieldErrs != nil {
+		return detailsValidationError(c, fieldErrs)
+	}
+	req.Details = details
+
 	// Ambil user_id dari context (dari JWT)
 	userIDStr := middleware.GetUserID(c)
 	if userIDStr == "" {
@@ -64,7 +112,7 @@ func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
 	}
 
 	// Submit achievement
-	response, err := s.AchievementRepo.SubmitAchievement(student.ID, req)
+	response, err := s.AchievementRepo.SubmitAchievement(student.ID, userID, req)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed to submit achievement",
@@ -85,16 +133,7 @@ func (s *AchievementService) validateSubmitRequest(req model.SubmitAchievementRe
 	}
 
 	// Validasi achievement_type
-	validTypes := map[string]bool{
-		"academic":      true,
-		"competition":   true,
-		"organization":  true,
-		"publication":   true,
-		"certification": true,
-		"other":         true,
-	}
-
-	if !validTypes[req.AchievementType] {
+	if !model.IsValidAchievementType(req.AchievementType) {
 		return &ValidationError{Message: "achievement_type tidak valid"}
 	}
 
@@ -109,45 +148,6 @@ func (s *AchievementService) validateSubmitRequest(req model.SubmitAchievementRe
 	return nil
 }
 
-// GetMyAchievements - Mahasiswa melihat prestasi sendiri
-func (s *AchievementService) GetMyAchievements(c *fiber.Ctx) error {
-	// Ambil user_id dari context
-	userIDStr := middleware.GetUserID(c)
-	if userIDStr == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "user not authenticated",
-		})
-	}
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid user id",
-		})
-	}
-
-	// Ambil student_id dari user_id
-	student, err := s.AchievementRepo.GetStudentByUserID(userID)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "student not found",
-		})
-	}
-
-	// Ambil semua achievements
-	achievements, err := s.AchievementRepo.GetAchievementsByStudentID(student.ID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get achievements",
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"message": "success",
-		"data":    achievements,
-	})
-}
-
 // GetAchievementDetail - Melihat detail prestasi
 func (s *AchievementService) GetAchievementDetail(c *fiber.Ctx) error {
 	achievementID := c.Params("id")
@@ -180,6 +180,8 @@ func (s *AchievementService) GetAchievementDetail(c *fiber.Ctx) error {
 		}
 	}
 
+	c.Set(fiber.HeaderETag, achievementETag(achievement.Version))
+
 	return c.JSON(fiber.Map{
 		"message": "success",
 		"data":    achievement,
@@ -188,7 +190,7 @@ func (s *AchievementService) GetAchievementDetail(c *fiber.Ctx) error {
 
 // SubmitForVerification - Mahasiswa submit prestasi draft untuk diverifikasi (FR-004)
 func (s *AchievementService) SubmitForVerification(c *fiber.Ctx) error {
-	referenceIDStr := c.Params("reference_id")
+	referenceIDStr := c.Params("id")
 
 	if referenceIDStr == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -249,7 +251,7 @@ func (s *AchievementService) SubmitForVerification(c *fiber.Ctx) error {
 	}
 
 	// Update status menjadi 'submitted'
-	err = s.AchievementRepo.SubmitForVerification(referenceID)
+	err = s.AchievementRepo.SubmitForVerification(referenceID, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed to submit achievement for verification",
@@ -286,8 +288,8 @@ func (s *AchievementService) SubmitForVerification(c *fiber.Ctx) error {
 
 // createNotificationForAdvisor membuat notifikasi untuk dosen wali
 func (s *AchievementService) createNotificationForAdvisor(student *model.Student, achievementRef *model.AchievementReference, achievement *model.Achievement) error {
-	// Ambil advisor_id
-	advisorID, err := s.AchievementRepo.GetAdvisorByStudentID(student.ID)
+	// Ambil user_id dosen wali (notifikasi dikirim ke user, bukan ke lecturer)
+	advisorUserID, err := s.AchievementRepo.GetAdvisorUserIDByStudentID(student.ID)
 	if err != nil {
 		return err
 	}
@@ -307,31 +309,30 @@ func (s *AchievementService) createNotificationForAdvisor(student *model.Student
 		AchievementTitle:       achievement.Title,
 	}
 
-	// Convert to JSON string
-	dataJSON, err := json.Marshal(notifData)
-	if err != nil {
-		return err
-	}
-
 	// Create notification
 	notification := model.Notification{
 		ID:        uuid.New(),
-		UserID:    advisorID,
+		UserID:    advisorUserID,
 		Type:      "achievement_submitted",
 		Title:     "Prestasi Baru Menunggu Verifikasi",
 		Message:   fmt.Sprintf("Mahasiswa %s telah mengajukan prestasi '%s' untuk diverifikasi", studentUser.FullName, achievement.Title),
-		Data:      string(dataJSON),
+		Data:      &notifData,
 		IsRead:    false,
 		CreatedAt: time.Now(),
 	}
 
-	return s.AchievementRepo.CreateNotification(notification)
+	if err := s.AchievementRepo.CreateNotification(notification); err != nil {
+		return err
+	}
+
+	s.Notifier.Dispatch(notification)
+	return nil
 }
 
 
 // DeleteAchievement - Mahasiswa hapus prestasi draft (FR-005)
 func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
-	referenceIDStr := c.Params("reference_id")
+	referenceIDStr := c.Params("id")
 
 	if referenceIDStr == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -392,7 +393,7 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 	}
 
 	// Delete achievement (soft delete)
-	err = s.AchievementRepo.DeleteAchievement(referenceID, achievementRef.MongoAchievementID)
+	err = s.AchievementRepo.DeleteAchievement(referenceID, achievementRef.MongoAchievementID, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed to delete achievement",
@@ -410,70 +411,767 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 }
 // GetAchievements - Get achievements list (filtered by role)
 // @Summary Get achievements list
-// @Description Mendapatkan daftar prestasi berdasarkan role user
+// @Description Mendapatkan daftar prestasi sesuai role: mahasiswa melihat prestasi sendiri, dosen wali melihat prestasi mahasiswa bimbingan, user dengan permission achievements:read_all melihat semua prestasi.
 // @Tags Achievements
 // @Security BearerAuth
 // @Param page query int false "Page number"
-// @Param per_page query int false "Items per page"
-// @Param status query string false "Achievement status"
-// @Success 200 {object} map[string]interface{} "List of achievements"
+// @Param per_page query int false "Items per page (maks. 100)"
+// @Param status query string false "Achievement status (draft, submitted, verified, rejected, deleted)"
+// @Param achievement_type query string false "Achievement type"
+// @Param start_date query string false "Dibuat sejak tanggal (YYYY-MM-DD)"
+// @Param end_date query string false "Dibuat sampai tanggal (YYYY-MM-DD)"
+// @Param program_study query string false "Program studi mahasiswa"
+// @Param academic_year query string false "Angkatan mahasiswa"
+// @Param search query string false "Kata kunci pada title dan description"
+// @Param sort_by query string false "created_at, updated_at, submitted_at, verified_at, status"
+// @Param sort_order query string false "asc atau desc (default desc)"
+// @Param cursor query string false "Pagination keyset: kosong untuk halaman pertama, lalu next_cursor/prev_cursor (hanya sort_by=created_at)"
+// @Param include_total query bool false "false untuk melewati perhitungan total_items"
+// @Success 200 {object} model.PaginatedAchievementsResponse "List of achievements"
+// @Failure 400 {object} map[string]interface{} "Invalid filter"
+// @Failure 403 {object} map[string]string "Forbidden"
 // @Router /api/v1/achievements [get]
 func (s *AchievementService) GetAchievements(c *fiber.Ctx) error {
-	// TODO: Implement role-based achievement listing
+	filter, fieldErrs := parseAchievementListFilter(c)
+	if fieldErrs != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid filter",
+			"details": fieldErrs,
+		})
+	}
+
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	// Batasi hasil sesuai role
+	studentIDs, err := achievementScope(c, s.AchievementRepo, userID)
+	if err == errNoAchievementScope {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "you are not allowed to list achievements",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to check access",
+		})
+	}
+	filter.StudentIDs = studentIDs
+
+	response := model.PaginatedAchievementsResponse{
+		Data: []model.AchievementWithStudent{},
+	}
+
+	// Dosen tanpa mahasiswa bimbingan
+	if studentIDs != nil && len(studentIDs) == 0 {
+		var info model.PageInfo
+		if !filter.SkipCount {
+			info.TotalItems = new(int64)
+		}
+		response.Pagination = filter.Meta(info)
+		return c.JSON(fiber.Map{
+			"message": "success",
+			"data":    response,
+		})
+	}
+
+	references, pageInfo, err := s.AchievementRepo.ListAchievementReferences(filter)
+	if errors.Is(err, repository.ErrAchievementSearchTooBroad) {
+		return searchTooBroadError(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get achievements",
+		})
+	}
+
+	mongoIDs := make([]string, len(references))
+	for i, ref := range references {
+		mongoIDs[i] = ref.MongoAchievementID
+	}
+
+	achievementsMap, err := s.AchievementRepo.GetAchievementsByIDs(mongoIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get achievement details",
+		})
+	}
+
+	for _, ref := range references {
+		achievement, exists := achievementsMap[ref.MongoAchievementID]
+		if !exists {
+			continue
+		}
+
+		response.Data = append(response.Data, model.AchievementWithStudent{
+			Achievement:            achievement,
+			AchievementReferenceID: ref.ID,
+			Status:                 ref.Status,
+			SubmittedAt:            ref.SubmittedAt,
+			VerifiedAt:             ref.VerifiedAt,
+			StudentName:            ref.StudentName,
+			StudentIDNumber:        ref.StudentIDNumber,
+			ProgramStudy:           ref.ProgramStudy,
+		})
+	}
+
+	response.Pagination = filter.Meta(pageInfo)
+
 	return c.JSON(fiber.Map{
-		"message": "get achievements list - coming soon",
+		"message": "success",
+		"data":    response,
 	})
 }
 
+var errNoAchievementScope = errors.New("user has no achievement scope")
+
+// achievementScope daftar mahasiswa yang prestasinya boleh dilihat user.
+// nil berarti semua mahasiswa (permission achievements:read_all).
+func achievementScope(c *fiber.Ctx, achievementRepo achievementScopeRepository, userID uuid.UUID) ([]uuid.UUID, error) {
+	if middleware.HasPermission(c, "achievements", "read_all") {
+		return nil, nil
+	}
+
+	student, err := achievementRepo.GetStudentByUserID(userID)
+	if err == nil {
+		return []uuid.UUID{student.ID}, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	lecturer, err := achievementRepo.GetLecturerByUserID(userID)
+	if err == sql.ErrNoRows {
+		return nil, errNoAchievementScope
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	studentIDs, err := achievementRepo.GetStudentIDsByAdvisor(lecturer.ID)
+	if err != nil {
+		return nil, err
+	}
+	if studentIDs == nil {
+		studentIDs = []uuid.UUID{}
+	}
+	return studentIDs, nil
+}
+
+// parseAchievementListFilter membaca query parameter daftar prestasi
+func parseAchievementListFilter(c *fiber.Ctx) (model.AchievementListFilter, model.FieldErrors) {
+	var errs model.FieldErrors
+
+	filter := model.AchievementListFilter{
+		Status:          c.Query("status"),
+		AchievementType: c.Query("achievement_type"),
+		ProgramStudy:    c.Query("program_study"),
+		AcademicYear:    c.Query("academic_year"),
+		Search:          strings.TrimSpace(c.Query("search")),
+		SortBy:          c.Query("sort_by", "created_at"),
+		SortOrder:       strings.ToLower(c.Query("sort_order", "desc")),
+	}
+
+	filter.PageRequest, errs = parsePageRequest(c)
+
+	switch filter.Status {
+	case "", "draft", "submitted", "verified", "rejected", "deleted":
+	default:
+		errs = append(errs, model.FieldError{Field: "status", Message: "status tidak valid"})
+	}
+
+	if filter.AchievementType != "" && !model.IsValidAchievementType(filter.AchievementType) {
+		errs = append(errs, model.FieldError{Field: "achievement_type", Message: "achievement_type tidak valid"})
+	}
+
+	validSort := false
+	for _, field := range model.AchievementSortFields {
+		if filter.SortBy == field {
+			validSort = true
+		}
+	}
+	if !validSort {
+		errs = append(errs, model.FieldError{
+			Field:   "sort_by",
+			Message: "harus salah satu dari: " + strings.Join(model.AchievementSortFields, ", "),
+		})
+	}
+	if filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+		errs = append(errs, model.FieldError{Field: "sort_order", Message: "harus asc atau desc"})
+	}
+	if filter.UseCursor && filter.SortBy != "created_at" {
+		errs = append(errs, model.FieldError{Field: "sort_by", Message: "pagination cursor hanya mendukung sort_by=created_at"})
+	}
+
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"start_date", &filter.StartDate},
+		{"end_date", &filter.EndDate},
+	} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: param.name, Message: "format tanggal harus YYYY-MM-DD"})
+			continue
+		}
+		*param.target = &date
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		errs = append(errs, model.FieldError{Field: "end_date", Message: "tidak boleh sebelum start_date"})
+	}
+
+	return filter, errs
+}
+
 // UpdateAchievement - Update achievement (Mahasiswa)
 // @Summary Update achievement
-// @Description Mahasiswa mengupdate prestasi yang masih draft
+// @Description Mahasiswa mengupdate prestasi yang masih draft atau rejected. Versi yang diedit dikirim lewat header If-Match (ETag dari detail prestasi) atau field version. Prestasi rejected kembali menjadi draft.
 // @Tags Achievements
 // @Security BearerAuth
-// @Param id path string true "Achievement ID"
-// @Param request body model.SubmitAchievementRequest true "Achievement update data"
+// @Param id path string true "Achievement reference ID"
+// @Param If-Match header string false "ETag versi prestasi yang diedit"
+// @Param request body model.UpdateAchievementRequest true "Achievement update data"
 // @Success 200 {object} map[string]interface{} "Achievement updated"
 // @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 409 {object} map[string]interface{} "Version conflict, berisi versi terbaru"
+// @Failure 428 {object} map[string]string "Version or If-Match required"
 // @Router /api/v1/achievements/{id} [put]
 func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
-	// TODO: Implement update achievement
+	referenceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid achievement reference id",
+		})
+	}
+
+	var req model.UpdateAchievementRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	if req.Title == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "title harus diisi",
+		})
+	}
+	if req.Description == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "description harus diisi",
+		})
+	}
+
+	// Versi yang diedit: header If-Match lebih diutamakan daripada field version
+	expectedVersion, ok, err := parseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid If-Match header",
+		})
+	}
+	if !ok {
+		if req.Version == nil {
+			return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+				"error": "version or If-Match header required",
+			})
+		}
+		expectedVersion = *req.Version
+	}
+
+	// Ambil user_id dari context
+	userIDStr := middleware.GetUserID(c)
+	if userIDStr == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid user id",
+		})
+	}
+
+	// Ambil student_id dari user_id
+	student, err := s.AchievementRepo.GetStudentByUserID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "student not found",
+		})
+	}
+
+	// Ambil achievement reference
+	achievementRef, err := s.AchievementRepo.GetAchievementReferenceByID(referenceID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "achievement reference not found",
+		})
+	}
+
+	// Verify ownership
+	if achievementRef.StudentID != student.ID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "you can only update your own achievements",
+		})
+	}
+
+	// achievement_type tidak bisa diubah, details divalidasi sesuai type yang tersimpan
+	current, err := s.AchievementRepo.GetAchievementByID(achievementRef.MongoAchievementID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "achievement not found",
+		})
+	}
+
+	details, fieldErrs := model.DecodeAchievementDetails(current.AchievementType, req.Details)
+	if fieldErrs != nil {
+		return detailsValidationError(c, fieldErrs)
+	}
+	req.Details = details
+
+	achievement, err := s.AchievementRepo.UpdateAchievement(referenceID, userID, expectedVersion, req)
+	if err != nil {
+		switch err {
+		case repository.ErrAchievementNotEditable:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "only draft or rejected achievements can be updated",
+			})
+		case repository.ErrAchievementVersionConflict:
+			return s.achievementConflict(c, achievementRef.MongoAchievementID)
+		case sql.ErrNoRows:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "achievement reference not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to update achievement",
+		})
+	}
+
+	c.Set(fiber.HeaderETag, achievementETag(achievement.Version))
+
 	return c.JSON(fiber.Map{
-		"message": "update achievement - coming soon",
+		"message": "prestasi berhasil diupdate",
+		"data":    achievement,
+	})
+}
+
+// searchTooBroadError response 400 jika filter achievement_type/search cocok dengan terlalu banyak prestasi
+func searchTooBroadError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "search matches too many achievements, narrow it with status, date, program_study or a more specific search",
+	})
+}
+
+// detailsValidationError response 400 berisi daftar field yang tidak valid
+func detailsValidationError(c *fiber.Ctx, fieldErrs model.FieldErrors) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error":   "details tidak valid",
+		"details": fieldErrs,
 	})
 }
 
+// achievementConflict response 409 berisi versi prestasi terbaru agar client bisa merge ulang
+func (s *AchievementService) achievementConflict(c *fiber.Ctx, mongoAchievementID string) error {
+	current, err := s.AchievementRepo.GetAchievementByID(mongoAchievementID)
+	if err != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "achievement has been modified",
+		})
+	}
+
+	c.Set(fiber.HeaderETag, achievementETag(current.Version))
+
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"error": "achievement has been modified",
+		"data": fiber.Map{
+			"current_version": current.Version,
+			"achievement":     current,
+		},
+	})
+}
+
+// achievementETag ETag untuk versi prestasi
+func achievementETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch membaca versi dari header If-Match. ok false jika header tidak dikirim.
+func parseIfMatch(header string) (version int, ok bool, err error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, false, nil
+	}
+
+	header = strings.TrimPrefix(header, "W/")
+	version, err = strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || version < 0 {
+		return 0, false, fmt.Errorf("invalid If-Match header %q", header)
+	}
+
+	return version, true, nil
+}
+
 // GetAchievementHistory - Get achievement status history
 // @Summary Get achievement history
-// @Description Mendapatkan riwayat status perubahan prestasi
+// @Description Mendapatkan riwayat perubahan status prestasi (siapa, dari/ke status apa, kapan), urut dari yang paling lama. Dapat diakses pemilik prestasi, dosen wali, dan user dengan permission achievements:read_all.
 // @Tags Achievements
 // @Security BearerAuth
-// @Param id path string true "Achievement ID"
+// @Param id path string true "Achievement reference ID"
 // @Success 200 {object} map[string]interface{} "Achievement history"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Achievement not found"
 // @Router /api/v1/achievements/{id}/history [get]
 func (s *AchievementService) GetAchievementHistory(c *fiber.Ctx) error {
-	// TODO: Implement achievement history
+	referenceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid achievement reference id",
+		})
+	}
+
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	achievementRef, err := s.AchievementRepo.GetAchievementReferenceByID(referenceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "achievement reference not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get achievement reference",
+		})
+	}
+
+	allowed, err := s.canViewAchievement(c, userID, achievementRef)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to check access",
+		})
+	}
+	if !allowed {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "you are not allowed to view this achievement history",
+		})
+	}
+
+	history, err := s.AchievementRepo.GetAchievementStatusHistory(referenceID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get achievement history",
+		})
+	}
+
 	return c.JSON(fiber.Map{
-		"message": "get achievement history - coming soon",
+		"message": "success",
+		"data":    history,
 	})
 }
 
+// canViewAchievement pemilik prestasi, dosen wali mahasiswa, atau user dengan achievements:read_all
+func (s *AchievementService) canViewAchievement(c *fiber.Ctx, userID uuid.UUID, achievementRef *model.AchievementReference) (bool, error) {
+	if middleware.HasPermission(c, "achievements", "read_all") {
+		return true, nil
+	}
+
+	student, err := s.AchievementRepo.GetStudentByUserID(userID)
+	if err == nil {
+		return achievementRef.StudentID == student.ID, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	lecturer, err := s.AchievementRepo.GetLecturerByUserID(userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return s.AchievementRepo.CheckLecturerOwnsStudent(lecturer.ID, achievementRef.StudentID)
+}
+
 // UploadAttachments - Upload achievement attachments
 // @Summary Upload attachments
-// @Description Upload dokumen pendukung prestasi
+// @Description Mahasiswa mengupload dokumen pendukung (sertifikat, foto) untuk prestasi draft atau rejected miliknya. Tipe file ditentukan dari isi file (PDF, JPEG, PNG, WebP) dan ukurannya dibatasi.
 // @Tags Achievements
 // @Security BearerAuth
-// @Param id path string true "Achievement ID"
+// @Accept multipart/form-data
+// @Param id path string true "Achievement reference ID"
 // @Param files formData file true "Attachment files"
-// @Success 200 {object} map[string]interface{} "Files uploaded"
+// @Success 201 {object} map[string]interface{} "Files uploaded"
 // @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 413 {object} map[string]string "File too large"
+// @Failure 415 {object} map[string]string "Unsupported file type"
 // @Router /api/v1/achievements/{id}/attachments [post]
 func (s *AchievementService) UploadAttachments(c *fiber.Ctx) error {
-	// TODO: Implement file upload
-	return c.JSON(fiber.Map{
-		"message": "upload attachments - coming soon",
+	referenceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid achievement reference id",
+		})
+	}
+
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	student, err := s.AchievementRepo.GetStudentByUserID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "only students can upload attachments",
+		})
+	}
+
+	achievementRef, err := s.AchievementRepo.GetAchievementReferenceByID(referenceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "achievement reference not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get achievement reference",
+		})
+	}
+
+	// Verify ownership
+	if achievementRef.StudentID != student.ID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "you can only upload attachments to your own achievements",
+		})
+	}
+
+	if achievementRef.Status != "draft" && achievementRef.Status != "rejected" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "attachments can only be uploaded to draft or rejected achievements",
+		})
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid multipart form",
+		})
+	}
+
+	files := form.File["files"]
+	if len(files) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "files harus diisi",
+		})
+	}
+	if len(files) > s.AttachmentPolicy.MaxFiles {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("maksimal %d file per upload", s.AttachmentPolicy.MaxFiles),
+		})
+	}
+
+	ctx := c.UserContext()
+	now := time.Now()
+	attachments := make([]model.Attachment, 0, len(files))
+
+	// cleanup menghapus file yang sudah tersimpan jika upload gagal di tengah jalan
+	cleanup := func() {
+		for _, attachment := range attachments {
+			s.Attachments.Delete(ctx, attachment.StorageKey)
+		}
+	}
+
+	for _, fh := range files {
+		attachment := model.Attachment{
+			ID:         uuid.New(),
+			FileName:   filepath.Base(fh.Filename),
+			UploadedBy: userID,
+			UploadedAt: now,
+		}
+		attachment.StorageKey = "achievements/" + achievementRef.MongoAchievementID + "/" + attachment.ID.String()
+
+		file, err := fh.Open()
+		if err != nil {
+			cleanup()
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "failed to read uploaded file",
+			})
+		}
+
+		object, err := storage.Save(ctx, s.Attachments, attachment.StorageKey, file, fh.Size, s.AttachmentPolicy)
+		file.Close()
+		if err != nil {
+			cleanup()
+			return attachmentError(c, fh.Filename, err)
+		}
+
+		attachment.ContentType = object.ContentType
+		attachment.Size = object.Size
+		attachment.SHA256 = object.SHA256
+		attachments = append(attachments, attachment)
+	}
+
+	if err := s.AchievementRepo.AddAttachments(referenceID, achievementRef.MongoAchievementID, attachments); err != nil {
+		cleanup()
+		switch err {
+		case repository.ErrAchievementNotEditable:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "attachments can only be uploaded to draft or rejected achievements",
+			})
+		case sql.ErrNoRows:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "achievement reference not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to save attachments",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "lampiran berhasil diupload",
+		"data":    attachments,
 	})
 }
 
+// attachmentError memetakan error validasi file ke status HTTP
+func attachmentError(c *fiber.Ctx, fileName string, err error) error {
+	switch {
+	case errors.Is(err, storage.ErrFileTooLarge):
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": fmt.Sprintf("file %s terlalu besar", fileName),
+		})
+	case errors.Is(err, storage.ErrUnsupportedType):
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error": fmt.Sprintf("tipe file %s tidak didukung", fileName),
+		})
+	case errors.Is(err, storage.ErrEmptyFile):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("file %s kosong", fileName),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to store attachment",
+		})
+	}
+}
+
+// DownloadAttachment - Download achievement attachment
+// @Summary Download attachment
+// @Description Mengunduh lampiran prestasi. Dapat diakses pemilik prestasi, dosen wali, dan user dengan permission achievements:read_all.
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce octet-stream
+// @Param id path string true "Achievement reference ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} file "Attachment content"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Attachment not found"
+// @Router /api/v1/achievements/{id}/attachments/{attachmentId} [get]
+func (s *AchievementService) DownloadAttachment(c *fiber.Ctx) error {
+	referenceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid achievement reference id",
+		})
+	}
+
+	attachmentID, err := uuid.Parse(c.Params("attachmentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid attachment id",
+		})
+	}
+
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	achievementRef, err := s.AchievementRepo.GetAchievementReferenceByID(referenceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "achievement reference not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get achievement reference",
+		})
+	}
+
+	allowed, err := s.canViewAchievement(c, userID, achievementRef)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to check access",
+		})
+	}
+	if !allowed {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "you are not allowed to view this attachment",
+		})
+	}
+
+	achievement, err := s.AchievementRepo.GetAchievementByID(achievementRef.MongoAchievementID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "achievement not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get achievement",
+		})
+	}
+
+	attachment := achievement.FindAttachment(attachmentID)
+	if attachment == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "attachment not found",
+		})
+	}
+
+	reader, err := s.Attachments.Get(c.UserContext(), attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "attachment file not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to read attachment",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, attachment.ContentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderETag, `"`+attachment.SHA256+`"`)
+
+	// Reader ditutup oleh fasthttp setelah body selesai dikirim
+	return c.SendStream(reader, int(attachment.Size))
+}
+
 // GetStudentAchievements - Get achievements for specific student
 // @Summary Get student achievements
 // @Description Mendapatkan daftar prestasi mahasiswa tertentu
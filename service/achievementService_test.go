@@ -2,6 +2,7 @@ package service
 
 import (
 	"POJECT_UAS/model"
+	"POJECT_UAS/repository"
 	"bytes"
 	"encoding/json"
 	"net/http/httptest"
@@ -19,8 +20,8 @@ type MockAchievementRepository struct {
 	mock.Mock
 }
 
-func (m *MockAchievementRepository) SubmitAchievement(studentID uuid.UUID, req model.SubmitAchievementRequest) (*model.SubmitAchievementResponse, error) {
-	args := m.Called(studentID, req)
+func (m *MockAchievementRepository) SubmitAchievement(studentID uuid.UUID, createdBy uuid.UUID, req model.SubmitAchievementRequest) (*model.SubmitAchievementResponse, error) {
+	args := m.Called(studentID, createdBy, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -35,6 +36,43 @@ func (m *MockAchievementRepository) GetStudentByUserID(userID uuid.UUID) (*model
 	return args.Get(0).(*model.Student), args.Error(1)
 }
 
+func (m *MockAchievementRepository) GetLecturerByUserID(userID uuid.UUID) (*model.Lecturers, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Lecturers), args.Error(1)
+}
+
+func (m *MockAchievementRepository) GetStudentIDsByAdvisor(advisorID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(advisorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockAchievementRepository) CheckLecturerOwnsStudent(lecturerID uuid.UUID, studentID uuid.UUID) (bool, error) {
+	args := m.Called(lecturerID, studentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAchievementRepository) GetAchievementByID(achievementID string) (*model.Achievement, error) {
+	args := m.Called(achievementID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementRepository) GetAchievementsByIDs(achievementIDs []string) (map[string]model.Achievement, error) {
+	args := m.Called(achievementIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]model.Achievement), args.Error(1)
+}
+
 func (m *MockAchievementRepository) GetAchievementReferenceByID(referenceID uuid.UUID) (*model.AchievementReference, error) {
 	args := m.Called(referenceID)
 	if args.Get(0) == nil {
@@ -43,8 +81,37 @@ func (m *MockAchievementRepository) GetAchievementReferenceByID(referenceID uuid
 	return args.Get(0).(*model.AchievementReference), args.Error(1)
 }
 
-func (m *MockAchievementRepository) SubmitForVerification(referenceID uuid.UUID) error {
+func (m *MockAchievementRepository) GetAchievementStatusHistory(referenceID uuid.UUID) ([]model.AchievementStatusHistory, error) {
 	args := m.Called(referenceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AchievementStatusHistory), args.Error(1)
+}
+
+func (m *MockAchievementRepository) ListAchievementReferences(filter model.AchievementListFilter) ([]model.AchievementReferenceWithStudent, model.PageInfo, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(model.PageInfo), args.Error(2)
+	}
+	return args.Get(0).([]model.AchievementReferenceWithStudent), args.Get(1).(model.PageInfo), args.Error(2)
+}
+
+func (m *MockAchievementRepository) UpdateAchievement(referenceID uuid.UUID, updatedBy uuid.UUID, expectedVersion int, req model.UpdateAchievementRequest) (*model.Achievement, error) {
+	args := m.Called(referenceID, updatedBy, expectedVersion, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementRepository) AddAttachments(referenceID uuid.UUID, mongoAchievementID string, attachments []model.Attachment) error {
+	args := m.Called(referenceID, mongoAchievementID, attachments)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepository) SubmitForVerification(referenceID uuid.UUID, submittedBy uuid.UUID) error {
+	args := m.Called(referenceID, submittedBy)
 	return args.Error(0)
 }
 
@@ -53,7 +120,7 @@ func (m *MockAchievementRepository) CreateNotification(notification model.Notifi
 	return args.Error(0)
 }
 
-func (m *MockAchievementRepository) GetAdvisorByStudentID(studentID uuid.UUID) (uuid.UUID, error) {
+func (m *MockAchievementRepository) GetAdvisorUserIDByStudentID(studentID uuid.UUID) (uuid.UUID, error) {
 	args := m.Called(studentID)
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
@@ -66,11 +133,31 @@ func (m *MockAchievementRepository) GetUserByID(userID uuid.UUID) (*model.Users,
 	return args.Get(0).(*model.Users), args.Error(1)
 }
 
-func (m *MockAchievementRepository) DeleteAchievement(referenceID uuid.UUID, mongoAchievementID string) error {
-	args := m.Called(referenceID, mongoAchievementID)
+func (m *MockAchievementRepository) DeleteAchievement(referenceID uuid.UUID, mongoAchievementID string, deletedBy uuid.UUID) error {
+	args := m.Called(referenceID, mongoAchievementID, deletedBy)
 	return args.Error(0)
 }
 
+// withUserID mensimulasikan JWTAuth: user_id disimpan sebagai string
+func withUserID(userID uuid.UUID) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID.String())
+		return c.Next()
+	}
+}
+
+func competitionSubmitRequest(title string) model.SubmitAchievementRequest {
+	return model.SubmitAchievementRequest{
+		AchievementType: "competition",
+		Title:           title,
+		Description:     "Kompetisi programming tingkat nasional",
+		Details: map[string]interface{}{
+			"competition_name":  "Gemastik",
+			"competition_level": "national",
+		},
+	}
+}
+
 func TestAchievementService_SubmitAchievement_Success(t *testing.T) {
 	// Setup
 	mockRepo := new(MockAchievementRepository)
@@ -79,7 +166,7 @@ func TestAchievementService_SubmitAchievement_Success(t *testing.T) {
 	// Mock data
 	userID := uuid.New()
 	studentID := uuid.New()
-	
+
 	student := &model.Student{
 		ID:           studentID,
 		UserID:       userID,
@@ -89,40 +176,29 @@ func TestAchievementService_SubmitAchievement_Success(t *testing.T) {
 		CreatedAt:    time.Now(),
 	}
 
-	submitReq := model.SubmitAchievementRequest{
-		AchievementType: "akademik",
-		Title:           "Juara 1 Programming Contest",
-		Description:     "Kompetisi programming tingkat nasional",
-		Details: map[string]interface{}{
-			"level":    "nasional",
-			"category": "programming",
-		},
-	}
+	submitReq := competitionSubmitRequest("Juara 1 Programming Contest")
 
 	expectedResponse := &model.SubmitAchievementResponse{
 		AchievementID:          "507f1f77bcf86cd799439011",
 		AchievementReferenceID: uuid.New(),
 		StudentID:              studentID,
-		AchievementType:        "akademik",
+		AchievementType:        "competition",
 		Title:                  "Juara 1 Programming Contest",
 		Description:            "Kompetisi programming tingkat nasional",
 		Status:                 "draft",
 		CreatedAt:              time.Now().Format(time.RFC3339),
 	}
 
-	// Mock expectations
+	// Mock expectations: details sudah di-decode sesuai achievement_type
 	mockRepo.On("GetStudentByUserID", userID).Return(student, nil)
-	mockRepo.On("SubmitAchievement", studentID, submitReq).Return(expectedResponse, nil)
+	mockRepo.On("SubmitAchievement", studentID, userID, mock.MatchedBy(func(req model.SubmitAchievementRequest) bool {
+		details, ok := req.Details.(*model.CompetitionDetails)
+		return ok && req.Title == submitReq.Title && *details.CompetitionLevel == "national"
+	})).Return(expectedResponse, nil)
 
 	// Create Fiber app and request
 	app := fiber.New()
-	
-	// Middleware to set user_id in locals
-	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", userID)
-		return c.Next()
-	})
-	
+	app.Use(withUserID(userID))
 	app.Post("/achievements", achievementService.SubmitAchievement)
 
 	reqBody, _ := json.Marshal(submitReq)
@@ -152,21 +228,10 @@ func TestAchievementService_SubmitAchievement_StudentNotFound(t *testing.T) {
 
 	// Create Fiber app and request
 	app := fiber.New()
-	
-	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", userID)
-		return c.Next()
-	})
-	
+	app.Use(withUserID(userID))
 	app.Post("/achievements", achievementService.SubmitAchievement)
 
-	submitReq := model.SubmitAchievementRequest{
-		AchievementType: "akademik",
-		Title:           "Test Achievement",
-		Description:     "Test Description",
-	}
-	reqBody, _ := json.Marshal(submitReq)
-
+	reqBody, _ := json.Marshal(competitionSubmitRequest("Test Achievement"))
 	req := httptest.NewRequest("POST", "/achievements", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 
@@ -179,6 +244,32 @@ func TestAchievementService_SubmitAchievement_StudentNotFound(t *testing.T) {
 
 	// Verify mock was called
 	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "SubmitAchievement", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAchievementService_SubmitAchievement_InvalidDetails(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAchievementRepository)
+	achievementService := &AchievementService{AchievementRepo: mockRepo}
+
+	app := fiber.New()
+	app.Use(withUserID(uuid.New()))
+	app.Post("/achievements", achievementService.SubmitAchievement)
+
+	// competition_level wajib diisi
+	submitReq := competitionSubmitRequest("Test Achievement")
+	submitReq.Details = map[string]interface{}{"competition_name": "Gemastik"}
+	reqBody, _ := json.Marshal(submitReq)
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	resp, err := app.Test(req)
+
+	// Assert: validasi gagal sebelum repository dipanggil
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	mockRepo.AssertNotCalled(t, "GetStudentByUserID", mock.Anything)
 }
 
 func TestAchievementService_SubmitForVerification_Success(t *testing.T) {
@@ -190,35 +281,37 @@ func TestAchievementService_SubmitForVerification_Success(t *testing.T) {
 	studentID := uuid.New()
 	referenceID := uuid.New()
 	advisorID := uuid.New()
+	advisorUserID := uuid.New()
+	mongoID := "507f1f77bcf86cd799439011"
 
 	student := &model.Student{
 		ID:        studentID,
 		UserID:    userID,
-		AdvisorID: &advisorID,
+		AdvisorID: advisorID,
 	}
 
 	achievementRef := &model.AchievementReference{
-		ID:        referenceID,
-		StudentID: studentID,
-		Status:    "draft",
+		ID:                 referenceID,
+		StudentID:          studentID,
+		MongoAchievementID: mongoID,
+		Status:             "draft",
 	}
 
 	// Mock expectations
 	mockRepo.On("GetStudentByUserID", userID).Return(student, nil)
 	mockRepo.On("GetAchievementReferenceByID", referenceID).Return(achievementRef, nil)
-	mockRepo.On("SubmitForVerification", referenceID).Return(nil)
-	mockRepo.On("GetAdvisorByStudentID", studentID).Return(advisorID, nil)
-	mockRepo.On("CreateNotification", mock.AnythingOfType("model.Notification")).Return(nil)
+	mockRepo.On("SubmitForVerification", referenceID, userID).Return(nil)
+	mockRepo.On("GetAchievementByID", mongoID).Return(&model.Achievement{Title: "Juara 1"}, nil)
+	mockRepo.On("GetAdvisorUserIDByStudentID", studentID).Return(advisorUserID, nil)
+	mockRepo.On("GetUserByID", userID).Return(&model.Users{ID: userID, FullName: "Test Student"}, nil)
+	mockRepo.On("CreateNotification", mock.MatchedBy(func(n model.Notification) bool {
+		return n.UserID == advisorUserID && n.Type == "achievement_submitted"
+	})).Return(nil)
 
 	// Create Fiber app and request
 	app := fiber.New()
-	
-	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", userID)
-		return c.Next()
-	})
-	
-	app.Post("/achievements/:reference_id/submit", achievementService.SubmitForVerification)
+	app.Use(withUserID(userID))
+	app.Post("/achievements/:id/submit", achievementService.SubmitForVerification)
 
 	req := httptest.NewRequest("POST", "/achievements/"+referenceID.String()+"/submit", nil)
 
@@ -233,6 +326,35 @@ func TestAchievementService_SubmitForVerification_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestAchievementService_SubmitForVerification_NotOwner(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAchievementRepository)
+	achievementService := &AchievementService{AchievementRepo: mockRepo}
+
+	userID := uuid.New()
+	referenceID := uuid.New()
+
+	mockRepo.On("GetStudentByUserID", userID).Return(&model.Student{ID: uuid.New(), UserID: userID}, nil)
+	mockRepo.On("GetAchievementReferenceByID", referenceID).Return(&model.AchievementReference{
+		ID:        referenceID,
+		StudentID: uuid.New(),
+		Status:    "draft",
+	}, nil)
+
+	app := fiber.New()
+	app.Use(withUserID(userID))
+	app.Post("/achievements/:id/submit", achievementService.SubmitForVerification)
+
+	// Execute
+	resp, err := app.Test(httptest.NewRequest("POST", "/achievements/"+referenceID.String()+"/submit", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 403, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "SubmitForVerification", mock.Anything, mock.Anything)
+}
+
 func TestAchievementService_DeleteAchievement_Success(t *testing.T) {
 	// Setup
 	mockRepo := new(MockAchievementRepository)
@@ -258,17 +380,12 @@ func TestAchievementService_DeleteAchievement_Success(t *testing.T) {
 	// Mock expectations
 	mockRepo.On("GetStudentByUserID", userID).Return(student, nil)
 	mockRepo.On("GetAchievementReferenceByID", referenceID).Return(achievementRef, nil)
-	mockRepo.On("DeleteAchievement", referenceID, mongoID).Return(nil)
+	mockRepo.On("DeleteAchievement", referenceID, mongoID, userID).Return(nil)
 
 	// Create Fiber app and request
 	app := fiber.New()
-	
-	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", userID)
-		return c.Next()
-	})
-	
-	app.Delete("/achievements/:reference_id", achievementService.DeleteAchievement)
+	app.Use(withUserID(userID))
+	app.Delete("/achievements/:id", achievementService.DeleteAchievement)
 
 	req := httptest.NewRequest("DELETE", "/achievements/"+referenceID.String(), nil)
 
@@ -281,4 +398,61 @@ func TestAchievementService_DeleteAchievement_Success(t *testing.T) {
 
 	// Verify mock was called
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestAchievementService_DeleteAchievement_NotDraft(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAchievementRepository)
+	achievementService := &AchievementService{AchievementRepo: mockRepo}
+
+	userID := uuid.New()
+	studentID := uuid.New()
+	referenceID := uuid.New()
+
+	mockRepo.On("GetStudentByUserID", userID).Return(&model.Student{ID: studentID, UserID: userID}, nil)
+	mockRepo.On("GetAchievementReferenceByID", referenceID).Return(&model.AchievementReference{
+		ID:        referenceID,
+		StudentID: studentID,
+		Status:    "submitted",
+	}, nil)
+
+	app := fiber.New()
+	app.Use(withUserID(userID))
+	app.Delete("/achievements/:id", achievementService.DeleteAchievement)
+
+	// Execute
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/achievements/"+referenceID.String(), nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "DeleteAchievement", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAchievementService_GetAchievements_SearchTooBroad(t *testing.T) {
+	// Setup
+	mockRepo := new(MockAchievementRepository)
+	achievementService := &AchievementService{AchievementRepo: mockRepo}
+
+	userID := uuid.New()
+	studentID := uuid.New()
+
+	mockRepo.On("GetStudentByUserID", userID).Return(&model.Student{ID: studentID, UserID: userID}, nil)
+	mockRepo.On("ListAchievementReferences", mock.MatchedBy(func(filter model.AchievementListFilter) bool {
+		return filter.Search == "juara"
+	})).Return(nil, model.PageInfo{}, repository.ErrAchievementSearchTooBroad)
+
+	app := fiber.New()
+	app.Use(withUserID(userID))
+	app.Get("/achievements", achievementService.GetAchievements)
+
+	// Execute
+	resp, err := app.Test(httptest.NewRequest("GET", "/achievements?search=juara", nil))
+
+	// Assert: filter terlalu luas dilaporkan sebagai 400, bukan 500
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetAchievementsByIDs", mock.Anything)
+}
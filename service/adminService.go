@@ -1,10 +1,13 @@
 package service
 
 import (
+	"POJECT_UAS/middleware"
 	"POJECT_UAS/model"
 	"POJECT_UAS/repository"
-	"math"
-	"strconv"
+	"POJECT_UAS/userimport"
+	"database/sql"
+	"errors"
+	"io"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -13,12 +16,20 @@ import (
 type AdminService struct {
 	UserRepo        *repository.UserRepository
 	AchievementRepo *repository.AchievementRepository
+	Revocations     *middleware.RevocationStore
+	Importer        *userimport.Importer // import user dari CSV/XLSX, diisi di main
+	RoleRepo        *repository.RoleRepository
 }
 
-func NewAdminService(userRepo *repository.UserRepository, achievementRepo *repository.AchievementRepository) *AdminService {
+func NewAdminService(
+	userRepo *repository.UserRepository,
+	achievementRepo *repository.AchievementRepository,
+	revocations *middleware.RevocationStore,
+) *AdminService {
 	return &AdminService{
 		UserRepo:        userRepo,
 		AchievementRepo: achievementRepo,
+		Revocations:     revocations,
 	}
 }
 
@@ -79,6 +90,15 @@ func (s *AdminService) UpdateUser(c *fiber.Ctx) error {
 		})
 	}
 
+	// User yang dinonaktifkan tidak boleh tetap memakai token lamanya
+	if !user.IsActive {
+		if err := s.Revocations.RevokeUser(userID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "user updated but failed to revoke sessions",
+			})
+		}
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "user updated successfully",
 		"data":    user,
@@ -104,42 +124,41 @@ func (s *AdminService) DeleteUser(c *fiber.Ctx) error {
 		})
 	}
 
+	// Cabut semua sesi agar token user yang dinonaktifkan langsung tidak berlaku
+	if err := s.Revocations.RevokeUser(userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "user deleted but failed to revoke sessions",
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "user deleted successfully",
 	})
 }
 
 // GetAllUsers - Admin get all users (FR-009)
+// Mendukung pagination page/per_page atau cursor (lihat parsePageRequest)
 func (s *AdminService) GetAllUsers(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	perPage, _ := strconv.Atoi(c.Query("per_page", "10"))
-
-	if page < 1 {
-		page = 1
-	}
-	if perPage < 1 || perPage > 100 {
-		perPage = 10
+	page, fieldErrs := parsePageRequest(c)
+	if fieldErrs != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid pagination",
+			"details": fieldErrs,
+		})
 	}
 
-	users, totalCount, err := s.UserRepo.GetAllUsers(page, perPage)
+	users, pageInfo, err := s.UserRepo.GetAllUsers(page)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed to get users",
 		})
 	}
 
-	totalPages := int(math.Ceil(float64(totalCount) / float64(perPage)))
-
 	return c.JSON(fiber.Map{
 		"message": "success",
 		"data": fiber.Map{
-			"users": users,
-			"pagination": fiber.Map{
-				"current_page": page,
-				"per_page":     perPage,
-				"total_pages":  totalPages,
-				"total_items":  totalCount,
-			},
+			"users":      users,
+			"pagination": page.Meta(pageInfo),
 		},
 	})
 }
@@ -237,165 +256,111 @@ func (s *AdminService) UpdateStudentAdvisor(c *fiber.Ctx) error {
 	})
 }
 
-// GetAllRoles - Admin get all roles (FR-009)
-func (s *AdminService) GetAllRoles(c *fiber.Ctx) error {
-	roles, err := s.UserRepo.GetAllRoles()
+// RevokeUserSessions - Admin cabut semua sesi user
+// @Summary Revoke user sessions
+// @Description Admin mencabut semua access token dan refresh token milik user
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{} "Sessions revoked"
+// @Failure 400 {object} map[string]string "Invalid user id"
+// @Router /api/v1/users/{id}/revoke-sessions [post]
+func (s *AdminService) RevokeUserSessions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid user id",
+		})
+	}
+
+	if err := s.Revocations.RevokeUser(userID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get roles",
+			"error": "failed to revoke sessions",
 		})
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "success",
-		"data":    roles,
+		"message": "all sessions revoked",
+		"data": fiber.Map{
+			"user_id": userID,
+		},
 	})
 }
 
-// ViewAllAchievements - Admin view all achievements dengan filter dan pagination (FR-010)
-func (s *AdminService) ViewAllAchievements(c *fiber.Ctx) error {
-	// Parse query parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	perPage, _ := strconv.Atoi(c.Query("per_page", "10"))
-	status := c.Query("status", "")
-	achievementType := c.Query("achievement_type", "")
-
-	// Validasi pagination
-	if page < 1 {
-		page = 1
-	}
-	if perPage < 1 || perPage > 100 {
-		perPage = 10
-	}
-
-	// 1. Get achievement references dengan filter dan pagination
-	references, totalCount, err := s.AchievementRepo.GetAllAchievementReferencesWithPagination(
-		status,
-		achievementType,
-		page,
-		perPage,
-	)
+// ImportUsers - Admin import user beserta profil mahasiswa/dosen dari CSV/XLSX
+// @Summary Import users
+// @Description Membuat user, profil mahasiswa (dengan dosen wali berdasarkan NIDN) dan profil dosen dari file CSV/XLSX.
+// @Description Seluruh file disimpan dalam satu transaksi dan hanya jika semua baris valid; dry_run=true hanya memvalidasi.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Param file formData file true "File CSV atau XLSX"
+// @Param dry_run query bool false "Hanya validasi, tidak ada yang disimpan"
+// @Success 200 {object} userimport.Report "Dry-run valid"
+// @Success 201 {object} userimport.Report "Imported"
+// @Failure 400 {object} map[string]string "Invalid file"
+// @Failure 422 {object} userimport.Report "Some rows are invalid"
+// @Router /api/v1/users/import [post]
+func (s *AdminService) ImportUsers(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get achievement references",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "file is required",
 		})
 	}
-
-	if len(references) == 0 {
-		return c.JSON(fiber.Map{
-			"message": "success",
-			"data": fiber.Map{
-				"achievements": []interface{}{},
-				"pagination": fiber.Map{
-					"current_page": page,
-					"per_page":     perPage,
-					"total_pages":  0,
-					"total_items":  totalCount,
-				},
-			},
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "failed to read file",
 		})
 	}
-
-	// 2. Collect MongoDB achievement IDs
-	var mongoIDs []string
-	var studentIDs []uuid.UUID
-	for _, ref := range references {
-		mongoIDs = append(mongoIDs, ref.MongoAchievementID)
-		studentIDs = append(studentIDs, ref.StudentID)
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "failed to read file",
+		})
 	}
 
-	// 3. Batch fetch achievements dari MongoDB
-	achievementsMap, err := s.AchievementRepo.GetAchievementsByIDs(mongoIDs)
+	rows, err := userimport.ReadFile(data)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get achievements from MongoDB",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
-	// 4. Batch fetch student info
-	studentsMap := make(map[uuid.UUID]*model.Student)
-	usersMap := make(map[uuid.UUID]*model.Users)
-
-	for _, studentID := range studentIDs {
-		if _, exists := studentsMap[studentID]; !exists {
-			student, err := s.AchievementRepo.GetStudentByID(studentID)
-			if err != nil {
-				continue // Skip jika error
-			}
-			studentsMap[studentID] = student
-
-			// Get user info
-			user, err := s.AchievementRepo.GetUserByID(student.UserID)
-			if err != nil {
-				continue
-			}
-			usersMap[student.UserID] = user
-		}
+	dryRun := c.QueryBool("dry_run")
+	report, err := s.Importer.Run(c.UserContext(), rows, userimport.Options{DryRun: dryRun})
+	if errors.Is(err, userimport.ErrTooManyRows) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
 	}
-
-	// 5. Combine data
-	var result []fiber.Map
-	for _, ref := range references {
-		achievement, exists := achievementsMap[ref.MongoAchievementID]
-		if !exists {
-			continue
-		}
-
-		student := studentsMap[ref.StudentID]
-		var user *model.Users
-		if student != nil {
-			user = usersMap[student.UserID]
-		}
-
-		// Filter by achievement type if specified
-		if achievementType != "" && achievement.AchievementType != achievementType {
-			continue
-		}
-
-		item := fiber.Map{
-			"reference_id":       ref.ID,
-			"achievement_id":     ref.MongoAchievementID,
-			"student_id":         ref.StudentID,
-			"achievement_type":   achievement.AchievementType,
-			"title":              achievement.Title,
-			"description":        achievement.Description,
-			"status":             ref.Status,
-			"submitted_at":       ref.SubmittedAt,
-			"verified_at":        ref.VerifiedAt,
-			"verified_by":        ref.VerifiedBy,
-			"rejection_note":     ref.RejectionNote,
-			"created_at":         ref.CreatedAt,
-			"updated_at":         ref.UpdatedAt,
-		}
-
-		// Add student info if available
-		if student != nil && user != nil {
-			item["student_info"] = fiber.Map{
-				"student_id_number": student.StudentID,
-				"full_name":         user.FullName,
-				"program_study":     student.ProgramStudy,
-				"academic_year":     student.AcademicYear,
-			}
-		}
-
-		result = append(result, item)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to import users",
+		})
 	}
 
-	totalPages := int(math.Ceil(float64(totalCount) / float64(perPage)))
-
-	return c.JSON(fiber.Map{
-		"message": "success",
-		"data": fiber.Map{
-			"achievements": result,
-			"pagination": fiber.Map{
-				"current_page": page,
-				"per_page":     perPage,
-				"total_pages":  totalPages,
-				"total_items":  totalCount,
-			},
-		},
-	})
+	switch {
+	case report.ErrorRows > 0:
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": "import contains invalid rows, nothing was saved",
+			"data":  report,
+		})
+	case dryRun:
+		return c.JSON(fiber.Map{
+			"message": "all rows are valid",
+			"data":    report,
+		})
+	default:
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"message": "users imported successfully",
+			"data":    report,
+		})
+	}
 }
+
 // GetUserByID - Admin get user by ID
 // @Summary Get user by ID
 // @Description Admin mendapatkan detail user berdasarkan ID
@@ -414,18 +379,95 @@ func (s *AdminService) GetUserByID(c *fiber.Ctx) error {
 
 // UpdateUserRole - Admin update user role
 // @Summary Update user role
-// @Description Admin mengubah role user
+// @Description Admin mengubah role user. Semua sesi user dicabut agar role baru langsung berlaku.
+// @Description Hanya super_admin yang bisa memberikan atau mencabut role super_admin, dan admin tidak bisa mengubah role-nya sendiri.
 // @Tags Admin
 // @Security BearerAuth
 // @Param id path string true "User ID"
-// @Param request body map[string]string true "Role update request"
-// @Success 200 {object} map[string]string "Role updated"
+// @Param request body model.UpdateUserRoleRequest true "Role update request"
+// @Success 200 {object} map[string]interface{} "Role updated"
 // @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "User not found"
 // @Router /api/v1/users/{id}/role [put]
 func (s *AdminService) UpdateUserRole(c *fiber.Ctx) error {
-	// TODO: Implement update user role
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid user id",
+		})
+	}
+
+	var req model.UpdateUserRoleRequest
+	if err := c.BodyParser(&req); err != nil || req.RoleID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "role_id is required",
+		})
+	}
+
+	if userID.String() == middleware.GetUserID(c) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "you cannot change your own role",
+		})
+	}
+
+	ctx := c.Context()
+	role, err := s.RoleRepo.GetRole(ctx, req.RoleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "role not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get role",
+		})
+	}
+
+	currentRole, err := s.RoleRepo.GetUserRoleName(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "user not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get user",
+		})
+	}
+
+	// Admin biasa tidak boleh menaikkan siapa pun (termasuk dirinya lewat akun lain) ke super_admin
+	if (role.Name == "super_admin" || currentRole == "super_admin") && middleware.GetRoleName(c) != "super_admin" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "only super_admin can assign or revoke the super_admin role",
+		})
+	}
+
+	if err := s.RoleRepo.AssignUserRole(ctx, userID, role.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "user not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to update user role",
+		})
+	}
+
+	// Token lama masih membawa role dan permission sebelumnya
+	if err := s.Revocations.RevokeUser(userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "role updated but failed to revoke sessions",
+		})
+	}
+
 	return c.JSON(fiber.Map{
-		"message": "update user role - coming soon",
+		"message": "user role updated successfully",
+		"data": fiber.Map{
+			"user_id":   userID,
+			"role_id":   role.ID,
+			"role_name": role.Name,
+		},
 	})
 }
 
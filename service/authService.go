@@ -1,19 +1,31 @@
 package service
 
 import (
+	"POJECT_UAS/middleware"
 	"POJECT_UAS/model"
 	"POJECT_UAS/repository"
+	"errors"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
+// AuthRepository method repository.AuthRepository yang dipakai AuthService
+type AuthRepository interface {
+	Login(req model.LoginRequest) (*model.LoginResponse, error)
+	RefreshToken(refreshToken string) (*model.LoginResponse, error)
+	RevokeRefreshToken(refreshToken string, userID uuid.UUID) error
+}
+
 type AuthService struct {
-	AuthRepo *repository.AuthRepository
+	AuthRepo    AuthRepository
+	Revocations *middleware.RevocationStore
 }
 
-func NewAuthService(authRepo *repository.AuthRepository) *AuthService {
+func NewAuthService(authRepo AuthRepository, revocations *middleware.RevocationStore) *AuthService {
 	return &AuthService{
-		AuthRepo: authRepo,
+		AuthRepo:    authRepo,
+		Revocations: revocations,
 	}
 }
 
@@ -74,19 +86,18 @@ func (e *ValidationError) Error() string {
 }
 // RefreshToken - Refresh JWT token
 // @Summary Refresh JWT token
-// @Description Refresh expired JWT token dengan refresh token
+// @Description Tukar refresh token dengan access token baru. Refresh token dirotasi setiap dipakai;
+// @Description token lama yang dipakai ulang akan mencabut seluruh sesi terkait.
 // @Tags Authentication
 // @Accept json
 // @Produce json
-// @Param request body map[string]string true "Refresh token"
-// @Success 200 {object} map[string]interface{} "Token refreshed"
+// @Param request body model.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} model.LoginResponse "Token refreshed"
 // @Failure 400 {object} map[string]string "Invalid request"
 // @Failure 401 {object} map[string]string "Invalid refresh token"
 // @Router /api/v1/auth/refresh [post]
 func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
-	var req struct {
-		RefreshToken string `json:"refresh_token"`
-	}
+	var req model.RefreshTokenRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -100,29 +111,81 @@ func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	// TODO: Implement refresh token logic
-	// For now, return placeholder response
+	// Rotasi refresh token dan terbitkan access token baru
+	response, err := s.AuthRepo.RefreshToken(req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInvalidRefreshToken),
+			errors.Is(err, repository.ErrRefreshTokenExpired),
+			errors.Is(err, repository.ErrRefreshTokenReused),
+			errors.Is(err, repository.ErrAccountDisabled):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to refresh token",
+			})
+		}
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "token refreshed successfully",
-		"data": fiber.Map{
-			"token": "new-jwt-token",
-		},
+		"data":    response,
 	})
 }
 
 // Logout - User logout
 // @Summary User logout
-// @Description Logout user dan invalidate token
+// @Description Logout user: access token yang dipakai dicabut, dan jika refresh_token dikirim
+// @Description seluruh sesi refresh token tersebut ikut dicabut
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param request body model.RefreshTokenRequest false "Refresh token (opsional)"
 // @Success 200 {object} map[string]string "Logout successful"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Router /api/v1/auth/logout [post]
 func (s *AuthService) Logout(c *fiber.Ctx) error {
-	// TODO: Implement token blacklisting
-	// For now, return success response
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	jti := middleware.GetTokenID(c)
+	if jti == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "token does not support logout, please login again",
+		})
+	}
+
+	// Body opsional, berisi refresh token yang ikut dicabut
+	var req model.RefreshTokenRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+	}
+
+	if err := s.Revocations.RevokeToken(jti, userID, middleware.GetTokenExpiresAt(c)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to logout",
+		})
+	}
+
+	if req.RefreshToken != "" {
+		if err := s.AuthRepo.RevokeRefreshToken(req.RefreshToken, userID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to revoke refresh token",
+			})
+		}
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "logout successful",
 	})
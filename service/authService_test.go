@@ -2,12 +2,10 @@ package service
 
 import (
 	"POJECT_UAS/model"
-	"POJECT_UAS/repository"
 	"bytes"
 	"encoding/json"
 	"net/http/httptest"
 	"testing"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -20,14 +18,27 @@ type MockAuthRepository struct {
 	mock.Mock
 }
 
-func (m *MockAuthRepository) Login(username, password string) (*model.LoginResponse, error) {
-	args := m.Called(username, password)
+func (m *MockAuthRepository) Login(req model.LoginRequest) (*model.LoginResponse, error) {
+	args := m.Called(req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.LoginResponse), args.Error(1)
 }
 
+func (m *MockAuthRepository) RefreshToken(refreshToken string) (*model.LoginResponse, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LoginResponse), args.Error(1)
+}
+
+func (m *MockAuthRepository) RevokeRefreshToken(refreshToken string, userID uuid.UUID) error {
+	args := m.Called(refreshToken, userID)
+	return args.Error(0)
+}
+
 func TestAuthService_Login_Success(t *testing.T) {
 	// Setup
 	mockRepo := new(MockAuthRepository)
@@ -36,25 +47,25 @@ func TestAuthService_Login_Success(t *testing.T) {
 	// Mock data
 	expectedResponse := &model.LoginResponse{
 		Token: "mock-jwt-token",
-		User: model.UserProfile{
+		Profile: model.UserProfile{
 			ID:       uuid.New(),
 			Username: "testuser",
 			Email:    "test@example.com",
 			FullName: "Test User",
-			Role:     "student",
+			Role:     model.RoleInfo{Name: "student"},
 		},
 	}
 
 	// Mock expectations
-	mockRepo.On("Login", "testuser", "password123").Return(expectedResponse, nil)
+	mockRepo.On("Login", model.LoginRequest{Credential: "testuser", Password: "password123"}).Return(expectedResponse, nil)
 
 	// Create Fiber app and request
 	app := fiber.New()
 	app.Post("/login", authService.Login)
 
 	loginReq := model.LoginRequest{
-		Username: "testuser",
-		Password: "password123",
+		Credential: "testuser",
+		Password:   "password123",
 	}
 	reqBody, _ := json.Marshal(loginReq)
 
@@ -78,15 +89,15 @@ func TestAuthService_Login_InvalidCredentials(t *testing.T) {
 	authService := &AuthService{AuthRepo: mockRepo}
 
 	// Mock expectations - return error for invalid credentials
-	mockRepo.On("Login", "wronguser", "wrongpass").Return(nil, assert.AnError)
+	mockRepo.On("Login", model.LoginRequest{Credential: "wronguser", Password: "wrongpass"}).Return(nil, assert.AnError)
 
 	// Create Fiber app and request
 	app := fiber.New()
 	app.Post("/login", authService.Login)
 
 	loginReq := model.LoginRequest{
-		Username: "wronguser",
-		Password: "wrongpass",
+		Credential: "wronguser",
+		Password:   "wrongpass",
 	}
 	reqBody, _ := json.Marshal(loginReq)
 
@@ -125,7 +136,7 @@ func TestAuthService_Login_InvalidRequestBody(t *testing.T) {
 	assert.Equal(t, 400, resp.StatusCode)
 
 	// Verify no repository calls were made
-	mockRepo.AssertNotCalled(t, "Login")
+	mockRepo.AssertNotCalled(t, "Login", mock.Anything)
 }
 
 func TestAuthService_Login_MissingFields(t *testing.T) {
@@ -139,8 +150,8 @@ func TestAuthService_Login_MissingFields(t *testing.T) {
 
 	// Missing password
 	loginReq := model.LoginRequest{
-		Username: "testuser",
-		Password: "", // empty password
+		Credential: "testuser",
+		Password:   "", // empty password
 	}
 	reqBody, _ := json.Marshal(loginReq)
 
@@ -155,5 +166,5 @@ func TestAuthService_Login_MissingFields(t *testing.T) {
 	assert.Equal(t, 400, resp.StatusCode)
 
 	// Verify no repository calls were made
-	mockRepo.AssertNotCalled(t, "Login")
-}
\ No newline at end of file
+	mockRepo.AssertNotCalled(t, "Login", mock.Anything)
+}
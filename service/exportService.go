@@ -0,0 +1,445 @@
+package service
+
+import (
+	"POJECT_UAS/export"
+	"POJECT_UAS/middleware"
+	"POJECT_UAS/model"
+	"POJECT_UAS/repository"
+	"POJECT_UAS/storage"
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Bawaan ExportService, ditimpa dari konfigurasi export
+const (
+	defaultExportSyncLimit    = 5000
+	defaultExportBatchSize    = 500
+	defaultExportPollInterval = 5 * time.Second
+	defaultExportRetention    = 24 * time.Hour
+)
+
+// exportFilePrefix awalan nama file ekspor daftar prestasi
+const exportFilePrefix = "prestasi"
+
+type ExportService struct {
+	AchievementRepo *repository.AchievementRepository
+	Jobs            *repository.ExportJobRepository
+	Files           storage.AttachmentStore // file hasil job, key exports/<job id>.<format>
+
+	SyncLimit    int // ekspor lebih dari ini baris dijalankan sebagai job
+	BatchSize    int
+	PollInterval time.Duration
+	Retention    time.Duration // lama file hasil job disimpan
+}
+
+func NewExportService(achievementRepo *repository.AchievementRepository, jobs *repository.ExportJobRepository, files storage.AttachmentStore) *ExportService {
+	return &ExportService{
+		AchievementRepo: achievementRepo,
+		Jobs:            jobs,
+		Files:           files,
+		SyncLimit:       defaultExportSyncLimit,
+		BatchSize:       defaultExportBatchSize,
+		PollInterval:    defaultExportPollInterval,
+		Retention:       defaultExportRetention,
+	}
+}
+
+// ExportAchievements - Ekspor daftar prestasi ke CSV/XLSX
+// @Summary Export achievements
+// @Description Ekspor daftar prestasi beserta NIM, nama, program studi, status dan info verifikasi. Filter sama dengan daftar prestasi dan dibatasi sesuai role. Hasil sampai export.sync_limit baris langsung diunduh; lebih dari itu, atau jika async=true, dibuat job (202) yang hasilnya diunduh lewat /reports/exports/{id}/download.
+// @Tags Reports
+// @Security BearerAuth
+// @Produce octet-stream
+// @Param format query string false "csv (default) atau xlsx"
+// @Param async query bool false "true untuk selalu membuat job"
+// @Param status query string false "draft, submitted, verified, rejected atau deleted"
+// @Param achievement_type query string false "Tipe prestasi"
+// @Param program_study query string false "Program studi"
+// @Param academic_year query string false "Angkatan"
+// @Param search query string false "Cari di judul dan deskripsi"
+// @Param start_date query string false "YYYY-MM-DD"
+// @Param end_date query string false "YYYY-MM-DD"
+// @Param sort_by query string false "created_at, updated_at, submitted_at, verified_at atau status"
+// @Param sort_order query string false "asc atau desc (default desc)"
+// @Success 200 {file} file "File ekspor"
+// @Success 202 {object} model.ExportJob "Job ekspor dibuat"
+// @Failure 400 {object} map[string]interface{} "Invalid filter"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Router /api/v1/reports/achievements/export [get]
+func (s *ExportService) ExportAchievements(c *fiber.Ctx) error {
+	filter, fieldErrs := parseAchievementListFilter(c)
+
+	format := strings.ToLower(c.Query("format", export.FormatCSV))
+	if !export.IsValidFormat(format) {
+		fieldErrs = append(fieldErrs, model.FieldError{
+			Field:   "format",
+			Message: "harus salah satu dari: " + strings.Join(export.Formats, ", "),
+		})
+	}
+
+	async := false
+	if raw := c.Query("async"); raw != "" {
+		var err error
+		if async, err = strconv.ParseBool(raw); err != nil {
+			fieldErrs = append(fieldErrs, model.FieldError{Field: "async", Message: "harus true atau false"})
+		}
+	}
+
+	if fieldErrs != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid filter",
+			"details": fieldErrs,
+		})
+	}
+
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	// Batasi hasil sesuai role, sama seperti daftar prestasi
+	studentIDs, err := achievementScope(c, s.AchievementRepo, userID)
+	if err == errNoAchievementScope {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "you are not allowed to export achievements",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to check access",
+		})
+	}
+	filter.StudentIDs = studentIDs
+	filter.PageRequest = model.PageRequest{}
+
+	total, err := s.AchievementRepo.CountAchievementReferences(c.UserContext(), filter)
+	if errors.Is(err, repository.ErrAchievementSearchTooBroad) {
+		return searchTooBroadError(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to count achievements",
+		})
+	}
+
+	if async || total > int64(s.SyncLimit) {
+		return s.createExportJob(c, userID, format, filter)
+	}
+
+	c.Set(fiber.HeaderContentType, export.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{
+		"filename": export.FileName(exportFilePrefix, format, time.Now()),
+	}))
+	c.Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	// Body ditulis setelah handler selesai, jadi error di tengah jalan hanya bisa di-log
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if _, err := s.writeAchievements(context.Background(), format, filter, w); err != nil {
+			log.Printf("export: gagal menulis ekspor prestasi: %v", err)
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+// createExportJob memasukkan ekspor ke antrean job
+func (s *ExportService) createExportJob(c *fiber.Ctx, userID uuid.UUID, format string, filter model.AchievementListFilter) error {
+	rawFilter, err := json.Marshal(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to create export job",
+		})
+	}
+
+	job := &model.ExportJob{
+		RequestedBy: userID,
+		Format:      format,
+		Filter:      rawFilter,
+	}
+	if err := s.Jobs.Create(c.UserContext(), job); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to create export job",
+		})
+	}
+
+	c.Set(fiber.HeaderLocation, exportJobURL(job.ID))
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "export job created",
+		"data":    job,
+	})
+}
+
+// GetExportJob - Status job ekspor
+// @Summary Get export job
+// @Description Status job ekspor milik user yang login. download_url terisi jika status completed.
+// @Tags Reports
+// @Security BearerAuth
+// @Param id path string true "Export job ID"
+// @Success 200 {object} model.ExportJob
+// @Failure 404 {object} map[string]string "Job not found"
+// @Router /api/v1/reports/exports/{id} [get]
+func (s *ExportService) GetExportJob(c *fiber.Ctx) error {
+	job, err := s.ownExportJob(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "success",
+		"data":    job,
+	})
+}
+
+// DownloadExport - Unduh hasil job ekspor
+// @Summary Download export
+// @Tags Reports
+// @Security BearerAuth
+// @Produce octet-stream
+// @Param id path string true "Export job ID"
+// @Success 200 {file} file "File ekspor"
+// @Failure 404 {object} map[string]string "Job not found"
+// @Failure 409 {object} map[string]string "Job belum selesai"
+// @Failure 410 {object} map[string]string "File sudah dihapus"
+// @Router /api/v1/reports/exports/{id}/download [get]
+func (s *ExportService) DownloadExport(c *fiber.Ctx) error {
+	job, err := s.ownExportJob(c)
+	if err != nil {
+		return err
+	}
+
+	expired := job.Status == model.ExportExpired ||
+		(job.Status == model.ExportCompleted && job.ExpiresAt != nil && !time.Now().Before(*job.ExpiresAt))
+	if expired {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": "export file has expired",
+		})
+	}
+	if job.Status != model.ExportCompleted || job.FileKey == nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":  "export job is not completed",
+			"status": job.Status,
+		})
+	}
+
+	reader, err := s.Files.Get(c.UserContext(), *job.FileKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{
+				"error": "export file has expired",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to read export file",
+		})
+	}
+
+	size := -1
+	if job.FileSize != nil {
+		size = int(*job.FileSize)
+	}
+
+	c.Set(fiber.HeaderContentType, export.ContentType(job.Format))
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": *job.FileName}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+
+	// Reader ditutup oleh fasthttp setelah body selesai dikirim
+	return c.SendStream(reader, size)
+}
+
+// ownExportJob job dari path :id milik user yang login. Job milik user lain dianggap
+// tidak ada. Jika error tidak nil, response sudah dikirim.
+func (s *ExportService) ownExportJob(c *fiber.Ctx) (*model.ExportJob, error) {
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid export job id",
+		})
+	}
+
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return nil, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	job, err := s.Jobs.GetByID(c.UserContext(), jobID)
+	if err == sql.ErrNoRows || (err == nil && job.RequestedBy != userID) {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "export job not found",
+		})
+	}
+	if err != nil {
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get export job",
+		})
+	}
+
+	if job.Status == model.ExportCompleted {
+		job.DownloadURL = exportJobURL(job.ID) + "/download"
+	}
+	return job, nil
+}
+
+func exportJobURL(id uuid.UUID) string {
+	return "/api/v1/reports/exports/" + id.String()
+}
+
+// writeAchievements menulis header dan semua prestasi yang cocok dengan filter ke w.
+// Mengembalikan jumlah prestasi yang ditulis.
+func (s *ExportService) writeAchievements(ctx context.Context, format string, filter model.AchievementListFilter, w io.Writer) (int64, error) {
+	writer, err := export.NewWriter(format, w, "Prestasi")
+	if err != nil {
+		return 0, err
+	}
+	if err := writer.WriteRow(export.AchievementColumns...); err != nil {
+		return 0, err
+	}
+
+	var no int64
+	written, err := s.AchievementRepo.StreamAchievementExport(ctx, filter, s.BatchSize, func(rows []model.AchievementExportRow) error {
+		for _, row := range rows {
+			no++
+			if err := writer.WriteRow(export.AchievementRow(no, row)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return written, err
+	}
+
+	return written, writer.Close()
+}
+
+// RunWorker menjalankan job ekspor dan menghapus file yang kedaluwarsa setiap
+// PollInterval sampai ctx dibatalkan
+func (s *ExportService) RunWorker(ctx context.Context) {
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.processDue(ctx)
+		s.removeExpired(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processDue menjalankan job yang menunggu satu per satu sampai antrean kosong
+func (s *ExportService) processDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.Jobs.ClaimNext(ctx)
+		if err != nil {
+			log.Printf("export: gagal mengambil job: %v", err)
+			return
+		}
+		if job == nil {
+			return
+		}
+
+		runErr := s.runJob(ctx, job)
+		if runErr == nil {
+			continue
+		}
+
+		// Tetap dicatat walaupun worker sedang berhenti agar job bisa diambil lagi
+		final, err := s.Jobs.MarkFailed(context.WithoutCancel(ctx), *job, runErr)
+		if err != nil {
+			log.Printf("export %s: gagal menyimpan status: %v", job.ID, err)
+		}
+		if final {
+			log.Printf("export %s gagal permanen setelah %d percobaan: %v", job.ID, job.Attempts, runErr)
+		} else {
+			log.Printf("export %s gagal (percobaan %d), dicoba lagi: %v", job.ID, job.Attempts, runErr)
+		}
+	}
+}
+
+// runJob menulis ekspor langsung ke storage lewat pipe, tanpa file sementara
+func (s *ExportService) runJob(ctx context.Context, job *model.ExportJob) error {
+	var filter model.AchievementListFilter
+	if err := json.Unmarshal(job.Filter, &filter); err != nil {
+		return err
+	}
+
+	key := "exports/" + job.ID.String() + "." + job.Format
+	pr, pw := io.Pipe()
+	counter := &countingWriter{w: pw}
+
+	type result struct {
+		rows int64
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		rows, err := s.writeAchievements(ctx, job.Format, filter, counter)
+		pw.CloseWithError(err)
+		done <- result{rows, err}
+	}()
+
+	putErr := s.Files.Put(ctx, key, pr, -1, export.ContentType(job.Format))
+	// Hentikan penulis jika Put berhenti sebelum semua data terbaca
+	pr.CloseWithError(putErr)
+	res := <-done
+	if res.err != nil {
+		return res.err
+	}
+	if putErr != nil {
+		return putErr
+	}
+
+	fileName := export.FileName(exportFilePrefix, job.Format, job.CreatedAt)
+	return s.Jobs.MarkCompleted(ctx, job.ID, key, fileName, counter.n, res.rows, time.Now().Add(s.Retention))
+}
+
+// removeExpired menghapus file hasil job yang masa simpannya sudah habis
+func (s *ExportService) removeExpired(ctx context.Context) {
+	jobs, err := s.Jobs.ListExpired(ctx, 100)
+	if err != nil {
+		log.Printf("export: gagal mengambil job kedaluwarsa: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if job.FileKey != nil {
+			if err := s.Files.Delete(ctx, *job.FileKey); err != nil {
+				log.Printf("export %s: gagal menghapus file: %v", job.ID, err)
+				continue
+			}
+		}
+		if err := s.Jobs.MarkExpired(ctx, job.ID); err != nil {
+			log.Printf("export %s: gagal menandai kedaluwarsa: %v", job.ID, err)
+		}
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,471 @@
+package service
+
+import (
+	"POJECT_UAS/middleware"
+	"POJECT_UAS/model"
+	"POJECT_UAS/realtime"
+	"POJECT_UAS/repository"
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// streamHeartbeat interval ping agar proxy tidak menutup koneksi yang idle
+const streamHeartbeat = 25 * time.Second
+
+type NotificationService struct {
+	NotificationRepo *repository.NotificationRepository
+	Realtime         *realtime.Hub
+}
+
+func NewNotificationService(notificationRepo *repository.NotificationRepository, hub *realtime.Hub) *NotificationService {
+	return &NotificationService{
+		NotificationRepo: notificationRepo,
+		Realtime:         hub,
+	}
+}
+
+// currentUserID mengambil user_id pemanggil dari JWT
+func currentUserID(c *fiber.Ctx) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// GetNotifications godoc
+// @Summary Get notifications
+// @Description Daftar notifikasi milik user yang login, terbaru lebih dulu.
+// @Tags Notifications
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param per_page query int false "Items per page (maks. 100)"
+// @Param unread query bool false "true untuk hanya notifikasi yang belum dibaca"
+// @Param cursor query string false "Pagination keyset: kosong untuk halaman pertama, lalu next_cursor/prev_cursor"
+// @Param include_total query bool false "false untuk melewati perhitungan total_items"
+// @Success 200 {object} model.NotificationListResponse
+// @Failure 400 {object} map[string]interface{} "Invalid parameter"
+// @Router /notifications [get]
+func (s *NotificationService) GetNotifications(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	page, fieldErrs := parsePageRequest(c)
+
+	unreadOnly := false
+	if raw := c.Query("unread"); raw != "" {
+		var err error
+		unreadOnly, err = strconv.ParseBool(raw)
+		if err != nil {
+			fieldErrs = append(fieldErrs, model.FieldError{Field: "unread", Message: "harus true atau false"})
+		}
+	}
+
+	if fieldErrs != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid query parameters",
+			"details": fieldErrs,
+		})
+	}
+
+	notifications, pageInfo, err := s.NotificationRepo.ListNotifications(userID, unreadOnly, page)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get notifications",
+		})
+	}
+
+	unreadCount, err := s.NotificationRepo.CountUnread(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to count unread notifications",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "success",
+		"data": model.NotificationListResponse{
+			Notifications: notifications,
+			UnreadCount:   unreadCount,
+			Pagination:    page.Meta(pageInfo),
+		},
+	})
+}
+
+// GetUnreadCount godoc
+// @Summary Get unread notification count
+// @Tags Notifications
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /notifications/unread-count [get]
+func (s *NotificationService) GetUnreadCount(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	count, err := s.NotificationRepo.CountUnread(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to count unread notifications",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "success",
+		"data": fiber.Map{
+			"unread_count": count,
+		},
+	})
+}
+
+// MarkAsRead godoc
+// @Summary Mark notification as read
+// @Tags Notifications
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Notification not found"
+// @Router /notifications/{id}/read [post]
+func (s *NotificationService) MarkAsRead(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	notificationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid notification id",
+		})
+	}
+
+	// Notifikasi milik user lain diperlakukan sama dengan yang tidak ada
+	if err := s.NotificationRepo.MarkAsRead(userID, notificationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "notification not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to mark notification as read",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "notification marked as read",
+	})
+}
+
+// MarkAllAsRead godoc
+// @Summary Mark all notifications as read
+// @Tags Notifications
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /notifications/read-all [post]
+func (s *NotificationService) MarkAllAsRead(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	updated, err := s.NotificationRepo.MarkAllAsRead(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to mark notifications as read",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "all notifications marked as read",
+		"data": fiber.Map{
+			"updated": updated,
+		},
+	})
+}
+
+// DeleteNotification godoc
+// @Summary Delete notification
+// @Tags Notifications
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Notification not found"
+// @Router /notifications/{id} [delete]
+func (s *NotificationService) DeleteNotification(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	notificationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid notification id",
+		})
+	}
+
+	if err := s.NotificationRepo.DeleteNotification(userID, notificationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "notification not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to delete notification",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "notification deleted successfully",
+	})
+}
+
+// GetPreferences godoc
+// @Summary Get notification preferences
+// @Description Bahasa email dan channel notifikasi (realtime, email) milik user yang login. Inbox selalu aktif.
+// @Tags Notifications
+// @Security BearerAuth
+// @Success 200 {object} model.NotificationPreferences
+// @Router /notifications/preferences [get]
+func (s *NotificationService) GetPreferences(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	prefs, err := s.NotificationRepo.GetPreferences(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "user not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get notification preferences",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "success",
+		"data":    prefs,
+	})
+}
+
+// UpdatePreferences godoc
+// @Summary Update notification preferences
+// @Description Field yang tidak dikirim tidak diubah. Contoh: {"language":"en","channels":{"email":false}}
+// @Tags Notifications
+// @Security BearerAuth
+// @Param request body model.UpdateNotificationPreferencesRequest true "Preferences"
+// @Success 200 {object} model.NotificationPreferences
+// @Failure 400 {object} map[string]interface{} "Validation error"
+// @Router /notifications/preferences [put]
+func (s *NotificationService) UpdatePreferences(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	var req model.UpdateNotificationPreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	var fieldErrs model.FieldErrors
+	if req.Language != nil && *req.Language != model.LanguageID && *req.Language != model.LanguageEN {
+		fieldErrs = append(fieldErrs, model.FieldError{Field: "language", Message: "harus id atau en"})
+	}
+	for channel := range req.Channels {
+		if !slices.Contains(model.NotificationChannels, channel) {
+			fieldErrs = append(fieldErrs, model.FieldError{Field: "channels." + channel, Message: "channel tidak dikenal"})
+		}
+	}
+	if fieldErrs != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid preferences",
+			"details": fieldErrs,
+		})
+	}
+
+	prefs, err := s.NotificationRepo.GetPreferences(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "user not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get notification preferences",
+		})
+	}
+
+	if req.Language != nil {
+		prefs.Language = *req.Language
+	}
+	for channel, enabled := range req.Channels {
+		prefs.Channels[channel] = enabled
+	}
+
+	if err := s.NotificationRepo.SavePreferences(userID, prefs); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to update notification preferences",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "notification preferences updated",
+		"data":    prefs,
+	})
+}
+
+// StreamNotifications godoc
+// @Summary Notification stream (SSE)
+// @Description Server-Sent Events berisi notifikasi baru milik user yang login (event: notification). Token boleh dikirim lewat query access_token karena EventSource tidak bisa mengirim header.
+// @Tags Notifications
+// @Security BearerAuth
+// @Param access_token query string false "JWT access token"
+// @Produce text/event-stream
+// @Success 200 {string} string "event stream"
+// @Router /notifications/stream [get]
+func (s *NotificationService) StreamNotifications(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // nginx: jangan buffer response
+
+	sub := s.Realtime.Subscribe(userID)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		fmt.Fprint(w, "retry: 5000\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case event, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+
+			// Flush gagal berarti client sudah menutup koneksi
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// UpgradeWebSocket menolak request ke endpoint WebSocket yang bukan upgrade
+func (s *NotificationService) UpgradeWebSocket(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{
+			"error": "websocket upgrade required",
+		})
+	}
+	return c.Next()
+}
+
+// NotificationsWebSocket godoc
+// @Summary Notification stream (WebSocket)
+// @Description WebSocket yang mengirim notifikasi baru milik user yang login sebagai JSON {id, type, data}. Token boleh dikirim lewat query access_token.
+// @Tags Notifications
+// @Security BearerAuth
+// @Param access_token query string false "JWT access token"
+// @Success 101 {string} string "Switching Protocols"
+// @Router /notifications/ws [get]
+func (s *NotificationService) NotificationsWebSocket() fiber.Handler {
+	return websocket.New(func(conn *websocket.Conn) {
+		// Locals dari JWTAuthStream ikut disalin ke koneksi WebSocket
+		rawUserID, _ := conn.Locals("user_id").(string)
+		userID, err := uuid.Parse(rawUserID)
+		if err != nil {
+			conn.Close()
+			return
+		}
+
+		sub := s.Realtime.Subscribe(userID)
+		defer sub.Close()
+
+		// Pesan dari client diabaikan; pembacaan hanya untuk mendeteksi koneksi ditutup
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-closed:
+				return
+			case event, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				err = conn.WriteJSON(fiber.Map{
+					"id":   event.ID,
+					"type": event.Type,
+					"data": event.Data,
+				})
+			case <-heartbeat.C:
+				err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second))
+			}
+			if err != nil {
+				return
+			}
+		}
+	})
+}
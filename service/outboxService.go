@@ -0,0 +1,141 @@
+package service
+
+import (
+	"POJECT_UAS/model"
+	"POJECT_UAS/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type OutboxService struct {
+	OutboxRepo   *repository.OutboxRepository
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+func NewOutboxService(outboxRepo *repository.OutboxRepository, pollInterval time.Duration, batchSize int) *OutboxService {
+	return &OutboxService{
+		OutboxRepo:   outboxRepo,
+		PollInterval: pollInterval,
+		BatchSize:    batchSize,
+	}
+}
+
+// RunWorker menjalankan operasi outbox yang tertunda setiap PollInterval sampai ctx dibatalkan
+func (s *OutboxService) RunWorker(ctx context.Context) {
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.processDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processDue mengambil dan menjalankan satu batch operasi yang jatuh tempo
+func (s *OutboxService) processDue(ctx context.Context) {
+	ops, err := s.OutboxRepo.ClaimDue(ctx, s.BatchSize)
+	if err != nil {
+		log.Printf("outbox: gagal mengambil operasi: %v", err)
+		return
+	}
+
+	for _, op := range ops {
+		if err := s.OutboxRepo.Process(ctx, op); err != nil {
+			log.Printf("outbox %s (%s, percobaan %d): %v", op.ID, op.Operation, op.Attempts, err)
+		}
+	}
+}
+
+// GetOutbox godoc
+// @Summary List outbox operations
+// @Description Daftar operasi MongoDB yang tertunda atau gagal beserta jumlah per status. Tanpa filter status, operasi yang sudah done tidak ditampilkan.
+// @Tags Admin
+// @Security BearerAuth
+// @Param status query string false "pending, processing, done, failed, compensated"
+// @Param limit query int false "Jumlah data (default 50, maks. 200)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string "Invalid status"
+// @Router /admin/outbox [get]
+func (s *OutboxService) GetOutbox(c *fiber.Ctx) error {
+	status := c.Query("status")
+	switch status {
+	case "", model.OutboxPending, model.OutboxProcessing, model.OutboxDone, model.OutboxFailed, model.OutboxCompensated:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid status",
+		})
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit must be between 1 and 200",
+		})
+	}
+
+	ops, err := s.OutboxRepo.List(c.Context(), status, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get outbox operations",
+		})
+	}
+
+	counts, err := s.OutboxRepo.CountByStatus(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to count outbox operations",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "outbox operations retrieved successfully",
+		"data":    ops,
+		"summary": counts,
+	})
+}
+
+// RetryOutbox godoc
+// @Summary Retry failed outbox operation
+// @Description Mengembalikan operasi berstatus failed ke antrean dengan jumlah percobaan direset.
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Outbox operation ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Operation not found or not failed"
+// @Router /admin/outbox/{id}/retry [post]
+func (s *OutboxService) RetryOutbox(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid outbox operation id",
+		})
+	}
+
+	if err := s.OutboxRepo.Retry(c.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "outbox operation not found or not failed",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to retry outbox operation",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "outbox operation queued for retry",
+	})
+}
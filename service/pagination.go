@@ -0,0 +1,49 @@
+package service
+
+import (
+	"POJECT_UAS/model"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parsePageRequest membaca parameter pagination dari query:
+//   - page, per_page: mode offset (default)
+//   - cursor: mode keyset; kosong (?cursor=) untuk halaman pertama, selanjutnya pakai next_cursor/prev_cursor
+//   - include_total=false: lewati COUNT(*)
+func parsePageRequest(c *fiber.Ctx) (model.PageRequest, model.FieldErrors) {
+	var errs model.FieldErrors
+
+	page := model.PageRequest{
+		Page:    c.QueryInt("page", 1),
+		PerPage: c.QueryInt("per_page", 10),
+	}
+
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.PerPage < 1 || page.PerPage > 100 {
+		page.PerPage = 10
+	}
+
+	if c.Context().QueryArgs().Has("cursor") {
+		page.UseCursor = true
+		if raw := c.Query("cursor"); raw != "" {
+			cursor, err := model.DecodeCursor(raw)
+			if err != nil {
+				errs = append(errs, model.FieldError{Field: "cursor", Message: "cursor tidak valid"})
+			}
+			page.Cursor = cursor
+		}
+	}
+
+	if raw := c.Query("include_total"); raw != "" {
+		includeTotal, err := strconv.ParseBool(raw)
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: "include_total", Message: "harus true atau false"})
+		}
+		page.SkipCount = !includeTotal
+	}
+
+	return page, errs
+}
@@ -0,0 +1,758 @@
+package service
+
+import (
+	"POJECT_UAS/model"
+	"POJECT_UAS/repository"
+	"POJECT_UAS/seed"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// roleNamePattern nama role: huruf kecil, angka dan _, seperti role bawaan
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// RoleService CRUD role dan permission serta matriks role_permissions. Perubahan permission
+// berlaku saat access token diperbarui (refresh) atau user login ulang.
+type RoleService struct {
+	RoleRepo *repository.RoleRepository
+}
+
+func NewRoleService(roleRepo *repository.RoleRepository) *RoleService {
+	return &RoleService{RoleRepo: roleRepo}
+}
+
+// GetRoles godoc
+// @Summary List roles
+// @Description Semua role beserta jumlah user yang memakainya.
+// @Tags Admin
+// @Security BearerAuth
+// @Success 200 {array} model.RoleDetail
+// @Router /admin/roles [get]
+func (s *RoleService) GetRoles(c *fiber.Ctx) error {
+	roles, err := s.RoleRepo.ListRoles(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get roles",
+		})
+	}
+
+	for i := range roles {
+		roles[i].System = seed.IsSystemRole(roles[i].Name)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "success",
+		"data":    roles,
+	})
+}
+
+// GetRole godoc
+// @Summary Get role
+// @Description Role beserta permission dan jumlah user yang memakainya.
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Success 200 {object} model.RoleDetail
+// @Failure 404 {object} map[string]string "Role not found"
+// @Router /admin/roles/{id} [get]
+func (s *RoleService) GetRole(c *fiber.Ctx) error {
+	role, ok, err := s.findRole(c)
+	if !ok {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "success",
+		"data":    role,
+	})
+}
+
+// CreateRole godoc
+// @Summary Create role
+// @Description Nama role huruf kecil, angka dan _. Permissions berisi nama "resource:action" yang sudah ada.
+// @Tags Admin
+// @Security BearerAuth
+// @Param request body model.CreateRoleRequest true "Role"
+// @Success 201 {object} model.RoleDetail
+// @Failure 400 {object} map[string]interface{} "Validation error"
+// @Failure 409 {object} map[string]string "Role already exists"
+// @Router /admin/roles [post]
+func (s *RoleService) CreateRole(c *fiber.Ctx) error {
+	var req model.CreateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	fieldErrs := validateRoleName(req.Name)
+	permissions, permErrs, err := s.resolvePermissions(c, req.Permissions)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get permissions",
+		})
+	}
+	fieldErrs = append(fieldErrs, permErrs...)
+	if fieldErrs != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid role",
+			"details": fieldErrs,
+		})
+	}
+
+	role := model.RoleDetail{
+		Roles: model.Roles{
+			ID:          uuid.New(),
+			Name:        req.Name,
+			Description: strings.TrimSpace(req.Description),
+			CreatedAt:   time.Now(),
+		},
+		Permissions: permissions,
+	}
+
+	if err := s.RoleRepo.CreateRole(c.Context(), role.Roles, permissionIDs(permissions)); err != nil {
+		if errors.Is(err, repository.ErrRoleExists) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to create role",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "role created successfully",
+		"data":    role,
+	})
+}
+
+// UpdateRole godoc
+// @Summary Update role
+// @Description Field yang tidak dikirim tidak diubah. Role bawaan tidak bisa diganti nama karena dipakai pengecekan role di route.
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param request body model.UpdateRoleRequest true "Role"
+// @Success 200 {object} model.RoleDetail
+// @Failure 400 {object} map[string]interface{} "Validation error"
+// @Failure 403 {object} map[string]string "System role"
+// @Failure 404 {object} map[string]string "Role not found"
+// @Failure 409 {object} map[string]string "Role already exists"
+// @Router /admin/roles/{id} [put]
+func (s *RoleService) UpdateRole(c *fiber.Ctx) error {
+	var req model.UpdateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	role, ok, err := s.findRole(c)
+	if !ok {
+		return err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name != role.Name && role.System {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "system roles cannot be renamed",
+			})
+		}
+		if fieldErrs := validateRoleName(name); fieldErrs != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "invalid role",
+				"details": fieldErrs,
+			})
+		}
+		role.Name = name
+	}
+	if req.Description != nil {
+		role.Description = strings.TrimSpace(*req.Description)
+	}
+
+	if err := s.RoleRepo.UpdateRole(c.Context(), role.Roles); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRoleExists):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "role not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to update role",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "role updated successfully",
+		"data":    role,
+	})
+}
+
+// DeleteRole godoc
+// @Summary Delete role
+// @Description Role bawaan dan role yang masih dipakai user tidak bisa dihapus.
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string "System role"
+// @Failure 404 {object} map[string]string "Role not found"
+// @Failure 409 {object} map[string]string "Role in use"
+// @Router /admin/roles/{id} [delete]
+func (s *RoleService) DeleteRole(c *fiber.Ctx) error {
+	role, ok, err := s.findRole(c)
+	if !ok {
+		return err
+	}
+
+	if role.System {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "system roles cannot be deleted",
+		})
+	}
+	if role.UserCount > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": repository.ErrRoleInUse.Error(),
+		})
+	}
+
+	if err := s.RoleRepo.DeleteRole(c.Context(), role.ID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRoleInUse):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "role not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to delete role",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "role deleted successfully",
+	})
+}
+
+// SetRolePermissions godoc
+// @Summary Replace role permissions
+// @Description Mengganti seluruh permission role (satu baris matriks) dengan daftar nama "resource:action". Role bawaan tidak boleh dikosongkan.
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param request body model.RolePermissionsRequest true "Permissions"
+// @Success 200 {object} model.RoleDetail
+// @Failure 400 {object} map[string]interface{} "Validation error"
+// @Failure 404 {object} map[string]string "Role not found"
+// @Failure 409 {object} map[string]string "System role without permissions"
+// @Router /admin/roles/{id}/permissions [put]
+func (s *RoleService) SetRolePermissions(c *fiber.Ctx) error {
+	var req model.RolePermissionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	role, ok, err := s.findRole(c)
+	if !ok {
+		return err
+	}
+
+	permissions, fieldErrs, err := s.resolvePermissions(c, req.Permissions)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get permissions",
+		})
+	}
+	if fieldErrs != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid permissions",
+			"details": fieldErrs,
+		})
+	}
+
+	// Role bawaan tanpa permission mengunci user-nya dari seluruh endpoint
+	if role.System && len(permissions) == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": repository.ErrRoleNeedsPermission.Error(),
+		})
+	}
+
+	if err := s.RoleRepo.SetRolePermissions(c.Context(), role.ID, permissionIDs(permissions)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "role not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to update role permissions",
+		})
+	}
+
+	role.Permissions = permissions
+	return c.JSON(fiber.Map{
+		"message": "role permissions updated successfully",
+		"data":    role,
+	})
+}
+
+// AddRolePermission godoc
+// @Summary Attach permission to role
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param permissionId path string true "Permission ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Role or permission not found"
+// @Router /admin/roles/{id}/permissions/{permissionId} [post]
+func (s *RoleService) AddRolePermission(c *fiber.Ctx) error {
+	roleID, permissionID, ok, err := rolePermissionParams(c)
+	if !ok {
+		return err
+	}
+
+	if err := s.RoleRepo.AddRolePermission(c.Context(), roleID, permissionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "role or permission not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to attach permission",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "permission attached successfully",
+	})
+}
+
+// RemoveRolePermission godoc
+// @Summary Detach permission from role
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param permissionId path string true "Permission ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Role does not have the permission"
+// @Failure 409 {object} map[string]string "Last permission of a system role"
+// @Router /admin/roles/{id}/permissions/{permissionId} [delete]
+func (s *RoleService) RemoveRolePermission(c *fiber.Ctx) error {
+	_, permissionID, ok, err := rolePermissionParams(c)
+	if !ok {
+		return err
+	}
+
+	role, ok, err := s.findRole(c)
+	if !ok {
+		return err
+	}
+
+	if err := s.RoleRepo.RemoveRolePermission(c.Context(), role.ID, permissionID, role.System); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRoleNeedsPermission):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "role does not have this permission",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to detach permission",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "permission detached successfully",
+	})
+}
+
+// GetPermissions godoc
+// @Summary List permissions
+// @Tags Admin
+// @Security BearerAuth
+// @Success 200 {array} model.Permissions
+// @Router /admin/permissions [get]
+func (s *RoleService) GetPermissions(c *fiber.Ctx) error {
+	permissions, err := s.RoleRepo.ListPermissions(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get permissions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "permissions retrieved successfully",
+		"data":    permissions,
+	})
+}
+
+// GetAvailablePermissions godoc
+// @Summary List available permissions
+// @Description Semua pasangan resource:action yang dicek oleh route, dengan ID jika permission-nya sudah dibuat.
+// @Tags Admin
+// @Security BearerAuth
+// @Success 200 {array} model.AvailablePermission
+// @Router /admin/permissions/available [get]
+func (s *RoleService) GetAvailablePermissions(c *fiber.Ctx) error {
+	existing, err := s.RoleRepo.ListPermissions(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get permissions",
+		})
+	}
+
+	ids := make(map[string]uuid.UUID, len(existing))
+	for _, p := range existing {
+		ids[p.Resource+":"+p.Action] = p.ID
+	}
+
+	available := make([]model.AvailablePermission, 0, len(seed.Permissions))
+	for _, p := range seed.Permissions {
+		item := model.AvailablePermission{
+			Name:        p.Name(),
+			Resource:    p.Resource,
+			Action:      p.Action,
+			Description: p.Description,
+		}
+		if id, ok := ids[p.Name()]; ok {
+			item.ID = &id
+		}
+		available = append(available, item)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "success",
+		"data":    available,
+	})
+}
+
+// GetPermissionMatrix godoc
+// @Summary Get permission matrix
+// @Description Semua permission dan, untuk setiap role, nama permission yang dimilikinya.
+// @Tags Admin
+// @Security BearerAuth
+// @Success 200 {object} model.PermissionMatrix
+// @Router /admin/permissions/matrix [get]
+func (s *RoleService) GetPermissionMatrix(c *fiber.Ctx) error {
+	ctx := c.Context()
+	roles, err := s.RoleRepo.ListRoles(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get roles",
+		})
+	}
+	permissions, err := s.RoleRepo.ListPermissions(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get permissions",
+		})
+	}
+	grants, err := s.RoleRepo.GetRoleGrants(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get role permissions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "success",
+		"data":    buildPermissionMatrix(roles, permissions, grants),
+	})
+}
+
+// CreatePermission godoc
+// @Summary Create permission
+// @Description Resource dan action harus pasangan yang dicek oleh route (lihat /admin/permissions/available). Nama dibuat otomatis "resource:action".
+// @Tags Admin
+// @Security BearerAuth
+// @Param request body model.CreatePermissionRequest true "Permission"
+// @Success 201 {object} model.Permissions
+// @Failure 400 {object} map[string]interface{} "Validation error"
+// @Failure 409 {object} map[string]string "Permission already exists"
+// @Router /admin/permissions [post]
+func (s *RoleService) CreatePermission(c *fiber.Ctx) error {
+	var req model.CreatePermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	resource := strings.TrimSpace(req.Resource)
+	action := strings.TrimSpace(req.Action)
+	known, ok := seed.LookupPermission(resource, action)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid permission",
+			"details": model.FieldErrors{
+				{Field: "action", Message: "pasangan resource:action tidak dipakai oleh route: " + resource + ":" + action},
+			},
+		})
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if description == "" {
+		description = known.Description
+	}
+	permission := model.Permissions{
+		ID:          uuid.New(),
+		Name:        known.Name(),
+		Resource:    known.Resource,
+		Action:      known.Action,
+		Description: description,
+	}
+
+	if err := s.RoleRepo.CreatePermission(c.Context(), permission); err != nil {
+		if errors.Is(err, repository.ErrPermissionExists) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to create permission",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "permission created successfully",
+		"data":    permission,
+	})
+}
+
+// UpdatePermission godoc
+// @Summary Update permission
+// @Description Hanya deskripsi yang bisa diubah.
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Permission ID"
+// @Param request body model.UpdatePermissionRequest true "Permission"
+// @Success 200 {object} model.Permissions
+// @Failure 404 {object} map[string]string "Permission not found"
+// @Router /admin/permissions/{id} [put]
+func (s *RoleService) UpdatePermission(c *fiber.Ctx) error {
+	var req model.UpdatePermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid permission id",
+		})
+	}
+
+	permission, err := s.RoleRepo.GetPermission(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "permission not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get permission",
+		})
+	}
+
+	if req.Description != nil {
+		permission.Description = strings.TrimSpace(*req.Description)
+	}
+
+	if err := s.RoleRepo.UpdatePermission(c.Context(), *permission); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "permission not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to update permission",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "permission updated successfully",
+		"data":    permission,
+	})
+}
+
+// DeletePermission godoc
+// @Summary Delete permission
+// @Description Permission dihapus dan dicabut dari semua role.
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Permission ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Permission not found"
+// @Router /admin/permissions/{id} [delete]
+func (s *RoleService) DeletePermission(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid permission id",
+		})
+	}
+
+	if err := s.RoleRepo.DeletePermission(c.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "permission not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to delete permission",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "permission deleted successfully",
+	})
+}
+
+func (s *RoleService) findRole(c *fiber.Ctx) (*model.RoleDetail, bool, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid role id",
+		})
+	}
+
+	role, err := s.RoleRepo.GetRole(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "role not found",
+			})
+		}
+		return nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get role",
+		})
+	}
+
+	role.System = seed.IsSystemRole(role.Name)
+	return role, true, nil
+}
+
+// resolvePermissions mencocokkan nama "resource:action" dengan permission di database.
+// Nama duplikat diabaikan; nama yang tidak ada dikembalikan sebagai field error.
+func (s *RoleService) resolvePermissions(c *fiber.Ctx, names []string) ([]model.Permissions, model.FieldErrors, error) {
+	resolved := []model.Permissions{}
+	if len(names) == 0 {
+		return resolved, nil, nil
+	}
+
+	existing, err := s.RoleRepo.ListPermissions(c.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+	byName := make(map[string]model.Permissions, len(existing))
+	for _, p := range existing {
+		byName[p.Name] = p
+	}
+
+	var errs model.FieldErrors
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		p, ok := byName[name]
+		if !ok {
+			errs = append(errs, model.FieldError{Field: "permissions", Message: "permission tidak dikenal: " + name})
+			continue
+		}
+		resolved = append(resolved, p)
+	}
+
+	return resolved, errs, nil
+}
+
+// validateRoleName memeriksa format nama role
+func validateRoleName(name string) model.FieldErrors {
+	if name == "" {
+		return model.FieldErrors{{Field: "name", Message: "wajib diisi"}}
+	}
+	if !roleNamePattern.MatchString(name) {
+		return model.FieldErrors{{Field: "name", Message: "harus 2-50 karakter huruf kecil, angka atau _, diawali huruf"}}
+	}
+	return nil
+}
+
+// buildPermissionMatrix menyusun matriks role × permission; nama permission setiap role
+// mengikuti urutan daftar permission
+func buildPermissionMatrix(roles []model.RoleDetail, permissions []model.Permissions, grants map[uuid.UUID][]uuid.UUID) model.PermissionMatrix {
+	matrix := model.PermissionMatrix{
+		Permissions: permissions,
+		Roles:       make([]model.PermissionMatrixRole, 0, len(roles)),
+	}
+
+	for _, role := range roles {
+		granted := make(map[uuid.UUID]bool, len(grants[role.ID]))
+		for _, id := range grants[role.ID] {
+			granted[id] = true
+		}
+
+		row := model.PermissionMatrixRole{
+			ID:          role.ID,
+			Name:        role.Name,
+			System:      seed.IsSystemRole(role.Name),
+			Permissions: []string{},
+		}
+		for _, p := range permissions {
+			if granted[p.ID] {
+				row.Permissions = append(row.Permissions, p.Name)
+			}
+		}
+		matrix.Roles = append(matrix.Roles, row)
+	}
+
+	return matrix
+}
+
+func permissionIDs(permissions []model.Permissions) []uuid.UUID {
+	ids := make([]uuid.UUID, len(permissions))
+	for i, p := range permissions {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func rolePermissionParams(c *fiber.Ctx) (uuid.UUID, uuid.UUID, bool, error) {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid role id",
+		})
+	}
+	permissionID, err := uuid.Parse(c.Params("permissionId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid permission id",
+		})
+	}
+	return roleID, permissionID, true, nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"POJECT_UAS/model"
+	"POJECT_UAS/repository"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleService_DeleteRole_ProtectsSystemAndInUseRoles(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	roleService := NewRoleService(repository.NewRoleRepository(db))
+	app := fiber.New()
+	app.Delete("/roles/:id", roleService.DeleteRole)
+
+	expectRole := func(id uuid.UUID, name string, users int) {
+		mock.ExpectQuery(`FROM roles r\s+WHERE r.id = \$1`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "count"}).
+				AddRow(id, name, "", time.Now(), users))
+		mock.ExpectQuery(`FROM permissions p\s+INNER JOIN role_permissions`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "resource", "action", "description"}))
+	}
+	systemID, inUseID, customID := uuid.New(), uuid.New(), uuid.New()
+	expectRole(systemID, "student", 0)
+	expectRole(inUseID, "kaprodi", 2)
+	expectRole(customID, "kaprodi", 0)
+	mock.ExpectExec(`DELETE FROM roles WHERE id = \$1`).
+		WithArgs(customID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute & Assert: urutan sama dengan ekspektasi query
+	for _, tc := range []struct {
+		id     uuid.UUID
+		status int
+	}{
+		{systemID, fiber.StatusForbidden},
+		{inUseID, fiber.StatusConflict},
+		{customID, fiber.StatusOK},
+	} {
+		resp, err := app.Test(httptest.NewRequest("DELETE", "/roles/"+tc.id.String(), nil))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.status, resp.StatusCode)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleService_GetRoles_ListsUserCounts(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	roleService := NewRoleService(repository.NewRoleRepository(db))
+	app := fiber.New()
+	app.Get("/roles", roleService.GetRoles)
+
+	mock.ExpectQuery(`FROM roles r\s+ORDER BY r.name`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "count"}).
+			AddRow(uuid.New(), "kaprodi", "", time.Now(), 0).
+			AddRow(uuid.New(), "student", "", time.Now(), 12))
+
+	// Execute
+	resp, err := app.Test(httptest.NewRequest("GET", "/roles", nil))
+	assert.NoError(t, err)
+
+	var body struct {
+		Data []model.RoleDetail `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	// Assert
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Len(t, body.Data, 2)
+	assert.False(t, body.Data[0].System)
+	assert.True(t, body.Data[1].System)
+	assert.Equal(t, int64(12), body.Data[1].UserCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleService_SystemRoleKeepsPermissions(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	roleService := NewRoleService(repository.NewRoleRepository(db))
+	app := fiber.New()
+	app.Put("/roles/:id/permissions", roleService.SetRolePermissions)
+	app.Delete("/roles/:id/permissions/:permissionId", roleService.RemoveRolePermission)
+
+	roleID, permissionID := uuid.New(), uuid.New()
+	expectSuperAdmin := func() {
+		mock.ExpectQuery(`FROM roles r\s+WHERE r.id = \$1`).
+			WithArgs(roleID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "count"}).
+				AddRow(roleID, "super_admin", "", time.Now(), 1))
+		mock.ExpectQuery(`FROM permissions p\s+INNER JOIN role_permissions`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "resource", "action", "description"}).
+				AddRow(permissionID, "users:manage", "users", "manage", ""))
+	}
+
+	// Mengosongkan seluruh permission ditolak sebelum menyentuh role_permissions
+	expectSuperAdmin()
+	req := httptest.NewRequest("PUT", "/roles/"+roleID.String()+"/permissions", strings.NewReader(`{"permissions":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	setResp, err := app.Test(req)
+	assert.NoError(t, err)
+
+	// Mencabut permission terakhir dibatalkan di dalam transaksi
+	expectSuperAdmin()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM roles WHERE id = \$1 FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(roleID))
+	mock.ExpectExec(`DELETE FROM role_permissions`).
+		WithArgs(roleID, permissionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM role_permissions`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+	removeResp, err := app.Test(httptest.NewRequest("DELETE", "/roles/"+roleID.String()+"/permissions/"+permissionID.String(), nil))
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, fiber.StatusConflict, setResp.StatusCode)
+	assert.Equal(t, fiber.StatusConflict, removeResp.StatusCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleService_CreatePermission_RejectsUncheckedPair(t *testing.T) {
+	// Setup: tidak ada query karena validasi gagal lebih dulu
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	roleService := NewRoleService(repository.NewRoleRepository(db))
+	app := fiber.New()
+	app.Post("/permissions", roleService.CreatePermission)
+
+	req := httptest.NewRequest("POST", "/permissions", strings.NewReader(`{"resource":"achievements","action":"approve_all"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildPermissionMatrix(t *testing.T) {
+	read := model.Permissions{ID: uuid.New(), Name: "achievements:read"}
+	create := model.Permissions{ID: uuid.New(), Name: "achievements:create"}
+	student := model.RoleDetail{Roles: model.Roles{ID: uuid.New(), Name: "student"}}
+	guest := model.RoleDetail{Roles: model.Roles{ID: uuid.New(), Name: "tamu"}}
+
+	// Execute
+	matrix := buildPermissionMatrix(
+		[]model.RoleDetail{student, guest},
+		[]model.Permissions{create, read},
+		map[uuid.UUID][]uuid.UUID{student.ID: {read.ID, create.ID}},
+	)
+
+	// Assert: nama permission mengikuti urutan daftar permission, role tanpa permission tetap muncul
+	assert.Len(t, matrix.Roles, 2)
+	assert.Equal(t, []string{"achievements:create", "achievements:read"}, matrix.Roles[0].Permissions)
+	assert.True(t, matrix.Roles[0].System)
+	assert.Equal(t, []string{}, matrix.Roles[1].Permissions)
+	assert.False(t, matrix.Roles[1].System)
+}
+
+func TestValidateRoleName(t *testing.T) {
+	assert.Nil(t, validateRoleName("kaprodi_ti"))
+	assert.NotNil(t, validateRoleName(""))
+	assert.NotNil(t, validateRoleName("Kaprodi"))
+	assert.NotNil(t, validateRoleName("1role"))
+	assert.NotNil(t, validateRoleName("ketua prodi"))
+}
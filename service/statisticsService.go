@@ -1,29 +1,88 @@
 package service
 
 import (
+	"POJECT_UAS/cache"
+	"POJECT_UAS/export"
+	"POJECT_UAS/middleware"
 	"POJECT_UAS/model"
+	"POJECT_UAS/report"
 	"POJECT_UAS/repository"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"mime"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
+// StatisticsRepository method repository.AchievementRepository yang dipakai
+// StatisticsService, berupa interface agar handler bisa diuji dengan mock
+type StatisticsRepository interface {
+	achievementScopeRepository
+	GetAchievementStatistics(studentIDs []uuid.UUID, filter model.StatisticsRequest) (*model.AchievementStatistics, error)
+	GetStudentReportProfile(ctx context.Context, studentID uuid.UUID) (*model.StudentReportProfile, error)
+	StreamAchievementExport(ctx context.Context, filter model.AchievementListFilter, batchSize int, fn func([]model.AchievementExportRow) error) (int64, error)
+}
+
 type StatisticsService struct {
-	AchievementRepo *repository.AchievementRepository
+	AchievementRepo StatisticsRepository
+	Calendar        model.AcademicCalendar // tanggal mulai semester untuk period_type=semester
+	Cache           *cache.Cache           // nil berarti statistik selalu dihitung ulang
+
+	// Laporan PDF mahasiswa yang ditandatangani. Tanpa Reports dan Signer, format=pdf
+	// dan endpoint verifikasi mengembalikan 503.
+	Reports       *repository.StudentReportRepository
+	Signer        *report.Signer
+	PublicBaseURL string // URL publik API untuk QR code verifikasi
 }
 
-func NewStatisticsService(achievementRepo *repository.AchievementRepository) *StatisticsService {
+func NewStatisticsService(achievementRepo StatisticsRepository, calendar model.AcademicCalendar, statsCache *cache.Cache) *StatisticsService {
 	return &StatisticsService{
 		AchievementRepo: achievementRepo,
+		Calendar:        calendar,
+		Cache:           statsCache,
+	}
+}
+
+// Scope cache statistik. Statistik mahasiswa hanya bergantung pada prestasinya sendiri,
+// statistik dosen wali dan admin bergantung pada semua prestasi.
+const statisticsScopeAll = "all"
+
+func statisticsScopeStudent(studentID uuid.UUID) string {
+	return "student:" + studentID.String()
+}
+
+// InvalidateStudent menghapus cache statistik yang memuat prestasi mahasiswa studentID,
+// dipanggil setiap status prestasinya berubah
+func (s *StatisticsService) InvalidateStudent(ctx context.Context, studentID uuid.UUID) error {
+	if s.Cache == nil {
+		return nil
 	}
+	return s.Cache.Invalidate(ctx, statisticsScopeStudent(studentID), statisticsScopeAll)
+}
+
+// InvalidateAll menghapus semua cache statistik
+func (s *StatisticsService) InvalidateAll(ctx context.Context) error {
+	if s.Cache == nil {
+		return nil
+	}
+	return s.Cache.Clear(ctx)
 }
 
 // GetMyStatistics - Mahasiswa melihat statistik prestasi sendiri (FR-011)
 func (s *StatisticsService) GetMyStatistics(c *fiber.Ctx) error {
-	userID := c.Locals("user_id").(uuid.UUID)
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not authenticated"})
+	}
 
 	// Get student data
 	student, err := s.AchievementRepo.GetStudentByUserID(userID)
@@ -34,38 +93,38 @@ func (s *StatisticsService) GetMyStatistics(c *fiber.Ctx) error {
 	}
 
 	// Parse query parameters
-	startDate := parseDate(c.Query("start_date"))
-	endDate := parseDate(c.Query("end_date"))
-	achievementType := parseString(c.Query("achievement_type"))
-	status := parseString(c.Query("status"))
+	filter, fieldErrs := s.parseStatisticsRequest(c, 10)
+	if fieldErrs != nil {
+		return statisticsFilterError(c, fieldErrs)
+	}
 
 	// Get statistics for this student only
-	studentIDs := []uuid.UUID{student.ID}
-	statistics, err := s.AchievementRepo.GetAchievementStatistics(
-		studentIDs,
-		startDate,
-		endDate,
-		achievementType,
-		status,
-	)
+	scope := statisticsScopeStudent(student.ID)
+	statistics, meta, err := cache.Fetch(c.UserContext(), s.Cache, statisticsCacheKey(scope, filter), []string{scope},
+		func() (*model.AchievementStatistics, error) {
+			return s.computeStatistics([]uuid.UUID{student.ID}, filter)
+		})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed to get statistics",
 		})
 	}
 
-	// Sort and limit results
-	s.sortAndLimitStatistics(statistics, 10)
-
-	return c.JSON(fiber.Map{
-		"message": "success",
-		"data":    statistics,
-	})
+	return statisticsResponse(c, statistics, meta)
 }
 
 // GetAdviseeStatistics - Dosen wali melihat statistik mahasiswa bimbingan (FR-011)
 func (s *StatisticsService) GetAdviseeStatistics(c *fiber.Ctx) error {
-	userID := c.Locals("user_id").(uuid.UUID)
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not authenticated"})
+	}
+
+	// Parse query parameters
+	filter, fieldErrs := s.parseStatisticsRequest(c, 10)
+	if fieldErrs != nil {
+		return statisticsFilterError(c, fieldErrs)
+	}
 
 	// Get lecturer data
 	lecturer, err := s.AchievementRepo.GetLecturerByUserID(userID)
@@ -75,92 +134,184 @@ func (s *StatisticsService) GetAdviseeStatistics(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get student IDs yang dibimbing
-	studentIDs, err := s.AchievementRepo.GetStudentIDsByAdvisor(lecturer.ID)
+	// Get statistics for advisee students
+	key := statisticsCacheKey("advisor:"+lecturer.ID.String(), filter)
+	statistics, meta, err := cache.Fetch(c.UserContext(), s.Cache, key, []string{statisticsScopeAll},
+		func() (*model.AchievementStatistics, error) {
+			// Get student IDs yang dibimbing
+			studentIDs, err := s.AchievementRepo.GetStudentIDsByAdvisor(lecturer.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(studentIDs) == 0 {
+				return &model.AchievementStatistics{
+					TotalByType:       []model.TypeStatistic{},
+					TotalByPeriod:     model.BucketPeriods(filter.PeriodType, filter.Calendar, nil, filter.StartDate, filter.EndDate),
+					TopStudents:       []model.TopStudent{},
+					CompetitionLevels: []model.LevelStatistic{},
+				}, nil
+			}
+			return s.computeStatistics(studentIDs, filter)
+		})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get advisee students",
+			"error": "failed to get statistics",
 		})
 	}
 
-	if len(studentIDs) == 0 {
-		return c.JSON(fiber.Map{
-			"message": "success",
-			"data": &model.AchievementStatistics{
-				TotalByType:       []model.TypeStatistic{},
-				TotalByPeriod:     []model.PeriodStatistic{},
-				TopStudents:       []model.TopStudent{},
-				CompetitionLevels: []model.LevelStatistic{},
-				Summary: model.StatisticSummary{
-					TotalAchievements:    0,
-					VerifiedAchievements: 0,
-					PendingAchievements:  0,
-					RejectedAchievements: 0,
-					TotalStudents:        0,
-				},
-			},
-		})
-	}
+	return statisticsResponse(c, statistics, meta)
+}
 
+// GetAllStatistics - Admin melihat statistik semua prestasi (FR-011)
+func (s *StatisticsService) GetAllStatistics(c *fiber.Ctx) error {
 	// Parse query parameters
-	startDate := parseDate(c.Query("start_date"))
-	endDate := parseDate(c.Query("end_date"))
-	achievementType := parseString(c.Query("achievement_type"))
-	status := parseString(c.Query("status"))
-	topLimit, _ := strconv.Atoi(c.Query("top_limit", "10"))
+	filter, fieldErrs := s.parseStatisticsRequest(c, 20)
+	if fieldErrs != nil {
+		return statisticsFilterError(c, fieldErrs)
+	}
 
-	// Get statistics for advisee students
-	statistics, err := s.AchievementRepo.GetAchievementStatistics(
-		studentIDs,
-		startDate,
-		endDate,
-		achievementType,
-		status,
-	)
+	// Get statistics for all students (empty studentIDs)
+	key := statisticsCacheKey(statisticsScopeAll, filter)
+	statistics, meta, err := cache.Fetch(c.UserContext(), s.Cache, key, []string{statisticsScopeAll},
+		func() (*model.AchievementStatistics, error) {
+			return s.computeStatistics(nil, filter)
+		})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed to get statistics",
 		})
 	}
 
+	return statisticsResponse(c, statistics, meta)
+}
+
+// computeStatistics menghitung statistik lalu mengurutkan dan membatasi hasilnya
+func (s *StatisticsService) computeStatistics(studentIDs []uuid.UUID, filter model.StatisticsRequest) (*model.AchievementStatistics, error) {
+	statistics, err := s.AchievementRepo.GetAchievementStatistics(studentIDs, filter)
+	if err != nil {
+		return nil, err
+	}
+
 	// Sort and limit results
-	s.sortAndLimitStatistics(statistics, topLimit)
+	s.sortAndLimitStatistics(statistics, filter.TopLimit)
+	return statistics, nil
+}
+
+// statisticsCacheKey key cache untuk scope dan semua filter yang memengaruhi hasil
+func statisticsCacheKey(scope string, filter model.StatisticsRequest) string {
+	formatDate := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	deref := func(v *string) string {
+		if v == nil {
+			return ""
+		}
+		return *v
+	}
+
+	params := strings.Join([]string{
+		formatDate(filter.StartDate),
+		formatDate(filter.EndDate),
+		deref(filter.AchievementType),
+		deref(filter.Status),
+		filter.PeriodType,
+		strconv.Itoa(filter.TopLimit),
+		filter.Calendar.GanjilStart.String(),
+		filter.Calendar.GenapStart.String(),
+	}, "\x00")
+	sum := sha256.Sum256([]byte(params))
+	return "statistics:" + scope + ":" + hex.EncodeToString(sum[:16])
+}
+
+// statisticsResponse response statistik beserta keterangan kesegaran cache. Header Age
+// dan X-Cache ikut di-set untuk proxy dan dashboard. Dengan query format=csv|xlsx,
+// statistik dikirim sebagai file.
+func statisticsResponse(c *fiber.Ctx, statistics *model.AchievementStatistics, meta cache.Meta) error {
+	if meta.Cached {
+		c.Set("X-Cache", "HIT")
+		c.Set(fiber.HeaderAge, strconv.Itoa(meta.AgeSeconds))
+	} else {
+		c.Set("X-Cache", "MISS")
+	}
+
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		return sendStatisticsFile(c, statistics, format)
+	}
 
 	return c.JSON(fiber.Map{
-		"message": "success",
-		"data":    statistics,
+		"message":   "success",
+		"data":      statistics,
+		"freshness": meta,
 	})
 }
 
-// GetAllStatistics - Admin melihat statistik semua prestasi (FR-011)
-func (s *StatisticsService) GetAllStatistics(c *fiber.Ctx) error {
-	// Parse query parameters
-	startDate := parseDate(c.Query("start_date"))
-	endDate := parseDate(c.Query("end_date"))
-	achievementType := parseString(c.Query("achievement_type"))
-	status := parseString(c.Query("status"))
-	topLimit, _ := strconv.Atoi(c.Query("top_limit", "20"))
-
-	// Get statistics for all students (empty studentIDs)
-	statistics, err := s.AchievementRepo.GetAchievementStatistics(
-		nil, // all students
-		startDate,
-		endDate,
-		achievementType,
-		status,
-	)
+// sendStatisticsFile mengirim statistik sebagai file CSV atau XLSX. Statistik berukuran
+// kecil sehingga cukup ditulis ke memori.
+func sendStatisticsFile(c *fiber.Ctx, statistics *model.AchievementStatistics, format string) error {
+	var buf bytes.Buffer
+	writer, err := export.NewWriter(format, &buf, "Statistik")
+	if err == nil {
+		err = export.WriteStatistics(writer, statistics)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get statistics",
+			"error": "failed to export statistics",
 		})
 	}
 
-	// Sort and limit results
-	s.sortAndLimitStatistics(statistics, topLimit)
+	c.Set(fiber.HeaderContentType, export.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{
+		"filename": export.FileName("statistik", format, time.Now()),
+	}))
+	return c.Send(buf.Bytes())
+}
 
-	return c.JSON(fiber.Map{
-		"message": "success",
-		"data":    statistics,
+// parseStatisticsRequest membaca query parameter statistik. Tanggal yang tidak valid
+// diabaikan seperti sebelumnya, period_type yang tidak dikenal ditolak.
+func (s *StatisticsService) parseStatisticsRequest(c *fiber.Ctx, defaultTopLimit int) (model.StatisticsRequest, model.FieldErrors) {
+	var errs model.FieldErrors
+
+	filter := model.StatisticsRequest{
+		StartDate:       parseDate(c.Query("start_date")),
+		EndDate:         parseDate(c.Query("end_date")),
+		AchievementType: parseString(c.Query("achievement_type")),
+		Status:          parseString(c.Query("status")),
+		PeriodType:      c.Query("period_type", model.PeriodMonthly),
+		Calendar:        s.Calendar,
+	}
+	filter.TopLimit, _ = strconv.Atoi(c.Query("top_limit", strconv.Itoa(defaultTopLimit)))
+
+	validPeriod := false
+	for _, periodType := range model.PeriodTypes {
+		if filter.PeriodType == periodType {
+			validPeriod = true
+		}
+	}
+	if !validPeriod {
+		errs = append(errs, model.FieldError{
+			Field:   "period_type",
+			Message: "harus salah satu dari: " + strings.Join(model.PeriodTypes, ", "),
+		})
+	}
+
+	if format := strings.ToLower(c.Query("format")); format != "" && !export.IsValidFormat(format) {
+		errs = append(errs, model.FieldError{
+			Field:   "format",
+			Message: "harus salah satu dari: " + strings.Join(export.Formats, ", "),
+		})
+	}
+
+	return filter, errs
+}
+
+// statisticsFilterError response 400 untuk query parameter statistik yang tidak valid
+func statisticsFilterError(c *fiber.Ctx, fieldErrs model.FieldErrors) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error":   "invalid filter",
+		"details": fieldErrs,
 	})
 }
 
@@ -201,27 +352,273 @@ func (s *StatisticsService) sortAndLimitStatistics(statistics *model.Achievement
 		return statistics.TotalByType[i].Count > statistics.TotalByType[j].Count
 	})
 
-	// Sort period statistics by period (ascending)
-	sort.Slice(statistics.TotalByPeriod, func(i, j int) bool {
-		return statistics.TotalByPeriod[i].Period < statistics.TotalByPeriod[j].Period
-	})
+	// Sort period statistics chronologically
+	model.SortPeriodStatistics(statistics.TotalByPeriod)
 
 	// Sort level statistics by count (descending)
 	sort.Slice(statistics.CompetitionLevels, func(i, j int) bool {
 		return statistics.CompetitionLevels[i].Count > statistics.CompetitionLevels[j].Count
 	})
 }
+// studentReportBatchSize prestasi per pengambilan dokumen MongoDB saat menyusun laporan
+const studentReportBatchSize = 200
+
 // GetStudentReport - Get detailed student report
 // @Summary Get student report
-// @Description Mendapatkan laporan detail prestasi mahasiswa
+// @Description Laporan prestasi terverifikasi seorang mahasiswa: identitas, ringkasan per tipe dan tingkat, dan daftar prestasi. Dengan format=pdf, laporan diterbitkan sebagai PDF yang ditandatangani server dan memuat QR code ke endpoint verifikasi publik. Mahasiswa hanya dapat melihat laporannya sendiri, dosen wali laporan mahasiswa bimbingannya.
 // @Tags Reports
 // @Security BearerAuth
+// @Produce json
+// @Produce application/pdf
 // @Param id path string true "Student ID"
+// @Param format query string false "json (default) atau pdf"
 // @Success 200 {object} map[string]interface{} "Student report"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Student not found"
 // @Router /api/v1/reports/student/{id} [get]
 func (s *StatisticsService) GetStudentReport(c *fiber.Ctx) error {
-	// TODO: Implement student report
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "user not authenticated",
+		})
+	}
+
+	studentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid student id",
+		})
+	}
+
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "pdf" {
+		return statisticsFilterError(c, model.FieldErrors{{
+			Field:   "format",
+			Message: "harus salah satu dari: json, pdf",
+		}})
+	}
+
+	// Hak akses sama dengan daftar prestasi: diri sendiri, mahasiswa bimbingan, atau read_all
+	studentIDs, err := achievementScope(c, s.AchievementRepo, userID)
+	if err != nil && err != errNoAchievementScope {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to check access",
+		})
+	}
+	if err == errNoAchievementScope || (studentIDs != nil && !containsUUID(studentIDs, studentID)) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "you are not allowed to view this student report",
+		})
+	}
+
+	studentReport, err := s.buildStudentReport(c.UserContext(), studentID)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "student not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get student report",
+		})
+	}
+
+	if format == "pdf" {
+		return s.sendStudentReportPDF(c, userID, studentReport)
+	}
+
 	return c.JSON(fiber.Map{
-		"message": "get student report - coming soon",
+		"message": "success",
+		"data":    studentReport,
 	})
-}
\ No newline at end of file
+}
+
+// buildStudentReport menyusun laporan dari identitas mahasiswa dan prestasi berstatus
+// verified, urut dari yang paling awal diverifikasi
+func (s *StatisticsService) buildStudentReport(ctx context.Context, studentID uuid.UUID) (*model.StudentReport, error) {
+	profile, err := s.AchievementRepo.GetStudentReportProfile(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := model.AchievementListFilter{
+		StudentIDs: []uuid.UUID{studentID},
+		Status:     "verified",
+		SortBy:     "verified_at",
+		SortOrder:  "asc",
+	}
+
+	achievements := []model.StudentReportAchievement{}
+	_, err = s.AchievementRepo.StreamAchievementExport(ctx, filter, studentReportBatchSize, func(rows []model.AchievementExportRow) error {
+		for _, row := range rows {
+			achievements = append(achievements, model.StudentReportAchievement{
+				ID:              row.ID,
+				AchievementType: row.Achievement.AchievementType,
+				Title:           row.Achievement.Title,
+				Level:           row.Achievement.DetailsLevel(),
+				VerifiedAt:      row.VerifiedAt,
+				VerifiedBy:      row.VerifierName,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StudentReport{
+		Student:      *profile,
+		Summary:      model.NewStudentReportSummary(achievements),
+		Achievements: achievements,
+	}, nil
+}
+
+// sendStudentReportPDF menerbitkan laporan: isi laporan di-hash dan ditandatangani, disimpan
+// di student_reports, lalu dirender ke PDF dengan QR code ke endpoint verifikasi
+func (s *StatisticsService) sendStudentReportPDF(c *fiber.Ctx, userID uuid.UUID, studentReport *model.StudentReport) error {
+	if s.Reports == nil || s.Signer == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "report signing is not configured",
+		})
+	}
+
+	content, err := json.Marshal(studentReport)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to generate report",
+		})
+	}
+
+	// Presisi mikrodetik sesuai TIMESTAMPTZ agar payload tanda tangan sama saat dibaca ulang
+	issued := &model.IssuedStudentReport{
+		ID:          uuid.New(),
+		StudentID:   studentReport.Student.ID,
+		IssuedBy:    userID,
+		Content:     content,
+		ContentHash: report.ContentHash(content),
+		IssuedAt:    time.Now().UTC().Truncate(time.Microsecond),
+	}
+	issued.Signature = s.Signer.Sign(report.SigningPayload(issued.ID, issued.StudentID, issued.ContentHash, issued.IssuedAt))
+
+	// Render dulu agar laporan yang gagal dibuat tidak tercatat sebagai terbit
+	var buf bytes.Buffer
+	err = report.RenderStudentReport(&buf, report.StudentReportDocument{
+		ID:          issued.ID,
+		Report:      *studentReport,
+		IssuedAt:    issued.IssuedAt,
+		ContentHash: issued.ContentHash,
+		Signature:   issued.Signature,
+		VerifyURL:   s.studentReportVerifyURL(issued),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to generate report",
+		})
+	}
+
+	if err := s.Reports.Create(c.UserContext(), issued); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to save report",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{
+		"filename": "laporan-prestasi-" + studentReport.Student.StudentID + "-" + issued.IssuedAt.Format("20060102") + ".pdf",
+	}))
+	c.Set("X-Report-ID", issued.ID.String())
+	return c.Send(buf.Bytes())
+}
+
+// studentReportHashPrefix jumlah karakter hash isi yang ikut di URL verifikasi
+const studentReportHashPrefix = 16
+
+// studentReportVerifyURL URL verifikasi yang dikodekan ke QR code. Parameter h berisi awal
+// hash isi sehingga halaman verifikasi bisa memastikan cetakan cocok dengan arsip.
+func (s *StatisticsService) studentReportVerifyURL(issued *model.IssuedStudentReport) string {
+	return strings.TrimRight(s.PublicBaseURL, "/") + "/api/v1/public/reports/" + issued.ID.String() +
+		"?h=" + issued.ContentHash[:studentReportHashPrefix]
+}
+
+// VerifyStudentReport - Verifikasi publik laporan prestasi
+// @Summary Verify student report
+// @Description Endpoint publik (tanpa login) yang dibuka dari QR code laporan PDF. Memeriksa tanda tangan server dan hash isi laporan, lalu menampilkan isi laporan yang diterbitkan agar bisa dicocokkan dengan dokumen cetak.
+// @Tags Reports
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param h query string false "Awal hash isi yang tercetak di dokumen"
+// @Success 200 {object} model.StudentReportVerification "Verification result"
+// @Failure 400 {object} map[string]string "Invalid report id"
+// @Failure 404 {object} map[string]string "Report not found"
+// @Router /api/v1/public/reports/{id} [get]
+func (s *StatisticsService) VerifyStudentReport(c *fiber.Ctx) error {
+	reportID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid report id",
+		})
+	}
+	if s.Reports == nil || s.Signer == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "report signing is not configured",
+		})
+	}
+
+	issued, err := s.Reports.GetByID(c.UserContext(), reportID)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "report not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get report",
+		})
+	}
+
+	return c.JSON(s.verifyStudentReport(issued, c.Query("h")))
+}
+
+// verifyStudentReport memeriksa hash isi, tanda tangan dan (jika ada) awal hash dari dokumen.
+// Isi laporan hanya disertakan jika valid.
+func (s *StatisticsService) verifyStudentReport(issued *model.IssuedStudentReport, printedHash string) model.StudentReportVerification {
+	result := model.StudentReportVerification{
+		ReportID:    issued.ID,
+		IssuedAt:    issued.IssuedAt,
+		ContentHash: issued.ContentHash,
+		Signature:   issued.Signature,
+		Algorithm:   report.SignatureAlgorithm,
+		PublicKey:   s.Signer.PublicKey(),
+	}
+
+	payload := report.SigningPayload(issued.ID, issued.StudentID, issued.ContentHash, issued.IssuedAt)
+	printedHash = strings.ToLower(strings.TrimSpace(printedHash))
+
+	var content model.StudentReport
+	switch {
+	case report.ContentHash(issued.Content) != issued.ContentHash:
+		result.Reason = "report content does not match its hash"
+	case !s.Signer.Verify(payload, issued.Signature):
+		result.Reason = "invalid signature"
+	case printedHash != "" && !strings.HasPrefix(issued.ContentHash, printedHash):
+		result.Reason = "document hash does not match the issued report"
+	case json.Unmarshal(issued.Content, &content) != nil:
+		result.Reason = "report content is not readable"
+	default:
+		result.Valid = true
+		result.Report = &content
+	}
+
+	return result
+}
+
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
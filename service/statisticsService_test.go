@@ -1,12 +1,16 @@
 package service
 
 import (
+	config "POJECT_UAS/Config"
+	"POJECT_UAS/middleware"
 	"POJECT_UAS/model"
+	"context"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -40,18 +44,34 @@ func (m *MockStatisticsRepository) GetStudentIDsByAdvisor(advisorID uuid.UUID) (
 
 func (m *MockStatisticsRepository) GetAchievementStatistics(
 	studentIDs []uuid.UUID,
-	startDate *time.Time,
-	endDate *time.Time,
-	achievementType *string,
-	status *string,
+	filter model.StatisticsRequest,
 ) (*model.AchievementStatistics, error) {
-	args := m.Called(studentIDs, startDate, endDate, achievementType, status)
+	args := m.Called(studentIDs, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.AchievementStatistics), args.Error(1)
 }
 
+func (m *MockStatisticsRepository) GetStudentReportProfile(ctx context.Context, studentID uuid.UUID) (*model.StudentReportProfile, error) {
+	args := m.Called(studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.StudentReportProfile), args.Error(1)
+}
+
+func (m *MockStatisticsRepository) StreamAchievementExport(ctx context.Context, filter model.AchievementListFilter, batchSize int, fn func([]model.AchievementExportRow) error) (int64, error) {
+	args := m.Called(filter, batchSize)
+	rows, _ := args.Get(0).([]model.AchievementExportRow)
+	if len(rows) > 0 {
+		if err := fn(rows); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(rows)), args.Error(1)
+}
+
 func TestStatisticsService_GetMyStatistics_Success(t *testing.T) {
 	// Setup
 	mockRepo := new(MockStatisticsRepository)
@@ -102,13 +122,13 @@ func TestStatisticsService_GetMyStatistics_Success(t *testing.T) {
 
 	// Mock expectations
 	mockRepo.On("GetStudentByUserID", userID).Return(student, nil)
-	mockRepo.On("GetAchievementStatistics", []uuid.UUID{studentID}, (*time.Time)(nil), (*time.Time)(nil), (*string)(nil), (*string)(nil)).Return(expectedStats, nil)
+	mockRepo.On("GetAchievementStatistics", []uuid.UUID{studentID}, mock.AnythingOfType("model.StatisticsRequest")).Return(expectedStats, nil)
 
 	// Create Fiber app and request
 	app := fiber.New()
 
 	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", userID)
+		c.Locals("user_id", userID.String())
 		return c.Next()
 	})
 
@@ -141,7 +161,7 @@ func TestStatisticsService_GetMyStatistics_StudentNotFound(t *testing.T) {
 	app := fiber.New()
 
 	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", userID)
+		c.Locals("user_id", userID.String())
 		return c.Next()
 	})
 
@@ -213,13 +233,13 @@ func TestStatisticsService_GetAdviseeStatistics_Success(t *testing.T) {
 	// Mock expectations
 	mockRepo.On("GetLecturerByUserID", userID).Return(lecturer, nil)
 	mockRepo.On("GetStudentIDsByAdvisor", lecturerID).Return(studentIDs, nil)
-	mockRepo.On("GetAchievementStatistics", studentIDs, (*time.Time)(nil), (*time.Time)(nil), (*string)(nil), (*string)(nil)).Return(expectedStats, nil)
+	mockRepo.On("GetAchievementStatistics", studentIDs, mock.AnythingOfType("model.StatisticsRequest")).Return(expectedStats, nil)
 
 	// Create Fiber app and request
 	app := fiber.New()
 
 	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", userID)
+		c.Locals("user_id", userID.String())
 		return c.Next()
 	})
 
@@ -262,7 +282,7 @@ func TestStatisticsService_GetAdviseeStatistics_NoStudents(t *testing.T) {
 	app := fiber.New()
 
 	app.Use(func(c *fiber.Ctx) error {
-		c.Locals("user_id", userID)
+		c.Locals("user_id", userID.String())
 		return c.Next()
 	})
 
@@ -317,7 +337,7 @@ func TestStatisticsService_GetAllStatistics_Success(t *testing.T) {
 	}
 
 	// Mock expectations - nil studentIDs for all students
-	mockRepo.On("GetAchievementStatistics", ([]uuid.UUID)(nil), (*time.Time)(nil), (*time.Time)(nil), (*string)(nil), (*string)(nil)).Return(expectedStats, nil)
+	mockRepo.On("GetAchievementStatistics", ([]uuid.UUID)(nil), mock.AnythingOfType("model.StatisticsRequest")).Return(expectedStats, nil)
 
 	// Create Fiber app and request
 	app := fiber.New()
@@ -356,7 +376,14 @@ func TestStatisticsService_GetAllStatistics_WithFilters(t *testing.T) {
 	}
 
 	// Mock expectations with filters
-	mockRepo.On("GetAchievementStatistics", ([]uuid.UUID)(nil), &startDate, &endDate, &achievementType, &status).Return(expectedStats, nil)
+	mockRepo.On("GetAchievementStatistics", ([]uuid.UUID)(nil), model.StatisticsRequest{
+		StartDate:       &startDate,
+		EndDate:         &endDate,
+		AchievementType: &achievementType,
+		Status:          &status,
+		PeriodType:      model.PeriodMonthly,
+		TopLimit:        15,
+	}).Return(expectedStats, nil)
 
 	// Create Fiber app and request
 	app := fiber.New()
@@ -373,4 +400,63 @@ func TestStatisticsService_GetAllStatistics_WithFilters(t *testing.T) {
 
 	// Verify mock was called
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+// signTestToken access token seperti yang diterbitkan AuthRepository, ditandatangani dengan
+// secret konfigurasi aktif agar lolos JWTAuth
+func signTestToken(t *testing.T, userID uuid.UUID) string {
+	now := time.Now()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":     userID.String(),
+		"username":    "student1",
+		"email":       "student1@example.com",
+		"role_id":     uuid.New().String(),
+		"permissions": []map[string]string{{"name": "achievements:read", "resource": "achievements", "action": "read"}},
+		"jti":         uuid.New().String(),
+		"iat":         now.Unix(),
+		"exp":         now.Add(time.Minute).Unix(),
+	}).SignedString([]byte(config.GetJWTSecret()))
+	assert.NoError(t, err)
+	return token
+}
+
+func TestStatisticsService_GetStudentReport_ThroughJWTAuth(t *testing.T) {
+	// Setup
+	mockRepo := new(MockStatisticsRepository)
+	statisticsService := &StatisticsService{AchievementRepo: mockRepo}
+
+	userID := uuid.New()
+	studentID := uuid.New()
+	otherStudentID := uuid.New()
+
+	// Mahasiswa hanya boleh melihat laporannya sendiri
+	mockRepo.On("GetStudentByUserID", userID).Return(&model.Student{ID: studentID, UserID: userID}, nil)
+	mockRepo.On("GetStudentReportProfile", studentID).Return(&model.StudentReportProfile{
+		ID:        studentID,
+		StudentID: "2021001",
+		FullName:  "Test Student",
+	}, nil)
+	mockRepo.On("StreamAchievementExport", mock.MatchedBy(func(filter model.AchievementListFilter) bool {
+		return filter.Status == "verified" && len(filter.StudentIDs) == 1 && filter.StudentIDs[0] == studentID
+	}), studentReportBatchSize).Return([]model.AchievementExportRow{}, nil)
+
+	app := fiber.New()
+	app.Get("/reports/student/:id", middleware.JWTAuth(nil), statisticsService.GetStudentReport)
+	token := signTestToken(t, userID)
+
+	get := func(id uuid.UUID, withToken bool) int {
+		req := httptest.NewRequest("GET", "/reports/student/"+id.String(), nil)
+		if withToken {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	// Execute & Assert
+	assert.Equal(t, 200, get(studentID, true))
+	assert.Equal(t, 403, get(otherStudentID, true))
+	assert.Equal(t, 401, get(studentID, false))
+	mockRepo.AssertExpectations(t)
+}
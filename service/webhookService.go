@@ -0,0 +1,421 @@
+package service
+
+import (
+	"POJECT_UAS/middleware"
+	"POJECT_UAS/model"
+	"POJECT_UAS/repository"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// minWebhookSecretLength panjang minimal secret yang diisi sendiri oleh admin
+const minWebhookSecretLength = 16
+
+type WebhookService struct {
+	WebhookRepo *repository.WebhookRepository
+}
+
+func NewWebhookService(webhookRepo *repository.WebhookRepository) *WebhookService {
+	return &WebhookService{WebhookRepo: webhookRepo}
+}
+
+// GetWebhooks godoc
+// @Summary List webhook subscriptions
+// @Description Secret tidak ditampilkan.
+// @Tags Admin
+// @Security BearerAuth
+// @Success 200 {array} model.WebhookSubscription
+// @Router /admin/webhooks [get]
+func (s *WebhookService) GetWebhooks(c *fiber.Ctx) error {
+	subs, err := s.WebhookRepo.ListSubscriptions(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get webhooks",
+		})
+	}
+
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "webhooks retrieved successfully",
+		"data":    subs,
+	})
+}
+
+// GetWebhook godoc
+// @Summary Get webhook subscription
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} model.WebhookSubscription
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Router /admin/webhooks/{id} [get]
+func (s *WebhookService) GetWebhook(c *fiber.Ctx) error {
+	sub, ok, err := s.findSubscription(c)
+	if !ok {
+		return err
+	}
+
+	sub.Secret = ""
+	return c.JSON(fiber.Map{
+		"message": "success",
+		"data":    sub,
+	})
+}
+
+// CreateWebhook godoc
+// @Summary Create webhook subscription
+// @Description Events kosong berarti semua event (achievement.created, .submitted, .verified, .rejected, .deleted). Secret dibuat otomatis jika kosong dan hanya ditampilkan di response ini.
+// @Tags Admin
+// @Security BearerAuth
+// @Param request body model.CreateWebhookRequest true "Webhook"
+// @Success 201 {object} model.WebhookSubscription
+// @Failure 400 {object} map[string]interface{} "Validation error"
+// @Router /admin/webhooks [post]
+func (s *WebhookService) CreateWebhook(c *fiber.Ctx) error {
+	var req model.CreateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	fieldErrs := validateWebhook(req.Name, req.URL, req.Events)
+	if req.Secret != "" && len(req.Secret) < minWebhookSecretLength {
+		fieldErrs = append(fieldErrs, model.FieldError{Field: "secret", Message: "minimal 16 karakter"})
+	}
+	if fieldErrs != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid webhook",
+			"details": fieldErrs,
+		})
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = newWebhookSecret()
+	}
+
+	now := time.Now()
+	sub := model.WebhookSubscription{
+		ID:        uuid.New(),
+		Name:      req.Name,
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    normalizeWebhookEvents(req.Events),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if createdBy, err := uuid.Parse(middleware.GetUserID(c)); err == nil {
+		sub.CreatedBy = &createdBy
+	}
+
+	if err := s.WebhookRepo.CreateSubscription(c.Context(), sub); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to create webhook",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "webhook created successfully, simpan secret karena tidak akan ditampilkan lagi",
+		"data":    sub,
+	})
+}
+
+// UpdateWebhook godoc
+// @Summary Update webhook subscription
+// @Description Field yang tidak dikirim tidak diubah. is_active false menunda pengiriman sampai diaktifkan lagi.
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param request body model.UpdateWebhookRequest true "Webhook"
+// @Success 200 {object} model.WebhookSubscription
+// @Failure 400 {object} map[string]interface{} "Validation error"
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Router /admin/webhooks/{id} [put]
+func (s *WebhookService) UpdateWebhook(c *fiber.Ctx) error {
+	var req model.UpdateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	sub, ok, err := s.findSubscription(c)
+	if !ok {
+		return err
+	}
+
+	if req.Name != nil {
+		sub.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.URL != nil {
+		sub.URL = *req.URL
+	}
+	if req.Events != nil {
+		sub.Events = *req.Events
+	}
+	if req.IsActive != nil {
+		sub.IsActive = *req.IsActive
+	}
+
+	fieldErrs := validateWebhook(sub.Name, sub.URL, sub.Events)
+	if req.Secret != nil {
+		if len(*req.Secret) < minWebhookSecretLength {
+			fieldErrs = append(fieldErrs, model.FieldError{Field: "secret", Message: "minimal 16 karakter"})
+		}
+		sub.Secret = *req.Secret
+	}
+	if fieldErrs != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid webhook",
+			"details": fieldErrs,
+		})
+	}
+
+	sub.Events = normalizeWebhookEvents(sub.Events)
+	sub.UpdatedAt = time.Now()
+
+	if err := s.WebhookRepo.UpdateSubscription(c.Context(), *sub); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "webhook not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to update webhook",
+		})
+	}
+
+	sub.Secret = ""
+	return c.JSON(fiber.Map{
+		"message": "webhook updated successfully",
+		"data":    sub,
+	})
+}
+
+// DeleteWebhook godoc
+// @Summary Delete webhook subscription
+// @Description Langganan dihapus beserta log pengirimannya.
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Router /admin/webhooks/{id} [delete]
+func (s *WebhookService) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid webhook id",
+		})
+	}
+
+	if err := s.WebhookRepo.DeleteSubscription(c.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "webhook not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to delete webhook",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "webhook deleted successfully",
+	})
+}
+
+// GetWebhookDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Log pengiriman satu langganan, terbaru lebih dulu.
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param status query string false "pending, delivering, delivered, failed"
+// @Param limit query int false "Jumlah data (default 50, maks. 200)"
+// @Success 200 {array} model.WebhookDelivery
+// @Failure 400 {object} map[string]string "Invalid parameter"
+// @Router /admin/webhooks/{id}/deliveries [get]
+func (s *WebhookService) GetWebhookDeliveries(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid webhook id",
+		})
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", model.WebhookPending, model.WebhookDelivering, model.WebhookDelivered, model.WebhookFailed:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid status",
+		})
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit must be between 1 and 200",
+		})
+	}
+
+	deliveries, err := s.WebhookRepo.ListDeliveries(c.Context(), id, status, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get webhook deliveries",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "webhook deliveries retrieved successfully",
+		"data":    deliveries,
+	})
+}
+
+// GetWebhookDelivery godoc
+// @Summary Get webhook delivery
+// @Description Detail delivery beserta log setiap percobaan (status code, error, potongan response).
+// @Tags Admin
+// @Security BearerAuth
+// @Param deliveryId path string true "Delivery ID"
+// @Success 200 {object} model.WebhookDelivery
+// @Failure 404 {object} map[string]string "Delivery not found"
+// @Router /admin/webhooks/deliveries/{deliveryId} [get]
+func (s *WebhookService) GetWebhookDelivery(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("deliveryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid delivery id",
+		})
+	}
+
+	delivery, err := s.WebhookRepo.GetDelivery(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "delivery not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get webhook delivery",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "success",
+		"data":    delivery,
+	})
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver webhook
+// @Description Mengantrekan ulang delivery (gagal maupun sudah terkirim) dengan payload dan ID yang sama; jumlah percobaan direset.
+// @Tags Admin
+// @Security BearerAuth
+// @Param deliveryId path string true "Delivery ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Delivery not found or in progress"
+// @Router /admin/webhooks/deliveries/{deliveryId}/redeliver [post]
+func (s *WebhookService) RedeliverWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("deliveryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid delivery id",
+		})
+	}
+
+	if err := s.WebhookRepo.Redeliver(c.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "delivery not found or currently being delivered",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to redeliver webhook",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "webhook delivery queued",
+	})
+}
+
+// findSubscription mengambil langganan dari parameter :id. ok false berarti response error sudah ditulis.
+func (s *WebhookService) findSubscription(c *fiber.Ctx) (*model.WebhookSubscription, bool, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid webhook id",
+		})
+	}
+
+	sub, err := s.WebhookRepo.GetSubscription(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "webhook not found",
+			})
+		}
+		return nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get webhook",
+		})
+	}
+
+	return sub, true, nil
+}
+
+// validateWebhook memeriksa nama, URL (http/https absolut) dan daftar event
+func validateWebhook(name, rawURL string, events []string) model.FieldErrors {
+	var errs model.FieldErrors
+
+	if name == "" {
+		errs = append(errs, model.FieldError{Field: "name", Message: "wajib diisi"})
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, model.FieldError{Field: "url", Message: "harus URL http atau https"})
+	}
+
+	for _, event := range events {
+		if !slices.Contains(model.WebhookEvents, event) {
+			errs = append(errs, model.FieldError{Field: "events", Message: "event tidak dikenal: " + event})
+		}
+	}
+
+	return errs
+}
+
+// normalizeWebhookEvents mengurutkan dan menghapus event duplikat
+func normalizeWebhookEvents(events []string) []string {
+	out := slices.Clone(events)
+	if out == nil {
+		return []string{}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// newWebhookSecret secret acak untuk langganan baru
+func newWebhookSecret() string {
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	return "whsec_" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+var (
+	ErrEmptyFile       = errors.New("file is empty")
+	ErrFileTooLarge    = errors.New("file too large")
+	ErrUnsupportedType = errors.New("unsupported file type")
+)
+
+// DefaultAllowedTypes tipe file bukti prestasi yang diterima (sertifikat dan foto)
+var DefaultAllowedTypes = []string{
+	"application/pdf",
+	"image/jpeg",
+	"image/png",
+	"image/webp",
+}
+
+// Policy batasan upload lampiran
+type Policy struct {
+	MaxFileSize  int64    // byte per file
+	MaxFiles     int      // jumlah file per request
+	AllowedTypes []string // hasil MIME sniffing, bukan Content-Type dari client
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxFileSize:  5 << 20,
+		MaxFiles:     5,
+		AllowedTypes: DefaultAllowedTypes,
+	}
+}
+
+// Allows true jika contentType termasuk tipe yang diizinkan
+func (p Policy) Allows(contentType string) bool {
+	for _, t := range p.AllowedTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Object hasil penyimpanan satu file
+type Object struct {
+	Size        int64
+	SHA256      string
+	ContentType string
+}
+
+// Save memvalidasi lalu menyimpan file ke store. Tipe file ditentukan dari isi file
+// (MIME sniffing 512 byte pertama), ukuran dibatasi saat streaming, dan checksum
+// SHA-256 dihitung dari byte yang benar-benar disimpan. size -1 jika tidak diketahui.
+func Save(ctx context.Context, store AttachmentStore, key string, r io.Reader, size int64, policy Policy) (*Object, error) {
+	if size > policy.MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrEmptyFile
+	}
+	head = head[:n]
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return nil, err
+	}
+	if !policy.Allows(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	hasher := sha256.New()
+	limited := &limitedReader{r: io.MultiReader(bytes.NewReader(head), r), remaining: policy.MaxFileSize}
+	counter := &countingReader{r: io.TeeReader(limited, hasher)}
+
+	if err := store.Put(ctx, key, counter, size, contentType); err != nil {
+		// Hapus sisa upload yang gagal (misalnya melebihi batas di tengah streaming)
+		store.Delete(context.Background(), key)
+		if limited.exceeded {
+			return nil, ErrFileTooLarge
+		}
+		return nil, err
+	}
+	if limited.exceeded {
+		store.Delete(context.Background(), key)
+		return nil, ErrFileTooLarge
+	}
+
+	return &Object{
+		Size:        counter.n,
+		SHA256:      hex.EncodeToString(hasher.Sum(nil)),
+		ContentType: contentType,
+	}, nil
+}
+
+// limitedReader seperti io.LimitReader, tetapi mengembalikan ErrFileTooLarge
+// jika masih ada data setelah batas tercapai
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			l.exceeded = true
+			return 0, ErrFileTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pdfContent isi file minimal yang dikenali sebagai application/pdf
+var pdfContent = []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n")
+
+func TestLocalStore_PutGetDelete(t *testing.T) {
+	// Setup
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	// Execute
+	err = store.Put(ctx, "achievements/abc/file1", bytes.NewReader(pdfContent), int64(len(pdfContent)), "application/pdf")
+	assert.NoError(t, err)
+
+	reader, err := store.Get(ctx, "achievements/abc/file1")
+	assert.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	reader.Close()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, pdfContent, content)
+
+	assert.NoError(t, store.Delete(ctx, "achievements/abc/file1"))
+	assert.NoError(t, store.Delete(ctx, "achievements/abc/file1"))
+	_, err = store.Get(ctx, "achievements/abc/file1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocalStore_RejectsPathTraversal(t *testing.T) {
+	// Setup
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	for _, key := range []string{"", "../secret", "/etc/passwd", "a/../../b", "a//b", `a\b`} {
+		// Execute
+		err := store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
+
+func TestSave_StoresWithChecksum(t *testing.T) {
+	// Setup
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	sum := sha256.Sum256(pdfContent)
+
+	// Execute
+	object, err := Save(context.Background(), store, "a/1", bytes.NewReader(pdfContent), -1, DefaultPolicy())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "application/pdf", object.ContentType)
+	assert.Equal(t, int64(len(pdfContent)), object.Size)
+	assert.Equal(t, hex.EncodeToString(sum[:]), object.SHA256)
+}
+
+func TestSave_RejectsUnsupportedType(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir)
+	assert.NoError(t, err)
+
+	// Execute: ekstensi/Content-Type dari client tidak dipercaya, isi file berupa HTML
+	_, err = Save(context.Background(), store, "a/1", strings.NewReader("<html><script>alert(1)</script></html>"), -1, DefaultPolicy())
+
+	// Assert
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+	_, err = os.Stat(dir + "/a/1")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSave_RejectsTooLargeWhileStreaming(t *testing.T) {
+	// Setup
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir)
+	assert.NoError(t, err)
+	policy := DefaultPolicy()
+	policy.MaxFileSize = int64(len(pdfContent)) - 1
+
+	// Execute: ukuran tidak diketahui di awal (-1)
+	_, err = Save(context.Background(), store, "a/1", bytes.NewReader(pdfContent), -1, policy)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrFileTooLarge)
+	_, err = store.Get(context.Background(), "a/1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSave_RejectsEmptyFile(t *testing.T) {
+	// Setup
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	// Execute
+	_, err = Save(context.Background(), store, "a/1", bytes.NewReader(nil), 0, DefaultPolicy())
+
+	// Assert
+	assert.ErrorIs(t, err, ErrEmptyFile)
+}
+
+// TestS3Store_MinIO berjalan terhadap MinIO lokal jika MINIO_ENDPOINT di-set, contoh:
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	MINIO_ENDPOINT=localhost:9000 MINIO_ACCESS_KEY=minioadmin MINIO_SECRET_KEY=minioadmin go test ./storage
+func TestS3Store_MinIO(t *testing.T) {
+	endpoint := os.Getenv("MINIO_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("MINIO_ENDPOINT not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	store, err := NewS3Store(ctx, S3Config{
+		Endpoint:  endpoint,
+		AccessKey: os.Getenv("MINIO_ACCESS_KEY"),
+		SecretKey: os.Getenv("MINIO_SECRET_KEY"),
+		Bucket:    "test-attachments",
+	})
+	assert.NoError(t, err)
+
+	key := "achievements/test/" + time.Now().Format("20060102150405.000000000")
+	object, err := Save(ctx, store, key, bytes.NewReader(pdfContent), int64(len(pdfContent)), DefaultPolicy())
+	assert.NoError(t, err)
+	assert.Equal(t, "application/pdf", object.ContentType)
+
+	reader, err := store.Get(ctx, key)
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(reader)
+	reader.Close()
+	assert.Equal(t, pdfContent, content)
+
+	assert.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("attachment not found")
+	ErrInvalidKey = errors.New("invalid attachment key")
+)
+
+// AttachmentStore penyimpanan file lampiran prestasi. Key berbentuk path relatif
+// dengan pemisah "/", misalnya "achievements/<mongo id>/<attachment id>".
+type AttachmentStore interface {
+	// Put menyimpan isi r. size -1 jika ukuran belum diketahui.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get membuka file untuk dibaca. Mengembalikan ErrNotFound jika key tidak ada.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete menghapus file. Menghapus key yang tidak ada bukan error.
+	Delete(ctx context.Context, key string) error
+}
+
+// validateKey menolak key kosong, absolut, atau yang keluar dari root penyimpanan
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -99,13 +99,13 @@ func (f *AchievementFixtures) RejectedAchievementReference() *model.AchievementR
 // ValidSubmitAchievementRequest returns a valid submit achievement request
 func (f *AchievementFixtures) ValidSubmitAchievementRequest() model.SubmitAchievementRequest {
 	return model.SubmitAchievementRequest{
-		AchievementType: "akademik",
+		AchievementType: "competition",
 		Title:           "Juara 1 Programming Contest",
 		Description:     "Kompetisi programming tingkat nasional",
 		Details: map[string]interface{}{
-			"level":    "nasional",
-			"category": "programming",
-			"date":     "2024-01-15",
+			"competition_name":  "Gemastik",
+			"competition_level": "national",
+			"event_date":        "2024-01-15",
 		},
 	}
 }
@@ -143,7 +143,7 @@ func (f *AchievementFixtures) ValidStudent() *model.Student {
 		StudentID:    "2021001",
 		ProgramStudy: "Teknik Informatika",
 		AcademicYear: "2021",
-		AdvisorID:    &advisorID,
+		AdvisorID:    advisorID,
 		CreatedAt:    time.Now(),
 	}
 }
@@ -167,7 +167,7 @@ func (f *AchievementFixtures) ValidNotification() model.Notification {
 		Type:      "achievement_submitted",
 		Title:     "Prestasi Baru Disubmit",
 		Message:   "Mahasiswa telah submit prestasi untuk verifikasi",
-		Data:      `{"achievement_id": "` + uuid.New().String() + `"}`,
+		Data:      &model.NotificationData{AchievementID: uuid.New().String()},
 		IsRead:    false,
 		CreatedAt: time.Now(),
 	}
@@ -150,15 +150,15 @@ func (f *UserFixtures) InvalidLoginRequest() model.LoginRequest {
 func (f *UserFixtures) ValidLoginResponse() *model.LoginResponse {
 	return &model.LoginResponse{
 		Token: "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.test.token",
-		User: model.UserProfile{
+		Profile: model.UserProfile{
 			ID:       uuid.New(),
 			Username: "testuser",
 			Email:    "test@example.com",
 			FullName: "Test User",
-			Role:     "student",
-			Permissions: []string{
-				"achievements:create",
-				"achievements:read",
+			Role:     model.RoleInfo{Name: "student"},
+			Permissions: []model.Permission{
+				{Name: "achievements:create", Resource: "achievements", Action: "create"},
+				{Name: "achievements:read", Resource: "achievements", Action: "read"},
 			},
 		},
 	}
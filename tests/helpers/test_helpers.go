@@ -3,6 +3,7 @@ package helpers
 import (
 	"bytes"
 	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
@@ -33,7 +34,7 @@ func (h *TestHelper) CreateFiberApp() *fiber.App {
 }
 
 // CreateJSONRequest creates an HTTP request with JSON body
-func (h *TestHelper) CreateJSONRequest(method, url string, body interface{}) *httptest.Request {
+func (h *TestHelper) CreateJSONRequest(method, url string, body interface{}) *http.Request {
 	var reqBody []byte
 	var err error
 
@@ -48,18 +49,18 @@ func (h *TestHelper) CreateJSONRequest(method, url string, body interface{}) *ht
 }
 
 // CreateAuthenticatedRequest creates an HTTP request with JWT token
-func (h *TestHelper) CreateAuthenticatedRequest(method, url string, body interface{}, token string) *httptest.Request {
+func (h *TestHelper) CreateAuthenticatedRequest(method, url string, body interface{}, token string) *http.Request {
 	req := h.CreateJSONRequest(method, url, body)
 	req.Header.Set("Authorization", "Bearer "+token)
 	return req
 }
 
-// SetFiberLocals sets locals in Fiber context for testing
+// SetFiberLocals sets locals in Fiber context the same way JWTAuth and RequireRole do
 func (h *TestHelper) SetFiberLocals(c *fiber.Ctx, userID uuid.UUID, username, email, role string) {
-	c.Locals("user_id", userID)
+	c.Locals("user_id", userID.String())
 	c.Locals("username", username)
 	c.Locals("email", email)
-	c.Locals("role", role)
+	c.Locals("role_name", role)
 }
 
 // CreateMiddleware creates a middleware that sets user context
@@ -160,7 +161,7 @@ func (h *TestHelper) AssertPaginationResponse(resp *httptest.ResponseRecorder, e
 }
 
 // CreateFormRequest creates a multipart form request for file uploads
-func (h *TestHelper) CreateFormRequest(method, url string, fields map[string]string, files map[string][]byte) *httptest.Request {
+func (h *TestHelper) CreateFormRequest(method, url string, fields map[string]string, files map[string][]byte) *http.Request {
 	// This would be implemented for file upload testing
 	// For now, return a basic request
 	req := httptest.NewRequest(method, url, nil)
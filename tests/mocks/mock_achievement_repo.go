@@ -2,7 +2,7 @@ package mocks
 
 import (
 	"POJECT_UAS/model"
-	"time"
+	"context"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -14,8 +14,8 @@ type MockAchievementRepository struct {
 }
 
 // SubmitAchievement mocks achievement submission
-func (m *MockAchievementRepository) SubmitAchievement(studentID uuid.UUID, req model.SubmitAchievementRequest) (*model.SubmitAchievementResponse, error) {
-	args := m.Called(studentID, req)
+func (m *MockAchievementRepository) SubmitAchievement(studentID uuid.UUID, createdBy uuid.UUID, req model.SubmitAchievementRequest) (*model.SubmitAchievementResponse, error) {
+	args := m.Called(studentID, createdBy, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -50,8 +50,8 @@ func (m *MockAchievementRepository) GetAchievementReferenceByID(referenceID uuid
 }
 
 // SubmitForVerification mocks submission for verification
-func (m *MockAchievementRepository) SubmitForVerification(referenceID uuid.UUID) error {
-	args := m.Called(referenceID)
+func (m *MockAchievementRepository) SubmitForVerification(referenceID uuid.UUID, submittedBy uuid.UUID) error {
+	args := m.Called(referenceID, submittedBy)
 	return args.Error(0)
 }
 
@@ -61,8 +61,8 @@ func (m *MockAchievementRepository) CreateNotification(notification model.Notifi
 	return args.Error(0)
 }
 
-// GetAdvisorByStudentID mocks getting advisor by student ID
-func (m *MockAchievementRepository) GetAdvisorByStudentID(studentID uuid.UUID) (uuid.UUID, error) {
+// GetAdvisorUserIDByStudentID mocks getting the advisor's user ID by student ID
+func (m *MockAchievementRepository) GetAdvisorUserIDByStudentID(studentID uuid.UUID) (uuid.UUID, error) {
 	args := m.Called(studentID)
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
@@ -77,8 +77,8 @@ func (m *MockAchievementRepository) GetUserByID(userID uuid.UUID) (*model.Users,
 }
 
 // DeleteAchievement mocks achievement deletion
-func (m *MockAchievementRepository) DeleteAchievement(referenceID uuid.UUID, mongoAchievementID string) error {
-	args := m.Called(referenceID, mongoAchievementID)
+func (m *MockAchievementRepository) DeleteAchievement(referenceID uuid.UUID, mongoAchievementID string, deletedBy uuid.UUID) error {
+	args := m.Called(referenceID, mongoAchievementID, deletedBy)
 	return args.Error(0)
 }
 
@@ -97,15 +97,35 @@ func (m *MockAchievementRepository) GetStudentIDsByAdvisor(advisorID uuid.UUID)
 	return args.Get(0).([]uuid.UUID), args.Error(1)
 }
 
-// GetAchievementReferencesWithPagination mocks paginated achievement references
-func (m *MockAchievementRepository) GetAchievementReferencesWithPagination(
-	studentIDs []uuid.UUID,
-	status string,
-	page int,
-	perPage int,
-) ([]model.AchievementReference, int64, error) {
-	args := m.Called(studentIDs, status, page, perPage)
-	return args.Get(0).([]model.AchievementReference), args.Get(1).(int64), args.Error(2)
+// ListAchievementReferences mocks the filtered, paginated achievement list
+func (m *MockAchievementRepository) ListAchievementReferences(filter model.AchievementListFilter) ([]model.AchievementReferenceWithStudent, model.PageInfo, error) {
+	args := m.Called(filter)
+	refs, _ := args.Get(0).([]model.AchievementReferenceWithStudent)
+	return refs, args.Get(1).(model.PageInfo), args.Error(2)
+}
+
+// UpdateAchievement mocks achievement update with optimistic locking
+func (m *MockAchievementRepository) UpdateAchievement(referenceID uuid.UUID, updatedBy uuid.UUID, expectedVersion int, req model.UpdateAchievementRequest) (*model.Achievement, error) {
+	args := m.Called(referenceID, updatedBy, expectedVersion, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Achievement), args.Error(1)
+}
+
+// AddAttachments mocks adding attachments to an achievement
+func (m *MockAchievementRepository) AddAttachments(referenceID uuid.UUID, mongoAchievementID string, attachments []model.Attachment) error {
+	args := m.Called(referenceID, mongoAchievementID, attachments)
+	return args.Error(0)
+}
+
+// GetAchievementStatusHistory mocks getting the status history of an achievement
+func (m *MockAchievementRepository) GetAchievementStatusHistory(referenceID uuid.UUID) ([]model.AchievementStatusHistory, error) {
+	args := m.Called(referenceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AchievementStatusHistory), args.Error(1)
 }
 
 // GetStudentByID mocks getting student by ID
@@ -141,26 +161,33 @@ func (m *MockAchievementRepository) CheckLecturerOwnsStudent(lecturerID uuid.UUI
 	return args.Bool(0), args.Error(1)
 }
 
-// GetAllAchievementReferencesWithPagination mocks getting all achievement references
-func (m *MockAchievementRepository) GetAllAchievementReferencesWithPagination(
-	status string,
-	achievementType string,
-	page int,
-	perPage int,
-) ([]model.AchievementReference, int64, error) {
-	args := m.Called(status, achievementType, page, perPage)
-	return args.Get(0).([]model.AchievementReference), args.Get(1).(int64), args.Error(2)
+// GetStudentReportProfile mocks getting the profile for a student report
+func (m *MockAchievementRepository) GetStudentReportProfile(ctx context.Context, studentID uuid.UUID) (*model.StudentReportProfile, error) {
+	args := m.Called(studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.StudentReportProfile), args.Error(1)
+}
+
+// StreamAchievementExport mocks streaming achievement export rows in one batch
+func (m *MockAchievementRepository) StreamAchievementExport(ctx context.Context, filter model.AchievementListFilter, batchSize int, fn func([]model.AchievementExportRow) error) (int64, error) {
+	args := m.Called(filter, batchSize)
+	rows, _ := args.Get(0).([]model.AchievementExportRow)
+	if len(rows) > 0 {
+		if err := fn(rows); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(rows)), args.Error(1)
 }
 
 // GetAchievementStatistics mocks getting achievement statistics
 func (m *MockAchievementRepository) GetAchievementStatistics(
 	studentIDs []uuid.UUID,
-	startDate *time.Time,
-	endDate *time.Time,
-	achievementType *string,
-	status *string,
+	filter model.StatisticsRequest,
 ) (*model.AchievementStatistics, error) {
-	args := m.Called(studentIDs, startDate, endDate, achievementType, status)
+	args := m.Called(studentIDs, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
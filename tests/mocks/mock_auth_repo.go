@@ -3,6 +3,8 @@ package mocks
 import (
 	"POJECT_UAS/model"
 
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -21,12 +23,12 @@ func (m *MockAuthRepository) Login(req model.LoginRequest) (*model.LoginResponse
 }
 
 // ValidateToken mocks token validation
-func (m *MockAuthRepository) ValidateToken(token string) (*model.TokenClaims, error) {
+func (m *MockAuthRepository) ValidateToken(token string) (jwt.MapClaims, error) {
 	args := m.Called(token)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*model.TokenClaims), args.Error(1)
+	return args.Get(0).(jwt.MapClaims), args.Error(1)
 }
 
 // RefreshToken mocks token refresh
@@ -42,4 +44,10 @@ func (m *MockAuthRepository) RefreshToken(refreshToken string) (*model.LoginResp
 func (m *MockAuthRepository) InvalidateToken(token string) error {
 	args := m.Called(token)
 	return args.Error(0)
-}
\ No newline at end of file
+}
+
+// RevokeRefreshToken mocks revoking a refresh token session
+func (m *MockAuthRepository) RevokeRefreshToken(refreshToken string, userID uuid.UUID) error {
+	args := m.Called(refreshToken, userID)
+	return args.Error(0)
+}
@@ -1,19 +1,23 @@
 package service_test
 
 import (
+	"POJECT_UAS/model"
 	"POJECT_UAS/service"
+	"POJECT_UAS/storage"
 	"POJECT_UAS/tests/fixtures"
 	"POJECT_UAS/tests/helpers"
 	"POJECT_UAS/tests/mocks"
 	"testing"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestAchievementService_SubmitAchievement_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockAchievementRepository)
-	achievementService := service.NewAchievementService(mockRepo)
+	achievementService := service.NewAchievementService(mockRepo, nil, storage.Policy{}, nil)
 	helper := helpers.NewTestHelper(t)
 	fixtures := fixtures.NewAchievementFixtures()
 
@@ -30,7 +34,11 @@ func TestAchievementService_SubmitAchievement_Success(t *testing.T) {
 
 	// Setup mock expectations
 	mockRepo.On("GetStudentByUserID", userID).Return(student, nil)
-	mockRepo.On("SubmitAchievement", student.ID, submitReq).Return(expectedResponse, nil)
+	mockRepo.On("SubmitAchievement", student.ID, userID, mock.MatchedBy(func(req model.SubmitAchievementRequest) bool {
+		// Details sudah di-decode sesuai achievement_type sebelum disimpan
+		_, ok := req.Details.(*model.CompetitionDetails)
+		return ok && req.Title == submitReq.Title
+	})).Return(expectedResponse, nil)
 
 	// Act
 	req := helper.CreateJSONRequest("POST", "/achievements", submitReq)
@@ -47,7 +55,7 @@ func TestAchievementService_SubmitAchievement_Success(t *testing.T) {
 func TestAchievementService_SubmitAchievement_StudentNotFound(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockAchievementRepository)
-	achievementService := service.NewAchievementService(mockRepo)
+	achievementService := service.NewAchievementService(mockRepo, nil, storage.Policy{}, nil)
 	helper := helpers.NewTestHelper(t)
 	fixtures := fixtures.NewAchievementFixtures()
 
@@ -77,7 +85,7 @@ func TestAchievementService_SubmitAchievement_StudentNotFound(t *testing.T) {
 func TestAchievementService_SubmitAchievement_InvalidRequest(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockAchievementRepository)
-	achievementService := service.NewAchievementService(mockRepo)
+	achievementService := service.NewAchievementService(mockRepo, nil, storage.Policy{}, nil)
 	helper := helpers.NewTestHelper(t)
 	fixtures := fixtures.NewAchievementFixtures()
 
@@ -99,14 +107,14 @@ func TestAchievementService_SubmitAchievement_InvalidRequest(t *testing.T) {
 	assert.Equal(t, 400, resp.StatusCode)
 
 	// Verify no repository calls were made
-	mockRepo.AssertNotCalled(t, "GetStudentByUserID")
-	mockRepo.AssertNotCalled(t, "SubmitAchievement")
+	mockRepo.AssertNotCalled(t, "GetStudentByUserID", mock.Anything)
+	mockRepo.AssertNotCalled(t, "SubmitAchievement", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestAchievementService_SubmitForVerification_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockAchievementRepository)
-	achievementService := service.NewAchievementService(mockRepo)
+	achievementService := service.NewAchievementService(mockRepo, nil, storage.Policy{}, nil)
 	helper := helpers.NewTestHelper(t)
 	fixtures := fixtures.NewAchievementFixtures()
 
@@ -115,8 +123,7 @@ func TestAchievementService_SubmitForVerification_Success(t *testing.T) {
 	student.UserID = userID
 	
 	referenceID := helper.GenerateUUID()
-	advisorID := helper.GenerateUUID()
-	student.AdvisorID = &advisorID
+	advisorUserID := helper.GenerateUUID()
 
 	achievementRef := fixtures.ValidAchievementReference()
 	achievementRef.ID = referenceID
@@ -124,13 +131,15 @@ func TestAchievementService_SubmitForVerification_Success(t *testing.T) {
 
 	app := helper.CreateFiberApp()
 	app.Use(helper.CreateMiddleware(userID, "student123", "student@example.com", "student"))
-	app.Post("/achievements/:reference_id/submit", achievementService.SubmitForVerification)
+	app.Post("/achievements/:id/submit", achievementService.SubmitForVerification)
 
 	// Setup mock expectations
 	mockRepo.On("GetStudentByUserID", userID).Return(student, nil)
 	mockRepo.On("GetAchievementReferenceByID", referenceID).Return(achievementRef, nil)
-	mockRepo.On("SubmitForVerification", referenceID).Return(nil)
-	mockRepo.On("GetAdvisorByStudentID", student.ID).Return(advisorID, nil)
+	mockRepo.On("SubmitForVerification", referenceID, userID).Return(nil)
+	mockRepo.On("GetAchievementByID", achievementRef.MongoAchievementID).Return(fixtures.ValidAchievement(), nil)
+	mockRepo.On("GetAdvisorUserIDByStudentID", student.ID).Return(advisorUserID, nil)
+	mockRepo.On("GetUserByID", userID).Return(&model.Users{ID: userID, FullName: "Test Student"}, nil)
 	mockRepo.On("CreateNotification", mock.AnythingOfType("model.Notification")).Return(nil)
 
 	// Act
@@ -148,7 +157,7 @@ func TestAchievementService_SubmitForVerification_Success(t *testing.T) {
 func TestAchievementService_SubmitForVerification_NotOwner(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockAchievementRepository)
-	achievementService := service.NewAchievementService(mockRepo)
+	achievementService := service.NewAchievementService(mockRepo, nil, storage.Policy{}, nil)
 	helper := helpers.NewTestHelper(t)
 	fixtures := fixtures.NewAchievementFixtures()
 
@@ -163,7 +172,7 @@ func TestAchievementService_SubmitForVerification_NotOwner(t *testing.T) {
 
 	app := helper.CreateFiberApp()
 	app.Use(helper.CreateMiddleware(userID, "student123", "student@example.com", "student"))
-	app.Post("/achievements/:reference_id/submit", achievementService.SubmitForVerification)
+	app.Post("/achievements/:id/submit", achievementService.SubmitForVerification)
 
 	// Setup mock expectations
 	mockRepo.On("GetStudentByUserID", userID).Return(student, nil)
@@ -184,7 +193,7 @@ func TestAchievementService_SubmitForVerification_NotOwner(t *testing.T) {
 func TestAchievementService_DeleteAchievement_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockAchievementRepository)
-	achievementService := service.NewAchievementService(mockRepo)
+	achievementService := service.NewAchievementService(mockRepo, nil, storage.Policy{}, nil)
 	helper := helpers.NewTestHelper(t)
 	fixtures := fixtures.NewAchievementFixtures()
 
@@ -203,12 +212,12 @@ func TestAchievementService_DeleteAchievement_Success(t *testing.T) {
 
 	app := helper.CreateFiberApp()
 	app.Use(helper.CreateMiddleware(userID, "student123", "student@example.com", "student"))
-	app.Delete("/achievements/:reference_id", achievementService.DeleteAchievement)
+	app.Delete("/achievements/:id", achievementService.DeleteAchievement)
 
 	// Setup mock expectations
 	mockRepo.On("GetStudentByUserID", userID).Return(student, nil)
 	mockRepo.On("GetAchievementReferenceByID", referenceID).Return(achievementRef, nil)
-	mockRepo.On("DeleteAchievement", referenceID, mongoID).Return(nil)
+	mockRepo.On("DeleteAchievement", referenceID, mongoID, userID).Return(nil)
 
 	// Act
 	req := helper.CreateJSONRequest("DELETE", "/achievements/"+referenceID.String(), nil)
@@ -225,7 +234,7 @@ func TestAchievementService_DeleteAchievement_Success(t *testing.T) {
 func TestAchievementService_DeleteAchievement_WrongStatus(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockAchievementRepository)
-	achievementService := service.NewAchievementService(mockRepo)
+	achievementService := service.NewAchievementService(mockRepo, nil, storage.Policy{}, nil)
 	helper := helpers.NewTestHelper(t)
 	fixtures := fixtures.NewAchievementFixtures()
 
@@ -241,7 +250,7 @@ func TestAchievementService_DeleteAchievement_WrongStatus(t *testing.T) {
 
 	app := helper.CreateFiberApp()
 	app.Use(helper.CreateMiddleware(userID, "student123", "student@example.com", "student"))
-	app.Delete("/achievements/:reference_id", achievementService.DeleteAchievement)
+	app.Delete("/achievements/:id", achievementService.DeleteAchievement)
 
 	// Setup mock expectations
 	mockRepo.On("GetStudentByUserID", userID).Return(student, nil)
@@ -262,30 +271,43 @@ func TestAchievementService_DeleteAchievement_WrongStatus(t *testing.T) {
 func TestAchievementService_GetAchievementDetail_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(mocks.MockAchievementRepository)
-	achievementService := service.NewAchievementService(mockRepo)
+	achievementService := service.NewAchievementService(mockRepo, nil, storage.Policy{}, nil)
 	helper := helpers.NewTestHelper(t)
+	fixtures := fixtures.NewAchievementFixtures()
 
 	userID := helper.GenerateUUID()
-	achievementID := "507f1f77bcf86cd799439011"
+	student := fixtures.ValidStudent()
+	student.UserID = userID
+
+	achievement := fixtures.ValidAchievement()
+	achievement.StudentID = student.ID
+	achievementID := achievement.ID.Hex()
 
 	app := helper.CreateFiberApp()
 	app.Use(helper.CreateMiddleware(userID, "student123", "student@example.com", "student"))
 	app.Get("/achievements/:id", achievementService.GetAchievementDetail)
 
+	// Setup mock expectations
+	mockRepo.On("GetAchievementByID", achievementID).Return(achievement, nil)
+	mockRepo.On("GetStudentByUserID", userID).Return(student, nil)
+
 	// Act
 	req := helper.CreateJSONRequest("GET", "/achievements/"+achievementID, nil)
 	resp, err := app.Test(req)
 
 	// Assert
 	assert.NoError(t, err)
-	// This would be 200 if the method was fully implemented
-	// For now, it might return a "coming soon" message
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("ETag"))
+
+	// Verify mock expectations
+	mockRepo.AssertExpectations(t)
 }
 
 // Benchmark tests
 func BenchmarkAchievementService_SubmitAchievement(b *testing.B) {
 	mockRepo := new(mocks.MockAchievementRepository)
-	achievementService := service.NewAchievementService(mockRepo)
+	achievementService := service.NewAchievementService(mockRepo, nil, storage.Policy{}, nil)
 	fixtures := fixtures.NewAchievementFixtures()
 
 	student := fixtures.ValidStudent()
@@ -293,7 +315,7 @@ func BenchmarkAchievementService_SubmitAchievement(b *testing.B) {
 	expectedResponse := fixtures.ValidSubmitAchievementResponse()
 
 	mockRepo.On("GetStudentByUserID", mock.Anything).Return(student, nil)
-	mockRepo.On("SubmitAchievement", mock.Anything, mock.Anything).Return(expectedResponse, nil)
+	mockRepo.On("SubmitAchievement", mock.Anything, mock.Anything, mock.Anything).Return(expectedResponse, nil)
 
 	app := fiber.New()
 	app.Post("/achievements", achievementService.SubmitAchievement)
@@ -0,0 +1,509 @@
+package userimport
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Role yang bisa dibuat lewat import, sesuai nama role bawaan di seed
+const (
+	RoleStudent  = "student"
+	RoleLecturer = "lecturer"
+)
+
+var roleAliases = map[string]string{
+	"student":   RoleStudent,
+	"mahasiswa": RoleStudent,
+	"lecturer":  RoleLecturer,
+	"dosen":     RoleLecturer,
+}
+
+// Status baris di laporan import
+const (
+	StatusValid   = "valid"   // lolos validasi, belum disimpan (dry-run atau ada baris lain yang error)
+	StatusCreated = "created" // sudah disimpan
+	StatusError   = "error"
+)
+
+const (
+	MinPasswordLength = 8
+	maxPasswordLength = 72 // batas bcrypt
+	defaultMaxRows    = 2000
+)
+
+// ErrTooManyRows file melebihi Importer.MaxRows
+var ErrTooManyRows = errors.New("jumlah baris melebihi batas")
+
+var academicYearPattern = regexp.MustCompile(`^(19|20)\d{2}$`)
+
+// Options pilihan import
+type Options struct {
+	DryRun bool // hanya validasi, tidak ada yang disimpan
+}
+
+// RowResult hasil validasi/import satu baris
+type RowResult struct {
+	Row      int               `json:"row"`
+	Role     string            `json:"role,omitempty"`
+	Username string            `json:"username,omitempty"`
+	Status   string            `json:"status"`
+	Errors   model.FieldErrors `json:"errors,omitempty"`
+}
+
+// Report laporan import per baris. Users, Students dan Lecturers menghitung baris yang
+// valid, yaitu yang dibuat jika Applied atau akan dibuat jika semua baris valid.
+type Report struct {
+	DryRun    bool        `json:"dry_run"`
+	Applied   bool        `json:"applied"`
+	TotalRows int         `json:"total_rows"`
+	ErrorRows int         `json:"error_rows"`
+	Users     int         `json:"users"`
+	Students  int         `json:"students"`
+	Lecturers int         `json:"lecturers"`
+	Rows      []RowResult `json:"rows"`
+}
+
+// Importer membuat user beserta profil mahasiswa/dosen dari spreadsheet. Seluruh file
+// divalidasi dulu dan hanya disimpan, dalam satu transaksi, jika tidak ada baris yang error.
+type Importer struct {
+	DB             *sql.DB
+	ProgramStudies []string // program studi yang diterima; kosong berarti bebas
+	MaxRows        int
+	PasswordCost   int // cost bcrypt
+}
+
+func NewImporter(db *sql.DB) *Importer {
+	return &Importer{
+		DB:           db,
+		MaxRows:      defaultMaxRows,
+		PasswordCost: bcrypt.DefaultCost,
+	}
+}
+
+// entry baris yang sudah dinormalisasi
+type entry struct {
+	result *RowResult
+
+	role         string
+	username     string
+	email        string
+	fullName     string
+	password     string
+	nim          string
+	programStudy string
+	academicYear string
+	advisorNIDN  string
+	nidn         string
+	department   string
+
+	advisorID    *uuid.UUID // dosen wali yang sudah ada di database
+	advisorEntry *entry     // dosen wali dari baris lain di file yang sama
+	lecturerID   uuid.UUID  // diisi saat profil dosen dibuat
+	passwordHash string
+}
+
+func (e *entry) addError(field, message string) {
+	e.result.Errors = append(e.result.Errors, model.FieldError{Field: field, Message: message})
+}
+
+// Run memvalidasi semua baris lalu, jika tidak dry-run dan tidak ada error, menyimpannya.
+// Error dikembalikan hanya untuk kegagalan sistem; kesalahan data ada di Report.
+func (im *Importer) Run(ctx context.Context, rows []Row, opts Options) (*Report, error) {
+	if im.MaxRows > 0 && len(rows) > im.MaxRows {
+		return nil, fmt.Errorf("%w: file berisi %d baris, maksimal %d", ErrTooManyRows, len(rows), im.MaxRows)
+	}
+
+	report := &Report{
+		DryRun:    opts.DryRun,
+		TotalRows: len(rows),
+		Rows:      make([]RowResult, len(rows)),
+	}
+	entries := make([]*entry, len(rows))
+	for i, row := range rows {
+		report.Rows[i] = RowResult{Row: row.Number}
+		entries[i] = im.parseRow(row, &report.Rows[i])
+	}
+
+	checkDuplicates(entries)
+	roleIDs, err := im.checkDatabase(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dosen wali dari file harus valid juga
+	for _, e := range entries {
+		if e.advisorEntry != nil && len(e.advisorEntry.result.Errors) > 0 {
+			e.addError(ColumnAdvisorNIDN, "dosen wali di baris "+strconv.Itoa(e.advisorEntry.result.Row)+" tidak valid")
+		}
+	}
+
+	for _, e := range entries {
+		if len(e.result.Errors) > 0 {
+			e.result.Status = StatusError
+			report.ErrorRows++
+			continue
+		}
+		e.result.Status = StatusValid
+		report.Users++
+		if e.role == RoleStudent {
+			report.Students++
+		} else {
+			report.Lecturers++
+		}
+	}
+
+	if report.ErrorRows > 0 || opts.DryRun || len(entries) == 0 {
+		return report, nil
+	}
+
+	failed, err := im.apply(ctx, entries, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	if failed != nil {
+		failed.result.Status = StatusError
+		report.ErrorRows = 1
+		return report, nil
+	}
+
+	for _, e := range entries {
+		e.result.Status = StatusCreated
+	}
+	report.Applied = true
+	return report, nil
+}
+
+// parseRow menormalisasi dan memvalidasi kolom satu baris
+func (im *Importer) parseRow(row Row, result *RowResult) *entry {
+	e := &entry{result: result}
+
+	text := func(column string, maxLength int, required bool) string {
+		value := row.Get(column)
+		switch {
+		case value == "" && required:
+			e.addError(column, "wajib diisi")
+		case len([]rune(value)) > maxLength:
+			e.addError(column, "maksimal "+strconv.Itoa(maxLength)+" karakter")
+		}
+		return value
+	}
+
+	role := strings.ToLower(row.Get(ColumnRole))
+	e.role = roleAliases[role]
+	if e.role == "" {
+		e.addError(ColumnRole, "harus salah satu dari: mahasiswa, dosen")
+	}
+	result.Role = e.role
+
+	e.username = text(ColumnUsername, 50, true)
+	if strings.ContainsAny(e.username, " \t") {
+		e.addError(ColumnUsername, "tidak boleh mengandung spasi")
+	}
+	result.Username = e.username
+
+	e.email = strings.ToLower(text(ColumnEmail, 100, true))
+	if e.email != "" {
+		if addr, err := mail.ParseAddress(e.email); err != nil || addr.Address != e.email {
+			e.addError(ColumnEmail, "format email tidak valid")
+		}
+	}
+
+	e.fullName = text(ColumnFullName, 100, true)
+
+	e.password = row.Get(ColumnPassword)
+	if n := len(e.password); n < MinPasswordLength || n > maxPasswordLength {
+		e.addError(ColumnPassword, fmt.Sprintf("harus %d sampai %d karakter", MinPasswordLength, maxPasswordLength))
+	}
+
+	switch e.role {
+	case RoleStudent:
+		e.nim = text(ColumnNIM, 20, true)
+		e.programStudy = im.programStudy(e, text(ColumnProgramStudy, 100, true))
+		e.academicYear = text(ColumnAcademicYear, 10, true)
+		if e.academicYear != "" && !academicYearPattern.MatchString(e.academicYear) {
+			e.addError(ColumnAcademicYear, "harus tahun empat digit, misalnya 2024")
+		}
+		e.advisorNIDN = text(ColumnAdvisorNIDN, 20, false)
+	case RoleLecturer:
+		e.nidn = text(ColumnNIDN, 20, true)
+		e.department = text(ColumnDepartment, 100, true)
+	}
+
+	return e
+}
+
+// programStudy mencocokkan program studi dengan daftar yang diterima tanpa membedakan huruf
+// besar/kecil dan mengembalikan penulisan bakunya
+func (im *Importer) programStudy(e *entry, value string) string {
+	if value == "" || len(im.ProgramStudies) == 0 {
+		return value
+	}
+	for _, programStudy := range im.ProgramStudies {
+		if strings.EqualFold(strings.Join(strings.Fields(value), " "), programStudy) {
+			return programStudy
+		}
+	}
+	e.addError(ColumnProgramStudy, "program studi tidak dikenal: "+value)
+	return value
+}
+
+// checkDuplicates menandai username, email, NIM dan NIDN yang muncul lebih dari sekali di file
+func checkDuplicates(entries []*entry) {
+	seen := map[string]map[string]int{}
+	check := func(e *entry, column, value string) {
+		if value == "" {
+			return
+		}
+		if seen[column] == nil {
+			seen[column] = map[string]int{}
+		}
+		if first, ok := seen[column][value]; ok {
+			e.addError(column, "sama dengan baris "+strconv.Itoa(first))
+			return
+		}
+		seen[column][value] = e.result.Row
+	}
+
+	for _, e := range entries {
+		check(e, ColumnUsername, e.username)
+		check(e, ColumnEmail, e.email)
+		check(e, ColumnNIM, e.nim)
+		check(e, ColumnNIDN, e.nidn)
+	}
+}
+
+// checkDatabase memeriksa data yang sudah terdaftar, mencari dosen wali berdasarkan NIDN
+// dan mengambil ID role
+func (im *Importer) checkDatabase(ctx context.Context, entries []*entry) (map[string]uuid.UUID, error) {
+	var usernames, emails, nims, nidns []string
+	fileLecturers := map[string]*entry{}
+	for _, e := range entries {
+		usernames = appendNonEmpty(usernames, e.username)
+		emails = appendNonEmpty(emails, e.email)
+		nims = appendNonEmpty(nims, e.nim)
+		nidns = appendNonEmpty(appendNonEmpty(nidns, e.nidn), e.advisorNIDN)
+		if e.role == RoleLecturer && e.nidn != "" {
+			if _, ok := fileLecturers[e.nidn]; !ok {
+				fileLecturers[e.nidn] = e
+			}
+		}
+	}
+
+	existingUsernames, err := im.existing(ctx, `SELECT username FROM users WHERE username = ANY($1)`, usernames)
+	if err != nil {
+		return nil, err
+	}
+	existingEmails, err := im.existing(ctx, `SELECT lower(email) FROM users WHERE lower(email) = ANY($1)`, emails)
+	if err != nil {
+		return nil, err
+	}
+	existingNIMs, err := im.existing(ctx, `SELECT student_id FROM students WHERE student_id = ANY($1)`, nims)
+	if err != nil {
+		return nil, err
+	}
+	lecturerIDs, err := im.lookup(ctx, `SELECT lecturer_id, id FROM lecturers WHERE lecturer_id = ANY($1)`, nidns)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if existingUsernames[e.username] {
+			e.addError(ColumnUsername, "sudah terdaftar")
+		}
+		if existingEmails[e.email] {
+			e.addError(ColumnEmail, "sudah terdaftar")
+		}
+		if existingNIMs[e.nim] {
+			e.addError(ColumnNIM, "sudah terdaftar")
+		}
+		if _, ok := lecturerIDs[e.nidn]; ok && e.nidn != "" {
+			e.addError(ColumnNIDN, "sudah terdaftar")
+		}
+
+		if e.advisorNIDN == "" {
+			continue
+		}
+		if id, ok := lecturerIDs[e.advisorNIDN]; ok {
+			e.advisorID = &id
+		} else if lecturer, ok := fileLecturers[e.advisorNIDN]; ok {
+			e.advisorEntry = lecturer
+		} else {
+			e.addError(ColumnAdvisorNIDN, "dosen dengan NIDN "+e.advisorNIDN+" tidak ditemukan")
+		}
+	}
+
+	roleIDs, err := im.lookup(ctx, `SELECT name, id FROM roles WHERE name = ANY($1)`, []string{RoleStudent, RoleLecturer})
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{RoleStudent, RoleLecturer} {
+		if _, ok := roleIDs[name]; !ok {
+			return nil, fmt.Errorf("role %q belum ada, jalankan seed terlebih dahulu", name)
+		}
+	}
+
+	return roleIDs, nil
+}
+
+// existing nilai dari values yang sudah ada menurut query (satu kolom)
+func (im *Importer) existing(ctx context.Context, query string, values []string) (map[string]bool, error) {
+	found := map[string]bool{}
+	if len(values) == 0 {
+		return found, nil
+	}
+
+	rows, err := im.DB.QueryContext(ctx, query, pq.Array(values))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		found[value] = true
+	}
+	return found, rows.Err()
+}
+
+// lookup peta kunci ke ID menurut query (kolom kunci, kolom id)
+func (im *Importer) lookup(ctx context.Context, query string, keys []string) (map[string]uuid.UUID, error) {
+	ids := map[string]uuid.UUID{}
+	if len(keys) == 0 {
+		return ids, nil
+	}
+
+	rows, err := im.DB.QueryContext(ctx, query, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var id uuid.UUID
+		if err := rows.Scan(&key, &id); err != nil {
+			return nil, err
+		}
+		ids[key] = id
+	}
+	return ids, rows.Err()
+}
+
+// apply menyimpan semua baris dalam satu transaksi: dosen lebih dulu agar bisa menjadi dosen
+// wali mahasiswa di file yang sama. Jika sebuah baris melanggar constraint unik (misalnya
+// dibuat bersamaan oleh admin lain), transaksi dibatalkan dan baris itu dikembalikan.
+func (im *Importer) apply(ctx context.Context, entries []*entry, roleIDs map[string]uuid.UUID) (*entry, error) {
+	if err := im.hashPasswords(entries); err != nil {
+		return nil, err
+	}
+
+	tx, err := im.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, role := range []string{RoleLecturer, RoleStudent} {
+		for _, e := range entries {
+			if e.role != role {
+				continue
+			}
+			if err := insertEntry(ctx, tx, e, roleIDs[role], now); err != nil {
+				var pqErr *pq.Error
+				if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+					e.addError("", "username, email, NIM atau NIDN sudah terdaftar")
+					return e, nil
+				}
+				return nil, fmt.Errorf("baris %d: %w", e.result.Row, err)
+			}
+		}
+	}
+
+	return nil, tx.Commit()
+}
+
+func insertEntry(ctx context.Context, tx *sql.Tx, e *entry, roleID uuid.UUID, now time.Time) error {
+	userID := uuid.New()
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, true, $7, $7)
+	`, userID, e.username, e.email, e.passwordHash, e.fullName, roleID, now)
+	if err != nil {
+		return err
+	}
+
+	if e.role == RoleLecturer {
+		e.lecturerID = uuid.New()
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO lecturers (id, user_id, lecturer_id, department, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, e.lecturerID, userID, e.nidn, e.department, now)
+		return err
+	}
+
+	advisorID := e.advisorID
+	if e.advisorEntry != nil {
+		advisorID = &e.advisorEntry.lecturerID
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO students (id, user_id, student_id, program_study, academic_year, advisor_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, uuid.New(), userID, e.nim, e.programStudy, e.academicYear, advisorID, now)
+	return err
+}
+
+// hashPasswords menghitung hash bcrypt secara paralel; ratusan baris dengan cost default
+// terlalu lama jika dikerjakan satu per satu
+func (im *Importer) hashPasswords(entries []*entry) error {
+	jobs := make(chan *entry)
+	errs := make(chan error, len(entries))
+	var wg sync.WaitGroup
+
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				hash, err := bcrypt.GenerateFromPassword([]byte(e.password), im.PasswordCost)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				e.passwordHash = string(hash)
+			}
+		}()
+	}
+	for _, e := range entries {
+		jobs <- e
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+func appendNonEmpty(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	return append(values, value)
+}
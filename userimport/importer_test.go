@@ -0,0 +1,213 @@
+package userimport
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestImporter(t *testing.T) (*Importer, sqlmock.Sqlmock, *sql.DB) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	importer := NewImporter(db)
+	importer.ProgramStudies = []string{"Teknik Informatika", "Sistem Informasi"}
+	importer.PasswordCost = bcrypt.MinCost
+	return importer, mock, db
+}
+
+func row(number int, values map[string]string) Row {
+	return Row{Number: number, Values: values}
+}
+
+func lecturerRow(number int, username, nidn string) Row {
+	return row(number, map[string]string{
+		ColumnRole: "dosen", ColumnUsername: username, ColumnEmail: username + "@kampus.ac.id",
+		ColumnFullName: "Dosen " + username, ColumnPassword: "rahasia123",
+		ColumnNIDN: nidn, ColumnDepartment: "Teknik Elektro",
+	})
+}
+
+func studentRow(number int, username, nim, advisorNIDN string) Row {
+	return row(number, map[string]string{
+		ColumnRole: "mahasiswa", ColumnUsername: username, ColumnEmail: username + "@kampus.ac.id",
+		ColumnFullName: "Mahasiswa " + username, ColumnPassword: "rahasia123",
+		ColumnNIM: nim, ColumnProgramStudy: "teknik  informatika", ColumnAcademicYear: "2024",
+		ColumnAdvisorNIDN: advisorNIDN,
+	})
+}
+
+// expectLookups query pengecekan data terdaftar, semuanya kosong kecuali lecturers
+func expectLookups(mock sqlmock.Sqlmock, lecturers *sqlmock.Rows) {
+	mock.ExpectQuery(`SELECT username FROM users`).WillReturnRows(sqlmock.NewRows([]string{"username"}))
+	mock.ExpectQuery(`SELECT lower\(email\) FROM users`).WillReturnRows(sqlmock.NewRows([]string{"email"}))
+	mock.ExpectQuery(`SELECT student_id FROM students`).WillReturnRows(sqlmock.NewRows([]string{"student_id"}))
+	mock.ExpectQuery(`SELECT lecturer_id, id FROM lecturers`).WillReturnRows(lecturers)
+	mock.ExpectQuery(`SELECT name, id FROM roles`).
+		WithArgs(pq.Array([]string{RoleStudent, RoleLecturer})).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "id"}).
+			AddRow(RoleStudent, uuid.New()).
+			AddRow(RoleLecturer, uuid.New()))
+}
+
+func TestImporter_DryRunValidatesWithoutWriting(t *testing.T) {
+	importer, mock, db := newTestImporter(t)
+	defer db.Close()
+
+	rows := []Row{
+		studentRow(2, "siti", "434221001", "0012345601"), // dosen wali dari baris 3
+		lecturerRow(3, "budi", "0012345601"),
+		studentRow(4, "andi", "434221002", "0099999901"), // dosen wali sudah terdaftar
+	}
+	expectLookups(mock, sqlmock.NewRows([]string{"lecturer_id", "id"}).AddRow("0099999901", uuid.New()))
+
+	// Execute
+	report, err := importer.Run(context.Background(), rows, Options{DryRun: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, report.Applied)
+	assert.Equal(t, 0, report.ErrorRows)
+	assert.Equal(t, 3, report.Users)
+	assert.Equal(t, 2, report.Students)
+	assert.Equal(t, 1, report.Lecturers)
+	for _, result := range report.Rows {
+		assert.Equal(t, StatusValid, result.Status)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImporter_ReportsRowErrors(t *testing.T) {
+	importer, mock, db := newTestImporter(t)
+	defer db.Close()
+
+	unknownProdi := studentRow(5, "rina", "434221004", "")
+	unknownProdi.Values[ColumnProgramStudy] = "Kedokteran"
+	badFields := row(6, map[string]string{
+		ColumnRole: "admin", ColumnUsername: "x y", ColumnEmail: "bukan-email",
+		ColumnFullName: "X", ColumnPassword: "pendek",
+	})
+	rows := []Row{
+		studentRow(2, "siti", "434221001", ""),
+		studentRow(3, "siti", "434221001", "0000000000"),
+		studentRow(4, "lama", "434221003", ""),
+		unknownProdi,
+		badFields,
+	}
+
+	mock.ExpectQuery(`SELECT username FROM users`).
+		WillReturnRows(sqlmock.NewRows([]string{"username"}).AddRow("lama"))
+	mock.ExpectQuery(`SELECT lower\(email\) FROM users`).WillReturnRows(sqlmock.NewRows([]string{"email"}))
+	mock.ExpectQuery(`SELECT student_id FROM students`).WillReturnRows(sqlmock.NewRows([]string{"student_id"}))
+	mock.ExpectQuery(`SELECT lecturer_id, id FROM lecturers`).WillReturnRows(sqlmock.NewRows([]string{"lecturer_id", "id"}))
+	mock.ExpectQuery(`SELECT name, id FROM roles`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "id"}).
+			AddRow(RoleStudent, uuid.New()).
+			AddRow(RoleLecturer, uuid.New()))
+
+	// Execute
+	report, err := importer.Run(context.Background(), rows, Options{})
+
+	// Assert: tidak ada yang disimpan dan setiap baris membawa alasannya
+	assert.NoError(t, err)
+	assert.False(t, report.Applied)
+	assert.Equal(t, 4, report.ErrorRows)
+	assert.Equal(t, StatusValid, report.Rows[0].Status)
+
+	assert.Equal(t, StatusError, report.Rows[1].Status)
+	assert.Contains(t, report.Rows[1].Errors.Error(), "username: sama dengan baris 2")
+	assert.Contains(t, report.Rows[1].Errors.Error(), "nim: sama dengan baris 2")
+	assert.Contains(t, report.Rows[1].Errors.Error(), "advisor_nidn: dosen dengan NIDN 0000000000 tidak ditemukan")
+
+	assert.Equal(t, "username: sudah terdaftar", report.Rows[2].Errors.Error())
+	assert.Equal(t, "program_study: program studi tidak dikenal: Kedokteran", report.Rows[3].Errors.Error())
+
+	fields := map[string]bool{}
+	for _, fe := range report.Rows[4].Errors {
+		fields[fe.Field] = true
+	}
+	assert.Equal(t, map[string]bool{ColumnRole: true, ColumnUsername: true, ColumnEmail: true, ColumnPassword: true}, fields)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImporter_AppliesInOneTransaction(t *testing.T) {
+	importer, mock, db := newTestImporter(t)
+	defer db.Close()
+
+	rows := []Row{
+		studentRow(2, "siti", "434221001", "0012345601"),
+		lecturerRow(3, "budi", "0012345601"),
+	}
+	expectLookups(mock, sqlmock.NewRows([]string{"lecturer_id", "id"}))
+
+	// Dosen dibuat lebih dulu, program studi disimpan dengan penulisan baku
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO users`).
+		WithArgs(sqlmock.AnyArg(), "budi", "budi@kampus.ac.id", sqlmock.AnyArg(), "Dosen budi", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO lecturers`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "0012345601", "Teknik Elektro", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO users`).
+		WithArgs(sqlmock.AnyArg(), "siti", "siti@kampus.ac.id", sqlmock.AnyArg(), "Mahasiswa siti", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO students`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "434221001", "Teknik Informatika", "2024", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	report, err := importer.Run(context.Background(), rows, Options{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, report.Applied)
+	assert.Equal(t, StatusCreated, report.Rows[0].Status)
+	assert.Equal(t, StatusCreated, report.Rows[1].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImporter_UniqueViolationRollsBack(t *testing.T) {
+	importer, mock, db := newTestImporter(t)
+	defer db.Close()
+
+	rows := []Row{studentRow(2, "siti", "434221001", "")}
+	mock.ExpectQuery(`SELECT username FROM users`).WillReturnRows(sqlmock.NewRows([]string{"username"}))
+	mock.ExpectQuery(`SELECT lower\(email\) FROM users`).WillReturnRows(sqlmock.NewRows([]string{"email"}))
+	mock.ExpectQuery(`SELECT student_id FROM students`).WillReturnRows(sqlmock.NewRows([]string{"student_id"}))
+	mock.ExpectQuery(`SELECT name, id FROM roles`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "id"}).
+			AddRow(RoleStudent, uuid.New()).
+			AddRow(RoleLecturer, uuid.New()))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO users`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO students`).WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	// Execute
+	report, err := importer.Run(context.Background(), rows, Options{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, report.Applied)
+	assert.Equal(t, 1, report.ErrorRows)
+	assert.Equal(t, StatusError, report.Rows[0].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImporter_TooManyRows(t *testing.T) {
+	importer, _, db := newTestImporter(t)
+	defer db.Close()
+	importer.MaxRows = 1
+
+	_, err := importer.Run(context.Background(), []Row{studentRow(2, "a", "1", ""), studentRow(3, "b", "2", "")}, Options{})
+	assert.ErrorIs(t, err, ErrTooManyRows)
+	assert.EqualError(t, err, "jumlah baris melebihi batas: file berisi 2 baris, maksimal 1")
+}
@@ -0,0 +1,375 @@
+package userimport
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Kolom spreadsheet import. Baris pertama file adalah header; urutan kolom bebas dan
+// kolom yang tidak dikenal diabaikan.
+const (
+	ColumnRole         = "role" // mahasiswa/student atau dosen/lecturer
+	ColumnUsername     = "username"
+	ColumnEmail        = "email"
+	ColumnFullName     = "full_name"
+	ColumnPassword     = "password"
+	ColumnNIM          = "nim"           // mahasiswa
+	ColumnProgramStudy = "program_study" // mahasiswa
+	ColumnAcademicYear = "academic_year" // mahasiswa, tahun angkatan
+	ColumnAdvisorNIDN  = "advisor_nidn"  // mahasiswa, opsional: NIDN dosen wali
+	ColumnNIDN         = "nidn"          // dosen
+	ColumnDepartment   = "department"    // dosen
+)
+
+// Columns urutan kolom template import
+var Columns = []string{
+	ColumnRole, ColumnUsername, ColumnEmail, ColumnFullName, ColumnPassword,
+	ColumnNIM, ColumnProgramStudy, ColumnAcademicYear, ColumnAdvisorNIDN,
+	ColumnNIDN, ColumnDepartment,
+}
+
+// requiredColumns kolom yang harus ada di header, kolom lain bergantung pada role
+var requiredColumns = []string{ColumnRole, ColumnUsername, ColumnEmail, ColumnFullName, ColumnPassword}
+
+// Nama header lain yang diterima, setelah dinormalisasi (huruf kecil, spasi jadi _)
+var columnAliases = map[string]string{
+	"peran":           ColumnRole,
+	"nama":            ColumnFullName,
+	"nama_lengkap":    ColumnFullName,
+	"student_id":      ColumnNIM,
+	"program_studi":   ColumnProgramStudy,
+	"prodi":           ColumnProgramStudy,
+	"angkatan":        ColumnAcademicYear,
+	"nidn_dosen_wali": ColumnAdvisorNIDN,
+	"dosen_wali":      ColumnAdvisorNIDN,
+	"lecturer_id":     ColumnNIDN,
+	"departemen":      ColumnDepartment,
+	"jurusan":         ColumnDepartment,
+}
+
+var (
+	ErrEmptyFile         = errors.New("file tidak berisi header")
+	ErrUnsupportedFormat = errors.New("format file harus CSV atau XLSX")
+)
+
+// Row satu baris data. Number nomor baris di file (header = baris 1) untuk laporan error.
+type Row struct {
+	Number int
+	Values map[string]string
+}
+
+// Get nilai kolom yang sudah di-trim
+func (r Row) Get(column string) string {
+	return strings.TrimSpace(r.Values[column])
+}
+
+// ReadFile membaca file CSV atau XLSX; XLSX dikenali dari signature ZIP
+func ReadFile(data []byte) ([]Row, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return ReadXLSX(data)
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil, ErrUnsupportedFormat
+	}
+	return ReadCSV(bytes.NewReader(data))
+}
+
+// ReadCSV membaca CSV berpemisah koma atau titik koma (format Excel dengan locale Indonesia).
+// BOM UTF-8 di awal file diabaikan.
+func ReadCSV(r io.Reader) ([]Row, error) {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && string(bom) == "\ufeff" {
+		br.Discard(3)
+	}
+
+	firstLine, _ := br.Peek(4096)
+	if i := bytes.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSV tidak valid: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return toRows(records)
+}
+
+// ReadXLSX membaca sheet pertama workbook XLSX
+func ReadXLSX(data []byte) ([]Row, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decodeZipXML(f, &sst); err != nil {
+			return nil, err
+		}
+		for _, item := range sst.Items {
+			shared = append(shared, item.String())
+		}
+	}
+
+	sheetFile, ok := files[firstSheetPath(files)]
+	if !ok {
+		return nil, errors.New("XLSX tidak berisi worksheet")
+	}
+	var sheet struct {
+		Rows []struct {
+			Number int `xml:"r,attr"`
+			Cells  []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeZipXML(sheetFile, &sheet); err != nil {
+		return nil, err
+	}
+
+	// Baris dan sel kosong tidak ditulis di XLSX, posisinya diambil dari atribut r
+	var records [][]string
+	for _, row := range sheet.Rows {
+		number := row.Number
+		if number == 0 {
+			number = len(records) + 1
+		}
+		for len(records) < number-1 {
+			records = append(records, nil)
+		}
+
+		var record []string
+		for _, cell := range row.Cells {
+			col := len(record)
+			if cell.Ref != "" {
+				if c, ok := columnIndex(cell.Ref); ok {
+					col = c
+				}
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("XLSX tidak valid: shared string %q di sel %s", cell.Value, cell.Ref)
+				}
+				value = shared[idx]
+			case "inlineStr":
+				value = cell.Inline.String()
+			case "", "n":
+				// Angka besar seperti NIM bisa tersimpan dalam notasi eksponen
+				if strings.ContainsAny(value, "Ee") {
+					if f, err := strconv.ParseFloat(value, 64); err == nil {
+						value = strconv.FormatFloat(f, 'f', -1, 64)
+					}
+				}
+			}
+			record[col] = value
+		}
+		records = append(records, record)
+	}
+
+	return toRows(records)
+}
+
+// xlsxText teks sel: <t> langsung atau beberapa run <r><t>
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	var b strings.Builder
+	b.WriteString(t.Text)
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+// firstSheetPath path worksheet pertama menurut workbook.xml dan relasinya
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	var workbook struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	wb, ok1 := files["xl/workbook.xml"]
+	rf, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok1 || !ok2 || decodeZipXML(wb, &workbook) != nil || decodeZipXML(rf, &rels) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+
+	for _, rel := range rels.Items {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("XLSX tidak valid (%s): %w", f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex indeks kolom (0 = A) dari referensi sel seperti "AB12"
+func columnIndex(ref string) (int, bool) {
+	index := 0
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		index = index*26 + int(c-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return index - 1, true
+}
+
+// toRows memetakan header ke nama kolom dan mengubah record menjadi Row. Baris yang
+// seluruhnya kosong dilewati.
+func toRows(records [][]string) ([]Row, error) {
+	headerIndex := -1
+	for i, record := range records {
+		if !isBlank(record) {
+			headerIndex = i
+			break
+		}
+	}
+	if headerIndex < 0 {
+		return nil, ErrEmptyFile
+	}
+
+	header := records[headerIndex]
+	columns := make([]string, len(header))
+	seen := map[string]bool{}
+	for i, name := range header {
+		column := normalizeHeader(name)
+		if alias, ok := columnAliases[column]; ok {
+			column = alias
+		}
+		if !isKnownColumn(column) {
+			continue
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("kolom %q muncul lebih dari sekali", column)
+		}
+		seen[column] = true
+		columns[i] = column
+	}
+
+	var missing []string
+	for _, column := range requiredColumns {
+		if !seen[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("kolom wajib tidak ada: %s", strings.Join(missing, ", "))
+	}
+
+	rows := []Row{}
+	for i := headerIndex + 1; i < len(records); i++ {
+		if isBlank(records[i]) {
+			continue
+		}
+		row := Row{Number: i + 1, Values: map[string]string{}}
+		for j, value := range records[i] {
+			if j < len(columns) && columns[j] != "" {
+				row.Values[columns[j]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func normalizeHeader(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
+}
+
+func isKnownColumn(column string) bool {
+	for _, c := range Columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
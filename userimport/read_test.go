@@ -0,0 +1,99 @@
+package userimport
+
+import (
+	"POJECT_UAS/export"
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCSV_HeaderAliasesAndBlankRows(t *testing.T) {
+	data := "\ufeffPeran,Username,Email,Nama Lengkap,Password,NIM,Prodi,Angkatan,Kolom Lain\n" +
+		"mahasiswa,siti,siti@kampus.ac.id,Siti Rahma,rahasia123,434221001,Teknik Informatika,2022,x\n" +
+		",,,,,,,,\n" +
+		"dosen, budi ,budi@kampus.ac.id,Dr. Budi,rahasia123,,,,\n"
+
+	rows, err := ReadFile([]byte(data))
+
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, 2, rows[0].Number)
+	assert.Equal(t, "Siti Rahma", rows[0].Get(ColumnFullName))
+	assert.Equal(t, "Teknik Informatika", rows[0].Get(ColumnProgramStudy))
+	assert.Equal(t, 4, rows[1].Number)
+	assert.Equal(t, "budi", rows[1].Get(ColumnUsername))
+}
+
+func TestReadCSV_SemicolonDelimiter(t *testing.T) {
+	data := "role;username;email;full_name;password;nidn;department\n" +
+		"dosen;budi;budi@kampus.ac.id;Budi, S.Kom.;rahasia123;0012345601;Teknik Elektro\n"
+
+	rows, err := ReadCSV(strings.NewReader(data))
+
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "Budi, S.Kom.", rows[0].Get(ColumnFullName))
+	assert.Equal(t, "0012345601", rows[0].Get(ColumnNIDN))
+}
+
+func TestReadCSV_MissingRequiredColumns(t *testing.T) {
+	_, err := ReadCSV(strings.NewReader("role,username\nmahasiswa,siti\n"))
+	assert.EqualError(t, err, "kolom wajib tidak ada: email, full_name, password")
+
+	_, err = ReadCSV(strings.NewReader("\n\n"))
+	assert.ErrorIs(t, err, ErrEmptyFile)
+}
+
+func TestReadXLSX_InlineStringsAndNumbers(t *testing.T) {
+	var buf bytes.Buffer
+	w := export.NewXLSXWriter(&buf, "Import")
+	assert.NoError(t, w.WriteRow("role", "username", "email", "full_name", "password", "nim", "", "academic_year"))
+	assert.NoError(t, w.WriteRow("mahasiswa", "siti", "siti@kampus.ac.id", "Siti & Rahma", "rahasia123", int64(434221001), "diabaikan", 2022))
+	assert.NoError(t, w.Close())
+
+	rows, err := ReadFile(buf.Bytes())
+
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "Siti & Rahma", rows[0].Get(ColumnFullName))
+	assert.Equal(t, "434221001", rows[0].Get(ColumnNIM))
+	assert.Equal(t, "2022", rows[0].Get(ColumnAcademicYear))
+}
+
+func TestReadXLSX_SharedStringsAndSparseCells(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/sharedStrings.xml": `<sst><si><t>role</t></si><si><t>username</t></si><si><t>email</t></si>` +
+			`<si><t>full_name</t></si><si><t>password</t></si><si><t>nim</t></si>` +
+			`<si><r><t>maha</t></r><r><t>siswa</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c>` +
+			`<c r="D1" t="s"><v>3</v></c><c r="E1" t="s"><v>4</v></c><c r="F1" t="s"><v>5</v></c></row>` +
+			`<row r="3"><c r="A3" t="s"><v>6</v></c><c r="F3"><v>4.34221001E8</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		f, err := zw.Create(name)
+		assert.NoError(t, err)
+		f.Write([]byte(content))
+	}
+	assert.NoError(t, zw.Close())
+
+	rows, err := ReadXLSX(buf.Bytes())
+
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, 3, rows[0].Number)
+	assert.Equal(t, "mahasiswa", rows[0].Get(ColumnRole))
+	assert.Equal(t, "", rows[0].Get(ColumnUsername))
+	assert.Equal(t, "434221001", rows[0].Get(ColumnNIM))
+}
+
+func TestReadFile_UnsupportedFormat(t *testing.T) {
+	_, err := ReadFile([]byte("%PDF-1.4\x00\x01"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
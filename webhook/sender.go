@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"POJECT_UAS/model"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+// maxResponseBody batas isi response yang disimpan di log percobaan
+const maxResponseBody = 1024
+
+// Sender mengirim satu delivery lewat HTTP POST
+type Sender struct {
+	Client    *http.Client
+	UserAgent string
+}
+
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		Client: &http.Client{
+			Timeout: timeout,
+			// Redirect tidak diikuti agar payload bertanda tangan tidak terkirim ke URL lain
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		UserAgent: "Prestasi-Webhook/1.0",
+	}
+}
+
+// Send mengirim delivery. delivered true hanya untuk response 2xx; hasilnya selalu
+// dikembalikan sebagai log percobaan, termasuk error koneksi.
+func (s *Sender) Send(ctx context.Context, delivery model.WebhookDelivery) (model.WebhookDeliveryAttempt, bool) {
+	started := time.Now()
+	attempt := model.WebhookDeliveryAttempt{Attempt: delivery.Attempts}
+
+	fail := func(err error) (model.WebhookDeliveryAttempt, bool) {
+		msg := err.Error()
+		attempt.Error = &msg
+		attempt.DurationMs = int(time.Since(started).Milliseconds())
+		return attempt, false
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fail(err)
+	}
+
+	timestamp := started.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.UserAgent)
+	req.Header.Set(HeaderID, delivery.ID.String())
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, fmt.Sprint(timestamp))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fail(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	attempt.DurationMs = int(time.Since(started).Milliseconds())
+	attempt.StatusCode = &resp.StatusCode
+	if len(body) > 0 {
+		text := string(body)
+		if !utf8.ValidString(text) {
+			text = fmt.Sprintf("<%d byte non-UTF-8>", len(body))
+		}
+		attempt.ResponseBody = &text
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := fmt.Sprintf("response %d", resp.StatusCode)
+		attempt.Error = &msg
+		return attempt, false
+	}
+
+	return attempt, true
+}
@@ -0,0 +1,69 @@
+// Package webhook mengirim event prestasi ke URL langganan dengan tanda tangan HMAC-SHA256.
+//
+// Setiap request membawa header:
+//
+//	X-Webhook-Id:        ID delivery (unik per langganan, sama di setiap retry)
+//	X-Webhook-Event:     tipe event, misalnya achievement.verified
+//	X-Webhook-Timestamp: waktu kirim (Unix detik)
+//	X-Webhook-Signature: sha256=<hex HMAC-SHA256(secret, timestamp + "." + body)>
+//
+// Penerima sebaiknya menolak timestamp yang terlalu lama untuk mencegah replay, lihat Verify.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+var (
+	ErrInvalidSignature = errors.New("webhook: signature tidak valid")
+	ErrExpiredTimestamp = errors.New("webhook: timestamp di luar toleransi")
+)
+
+// Sign tanda tangan untuk header X-Webhook-Signature
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify memeriksa header X-Webhook-Timestamp dan X-Webhook-Signature di sisi penerima.
+// tolerance 0 berarti timestamp tidak diperiksa.
+func Verify(secret, timestampHeader, signatureHeader string, body []byte, tolerance time.Duration, now time.Time) error {
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrExpiredTimestamp
+		}
+	}
+
+	if !strings.HasPrefix(signatureHeader, signaturePrefix) {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signatureHeader)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
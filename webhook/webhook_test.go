@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"POJECT_UAS/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"type":"achievement.verified"}`)
+	now := time.Unix(1760000000, 0)
+	signature := Sign("whsec_test", now.Unix(), body)
+
+	// Assert
+	assert.NoError(t, Verify("whsec_test", "1760000000", signature, body, 5*time.Minute, now))
+	assert.ErrorIs(t, Verify("whsec_other", "1760000000", signature, body, 5*time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("whsec_test", "1760000000", signature, []byte(`{}`), 5*time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("whsec_test", "1760000000", signature, body, 5*time.Minute, now.Add(time.Hour)), ErrExpiredTimestamp)
+}
+
+// memoryStore DeliveryStore in-memory untuk test worker
+type memoryStore struct {
+	pending     []model.WebhookDelivery
+	attempts    []model.WebhookDeliveryAttempt
+	delivered   []uuid.UUID
+	maxAttempts int
+}
+
+func (s *memoryStore) ClaimDue(_ context.Context, limit int) ([]model.WebhookDelivery, error) {
+	n := min(limit, len(s.pending))
+	claimed := s.pending[:n]
+	s.pending = s.pending[n:]
+	for i := range claimed {
+		claimed[i].Attempts++
+	}
+	return claimed, nil
+}
+
+func (s *memoryStore) RecordAttempt(_ context.Context, delivery model.WebhookDelivery, attempt model.WebhookDeliveryAttempt, delivered bool) (bool, error) {
+	s.attempts = append(s.attempts, attempt)
+	if delivered {
+		s.delivered = append(s.delivered, delivery.ID)
+		return true, nil
+	}
+	if delivery.Attempts >= s.maxAttempts {
+		return true, nil
+	}
+	s.pending = append(s.pending, delivery)
+	return false, nil
+}
+
+func TestWorker_RetriesUntilDelivered(t *testing.T) {
+	payload := []byte(`{"id":"e-1","type":"achievement.submitted"}`)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+
+		// Penerima memverifikasi tanda tangan
+		err := Verify("whsec_test", r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, time.Minute, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, model.WebhookAchievementSubmitted, r.Header.Get(HeaderEvent))
+		assert.JSONEq(t, string(payload), string(body))
+
+		if calls == 1 {
+			http.Error(w, "sedang maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+	}))
+	defer server.Close()
+
+	store := &memoryStore{maxAttempts: 5, pending: []model.WebhookDelivery{{
+		ID:        uuid.New(),
+		EventType: model.WebhookAchievementSubmitted,
+		Payload:   payload,
+		URL:       server.URL,
+		Secret:    "whsec_test",
+	}}}
+	worker := NewWorker(store, NewSender(time.Second), time.Second, 10)
+
+	// Execute: percobaan pertama 503, kedua 200
+	assert.Equal(t, 0, worker.DeliverDue(context.Background()))
+	assert.Equal(t, 1, worker.DeliverDue(context.Background()))
+
+	// Assert: dua percobaan tercatat di log
+	if assert.Len(t, store.attempts, 2) {
+		assert.Equal(t, http.StatusServiceUnavailable, *store.attempts[0].StatusCode)
+		assert.Equal(t, "sedang maintenance\n", *store.attempts[0].ResponseBody)
+		assert.Equal(t, 2, store.attempts[1].Attempt)
+		assert.Nil(t, store.attempts[1].Error)
+	}
+	assert.Len(t, store.delivered, 1)
+}
+
+func TestSender_ConnectionErrorIsLogged(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close() // koneksi akan ditolak
+
+	attempt, ok := NewSender(time.Second).Send(context.Background(), model.WebhookDelivery{
+		ID:       uuid.New(),
+		Attempts: 1,
+		URL:      url,
+		Payload:  []byte(`{}`),
+	})
+
+	assert.False(t, ok)
+	assert.Nil(t, attempt.StatusCode)
+	assert.NotNil(t, attempt.Error)
+}
+
+func TestSender_DoesNotFollowRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("redirect tidak boleh diikuti")
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer server.Close()
+
+	attempt, ok := NewSender(time.Second).Send(context.Background(), model.WebhookDelivery{URL: server.URL, Payload: []byte(`{}`)})
+
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusTemporaryRedirect, *attempt.StatusCode)
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"POJECT_UAS/model"
+	"context"
+	"log"
+	"time"
+)
+
+// DeliveryStore antrean delivery, diimplementasikan WebhookRepository
+type DeliveryStore interface {
+	ClaimDue(ctx context.Context, limit int) ([]model.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, delivery model.WebhookDelivery, attempt model.WebhookDeliveryAttempt, delivered bool) (bool, error)
+}
+
+// Worker mengirim delivery yang jatuh tempo setiap PollInterval
+type Worker struct {
+	Store        DeliveryStore
+	Sender       *Sender
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+func NewWorker(store DeliveryStore, sender *Sender, pollInterval time.Duration, batchSize int) *Worker {
+	return &Worker{
+		Store:        store,
+		Sender:       sender,
+		PollInterval: pollInterval,
+		BatchSize:    batchSize,
+	}
+}
+
+// Run berjalan sampai ctx dibatalkan
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.DeliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue mengirim satu batch delivery yang jatuh tempo, mengembalikan jumlah yang berhasil
+func (w *Worker) DeliverDue(ctx context.Context) int {
+	deliveries, err := w.Store.ClaimDue(ctx, w.BatchSize)
+	if err != nil {
+		log.Printf("webhook: gagal mengambil antrean: %v", err)
+		return 0
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		attempt, ok := w.Sender.Send(ctx, delivery)
+		if ok {
+			delivered++
+		}
+
+		final, err := w.Store.RecordAttempt(ctx, delivery, attempt, ok)
+		if err != nil {
+			log.Printf("webhook %s: gagal mencatat percobaan: %v", delivery.ID, err)
+			continue
+		}
+		if !ok && final {
+			log.Printf("webhook %s (%s) ke %s gagal permanen setelah %d percobaan", delivery.ID, delivery.EventType, delivery.URL, delivery.Attempts)
+		}
+	}
+
+	return delivered
+}